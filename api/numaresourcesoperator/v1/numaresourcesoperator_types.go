@@ -45,8 +45,25 @@ type NUMAResourcesOperatorSpec struct {
 	// +optional
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Optional ignore pod namespace/name glob patterns"
 	PodExcludes []NamespacedName `json:"podExcludes,omitempty"`
+	// DriftPolicy sets what the operator does with the owned objects the periodic drift scan finds
+	// differing from their desired state. Valid values are: "Report", "Correct".
+	// Defaults to "Report".
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Policy for the owned objects drifted from their desired state"
+	DriftPolicy *DriftPolicy `json:"driftPolicy,omitempty"`
 }
 
+// +kubebuilder:validation:Enum=Report;Correct
+type DriftPolicy string
+
+const (
+	// DriftPolicyReport reports the drifted objects in the status. It is the default.
+	DriftPolicyReport DriftPolicy = "Report"
+
+	// DriftPolicyCorrect reports the drifted objects in the status, and applies again their desired state.
+	DriftPolicyCorrect DriftPolicy = "Correct"
+)
+
 // +kubebuilder:validation:Enum=Disabled;Enabled;EnabledExclusiveResources
 type PodsFingerprintingMode string
 
@@ -163,6 +180,82 @@ type NUMAResourcesOperatorStatus struct {
 	// RelatedObjects list of objects of interest for this operator
 	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Related Objects"
 	RelatedObjects []configv1.ObjectReference `json:"relatedObjects,omitempty"`
+	// RenderedConfigs report the status of the RTE configuration rendered for each node group, matching by pool name
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="RTE configurations rendered from kubelet configurations"
+	RenderedConfigs []RenderedConfigStatus `json:"renderedConfigs,omitempty"`
+	// DriftedObjects report the owned objects the latest drift scan found differing from their desired state
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Owned objects drifted from their desired state"
+	DriftedObjects []DriftedObject `json:"driftedObjects,omitempty"`
+}
+
+// DriftedObject reports an owned object which differs from its desired state
+type DriftedObject struct {
+	// Kind of the drifted object
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Kind of the drifted object"
+	Kind string `json:"kind"`
+	// Namespace of the drifted object, empty if the object is cluster-scoped
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Namespace of the drifted object"
+	Namespace string `json:"namespace,omitempty"`
+	// Name of the drifted object
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Name of the drifted object"
+	Name string `json:"name"`
+	// Fields summarizes the paths of the fields which differ, like "metadata.annotations" or "spec.template.spec"
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Drifted fields"
+	Fields []string `json:"fields,omitempty"`
+	// Corrected is true if the desired state of the object was applied again, as per the drift policy
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Drift corrected"
+	Corrected bool `json:"corrected,omitempty"`
+}
+
+// RenderedConfigStatus reports the status of the RTE configuration rendered for a node group out of the KubeletConfig
+// targeting the nodes of the node group. The fields describing the rendered configuration (ConfigMap, Hash, LastRenderTime)
+// always refer to the last configuration successfully rendered. If the latest processing of the source KubeletConfig failed,
+// the reason is reported in Error, and the previously rendered configuration, if any, is kept.
+type RenderedConfigStatus struct {
+	// PoolName represents the pool name to which the nodes belong that the configuration is rendered for
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Pool name of nodes the configuration is rendered for"
+	PoolName string `json:"poolName"`
+	// KubeletConfig is the source object the RTE configuration is rendered from. If more KubeletConfigs
+	// target the same pool, this is the one taking precedence as per the MachineConfigOperator ordering rules.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Source KubeletConfig"
+	KubeletConfig NamespacedName `json:"kubeletConfig,omitempty"`
+	// ConfigMap holding the rendered RTE configuration
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Rendered RTE ConfigMap"
+	ConfigMap NamespacedName `json:"configMap,omitempty"`
+	// Hash of the rendered RTE configuration
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Rendered RTE configuration hash"
+	Hash string `json:"hash,omitempty"`
+	// LastRenderTime is the last time the RTE configuration was successfully rendered
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Last render time"
+	LastRenderTime *metav1.Time `json:"lastRenderTime,omitempty"`
+	// Error reports why the latest version of the source object could not be processed, if it could not
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Processing error"
+	Error string `json:"error,omitempty"`
+	// Conflicts lists the kubelet settings which are not honored because more KubeletConfigs target the same pool
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Conflicting kubelet settings"
+	Conflicts []KubeletConfigConflict `json:"conflicts,omitempty"`
+}
+
+// KubeletConfigConflict reports a kubelet setting which is not honored because another KubeletConfig
+// targeting the same pool takes precedence.
+type KubeletConfigConflict struct {
+	// Field is the name of the setting in the kubelet configuration
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Kubelet setting"
+	Field string `json:"field"`
+	// KubeletConfig is the object whose setting is not honored
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Overridden KubeletConfig"
+	KubeletConfig NamespacedName `json:"kubeletConfig"`
 }
 
 // MachineConfigPool defines the observed state of each MachineConfigPool selected by node groups
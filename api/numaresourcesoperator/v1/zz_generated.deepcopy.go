@@ -28,6 +28,42 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftedObject) DeepCopyInto(out *DriftedObject) {
+	*out = *in
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftedObject.
+func (in *DriftedObject) DeepCopy() *DriftedObject {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftedObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeletConfigConflict) DeepCopyInto(out *KubeletConfigConflict) {
+	*out = *in
+	out.KubeletConfig = in.KubeletConfig
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeletConfigConflict.
+func (in *KubeletConfigConflict) DeepCopy() *KubeletConfigConflict {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeletConfigConflict)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineConfigPool) DeepCopyInto(out *MachineConfigPool) {
 	*out = *in
@@ -129,6 +165,11 @@ func (in *NUMAResourcesOperatorSpec) DeepCopyInto(out *NUMAResourcesOperatorSpec
 		*out = make([]NamespacedName, len(*in))
 		copy(*out, *in)
 	}
+	if in.DriftPolicy != nil {
+		in, out := &in.DriftPolicy, &out.DriftPolicy
+		*out = new(DriftPolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NUMAResourcesOperatorSpec.
@@ -175,6 +216,20 @@ func (in *NUMAResourcesOperatorStatus) DeepCopyInto(out *NUMAResourcesOperatorSt
 		*out = make([]configv1.ObjectReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.RenderedConfigs != nil {
+		in, out := &in.RenderedConfigs, &out.RenderedConfigs
+		*out = make([]RenderedConfigStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DriftedObjects != nil {
+		in, out := &in.DriftedObjects, &out.DriftedObjects
+		*out = make([]DriftedObject, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NUMAResourcesOperatorStatus.
@@ -428,6 +483,32 @@ func (in *NodeGroupStatus) DeepCopy() *NodeGroupStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenderedConfigStatus) DeepCopyInto(out *RenderedConfigStatus) {
+	*out = *in
+	out.KubeletConfig = in.KubeletConfig
+	out.ConfigMap = in.ConfigMap
+	if in.LastRenderTime != nil {
+		in, out := &in.LastRenderTime, &out.LastRenderTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conflicts != nil {
+		in, out := &in.Conflicts, &out.Conflicts
+		*out = make([]KubeletConfigConflict, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenderedConfigStatus.
+func (in *RenderedConfigStatus) DeepCopy() *RenderedConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RenderedConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceSpecParams) DeepCopyInto(out *ResourceSpecParams) {
 	*out = *in
@@ -0,0 +1,47 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/openshift-kni/numaresources-operator/pkg/apply"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectstate"
+)
+
+// applyObject applies the object state, server side if requested. The fields the operator takes over
+// from other managers are reported as events on the owner, if a recorder is given.
+func applyObject(ctx context.Context, cli client.Client, serverSide bool, recorder record.EventRecorder, owner client.Object, objState objectstate.ObjectState) (client.Object, bool, error) {
+	if !serverSide {
+		return apply.ApplyObject(ctx, cli, objState)
+	}
+	return apply.ApplyObjectServerSide(ctx, cli, objState, func(obj client.Object, err error) {
+		if recorder == nil {
+			return
+		}
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		if gvk, err := apiutil.GVKForObject(obj, cli.Scheme()); err == nil {
+			kind = gvk.Kind
+		}
+		recorder.Eventf(owner, corev1.EventTypeWarning, "FieldConflict", "Took over the fields of %s %s/%s owned by other managers: %v", kind, obj.GetNamespace(), obj.GetName(), err)
+	})
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	testobjs "github.com/openshift-kni/numaresources-operator/internal/objects"
+	"github.com/openshift-kni/numaresources-operator/pkg/apply"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
+	cfgstate "github.com/openshift-kni/numaresources-operator/pkg/objectstate/cfg"
+)
+
+var _ = Describe("Test applying objects", func() {
+	var owner *nropv1.NUMAResourcesOperator
+	var cm *corev1.ConfigMap
+	var recorder *record.FakeRecorder
+	var patches []client.PatchOptions
+
+	// the fake client does not support server-side apply, so we emulate it, failing the non-forced patches
+	conflictingApply := func(ctx context.Context, cli client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+		if patch.Type() != types.ApplyPatchType {
+			return cli.Patch(ctx, obj, patch, opts...)
+		}
+		po := client.PatchOptions{}
+		po.ApplyOptions(opts)
+		patches = append(patches, po)
+		if po.Force == nil || !*po.Force {
+			return apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, obj.GetName(), nil)
+		}
+		return cli.Create(ctx, obj)
+	}
+
+	BeforeEach(func() {
+		owner = testobjs.NewNUMAResourcesOperator(objectnames.DefaultNUMAResourcesOperatorCrName)
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: testNamespace,
+				Name:      "test-config",
+			},
+			Data: map[string]string{
+				"config.yaml": "foo: bar",
+			},
+		}
+		recorder = record.NewFakeRecorder(bufferSize)
+		patches = nil
+	})
+
+	It("should report the conflicting fields as events on the owner", func(ctx context.Context) {
+		cli := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Patch: conflictingApply,
+		}).Build()
+
+		existing := cfgstate.FromClient(ctx, cli, cm.Namespace, cm.Name)
+		for _, objState := range existing.State(cfgstate.Manifests{Config: cm}) {
+			_, updated, err := applyObject(ctx, cli, true, recorder, owner, objState)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(updated).To(BeTrue())
+		}
+
+		Expect(patches).To(HaveLen(2))
+		for _, po := range patches {
+			Expect(po.FieldManager).To(Equal(apply.FieldManager))
+		}
+		Expect(recorder.Events).To(Receive(ContainSubstring("FieldConflict")))
+
+		got := &corev1.ConfigMap{}
+		Expect(cli.Get(ctx, client.ObjectKeyFromObject(cm), got)).To(Succeed())
+		Expect(got.Data).To(Equal(cm.Data))
+	})
+
+	It("should update the objects client side unless server-side apply is requested", func(ctx context.Context) {
+		cli := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Patch: conflictingApply,
+		}).Build()
+
+		existing := cfgstate.FromClient(ctx, cli, cm.Namespace, cm.Name)
+		for _, objState := range existing.State(cfgstate.Manifests{Config: cm}) {
+			_, _, err := applyObject(ctx, cli, false, recorder, owner, objState)
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		Expect(patches).To(BeEmpty())
+		Expect(recorder.Events).ToNot(Receive())
+
+		got := &corev1.ConfigMap{}
+		Expect(cli.Get(ctx, client.ObjectKeyFromObject(cm), got)).To(Succeed())
+	})
+})
@@ -0,0 +1,291 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
+	mcov1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	nodegroupv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1/helper/nodegroup"
+	"github.com/openshift-kni/numaresources-operator/internal/dangling"
+	"github.com/openshift-kni/numaresources-operator/pkg/apply"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectstate"
+	apistate "github.com/openshift-kni/numaresources-operator/pkg/objectstate/api"
+	cfgstate "github.com/openshift-kni/numaresources-operator/pkg/objectstate/cfg"
+	rtestate "github.com/openshift-kni/numaresources-operator/pkg/objectstate/rte"
+)
+
+// DryRunPlanKey is the key of the dry-run plan ConfigMap holding the JSON list of the changes
+// the reconciliation would make.
+const DryRunPlanKey = "plan.json"
+
+func (r *NUMAResourcesOperatorReconciler) reconcileDryRun(ctx context.Context, instance *nropv1.NUMAResourcesOperator, trees []nodegroupv1.Tree) (ctrl.Result, error) {
+	changes, err := r.planResources(ctx, instance, trees)
+	if err != nil {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "FailedDryRun", "Failed to compute the reconciliation plan: %v", err)
+		return ctrl.Result{}, err
+	}
+	cm, err := publishPlan(ctx, r.Client, r.Scheme, instance, r.Namespace, changes)
+	if err != nil {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "FailedDryRun", "Failed to publish the reconciliation plan: %v", err)
+		return ctrl.Result{}, err
+	}
+	r.Recorder.Eventf(instance, corev1.EventTypeNormal, "DryRun", "Published the reconciliation plan of %d changes in configmap %s/%s", len(changes), cm.Namespace, cm.Name)
+	return ctrl.Result{}, nil
+}
+
+// planResources computes the changes the reconciliation of the instance would make, without applying them.
+// The dangling objects the reconciliation would delete are part of the plan.
+func (r *NUMAResourcesOperatorReconciler) planResources(ctx context.Context, instance *nropv1.NUMAResourcesOperator, trees []nodegroupv1.Tree) ([]apply.Change, error) {
+	existingAPI := apistate.FromClient(ctx, r.Client, r.Platform, r.APIManifests)
+	objStates := existingAPI.State(r.APIManifests)
+
+	ownedStates, err := r.ownedObjectStates(ctx, instance, trees)
+	if err != nil {
+		return nil, err
+	}
+	objStates = append(objStates, ownedStates...)
+
+	var unused []client.Object
+	if r.Platform == platform.OpenShift {
+		machineConfigs, err := dangling.MachineConfigs(r.Client, ctx, instance, trees)
+		if err != nil {
+			return nil, err
+		}
+		for idx := range machineConfigs {
+			unused = append(unused, &machineConfigs[idx])
+		}
+	}
+
+	daemonSets, err := dangling.DaemonSets(r.Client, ctx, instance, trees)
+	if err != nil {
+		return nil, err
+	}
+	for idx := range daemonSets {
+		unused = append(unused, &daemonSets[idx])
+	}
+
+	return planChanges(r.Scheme, objStates, unused)
+}
+
+// ownedObjectStates computes the state of the objects the instance owns: the machine configs, the RTE objects
+// and the RTE configurations. The desired objects are controlled by the instance, like the reconciliation loops do.
+func (r *NUMAResourcesOperatorReconciler) ownedObjectStates(ctx context.Context, instance *nropv1.NUMAResourcesOperator, trees []nodegroupv1.Tree) ([]objectstate.ObjectState, error) {
+	var ownedStates []objectstate.ObjectState
+	if r.Platform == platform.OpenShift {
+		existing := rtestate.FromClient(ctx, r.Client, r.Platform, r.RTEManifests, instance, trees, r.Namespace)
+		mcStates, _ := existing.MachineConfigsState(r.RTEManifests)
+		for _, objState := range mcStates {
+			if !objState.IsCreateOrUpdate() {
+				continue
+			}
+			if err := validateMachineConfigLabels(objState.Desired, trees); err != nil {
+				return nil, err
+			}
+		}
+		ownedStates = append(ownedStates, mcStates...)
+	}
+
+	cfgStates, err := r.rteConfigStates(ctx, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	// the daemonsets must track the RTE configurations the kubeletconfig controller would apply, like they will
+	// once it does, so the rollouts the changed configurations trigger are part of the plan
+	rteStates, _, err := r.rteObjectStates(ctx, instance, trees, r.renderedConfigMap(desiredConfigMaps(cfgStates)))
+	if err != nil {
+		return nil, err
+	}
+	ownedStates = append(ownedStates, rteStates...)
+	if err := setControllerReferences(instance, ownedStates, r.Scheme); err != nil {
+		return nil, err
+	}
+	return append(ownedStates, cfgStates...), nil
+}
+
+func desiredConfigMaps(objStates []objectstate.ObjectState) []*corev1.ConfigMap {
+	var ret []*corev1.ConfigMap
+	for _, objState := range objStates {
+		if !objState.IsCreateOrUpdate() {
+			continue
+		}
+		if cm, ok := objState.Desired.(*corev1.ConfigMap); ok {
+			ret = append(ret, cm)
+		}
+	}
+	return ret
+}
+
+// rteConfigStates computes the state of the RTE configurations the kubeletconfig controller
+// would render out of the kubelet configuration objects.
+func (r *NUMAResourcesOperatorReconciler) rteConfigStates(ctx context.Context, instance *nropv1.NUMAResourcesOperator) ([]objectstate.ObjectState, error) {
+	var kcKeys []client.ObjectKey
+	switch r.Platform {
+	case platform.OpenShift:
+		kcList := mcov1.KubeletConfigList{}
+		if err := r.Client.List(ctx, &kcList); err != nil {
+			return nil, err
+		}
+		for idx := range kcList.Items {
+			kcKeys = append(kcKeys, client.ObjectKeyFromObject(&kcList.Items[idx]))
+		}
+	case platform.HyperShift:
+		cmList := corev1.ConfigMapList{}
+		if err := r.Client.List(ctx, &cmList, client.HasLabels{HypershiftKubeletConfigConfigMapLabel}); err != nil {
+			return nil, err
+		}
+		for idx := range cmList.Items {
+			kcKeys = append(kcKeys, client.ObjectKeyFromObject(&cmList.Items[idx]))
+		}
+	}
+
+	kcr := &KubeletConfigReconciler{
+		Client:    r.Client,
+		Scheme:    r.Scheme,
+		Namespace: r.Namespace,
+		Platform:  r.Platform,
+	}
+	var objStates []objectstate.ObjectState
+	poolNames := sets.New[string]()
+	for _, kcKey := range kcKeys {
+		kcHandler, kubeletConfig, err := kcr.effectiveKubeletConfig(ctx, instance, kcKey)
+		if err != nil {
+			return nil, err
+		}
+		// all the kubelet configurations targeting a pool render the same RTE configuration
+		if kcHandler == nil || poolNames.Has(kcHandler.poolName) {
+			continue
+		}
+		poolNames.Insert(kcHandler.poolName)
+
+		_, cfgStates, err := kcr.configMapStates(ctx, kubeletConfig, instance, kcHandler)
+		if err != nil {
+			return nil, err
+		}
+		objStates = append(objStates, cfgStates...)
+	}
+	return objStates, nil
+}
+
+func (r *NUMAResourcesSchedulerReconciler) reconcileDryRun(ctx context.Context, instance *nropv1.NUMAResourcesScheduler) (ctrl.Result, error) {
+	changes, err := r.planResources(ctx, instance)
+	if err != nil {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "FailedDryRun", "Failed to compute the reconciliation plan: %v", err)
+		return ctrl.Result{}, err
+	}
+	cm, err := publishPlan(ctx, r.Client, r.Scheme, instance, r.Namespace, changes)
+	if err != nil {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "FailedDryRun", "Failed to publish the reconciliation plan: %v", err)
+		return ctrl.Result{}, err
+	}
+	r.Recorder.Eventf(instance, corev1.EventTypeNormal, "DryRun", "Published the reconciliation plan of %d changes in configmap %s/%s", len(changes), cm.Namespace, cm.Name)
+	return ctrl.Result{}, nil
+}
+
+// planResources computes the changes the reconciliation of the instance would make, without applying them.
+func (r *NUMAResourcesSchedulerReconciler) planResources(ctx context.Context, instance *nropv1.NUMAResourcesScheduler) ([]apply.Change, error) {
+	_, objStates, err := r.schedulerObjectStates(ctx, instance)
+	if err != nil {
+		return nil, err
+	}
+	if err := setControllerReferences(instance, objStates, r.Scheme); err != nil {
+		return nil, err
+	}
+	return planChanges(r.Scheme, objStates, nil)
+}
+
+// setControllerReferences makes the owner the controller of the objects to create or update,
+// like the reconciliation loops do, so the plan does not report the owner references as changes.
+func setControllerReferences(owner client.Object, objStates []objectstate.ObjectState, scheme *runtime.Scheme) error {
+	for _, objState := range objStates {
+		if !objState.IsCreateOrUpdate() {
+			continue
+		}
+		if err := controllerutil.SetControllerReference(owner, objState.Desired, scheme); err != nil {
+			return fmt.Errorf("failed to set controller reference to %s %s: %w", objState.Desired.GetNamespace(), objState.Desired.GetName(), err)
+		}
+	}
+	return nil
+}
+
+func planChanges(scheme *runtime.Scheme, objStates []objectstate.ObjectState, unused []client.Object) ([]apply.Change, error) {
+	changes := []apply.Change{}
+	for _, objState := range objStates {
+		change, err := apply.PlanState(scheme, objState)
+		if err != nil {
+			return nil, err
+		}
+		if change.Action == apply.ActionNone {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	for _, obj := range unused {
+		change, err := apply.PlanDelete(scheme, obj)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// publishPlan stores the changes in the dry-run plan ConfigMap of the owner, in the given namespace.
+func publishPlan(ctx context.Context, cli client.Client, scheme *runtime.Scheme, owner client.Object, namespace string, changes []apply.Change) (*corev1.ConfigMap, error) {
+	data, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      objectnames.GetDryRunPlanName(owner.GetName()),
+			Namespace: namespace,
+		},
+		Data: map[string]string{
+			DryRunPlanKey: string(data),
+		},
+	}
+	if err := controllerutil.SetControllerReference(owner, cm, scheme); err != nil {
+		return nil, fmt.Errorf("failed to set controller reference to %s %s: %w", cm.Namespace, cm.Name, err)
+	}
+	existing := cfgstate.FromClient(ctx, cli, cm.Namespace, cm.Name)
+	for _, objState := range existing.State(cfgstate.Manifests{Config: cm}) {
+		if _, _, err := apply.ApplyObject(ctx, cli, objState); err != nil {
+			return nil, fmt.Errorf("could not apply %s/%s: %w", cm.Namespace, cm.Name, err)
+		}
+	}
+	return cm, nil
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeletconfigv1beta1 "k8s.io/kubelet/config/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	"github.com/openshift-kni/numaresources-operator/internal/api/annotations"
+	testobjs "github.com/openshift-kni/numaresources-operator/internal/objects"
+	"github.com/openshift-kni/numaresources-operator/pkg/apply"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
+)
+
+var _ = Describe("Test dry-run plan", func() {
+	It("should plan the rollout of the daemonsets tracking the changed RTE configurations", func() {
+		label := map[string]string{
+			"test": "test",
+		}
+		mcp := testobjs.NewMachineConfigPool("test", label, &metav1.LabelSelector{MatchLabels: label}, &metav1.LabelSelector{MatchLabels: label})
+		nro := testobjs.NewNUMAResourcesOperator(objectnames.DefaultNUMAResourcesOperatorCrName, nropv1.NodeGroup{
+			MachineConfigPoolSelector: &metav1.LabelSelector{MatchLabels: label},
+		})
+		reconciler, err := NewFakeNUMAResourcesOperatorReconciler(platform.OpenShift, defaultOCPVersion, nro, mcp)
+		Expect(err).ToNot(HaveOccurred())
+		key := client.ObjectKey{
+			Namespace: testNamespace,
+			Name:      objectnames.GetComponentName(nro.Name, mcp.Name),
+		}
+		reconciler.RTEManifests.ConfigMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: key.Namespace,
+				Name:      key.Name,
+			},
+		}
+
+		nroKey := client.ObjectKeyFromObject(nro)
+		_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: nroKey})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reconciler.Client.Get(context.TODO(), key, &appsv1.DaemonSet{})).To(Succeed())
+
+		By("adding a kubelet configuration the RTE configuration is rendered from")
+		kc := testobjs.NewKubeletConfig("test", label, mcp.Spec.MachineConfigSelector, &kubeletconfigv1beta1.KubeletConfiguration{
+			TopologyManagerPolicy: "single-numa-node",
+		})
+		Expect(reconciler.Client.Create(context.TODO(), kc)).To(Succeed())
+
+		updated := &nropv1.NUMAResourcesOperator{}
+		Expect(reconciler.Client.Get(context.TODO(), nroKey, updated)).To(Succeed())
+		updated.Annotations = map[string]string{
+			annotations.DryRunAnnotation: annotations.DryRunAnnotationEnabled,
+		}
+		Expect(reconciler.Client.Update(context.TODO(), updated)).To(Succeed())
+		_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: nroKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		changes := getDryRunPlan(reconciler.Client, nro.Name)
+		Expect(changes).To(ContainElement(SatisfyAll(
+			HaveField("Action", apply.ActionCreate),
+			HaveField("Kind", corev1.SchemeGroupVersion.WithKind("ConfigMap").String()),
+			HaveField("Name", key.Name),
+		)))
+		Expect(changes).To(ContainElement(SatisfyAll(
+			HaveField("Action", apply.ActionUpdate),
+			HaveField("Kind", appsv1.SchemeGroupVersion.WithKind("DaemonSet").String()),
+			HaveField("Name", key.Name),
+		)))
+	})
+})
@@ -21,9 +21,12 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -32,6 +35,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	kubeletconfigv1beta1 "k8s.io/kubelet/config/v1beta1"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -43,10 +47,12 @@ import (
 
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
 	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	"github.com/openshift-kni/numaresources-operator/internal/api/annotations"
 	"github.com/openshift-kni/numaresources-operator/internal/machineconfigpools"
-	"github.com/openshift-kni/numaresources-operator/pkg/apply"
+	"github.com/openshift-kni/numaresources-operator/pkg/hash"
 	"github.com/openshift-kni/numaresources-operator/pkg/kubeletconfig"
 	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectstate"
 	cfgstate "github.com/openshift-kni/numaresources-operator/pkg/objectstate/cfg"
 	rteconfig "github.com/openshift-kni/numaresources-operator/rte/pkg/config"
 )
@@ -68,23 +74,60 @@ type KubeletConfigReconciler struct {
 	Recorder  record.EventRecorder
 	Namespace string
 	Platform  platform.Platform
+	// ServerSideApply enables the server-side apply of the RTE configuration
+	ServerSideApply bool
+	// deletedLock protects the lists of deleted objects, which the event handlers
+	// fill concurrently with the reconciliation.
+	deletedLock sync.Mutex
 	// garbageCollectionFor is a list of objects
 	// that their dependent needs to be removed from the cluster.
 	garbageCollectionFor []*corev1.ConfigMap
+	// deletedKubeletConfigs is a list of objects
+	// whose pool needs to be rendered again out of the KubeletConfigs left.
+	deletedKubeletConfigs []*mcov1.KubeletConfig
 }
 
 type kubeletConfigHandler struct {
 	ownerObject client.Object
 	mcoKc       *mcov1.KubeletConfig
 	// mcp or nodePool name
-	poolName   string
-	setCtrlRef func(owner, controlled metav1.Object, scheme *runtime.Scheme, opts ...controllerutil.OwnerReferenceOption) error
+	poolName    string
+	setCtrlRef  func(owner, controlled metav1.Object, scheme *runtime.Scheme, opts ...controllerutil.OwnerReferenceOption) error
+	setOwnerRef func(owner, object metav1.Object, scheme *runtime.Scheme, opts ...controllerutil.OwnerReferenceOption) error
+	// sources are all the kubelet configurations targeting the pool
+	sources   []kubeletConfigSource
+	conflicts []kubeletconfig.Conflict
+}
+
+type kubeletConfigSource struct {
+	ownerObject client.Object
+	mcoKc       *mcov1.KubeletConfig
+}
+
+// selectEffective sets the kubelet configuration to render, and its owner, to the one
+// taking precedence among all the sources, as per the MCO ordering rules.
+func (h *kubeletConfigHandler) selectEffective() error {
+	mcoKcs := make([]*mcov1.KubeletConfig, 0, len(h.sources))
+	owners := make(map[*mcov1.KubeletConfig]client.Object, len(h.sources))
+	for _, src := range h.sources {
+		mcoKcs = append(mcoKcs, src.mcoKc)
+		owners[src.mcoKc] = src.ownerObject
+	}
+	effective, conflicts, err := kubeletconfig.EffectiveForPool(h.poolName, mcoKcs)
+	if err != nil {
+		return err
+	}
+	h.mcoKc = effective
+	h.ownerObject = owners[effective]
+	h.conflicts = conflicts
+	return nil
 }
 
 //+kubebuilder:rbac:groups="",resources=configmaps,verbs=*
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=machineconfiguration.openshift.io,resources=kubeletconfigs,verbs=get;list;watch
 //+kubebuilder:rbac:groups=machineconfiguration.openshift.io,resources=kubeletconfigs/finalizers,verbs=update
+//+kubebuilder:rbac:groups=nodetopology.openshift.io,resources=numaresourcesoperators/status,verbs=get;update;patch
 
 func (r *KubeletConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	klog.V(3).InfoS("Starting KubeletConfig reconcile loop", "object", req.NamespacedName)
@@ -105,6 +148,12 @@ func (r *KubeletConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, err
 	}
 
+	// the RTE configuration is part of the plan the NUMAResourcesOperator reconciler publishes
+	if annotations.IsDryRunEnabled(instance.Annotations) {
+		klog.V(2).InfoS("Dry run enabled, skipping", "object", req.NamespacedName)
+		return ctrl.Result{RequeueAfter: kubeletConfigRetryPeriod}, nil
+	}
+
 	// KubeletConfig changes are expected to be sporadic, yet are important enough
 	// to be made visible at kubernetes level. So we generate events to handle them
 	cm, err := r.reconcileConfigMap(ctx, instance, req.NamespacedName)
@@ -130,12 +179,16 @@ func (r *KubeletConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	var p predicate.Funcs
 	if r.Platform == platform.OpenShift {
 		o = &mcov1.KubeletConfig{}
-		// we have nothing to do in case of deletion
+		// the RTE config is garbage collected once all the KubeletConfigs targeting
+		// a pool are deleted, but it needs to be rendered again out of the ones left, if any.
 		p = predicate.Funcs{
 			DeleteFunc: func(e event.DeleteEvent) bool {
 				kubelet := e.Object.(*mcov1.KubeletConfig)
 				klog.InfoS("KubeletConfig object got deleted", "KubeletConfig", kubelet.Name)
-				return false
+				r.deletedLock.Lock()
+				defer r.deletedLock.Unlock()
+				r.deletedKubeletConfigs = append(r.deletedKubeletConfigs, kubelet)
+				return true
 			},
 		}
 	}
@@ -152,6 +205,8 @@ func (r *KubeletConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				return false
 			}
 			klog.InfoS("KubeletConfig ConfigMap object got deleted", "KubeletConfig", kubelet.Name)
+			r.deletedLock.Lock()
+			defer r.deletedLock.Unlock()
 			r.garbageCollectionFor = append(r.garbageCollectionFor, kubelet)
 			return true
 		}
@@ -175,29 +230,162 @@ func (e *InvalidKubeletConfig) Unwrap() error {
 	return e.Err
 }
 
-func (r *KubeletConfigReconciler) reconcileConfigMap(ctx context.Context, instance *nropv1.NUMAResourcesOperator, kcKey client.ObjectKey) (*corev1.ConfigMap, error) {
-	// first check if the ConfigMap should be deleted
-	// to save all the additional work related for create/update
-	cm, deleted, err := r.deleteConfigMap(ctx, instance, kcKey)
-	if deleted {
-		return cm, err
-	}
+// KubeletConfigParseError is returned when the kubelet configuration can't be extracted
+// from the source object, and it is reported in the NUMAResourcesOperator status.
+type KubeletConfigParseError struct {
+	ObjectName string
+	PoolName   string
+	Err        error
+}
+
+func (e *KubeletConfigParseError) Error() string {
+	return "cannot parse KubeletConfig object " + e.ObjectName + ": " + e.Err.Error()
+}
 
+func (e *KubeletConfigParseError) Unwrap() error {
+	return e.Err
+}
+
+func (r *KubeletConfigReconciler) reconcileConfigMap(ctx context.Context, instance *nropv1.NUMAResourcesOperator, kcKey client.ObjectKey) (*corev1.ConfigMap, error) {
 	kcHandler, err := r.makeKCHandlerForPlatform(ctx, instance, kcKey)
 	if err != nil {
+		return nil, r.reportParseError(ctx, instance, kcKey, err)
+	}
+
+	// the last kubelet configuration targeting the pool is gone
+	if len(kcHandler.sources) == 0 {
+		return r.deleteConfigMap(ctx, instance, kcKey, kcHandler.poolName)
+	}
+
+	// all the kubelet configurations targeting the pool must be valid to compute the effective one
+	kubeletConfigs := make(map[*mcov1.KubeletConfig]*kubeletconfigv1beta1.KubeletConfiguration, len(kcHandler.sources))
+	for _, src := range kcHandler.sources {
+		kubeletConfig, err := kubeletconfig.MCOKubeletConfToKubeletConf(src.mcoKc)
+		if err != nil {
+			klog.ErrorS(err, "cannot extract KubeletConfiguration from MCO KubeletConfig", "name", src.ownerObject.GetName())
+			return nil, r.reportParseError(ctx, instance, kcKey, &KubeletConfigParseError{
+				ObjectName: src.ownerObject.GetName(),
+				PoolName:   kcHandler.poolName,
+				Err:        err,
+			})
+		}
+		kubeletConfigs[src.mcoKc] = kubeletConfig
+	}
+
+	if err := kcHandler.selectEffective(); err != nil {
 		return nil, err
 	}
-	kubeletConfig, err := kubeletconfig.MCOKubeletConfToKubeletConf(kcHandler.mcoKc)
+	if len(kcHandler.sources) > 1 {
+		klog.V(2).InfoS("multiple KubeletConfigs target the same pool", "pool", kcHandler.poolName, "effective", kcHandler.ownerObject.GetName(), "sources", len(kcHandler.sources), "conflicts", len(kcHandler.conflicts))
+	}
+
+	cm, err := r.syncConfigMap(ctx, kubeletConfigs[kcHandler.mcoKc], instance, kcHandler)
 	if err != nil {
-		klog.ErrorS(err, "cannot extract KubeletConfiguration from MCO KubeletConfig", "name", kcKey.Name)
 		return nil, err
 	}
 
-	return r.syncConfigMap(ctx, kubeletConfig, instance, kcHandler)
+	if len(kcHandler.conflicts) > 0 {
+		r.Recorder.Event(instance, "Warning", "KubeletConfigConflict", fmt.Sprintf("Conflicting kubelet configurations for pool %s: %s", kcHandler.poolName, kubeletconfig.ConflictsString(kcHandler.conflicts)))
+	}
+
+	r.forgetDeleted(kcKey)
+
+	st := nropv1.RenderedConfigStatus{
+		PoolName:       kcHandler.poolName,
+		KubeletConfig:  nropv1.NamespacedName{Namespace: kcHandler.ownerObject.GetNamespace(), Name: kcHandler.ownerObject.GetName()},
+		ConfigMap:      nropv1.NamespacedName{Namespace: cm.Namespace, Name: cm.Name},
+		Hash:           hash.ConfigMapData(cm),
+		LastRenderTime: ptr.To(metav1.Now()),
+		Conflicts:      conflictsToStatus(kcHandler.ownerObject.GetNamespace(), kcHandler.conflicts),
+	}
+	// nothing changed, so there was no actual rendering
+	if cur := findRenderedConfigStatus(instance.Status.RenderedConfigs, kcHandler.poolName); cur != nil && cur.Hash == st.Hash && cur.LastRenderTime != nil {
+		st.LastRenderTime = cur.LastRenderTime.DeepCopy()
+	}
+	return cm, r.updateRenderedConfigs(ctx, instance, setRenderedConfigStatus(instance.Status.RenderedConfigs, st))
 }
 
-func (r *KubeletConfigReconciler) syncConfigMap(ctx context.Context, kubeletConfig *kubeletconfigv1beta1.KubeletConfiguration, instance *nropv1.NUMAResourcesOperator, kcHandler *kubeletConfigHandler) (*corev1.ConfigMap, error) {
-	generatedName := objectnames.GetComponentName(instance.Name, kcHandler.poolName)
+func conflictsToStatus(namespace string, conflicts []kubeletconfig.Conflict) []nropv1.KubeletConfigConflict {
+	if len(conflicts) == 0 {
+		return nil
+	}
+	ret := make([]nropv1.KubeletConfigConflict, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		ret = append(ret, nropv1.KubeletConfigConflict{
+			Field:         conflict.Field,
+			KubeletConfig: nropv1.NamespacedName{Namespace: namespace, Name: conflict.Overridden},
+		})
+	}
+	return ret
+}
+
+// reportParseError records in the status the failure to parse the source object, if err
+// is a parse error, and returns err unchanged so the caller can keep processing it.
+// The last successfully rendered configuration, if any, is preserved.
+func (r *KubeletConfigReconciler) reportParseError(ctx context.Context, instance *nropv1.NUMAResourcesOperator, kcKey client.ObjectKey, err error) error {
+	var parseErr *KubeletConfigParseError
+	if !errors.As(err, &parseErr) {
+		return err
+	}
+	st := nropv1.RenderedConfigStatus{
+		PoolName: parseErr.PoolName,
+	}
+	if cur := findRenderedConfigStatus(instance.Status.RenderedConfigs, parseErr.PoolName); cur != nil {
+		st = *cur.DeepCopy()
+	}
+	st.KubeletConfig = nropv1.NamespacedName{Namespace: kcKey.Namespace, Name: parseErr.ObjectName}
+	st.Error = parseErr.Error()
+	if updErr := r.updateRenderedConfigs(ctx, instance, setRenderedConfigStatus(instance.Status.RenderedConfigs, st)); updErr != nil {
+		klog.ErrorS(updErr, "failed to report parse error in status", "name", kcKey.Name, "pool", parseErr.PoolName)
+	}
+	return err
+}
+
+func (r *KubeletConfigReconciler) updateRenderedConfigs(ctx context.Context, instance *nropv1.NUMAResourcesOperator, renderedConfigs []nropv1.RenderedConfigStatus) error {
+	if equality.Semantic.DeepEqual(instance.Status.RenderedConfigs, renderedConfigs) {
+		return nil
+	}
+	instance.Status.RenderedConfigs = renderedConfigs
+	if err := r.Client.Status().Update(ctx, instance); err != nil {
+		return fmt.Errorf("could not update status for object %s: %w", client.ObjectKeyFromObject(instance), err)
+	}
+	return nil
+}
+
+func findRenderedConfigStatus(renderedConfigs []nropv1.RenderedConfigStatus, poolName string) *nropv1.RenderedConfigStatus {
+	for idx := range renderedConfigs {
+		if renderedConfigs[idx].PoolName == poolName {
+			return &renderedConfigs[idx]
+		}
+	}
+	return nil
+}
+
+// setRenderedConfigStatus returns a copy of renderedConfigs with st added, or replacing
+// the existing entry with the same pool name. The result is sorted by pool name.
+func setRenderedConfigStatus(renderedConfigs []nropv1.RenderedConfigStatus, st nropv1.RenderedConfigStatus) []nropv1.RenderedConfigStatus {
+	ret := removeRenderedConfigStatus(renderedConfigs, st.PoolName)
+	ret = append(ret, st)
+	slices.SortFunc(ret, func(a, b nropv1.RenderedConfigStatus) int {
+		return strings.Compare(a.PoolName, b.PoolName)
+	})
+	return ret
+}
+
+// removeRenderedConfigStatus returns a copy of renderedConfigs without the entry matching the pool name, if any.
+func removeRenderedConfigStatus(renderedConfigs []nropv1.RenderedConfigStatus, poolName string) []nropv1.RenderedConfigStatus {
+	ret := make([]nropv1.RenderedConfigStatus, 0, len(renderedConfigs))
+	for idx := range renderedConfigs {
+		if renderedConfigs[idx].PoolName == poolName {
+			continue
+		}
+		ret = append(ret, *renderedConfigs[idx].DeepCopy())
+	}
+	return ret
+}
+
+func (r *KubeletConfigReconciler) renderConfigMap(kubeletConfig *kubeletconfigv1beta1.KubeletConfiguration, instance *nropv1.NUMAResourcesOperator, poolName string) (*corev1.ConfigMap, error) {
+	generatedName := objectnames.GetComponentName(instance.Name, poolName)
 	klog.V(3).InfoS("generated configMap name", "generatedName", generatedName)
 
 	data, err := rteconfig.Render(kubeletConfig, instance.Spec.PodExcludes)
@@ -207,28 +395,66 @@ func (r *KubeletConfigReconciler) syncConfigMap(ctx context.Context, kubeletConf
 	}
 
 	rendered := rteconfig.CreateConfigMap(r.Namespace, generatedName, data)
+	return rteconfig.AddSoftRefLabels(rendered, instance.Name, poolName), nil
+}
+
+func (r *KubeletConfigReconciler) syncConfigMap(ctx context.Context, kubeletConfig *kubeletconfigv1beta1.KubeletConfiguration, instance *nropv1.NUMAResourcesOperator, kcHandler *kubeletConfigHandler) (*corev1.ConfigMap, error) {
+	rendered, objStates, err := r.configMapStates(ctx, kubeletConfig, instance, kcHandler)
+	if err != nil {
+		return nil, err
+	}
+	for _, objState := range objStates {
+		if _, _, err := applyObject(ctx, r.Client, r.ServerSideApply, r.Recorder, instance, objState); err != nil {
+			return nil, fmt.Errorf("could not create %s: %w", objState.Desired.GetObjectKind().GroupVersionKind().String(), err)
+		}
+	}
+	return rendered, nil
+}
+
+func (r *KubeletConfigReconciler) configMapStates(ctx context.Context, kubeletConfig *kubeletconfigv1beta1.KubeletConfiguration, instance *nropv1.NUMAResourcesOperator, kcHandler *kubeletConfigHandler) (*corev1.ConfigMap, []objectstate.ObjectState, error) {
+	rendered, err := r.renderConfigMap(kubeletConfig, instance, kcHandler.poolName)
+	if err != nil {
+		return nil, nil, err
+	}
 	cfgManifests := cfgstate.Manifests{
-		Config: rteconfig.AddSoftRefLabels(rendered, instance.Name, kcHandler.poolName),
+		Config: rendered,
 	}
-	existing := cfgstate.FromClient(ctx, r.Client, r.Namespace, generatedName)
-	for _, objState := range existing.State(cfgManifests) {
+	existing := cfgstate.FromClient(ctx, r.Client, r.Namespace, rendered.Name)
+	objStates := existing.State(cfgManifests)
+	for _, objState := range objStates {
 		if err := kcHandler.setCtrlRef(kcHandler.ownerObject, objState.Desired, r.Scheme); err != nil {
-			return nil, fmt.Errorf("failed to set controller reference to %s %s: %w", objState.Desired.GetNamespace(), objState.Desired.GetName(), err)
+			return nil, nil, fmt.Errorf("failed to set controller reference to %s %s: %w", objState.Desired.GetNamespace(), objState.Desired.GetName(), err)
 		}
-		if _, _, err := apply.ApplyObject(ctx, r.Client, objState); err != nil {
-			return nil, fmt.Errorf("could not create %s: %w", objState.Desired.GetObjectKind().GroupVersionKind().String(), err)
+		// the rendered configuration must outlive the KubeletConfig taking precedence, as long as other ones target the pool
+		for _, src := range kcHandler.sources {
+			if src.ownerObject == kcHandler.ownerObject {
+				continue
+			}
+			if err := kcHandler.setOwnerRef(src.ownerObject, objState.Desired, r.Scheme); err != nil {
+				return nil, nil, fmt.Errorf("failed to set owner reference to %s %s: %w", objState.Desired.GetNamespace(), objState.Desired.GetName(), err)
+			}
 		}
 	}
-	return rendered, nil
+	return rendered, objStates, nil
 }
 
 func (r *KubeletConfigReconciler) makeKCHandlerForPlatform(ctx context.Context, instance *nropv1.NUMAResourcesOperator, kcKey client.ObjectKey) (*kubeletConfigHandler, error) {
 	switch r.Platform {
 	case platform.OpenShift:
 		mcoKc := &mcov1.KubeletConfig{}
+		deleted := false
 		if err := r.Client.Get(ctx, kcKey, mcoKc); err != nil {
-			return nil, err
+			deletedKc, ok := r.deletedKubeletConfig(kcKey)
+			if !ok || !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			// we still need to know the pool to render it again out of the KubeletConfigs left
+			mcoKc = deletedKc
+			deleted = true
 		}
+		// the MCO holds KubeletConfigs with a finalizer until their MachineConfig is gone,
+		// but they no longer contribute to the pool configuration
+		deleted = deleted || !mcoKc.DeletionTimestamp.IsZero()
 
 		mcps, err := machineconfigpools.GetListByNodeGroupsV1(ctx, r.Client, instance.Spec.NodeGroups)
 		if err != nil {
@@ -251,93 +477,229 @@ func (r *KubeletConfigReconciler) makeKCHandlerForPlatform(ctx context.Context,
 		klog.V(3).InfoS("matched MCP to MCO KubeletConfig", "kubeletconfig name", kcKey.Name, "MCP name", mcp.Name)
 
 		// nothing we care about, and we can't do much anyway
-		if mcoKc.Spec.KubeletConfig == nil {
+		if !deleted && mcoKc.Spec.KubeletConfig == nil {
 			klog.InfoS("detected KubeletConfig with empty payload, ignoring", "name", kcKey.Name)
 			return nil, &InvalidKubeletConfig{ObjectName: kcKey.Name}
 		}
+
+		var requested *kubeletConfigSource
+		if !deleted {
+			requested = &kubeletConfigSource{
+				ownerObject: mcoKc,
+				mcoKc:       mcoKc,
+			}
+		}
+		sources, err := r.kubeletConfigSourcesForMCP(ctx, mcps, mcp.Name, requested)
+		if err != nil {
+			return nil, err
+		}
 		return &kubeletConfigHandler{
-			ownerObject: mcoKc,
-			mcoKc:       mcoKc,
 			poolName:    mcp.Name,
+			sources:     sources,
 			setCtrlRef:  controllerutil.SetControllerReference,
+			setOwnerRef: controllerutil.SetOwnerReference,
 		}, nil
 
 	case platform.HyperShift:
 		cmKc := &corev1.ConfigMap{}
+		deleted := false
 		if err := r.Client.Get(ctx, kcKey, cmKc); err != nil {
-			return nil, err
+			deletedCm, ok := r.deletedConfigMap(kcKey)
+			if !ok || !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			// we still need to know the node pool to render it again out of the KubeletConfigs left
+			cmKc = deletedCm
+			deleted = true
 		}
 
 		nodePoolName := cmKc.Labels[HyperShiftNodePoolLabel]
-		kcData := cmKc.Data[HyperShiftConfigMapConfigKey]
-		mcoKc := &mcov1.KubeletConfig{}
-		err := decodeKCFrom([]byte(kcData), r.Scheme, mcoKc)
+
+		var requested *kubeletConfigSource
+		if !deleted {
+			mcoKc, err := kubeletConfigFromConfigMap(cmKc, r.Scheme)
+			if err != nil {
+				return nil, &KubeletConfigParseError{
+					ObjectName: kcKey.Name,
+					PoolName:   nodePoolName,
+					Err:        err,
+				}
+			}
+
+			// nothing we care about, and we can't do much anyway
+			if mcoKc.Spec.KubeletConfig == nil {
+				klog.InfoS("detected KubeletConfig with empty payload, ignoring", "name", kcKey.Name)
+				return nil, &InvalidKubeletConfig{ObjectName: kcKey.Name}
+			}
+			requested = &kubeletConfigSource{
+				ownerObject: cmKc,
+				mcoKc:       mcoKc,
+			}
+		}
+		sources, err := r.kubeletConfigSourcesForNodePool(ctx, kcKey.Namespace, nodePoolName, requested)
 		if err != nil {
 			return nil, err
 		}
-
-		// nothing we care about, and we can't do much anyway
-		if mcoKc.Spec.KubeletConfig == nil {
-			klog.InfoS("detected KubeletConfig with empty payload, ignoring", "name", kcKey.Name)
-			return nil, &InvalidKubeletConfig{ObjectName: kcKey.Name}
+		// the owner should be the KubeletConfig object and not the NUMAResourcesOperator CR
+		// this means that when KubeletConfig will get deleted, the ConfigMap gets deleted as well
+		// TODO on HyperShift there's a cross-namespaced owner references that need to be fixed.
+		noopRef := func(owner, controlled metav1.Object, scheme *runtime.Scheme, opts ...controllerutil.OwnerReferenceOption) error {
+			return nil
 		}
 		return &kubeletConfigHandler{
-			ownerObject: cmKc,
-			mcoKc:       mcoKc,
 			poolName:    nodePoolName,
-			// the owner should be the KubeletConfig object and not the NUMAResourcesOperator CR
-			// this means that when KubeletConfig will get deleted, the ConfigMap gets deleted as well
-			// TODO on HyperShift there's a cross-namespaced owner references that need to be fixed.
-			setCtrlRef: func(owner, controlled metav1.Object, scheme *runtime.Scheme, opts ...controllerutil.OwnerReferenceOption) error {
-				return nil
-			},
+			sources:     sources,
+			setCtrlRef:  noopRef,
+			setOwnerRef: noopRef,
 		}, nil
 	}
 	return nil, fmt.Errorf("unsupported platform: %s", r.Platform)
 }
 
-func (r *KubeletConfigReconciler) deleteConfigMap(ctx context.Context, instance *nropv1.NUMAResourcesOperator, kcKey client.ObjectKey) (*corev1.ConfigMap, bool, error) {
-	cm := getDeletedOwner(kcKey, r.garbageCollectionFor)
-	if cm == nil {
-		return nil, false, nil
+// kubeletConfigSourcesForMCP returns all the KubeletConfigs targeting the given MCP, starting from the requested one, if any.
+func (r *KubeletConfigReconciler) kubeletConfigSourcesForMCP(ctx context.Context, mcps []*mcov1.MachineConfigPool, poolName string, requested *kubeletConfigSource) ([]kubeletConfigSource, error) {
+	var sources []kubeletConfigSource
+	if requested != nil {
+		sources = append(sources, *requested)
+	}
+
+	kcList := mcov1.KubeletConfigList{}
+	if err := r.Client.List(ctx, &kcList); err != nil {
+		return nil, err
+	}
+	for idx := range kcList.Items {
+		mcoKc := &kcList.Items[idx]
+		if requested != nil && mcoKc.Name == requested.ownerObject.GetName() {
+			continue
+		}
+		if mcoKc.Spec.KubeletConfig == nil || !mcoKc.DeletionTimestamp.IsZero() {
+			continue
+		}
+		mcp, err := machineconfigpools.FindBySelector(mcps, mcoKc.Spec.MachineConfigPoolSelector)
+		if err != nil || mcp.Name != poolName {
+			continue
+		}
+		sources = append(sources, kubeletConfigSource{
+			ownerObject: mcoKc,
+			mcoKc:       mcoKc,
+		})
+	}
+	return sources, nil
+}
+
+// kubeletConfigSourcesForNodePool returns all the KubeletConfigs targeting the given node pool, starting from the requested one, if any.
+func (r *KubeletConfigReconciler) kubeletConfigSourcesForNodePool(ctx context.Context, namespace, nodePoolName string, requested *kubeletConfigSource) ([]kubeletConfigSource, error) {
+	var sources []kubeletConfigSource
+	if requested != nil {
+		sources = append(sources, *requested)
+	}
+
+	cmList := corev1.ConfigMapList{}
+	err := r.Client.List(ctx, &cmList,
+		client.InNamespace(namespace),
+		client.HasLabels{HypershiftKubeletConfigConfigMapLabel},
+		client.MatchingLabels{HyperShiftNodePoolLabel: nodePoolName},
+	)
+	if err != nil {
+		return nil, err
+	}
+	for idx := range cmList.Items {
+		cmKc := &cmList.Items[idx]
+		if requested != nil && cmKc.Name == requested.ownerObject.GetName() {
+			continue
+		}
+		if !cmKc.DeletionTimestamp.IsZero() {
+			continue
+		}
+		mcoKc, err := kubeletConfigFromConfigMap(cmKc, r.Scheme)
+		if err != nil {
+			return nil, &KubeletConfigParseError{
+				ObjectName: cmKc.Name,
+				PoolName:   nodePoolName,
+				Err:        err,
+			}
+		}
+		if mcoKc.Spec.KubeletConfig == nil {
+			continue
+		}
+		sources = append(sources, kubeletConfigSource{
+			ownerObject: cmKc,
+			mcoKc:       mcoKc,
+		})
+	}
+	return sources, nil
+}
+
+// kubeletConfigFromConfigMap decodes the KubeletConfig carried by the given ConfigMap. On HyperShift the ConfigMap
+// is the object representing the KubeletConfig, so its identity is used to order and to report the KubeletConfig.
+func kubeletConfigFromConfigMap(cmKc *corev1.ConfigMap, scheme *runtime.Scheme) (*mcov1.KubeletConfig, error) {
+	mcoKc := &mcov1.KubeletConfig{}
+	if err := decodeKCFrom([]byte(cmKc.Data[HyperShiftConfigMapConfigKey]), scheme, mcoKc); err != nil {
+		return nil, err
 	}
-	// we'll get to this flow only on hypershift
-	// on openshift the deletion is done automatically by setting the owner reference on the dependent ConfigMap
-	nodePoolName := cm.Labels[HyperShiftNodePoolLabel]
-	generatedName := objectnames.GetComponentName(instance.Name, nodePoolName)
+	mcoKc.Name = cmKc.Name
+	mcoKc.CreationTimestamp = cmKc.CreationTimestamp
+	return mcoKc, nil
+}
+
+// deleteConfigMap cleans up once the last kubelet configuration targeting the pool is gone.
+func (r *KubeletConfigReconciler) deleteConfigMap(ctx context.Context, instance *nropv1.NUMAResourcesOperator, kcKey client.ObjectKey, poolName string) (*corev1.ConfigMap, error) {
+	generatedName := objectnames.GetComponentName(instance.Name, poolName)
 	dependentCM := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      generatedName,
 			Namespace: r.Namespace,
 		},
 	}
-	if err := r.Delete(ctx, dependentCM); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return cm, true, err
+	// on openshift the deletion is done automatically by setting the owner references on the dependent ConfigMap
+	if r.Platform == platform.HyperShift {
+		if err := r.Delete(ctx, dependentCM); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			klog.V(2).InfoS("could not delete ConfigMap since it was not found", "configmapName", dependentCM.Name)
 		}
-		klog.V(2).InfoS("could not delete ConfigMap since it was not found", "configmapName", dependentCM.Name)
 	}
+	r.forgetDeleted(kcKey)
+	return dependentCM, r.updateRenderedConfigs(ctx, instance, removeRenderedConfigStatus(instance.Status.RenderedConfigs, poolName))
+}
+
+func (r *KubeletConfigReconciler) forgetDeleted(kcKey client.ObjectKey) {
+	r.deletedLock.Lock()
+	defer r.deletedLock.Unlock()
 	r.garbageCollectionFor = removeDeletedOwner(kcKey, r.garbageCollectionFor)
-	return cm, true, nil
+	r.deletedKubeletConfigs = removeDeletedOwner(kcKey, r.deletedKubeletConfigs)
 }
 
-func getDeletedOwner(kcKey client.ObjectKey, ownerConfigMaps []*corev1.ConfigMap) *corev1.ConfigMap {
-	for i := range ownerConfigMaps {
-		cm := ownerConfigMaps[i]
-		if client.ObjectKeyFromObject(cm).String() == kcKey.String() {
-			return cm
+func (r *KubeletConfigReconciler) deletedKubeletConfig(kcKey client.ObjectKey) (*mcov1.KubeletConfig, bool) {
+	r.deletedLock.Lock()
+	defer r.deletedLock.Unlock()
+	return getDeletedOwner(kcKey, r.deletedKubeletConfigs)
+}
+
+func (r *KubeletConfigReconciler) deletedConfigMap(kcKey client.ObjectKey) (*corev1.ConfigMap, bool) {
+	r.deletedLock.Lock()
+	defer r.deletedLock.Unlock()
+	return getDeletedOwner(kcKey, r.garbageCollectionFor)
+}
+
+func getDeletedOwner[T client.Object](kcKey client.ObjectKey, owners []T) (T, bool) {
+	for i := range owners {
+		if client.ObjectKeyFromObject(owners[i]) == kcKey {
+			return owners[i], true
 		}
 	}
-	return nil
+	var zero T
+	return zero, false
 }
 
-func removeDeletedOwner(kcKey client.ObjectKey, ownerConfigMaps []*corev1.ConfigMap) []*corev1.ConfigMap {
-	for i := range ownerConfigMaps {
-		if client.ObjectKeyFromObject(ownerConfigMaps[i]) == kcKey {
-			return slices.Delete(ownerConfigMaps, i, i+1)
+func removeDeletedOwner[T client.Object](kcKey client.ObjectKey, owners []T) []T {
+	for i := range owners {
+		if client.ObjectKeyFromObject(owners[i]) == kcKey {
+			return slices.Delete(owners, i, i+1)
 		}
 	}
-	return ownerConfigMaps
+	return owners
 }
 
 func decodeKCFrom(data []byte, scheme *runtime.Scheme, mcoKc *mcov1.KubeletConfig) error {
@@ -18,11 +18,14 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	machineconfigv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -34,6 +37,9 @@ import (
 
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
 	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	"github.com/openshift-kni/numaresources-operator/internal/api/annotations"
+	"github.com/openshift-kni/numaresources-operator/pkg/hash"
+	"github.com/openshift-kni/numaresources-operator/pkg/kubeletconfig"
 	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
 	rteconfig "github.com/openshift-kni/numaresources-operator/rte/pkg/config"
 
@@ -47,7 +53,7 @@ const (
 )
 
 func NewFakeKubeletConfigReconciler(initObjects ...runtime.Object) (*KubeletConfigReconciler, error) {
-	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(initObjects...).Build()
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithStatusSubresource(&nropv1.NUMAResourcesOperator{}).WithRuntimeObjects(initObjects...).Build()
 	return &KubeletConfigReconciler{
 		Client:    fakeClient,
 		Scheme:    scheme.Scheme,
@@ -58,7 +64,7 @@ func NewFakeKubeletConfigReconciler(initObjects ...runtime.Object) (*KubeletConf
 }
 
 func NewFakeKubeletConfigReconcilerForHyperShift(initObjects ...runtime.Object) (*KubeletConfigReconciler, error) {
-	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(initObjects...).Build()
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithStatusSubresource(&nropv1.NUMAResourcesOperator{}).WithRuntimeObjects(initObjects...).Build()
 	return &KubeletConfigReconciler{
 		Client:    fakeClient,
 		Scheme:    scheme.Scheme,
@@ -126,6 +132,24 @@ var _ = Describe("Test KubeletConfig Reconcile", func() {
 				}
 				Expect(reconciler.Client.Get(context.TODO(), key, cm)).ToNot(HaveOccurred())
 			})
+			It("with NRO in dry-run mode, should not create configmap", func() {
+				nro.Annotations = map[string]string{
+					annotations.DryRunAnnotation: annotations.DryRunAnnotationEnabled,
+				}
+				reconciler, err := newFakeReconciler(nro, mcp1, mcoKc1, cmKc1)
+				Expect(err).ToNot(HaveOccurred())
+
+				result, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(Equal(reconcile.Result{RequeueAfter: kubeletConfigRetryPeriod}))
+
+				cm := &corev1.ConfigMap{}
+				key = client.ObjectKey{
+					Namespace: testNamespace,
+					Name:      objectnames.GetComponentName(nro.Name, poolName),
+				}
+				Expect(apierrors.IsNotFound(reconciler.Client.Get(context.TODO(), key, cm))).To(BeTrue())
+			})
 			It("with NRO present, the created configmap should have the linking labels", func() {
 				reconciler, err := newFakeReconciler(nro, mcp1, mcoKc1, cmKc1)
 				Expect(err).ToNot(HaveOccurred())
@@ -143,6 +167,73 @@ var _ = Describe("Test KubeletConfig Reconcile", func() {
 				Expect(cm.Labels).To(HaveKeyWithValue(rteconfig.LabelOperatorName, nro.Name))
 				Expect(cm.Labels).To(HaveKeyWithValue(rteconfig.LabelNodeGroupName+"/"+rteconfig.LabelNodeGroupKindMachineConfigPool, poolName))
 			})
+			It("with NRO present, should report the rendered config in the status", func() {
+				reconciler, err := newFakeReconciler(nro, mcp1, mcoKc1, cmKc1)
+				Expect(err).ToNot(HaveOccurred())
+
+				result, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(Equal(reconcile.Result{}))
+
+				cm := &corev1.ConfigMap{}
+				cmKey := client.ObjectKey{
+					Namespace: testNamespace,
+					Name:      objectnames.GetComponentName(nro.Name, poolName),
+				}
+				Expect(reconciler.Client.Get(context.TODO(), cmKey, cm)).ToNot(HaveOccurred())
+
+				updatedNRO := &nropv1.NUMAResourcesOperator{}
+				Expect(reconciler.Client.Get(context.TODO(), client.ObjectKeyFromObject(nro), updatedNRO)).ToNot(HaveOccurred())
+				Expect(updatedNRO.Status.RenderedConfigs).To(HaveLen(1))
+				st := updatedNRO.Status.RenderedConfigs[0]
+				Expect(st.PoolName).To(Equal(poolName))
+				Expect(st.KubeletConfig.Name).To(Equal(key.Name))
+				Expect(st.ConfigMap).To(Equal(nropv1.NamespacedName{Namespace: cm.Namespace, Name: cm.Name}))
+				Expect(st.Hash).To(Equal(hash.ConfigMapData(cm)))
+				Expect(st.LastRenderTime).ToNot(BeNil())
+				Expect(st.Error).To(BeEmpty())
+			})
+
+			It("should report the parse error in the status, keeping the last rendered config", func() {
+				reconciler, err := newFakeReconciler(nro, mcp1, mcoKc1, cmKc1)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+				Expect(err).ToNot(HaveOccurred())
+
+				renderedNRO := &nropv1.NUMAResourcesOperator{}
+				Expect(reconciler.Client.Get(context.TODO(), client.ObjectKeyFromObject(nro), renderedNRO)).ToNot(HaveOccurred())
+				Expect(renderedNRO.Status.RenderedConfigs).To(HaveLen(1))
+				renderedSt := renderedNRO.Status.RenderedConfigs[0]
+
+				if clusterPlatform == platform.HyperShift {
+					brokenCmKc := &corev1.ConfigMap{}
+					Expect(reconciler.Client.Get(context.TODO(), key, brokenCmKc)).ToNot(HaveOccurred())
+					brokenCmKc.Data[HyperShiftConfigMapConfigKey] = "this is: [not valid"
+					Expect(reconciler.Client.Update(context.TODO(), brokenCmKc)).ToNot(HaveOccurred())
+				} else {
+					brokenMcoKc := &machineconfigv1.KubeletConfig{}
+					Expect(reconciler.Client.Get(context.TODO(), key, brokenMcoKc)).ToNot(HaveOccurred())
+					brokenMcoKc.Spec.KubeletConfig.Raw = []byte(`{"cpuManagerPolicy": 42}`)
+					Expect(reconciler.Client.Update(context.TODO(), brokenMcoKc)).ToNot(HaveOccurred())
+				}
+
+				_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+				Expect(err).To(HaveOccurred())
+				var parseErr *KubeletConfigParseError
+				Expect(errors.As(err, &parseErr)).To(BeTrue())
+
+				updatedNRO := &nropv1.NUMAResourcesOperator{}
+				Expect(reconciler.Client.Get(context.TODO(), client.ObjectKeyFromObject(nro), updatedNRO)).ToNot(HaveOccurred())
+				Expect(updatedNRO.Status.RenderedConfigs).To(HaveLen(1))
+				st := updatedNRO.Status.RenderedConfigs[0]
+				Expect(st.PoolName).To(Equal(poolName))
+				Expect(st.KubeletConfig.Name).To(Equal(key.Name))
+				Expect(st.Error).To(ContainSubstring(key.Name))
+				Expect(st.ConfigMap).To(Equal(renderedSt.ConfigMap))
+				Expect(st.Hash).To(Equal(renderedSt.Hash))
+			})
+
 			It("should send events when NRO present and operation successful", func() {
 				reconciler, err := newFakeReconciler(nro, mcp1, mcoKc1, cmKc1)
 				Expect(err).ToNot(HaveOccurred())
@@ -265,6 +356,140 @@ var _ = Describe("Test KubeletConfig Reconcile", func() {
 				Expect(event).To(ContainSubstring(ctrlPlaneKc.Name))
 			})
 		})
+
+		Context("with multiple KubeletConfigs targeting the same pool", func() {
+			var kcObjs []client.Object
+			var keys []client.ObjectKey
+
+			BeforeEach(func() {
+				kcObjs = nil
+				keys = nil
+				for idx, tmPolicy := range []string{"restricted", "single-numa-node"} {
+					kcName := fmt.Sprintf("multi%d", idx)
+					mcoKc := testobjs.NewKubeletConfig(kcName, label1, mcp1.Spec.MachineConfigSelector, &kubeletconfigv1beta1.KubeletConfiguration{
+						TopologyManagerPolicy: tmPolicy,
+					})
+					if idx > 0 {
+						mcoKc.Annotations = map[string]string{
+							kubeletconfig.MCNameSuffixAnnotation: fmt.Sprintf("%d", idx),
+						}
+					}
+					var obj client.Object = mcoKc
+					if clusterPlatform == platform.HyperShift {
+						obj = testobjs.NewKubeletConfigConfigMap(kcName, map[string]string{
+							HypershiftKubeletConfigConfigMapLabel: "true",
+							HyperShiftNodePoolLabel:               poolName,
+						}, mcoKc)
+					}
+					kcObjs = append(kcObjs, obj)
+					keys = append(keys, client.ObjectKeyFromObject(obj))
+				}
+			})
+
+			It("should render the KubeletConfig taking precedence, reporting conflicts", func() {
+				reconciler, err := newFakeReconciler(nro, mcp1, kcObjs[0], kcObjs[1])
+				Expect(err).ToNot(HaveOccurred())
+
+				// regardless of which object triggers the reconciliation, the outcome must be the same
+				for _, key := range keys {
+					_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+					Expect(err).ToNot(HaveOccurred())
+
+					cm := &corev1.ConfigMap{}
+					cmKey := client.ObjectKey{
+						Namespace: testNamespace,
+						Name:      objectnames.GetComponentName(nro.Name, poolName),
+					}
+					Expect(reconciler.Client.Get(context.TODO(), cmKey, cm)).ToNot(HaveOccurred())
+					data, err := rteconfig.UnpackConfigMap(cm)
+					Expect(err).ToNot(HaveOccurred())
+					rteConf, err := rteconfig.Unrender(data)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(rteConf.Kubelet.TopologyManagerPolicy).To(Equal("single-numa-node"))
+
+					if clusterPlatform == platform.OpenShift {
+						Expect(cm.OwnerReferences).To(HaveLen(2))
+						ctrlRef := metav1.GetControllerOf(cm)
+						Expect(ctrlRef).ToNot(BeNil())
+						Expect(ctrlRef.Name).To(Equal(keys[1].Name))
+					}
+
+					updatedNRO := &nropv1.NUMAResourcesOperator{}
+					Expect(reconciler.Client.Get(context.TODO(), client.ObjectKeyFromObject(nro), updatedNRO)).ToNot(HaveOccurred())
+					Expect(updatedNRO.Status.RenderedConfigs).To(HaveLen(1))
+					st := updatedNRO.Status.RenderedConfigs[0]
+					Expect(st.KubeletConfig.Name).To(Equal(keys[1].Name))
+					Expect(st.Conflicts).To(Equal([]nropv1.KubeletConfigConflict{
+						{
+							Field:         "topologyManagerPolicy",
+							KubeletConfig: nropv1.NamespacedName{Namespace: keys[0].Namespace, Name: keys[0].Name},
+						},
+					}))
+				}
+
+				fakeRecorder, ok := reconciler.Recorder.(*record.FakeRecorder)
+				Expect(ok).To(BeTrue())
+				event := <-fakeRecorder.Events
+				Expect(event).To(ContainSubstring("KubeletConfigConflict"))
+				Expect(event).To(ContainSubstring("topologyManagerPolicy"))
+			})
+
+			It("should render again the pool out of the KubeletConfigs left after deletion", func() {
+				reconciler, err := newFakeReconciler(nro, mcp1, kcObjs[0], kcObjs[1])
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: keys[0]})
+				Expect(err).ToNot(HaveOccurred())
+
+				cmKey := client.ObjectKey{
+					Namespace: testNamespace,
+					Name:      objectnames.GetComponentName(nro.Name, poolName),
+				}
+
+				deleteKubeletConfig := func(obj client.Object) {
+					Expect(reconciler.Client.Delete(context.TODO(), obj)).ToNot(HaveOccurred())
+					// emulate the delete predicate
+					switch o := obj.(type) {
+					case *machineconfigv1.KubeletConfig:
+						reconciler.deletedKubeletConfigs = append(reconciler.deletedKubeletConfigs, o)
+					case *corev1.ConfigMap:
+						reconciler.garbageCollectionFor = append(reconciler.garbageCollectionFor, o)
+					}
+				}
+
+				deleteKubeletConfig(kcObjs[1])
+				_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: keys[1]})
+				Expect(err).ToNot(HaveOccurred())
+
+				cm := &corev1.ConfigMap{}
+				Expect(reconciler.Client.Get(context.TODO(), cmKey, cm)).ToNot(HaveOccurred())
+				data, err := rteconfig.UnpackConfigMap(cm)
+				Expect(err).ToNot(HaveOccurred())
+				rteConf, err := rteconfig.Unrender(data)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rteConf.Kubelet.TopologyManagerPolicy).To(Equal("restricted"))
+
+				updatedNRO := &nropv1.NUMAResourcesOperator{}
+				Expect(reconciler.Client.Get(context.TODO(), client.ObjectKeyFromObject(nro), updatedNRO)).ToNot(HaveOccurred())
+				Expect(updatedNRO.Status.RenderedConfigs).To(HaveLen(1))
+				Expect(updatedNRO.Status.RenderedConfigs[0].KubeletConfig.Name).To(Equal(keys[0].Name))
+				Expect(updatedNRO.Status.RenderedConfigs[0].Conflicts).To(BeEmpty())
+
+				deleteKubeletConfig(kcObjs[0])
+				_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: keys[0]})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(reconciler.Client.Get(context.TODO(), client.ObjectKeyFromObject(nro), updatedNRO)).ToNot(HaveOccurred())
+				Expect(updatedNRO.Status.RenderedConfigs).To(BeEmpty())
+				Expect(reconciler.deletedKubeletConfigs).To(BeEmpty())
+				Expect(reconciler.garbageCollectionFor).To(BeEmpty())
+				if clusterPlatform == platform.HyperShift {
+					// on OpenShift the garbage collection is done through the owner references
+					err = reconciler.Client.Get(context.TODO(), cmKey, cm)
+					Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				}
+			})
+		})
 	},
 		Entry("OpenShift Platform", NewFakeKubeletConfigReconciler, platform.OpenShift),
 		Entry("HyperShift Platform", NewFakeKubeletConfigReconcilerForHyperShift, platform.HyperShift),
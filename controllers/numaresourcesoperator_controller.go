@@ -62,6 +62,7 @@ import (
 	"github.com/openshift-kni/numaresources-operator/pkg/images"
 	"github.com/openshift-kni/numaresources-operator/pkg/loglevel"
 	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectstate"
 	apistate "github.com/openshift-kni/numaresources-operator/pkg/objectstate/api"
 	rtestate "github.com/openshift-kni/numaresources-operator/pkg/objectstate/rte"
 	rteupdate "github.com/openshift-kni/numaresources-operator/pkg/objectupdate/rte"
@@ -92,6 +93,8 @@ type NUMAResourcesOperatorReconciler struct {
 	ImagePullPolicy corev1.PullPolicy
 	Recorder        record.EventRecorder
 	ForwardMCPConds bool
+	// ServerSideApply enables the server-side apply of the supported RTE objects
+	ServerSideApply bool
 }
 
 // TODO: narrow down
@@ -169,9 +172,11 @@ func (r *NUMAResourcesOperatorReconciler) Reconcile(ctx context.Context, req ctr
 		}
 	}
 
-	for idx := range trees {
-		conf := trees[idx].NodeGroup.NormalizeConfig()
-		trees[idx].NodeGroup.Config = &conf
+	normalizeTreesConfig(trees)
+
+	if annotations.IsDryRunEnabled(instance.Annotations) {
+		klog.V(2).InfoS("Dry run enabled", "object", req.NamespacedName)
+		return r.reconcileDryRun(ctx, instance, trees)
 	}
 
 	curStatus := instance.Status.DeepCopy()
@@ -195,6 +200,13 @@ func (r *NUMAResourcesOperatorReconciler) Reconcile(ctx context.Context, req ctr
 	return step.Result, step.Error
 }
 
+func normalizeTreesConfig(trees []nodegroupv1.Tree) {
+	for idx := range trees {
+		conf := trees[idx].NodeGroup.NormalizeConfig()
+		trees[idx].NodeGroup.Config = &conf
+	}
+}
+
 // updateStatusConditionsIfNeeded returns true if conditions were updated.
 func updateStatusConditionsIfNeeded(instance *nropv1.NUMAResourcesOperator, cond conditioninfo.ConditionInfo) {
 	if cond.Type == "" { // backward (=legacy) compatibility
@@ -522,28 +534,83 @@ func (r *NUMAResourcesOperatorReconciler) syncNUMAResourcesOperatorResources(ctx
 		}
 	}
 
+	objStates, dsPoolPairs, err := r.rteObjectStates(ctx, instance, trees, r.currentConfigMap)
+	if err != nil {
+		return nil, err
+	}
+	for _, objState := range objStates {
+		err := controllerutil.SetControllerReference(instance, objState.Desired, r.Scheme)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set controller reference to %s %s: %w", objState.Desired.GetNamespace(), objState.Desired.GetName(), err)
+		}
+		_, _, err = applyObject(ctx, r.Client, r.ServerSideApply, r.Recorder, instance, objState)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply (%s) %s/%s: %w", objState.Desired.GetObjectKind().GroupVersionKind(), objState.Desired.GetNamespace(), objState.Desired.GetName(), err)
+		}
+	}
+	if len(dsPoolPairs) < len(trees) {
+		klog.Warningf("daemonset and tree size mismatch: expected %d got in daemonsets %d", len(trees), len(dsPoolPairs))
+	}
+	return dsPoolPairs, nil
+}
+
+// configMapGetter returns the current content of the given RTE configuration
+type configMapGetter func(ctx context.Context, cm *corev1.ConfigMap) (*corev1.ConfigMap, error)
+
+func (r *NUMAResourcesOperatorReconciler) currentConfigMap(ctx context.Context, cm *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	return hash.CurrentConfigMap(ctx, r.Client, cm)
+}
+
+// renderedConfigMap returns a configMapGetter preferring the given rendered RTE configurations over the current ones,
+// so the daemonsets track the configurations about to be applied
+func (r *NUMAResourcesOperatorReconciler) renderedConfigMap(configMaps []*corev1.ConfigMap) configMapGetter {
+	return func(ctx context.Context, cm *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+		key := client.ObjectKeyFromObject(cm)
+		for _, rendered := range configMaps {
+			if client.ObjectKeyFromObject(rendered) == key {
+				return rendered, nil
+			}
+		}
+		return r.currentConfigMap(ctx, cm)
+	}
+}
+
+// rteObjectStates updates the RTE manifests as per the instance spec, and computes the state of the RTE objects,
+// including the per-pool daemonsets, which need to be reconciled. The daemonsets track the RTE configuration
+// returned by getConfigMap.
+func (r *NUMAResourcesOperatorReconciler) rteObjectStates(ctx context.Context, instance *nropv1.NUMAResourcesOperator, trees []nodegroupv1.Tree, getConfigMap configMapGetter) ([]objectstate.ObjectState, []poolDaemonSet, error) {
 	// using a slice of poolDaemonSet instead of a map because Go maps assignment order is not consistent and non-deterministic
 	dsPoolPairs := []poolDaemonSet{}
-	err = rteupdate.DaemonSetUserImageSettings(r.RTEManifests.DaemonSet, instance.Spec.ExporterImage, r.Images.Preferred(), r.ImagePullPolicy)
+	err := rteupdate.DaemonSetUserImageSettings(r.RTEManifests.DaemonSet, instance.Spec.ExporterImage, r.Images.Preferred(), r.ImagePullPolicy)
 	if err != nil {
-		return dsPoolPairs, err
+		return nil, dsPoolPairs, err
 	}
 
 	err = rteupdate.DaemonSetPauseContainerSettings(r.RTEManifests.DaemonSet)
 	if err != nil {
-		return dsPoolPairs, err
+		return nil, dsPoolPairs, err
+	}
+
+	err = rteupdate.DaemonSetHealthProbes(r.RTEManifests.DaemonSet)
+	if err != nil {
+		return nil, dsPoolPairs, err
 	}
 
 	err = loglevel.UpdatePodSpec(&r.RTEManifests.DaemonSet.Spec.Template.Spec, manifests.ContainerNameRTE, instance.Spec.LogLevel)
 	if err != nil {
-		return dsPoolPairs, err
+		return nil, dsPoolPairs, err
 	}
 
 	// ConfigMap should be provided by the kubeletconfig reconciliation loop
 	if r.RTEManifests.ConfigMap != nil {
-		cmHash, err := hash.ComputeCurrentConfigMap(ctx, r.Client, r.RTEManifests.ConfigMap)
+		cm, err := getConfigMap(ctx, r.RTEManifests.ConfigMap)
+		if err != nil {
+			return nil, dsPoolPairs, err
+		}
+		// the RTE reloads most of its configuration at runtime, so roll out the pods only when needed
+		cmHash, err := rteupdate.ConfigMapRestartHash(cm)
 		if err != nil {
-			return dsPoolPairs, err
+			return nil, dsPoolPairs, err
 		}
 		rteupdate.DaemonSetHashAnnotation(r.RTEManifests.DaemonSet, cmHash)
 	}
@@ -559,7 +626,8 @@ func (r *NUMAResourcesOperatorReconciler) syncNUMAResourcesOperatorResources(ctx
 	}
 
 	existing := rtestate.FromClient(ctx, r.Client, r.Platform, r.RTEManifests, instance, trees, r.Namespace)
-	for _, objState := range existing.State(r.RTEManifests, processor, annotations.IsCustomPolicyEnabled(instance.Annotations)) {
+	objStates := existing.State(r.RTEManifests, processor, annotations.IsCustomPolicyEnabled(instance.Annotations))
+	for _, objState := range objStates {
 		if objState.Error != nil {
 			// We are likely in the bootstrap scenario. In this case, which is expected once, everything is fine.
 			// If it happens past bootstrap, still carry on. We know what to do, and we do want to enforce the desired state.
@@ -567,21 +635,10 @@ func (r *NUMAResourcesOperatorReconciler) syncNUMAResourcesOperatorResources(ctx
 		}
 		if objState.UpdateError != nil {
 			// this is an internal error. Should not happen. But if it happen, we don't want to send garbage to the cluster, so we abort
-			return nil, fmt.Errorf("failed to update (%s) %s/%s: %w", objState.Desired.GetObjectKind().GroupVersionKind(), objState.Desired.GetNamespace(), objState.Desired.GetName(), err)
+			return nil, dsPoolPairs, fmt.Errorf("failed to update (%s) %s/%s: %w", objState.Desired.GetObjectKind().GroupVersionKind(), objState.Desired.GetNamespace(), objState.Desired.GetName(), objState.UpdateError)
 		}
-		err := controllerutil.SetControllerReference(instance, objState.Desired, r.Scheme)
-		if err != nil {
-			return nil, fmt.Errorf("failed to set controller reference to %s %s: %w", objState.Desired.GetNamespace(), objState.Desired.GetName(), err)
-		}
-		_, _, err = apply.ApplyObject(ctx, r.Client, objState)
-		if err != nil {
-			return nil, fmt.Errorf("failed to apply (%s) %s/%s: %w", objState.Desired.GetObjectKind().GroupVersionKind(), objState.Desired.GetNamespace(), objState.Desired.GetName(), err)
-		}
-	}
-	if len(dsPoolPairs) < len(trees) {
-		klog.Warningf("daemonset and tree size mismatch: expected %d got in daemonsets %d", len(trees), len(dsPoolPairs))
 	}
-	return dsPoolPairs, nil
+	return objStates, dsPoolPairs, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
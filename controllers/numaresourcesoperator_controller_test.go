@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -48,6 +49,7 @@ import (
 	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
 	"github.com/openshift-kni/numaresources-operator/internal/api/annotations"
 	testobjs "github.com/openshift-kni/numaresources-operator/internal/objects"
+	"github.com/openshift-kni/numaresources-operator/pkg/apply"
 	"github.com/openshift-kni/numaresources-operator/pkg/images"
 	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
 	"github.com/openshift-kni/numaresources-operator/pkg/objectstate/rte"
@@ -438,6 +440,42 @@ var _ = Describe("Test NUMAResourcesOperator Reconcile", func() {
 					})
 
 				})
+				When("dry-run annotation enabled", func() {
+					BeforeEach(func() {
+						if nro.Annotations == nil {
+							nro.Annotations = map[string]string{}
+						}
+						nro.Annotations[annotations.DryRunAnnotation] = annotations.DryRunAnnotationEnabled
+						Expect(reconciler.Client.Update(context.TODO(), nro)).To(Succeed())
+					})
+					It("should report the DaemonSet update without applying it", func() {
+						dsKey := client.ObjectKey{
+							Name:      objectnames.GetComponentName(nro.Name, pn1),
+							Namespace: testNamespace,
+						}
+						ds := &appsv1.DaemonSet{}
+						Expect(reconciler.Client.Get(context.TODO(), dsKey, ds)).To(Succeed())
+
+						cnt := &ds.Spec.Template.Spec.Containers[0]
+						cnt.Image = "madeup-image:1"
+						Expect(reconciler.Client.Update(context.TODO(), ds)).To(Succeed())
+
+						_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: nroKey})
+						Expect(err).ToNot(HaveOccurred())
+
+						Expect(reconciler.Client.Get(context.TODO(), dsKey, ds)).ToNot(HaveOccurred())
+						cnt = &ds.Spec.Template.Spec.Containers[0]
+						By(fmt.Sprintf("Check that container %s image did not change", cnt.Name))
+						Expect(cnt.Image).To(Equal("madeup-image:1"))
+
+						changes := getDryRunPlan(reconciler.Client, nro.Name)
+						Expect(changes).To(ConsistOf(SatisfyAll(
+							HaveField("Action", apply.ActionUpdate),
+							HaveField("Kind", appsv1.SchemeGroupVersion.WithKind("DaemonSet").String()),
+							HaveField("Name", dsKey.Name),
+						)))
+					})
+				})
 			})
 
 			Context("with NodeGroupConfig", func() {
@@ -1924,6 +1962,19 @@ var _ = Describe("Test NUMAResourcesOperator Reconcile", func() {
 	})
 })
 
+func getDryRunPlan(cli client.Client, ownerName string) []apply.Change {
+	GinkgoHelper()
+	cm := &corev1.ConfigMap{}
+	key := client.ObjectKey{
+		Name:      objectnames.GetDryRunPlanName(ownerName),
+		Namespace: testNamespace,
+	}
+	Expect(cli.Get(context.TODO(), key, cm)).To(Succeed())
+	var changes []apply.Change
+	Expect(json.Unmarshal([]byte(cm.Data[DryRunPlanKey]), &changes)).To(Succeed())
+	return changes
+}
+
 func getConditionByType(conditions []metav1.Condition, conditionType string) *metav1.Condition {
 	for i := range conditions {
 		c := &conditions[i]
@@ -28,6 +28,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -37,19 +38,18 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
-	k8swgmanifests "github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
 	k8swgrbacupdate "github.com/k8stopologyawareschedwg/deployer/pkg/objectupdate/rbac"
 
 	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
 	"github.com/openshift-kni/numaresources-operator/internal/api/annotations"
 	"github.com/openshift-kni/numaresources-operator/internal/relatedobjects"
-	"github.com/openshift-kni/numaresources-operator/pkg/apply"
 	"github.com/openshift-kni/numaresources-operator/pkg/hash"
 	"github.com/openshift-kni/numaresources-operator/pkg/loglevel"
 	nrosched "github.com/openshift-kni/numaresources-operator/pkg/numaresourcesscheduler"
 	schedmanifests "github.com/openshift-kni/numaresources-operator/pkg/numaresourcesscheduler/manifests/sched"
 	schedstate "github.com/openshift-kni/numaresources-operator/pkg/numaresourcesscheduler/objectstate/sched"
 	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectstate"
 	schedupdate "github.com/openshift-kni/numaresources-operator/pkg/objectupdate/sched"
 	"github.com/openshift-kni/numaresources-operator/pkg/status"
 )
@@ -58,9 +58,14 @@ import (
 type NUMAResourcesSchedulerReconciler struct {
 	client.Client
 	Scheme             *runtime.Scheme
+	Recorder           record.EventRecorder
 	SchedulerManifests schedmanifests.Manifests
 	Namespace          string
 	AutodetectReplicas int
+	// PFPStatusImage is the image of the sidecar serving the pod fingerprint status dumps
+	PFPStatusImage string
+	// ServerSideApply enables the server-side apply of the supported scheduler objects
+	ServerSideApply bool
 }
 
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=*
@@ -68,6 +73,7 @@ type NUMAResourcesSchedulerReconciler struct {
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=*
 //+kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=*
 //+kubebuilder:rbac:groups="",resources=configmaps,verbs=*
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=nodetopology.openshift.io,resources=numaresourcesschedulers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=nodetopology.openshift.io,resources=numaresourcesschedulers/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=nodetopology.openshift.io,resources=numaresourcesschedulers/finalizers,verbs=update
@@ -108,6 +114,11 @@ func (r *NUMAResourcesSchedulerReconciler) Reconcile(ctx context.Context, req ct
 		return ctrl.Result{}, nil
 	}
 
+	if annotations.IsDryRunEnabled(instance.Annotations) {
+		klog.InfoS("Dry run enabled", "object", req.NamespacedName)
+		return r.reconcileDryRun(ctx, instance)
+	}
+
 	result, condition, err := r.reconcileResource(ctx, instance)
 	if err := r.updateStatus(ctx, instance, condition, status.ReasonFromError(err), status.MessageFromError(err)); err != nil {
 		klog.InfoS("Failed to update numaresourcesscheduler status", "Desired condition", condition, "error", err)
@@ -184,20 +195,46 @@ func (r *NUMAResourcesSchedulerReconciler) syncNUMASchedulerResources(ctx contex
 	klog.V(4).Info("SchedulerSync start")
 	defer klog.V(4).Info("SchedulerSync stop")
 
+	schedStatus, objStates, err := r.schedulerObjectStates(ctx, instance)
+	if err != nil {
+		return schedStatus, err
+	}
+	for _, objState := range objStates {
+		if err := controllerutil.SetControllerReference(instance, objState.Desired, r.Scheme); err != nil {
+			return schedStatus, fmt.Errorf("failed to set controller reference to %s %s: %w", objState.Desired.GetNamespace(), objState.Desired.GetName(), err)
+		}
+		obj, _, err := applyObject(ctx, r.Client, r.ServerSideApply, r.Recorder, instance, objState)
+		if err != nil {
+			return schedStatus, fmt.Errorf("could not apply (%s) %s/%s: %w", objState.Desired.GetObjectKind().GroupVersionKind(), objState.Desired.GetNamespace(), objState.Desired.GetName(), err)
+		}
+
+		if nname, ok := schedstate.DeploymentNamespacedNameFromObject(obj); ok {
+			schedStatus.Deployment = nname
+		}
+		if schedName, ok := schedstate.SchedulerNameFromObject(obj); ok {
+			schedStatus.SchedulerName = schedName
+		}
+	}
+	return schedStatus, nil
+}
+
+// schedulerObjectStates updates the scheduler manifests as per the instance spec, and computes the state
+// of the scheduler objects which need to be reconciled.
+func (r *NUMAResourcesSchedulerReconciler) schedulerObjectStates(ctx context.Context, instance *nropv1.NUMAResourcesScheduler) (nropv1.NUMAResourcesSchedulerStatus, []objectstate.ObjectState, error) {
 	schedSpec := instance.Spec.Normalize()
-	cacheResyncPeriod := unpackAPIResyncPeriod(schedSpec.CacheResyncPeriod)
-	params := configParamsFromSchedSpec(schedSpec, cacheResyncPeriod, r.Namespace)
+	cacheResyncPeriod := schedupdate.CacheResyncPeriod(schedSpec.CacheResyncPeriod)
+	params := schedupdate.ConfigParamsFromSpec(schedSpec, cacheResyncPeriod, r.Namespace)
 
 	schedName, ok := schedstate.SchedulerNameFromObject(r.SchedulerManifests.ConfigMap)
 	if !ok {
 		err := fmt.Errorf("missing scheduler name in builtin config map")
 		klog.V(2).ErrorS(err, "cannot find the scheduler profile name")
-		return nropv1.NUMAResourcesSchedulerStatus{}, err
+		return nropv1.NUMAResourcesSchedulerStatus{}, nil, err
 	}
 	klog.V(4).InfoS("detected scheduler profile", "profileName", schedName)
 
 	if err := schedupdate.SchedulerConfig(r.SchedulerManifests.ConfigMap, schedName, &params); err != nil {
-		return nropv1.NUMAResourcesSchedulerStatus{}, err
+		return nropv1.NUMAResourcesSchedulerStatus{}, nil, err
 	}
 
 	schedStatus := nropv1.NUMAResourcesSchedulerStatus{
@@ -219,31 +256,16 @@ func (r *NUMAResourcesSchedulerReconciler) syncNUMASchedulerResources(ctx contex
 	cmHash := hash.ConfigMapData(r.SchedulerManifests.ConfigMap)
 	schedupdate.DeploymentConfigMapSettings(r.SchedulerManifests.Deployment, r.SchedulerManifests.ConfigMap.Name, cmHash)
 	if err := loglevel.UpdatePodSpec(&r.SchedulerManifests.Deployment.Spec.Template.Spec, "", schedSpec.LogLevel); err != nil {
-		return schedStatus, err
+		return schedStatus, nil, err
 	}
 
 	schedupdate.DeploymentEnvVarSettings(r.SchedulerManifests.Deployment, schedSpec)
+	schedupdate.DeploymentPFPStatusSidecar(r.SchedulerManifests.Deployment, schedSpec, r.PFPStatusImage)
 
 	k8swgrbacupdate.RoleForLeaderElection(r.SchedulerManifests.Role, r.Namespace, nrosched.LeaderElectionResourceName)
 
 	existing := schedstate.FromClient(ctx, r.Client, r.SchedulerManifests)
-	for _, objState := range existing.State(r.SchedulerManifests) {
-		if err := controllerutil.SetControllerReference(instance, objState.Desired, r.Scheme); err != nil {
-			return schedStatus, fmt.Errorf("failed to set controller reference to %s %s: %w", objState.Desired.GetNamespace(), objState.Desired.GetName(), err)
-		}
-		obj, _, err := apply.ApplyObject(ctx, r.Client, objState)
-		if err != nil {
-			return schedStatus, fmt.Errorf("could not apply (%s) %s/%s: %w", objState.Desired.GetObjectKind().GroupVersionKind(), objState.Desired.GetNamespace(), objState.Desired.GetName(), err)
-		}
-
-		if nname, ok := schedstate.DeploymentNamespacedNameFromObject(obj); ok {
-			schedStatus.Deployment = nname
-		}
-		if schedName, ok := schedstate.SchedulerNameFromObject(obj); ok {
-			schedStatus.SchedulerName = schedName
-		}
-	}
-	return schedStatus, nil
+	return schedStatus, existing.State(r.SchedulerManifests), nil
 }
 
 func (r *NUMAResourcesSchedulerReconciler) updateStatus(ctx context.Context, sched *nropv1.NUMAResourcesScheduler, condition string, reason string, message string) error {
@@ -253,106 +275,3 @@ func (r *NUMAResourcesSchedulerReconciler) updateStatus(ctx context.Context, sch
 	}
 	return nil
 }
-
-func unpackAPIResyncPeriod(reconcilePeriod *metav1.Duration) time.Duration {
-	period := reconcilePeriod.Round(time.Second)
-	klog.InfoS("setting reconcile period", "computed", period, "supplied", reconcilePeriod)
-	return period
-}
-
-func configParamsFromSchedSpec(schedSpec nropv1.NUMAResourcesSchedulerSpec, cacheResyncPeriod time.Duration, namespace string) k8swgmanifests.ConfigParams {
-	resyncPeriod := int64(cacheResyncPeriod.Seconds())
-	// if no actual replicas are required, leader election is unnecessary, so
-	// we force it to off to reduce the background noise.
-	// note: the api validation/normalization layer must ensure this value is != nil
-	leaderElect := (*schedSpec.Replicas > 1)
-
-	params := k8swgmanifests.ConfigParams{
-		ProfileName: schedSpec.SchedulerName,
-		Cache: &k8swgmanifests.ConfigCacheParams{
-			ResyncPeriodSeconds: &resyncPeriod,
-		},
-		ScoringStrategy: &k8swgmanifests.ScoringStrategyParams{},
-		LeaderElection: &k8swgmanifests.LeaderElectionParams{
-			// Make sure to always set explicitly the value and override the configmap defaults.
-			LeaderElect: leaderElect,
-			// unconditionally set those to make sure
-			// to play nice with the cluster and the main scheduler
-			ResourceNamespace: namespace,
-			ResourceName:      nrosched.LeaderElectionResourceName,
-		},
-	}
-
-	klog.V(2).InfoS("setting leader election parameters", dumpLeaderElectionParams(params.LeaderElection)...)
-
-	var foreignPodsDetect string
-	var resyncMethod string = k8swgmanifests.CacheResyncAutodetect
-	var informerMode string
-	var scoringStrategyType string
-	if *schedSpec.CacheResyncDetection == nropv1.CacheResyncDetectionRelaxed {
-		foreignPodsDetect = k8swgmanifests.ForeignPodsDetectOnlyExclusiveResources
-	} else {
-		foreignPodsDetect = k8swgmanifests.ForeignPodsDetectAll
-	}
-	if *schedSpec.SchedulerInformer == k8swgmanifests.CacheInformerDedicated {
-		informerMode = k8swgmanifests.CacheInformerDedicated
-	} else {
-		informerMode = k8swgmanifests.CacheInformerShared
-	}
-	if schedSpec.ScoringStrategy.Type == nropv1.LeastAllocated {
-		scoringStrategyType = k8swgmanifests.ScoringStrategyLeastAllocated
-	} else if schedSpec.ScoringStrategy.Type == nropv1.BalancedAllocation {
-		scoringStrategyType = k8swgmanifests.ScoringStrategyBalancedAllocation
-	} else if schedSpec.ScoringStrategy.Type == nropv1.MostAllocated {
-		scoringStrategyType = k8swgmanifests.ScoringStrategyMostAllocated
-	} else {
-		scoringStrategyType = k8swgmanifests.ScoringStrategyLeastAllocated
-	}
-	params.ScoringStrategy.Type = scoringStrategyType
-
-	var resources []k8swgmanifests.ResourceSpecParams
-	for _, resource := range schedSpec.ScoringStrategy.Resources {
-		resources = append(resources, k8swgmanifests.ResourceSpecParams{
-			Name:   resource.Name,
-			Weight: resource.Weight,
-		})
-	}
-	params.ScoringStrategy.Resources = resources
-	params.Cache.ResyncMethod = &resyncMethod
-	params.Cache.ForeignPodsDetectMode = &foreignPodsDetect
-	params.Cache.InformerMode = &informerMode
-	klog.V(2).InfoS("setting cache parameters", dumpConfigCacheParams(params.Cache)...)
-
-	return params
-}
-
-func dumpConfigCacheParams(ccp *k8swgmanifests.ConfigCacheParams) []interface{} {
-	return []interface{}{
-		"resyncPeriod", strInt64Ptr(ccp.ResyncPeriodSeconds),
-		"resyncMethod", strStringPtr(ccp.ResyncMethod),
-		"foreignPodsDetectMode", strStringPtr(ccp.ForeignPodsDetectMode),
-		"informerMode", strStringPtr(ccp.InformerMode),
-	}
-}
-
-func dumpLeaderElectionParams(lep *k8swgmanifests.LeaderElectionParams) []interface{} {
-	return []interface{}{
-		"leaderElect", lep.LeaderElect,
-		"resourceNamespace", lep.ResourceNamespace,
-		"resourceName", lep.ResourceName,
-	}
-}
-
-func strInt64Ptr(ip *int64) string {
-	if ip == nil {
-		return "N/A"
-	}
-	return fmt.Sprintf("%d", *ip)
-}
-
-func strStringPtr(sp *string) string {
-	if sp == nil {
-		return "N/A"
-	}
-	return *sp
-}
@@ -29,9 +29,11 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -40,6 +42,8 @@ import (
 	depmanifests "github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
 	depobjupdate "github.com/k8stopologyawareschedwg/deployer/pkg/objectupdate"
 	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	"github.com/openshift-kni/numaresources-operator/internal/api/annotations"
+	"github.com/openshift-kni/numaresources-operator/pkg/apply"
 	"github.com/openshift-kni/numaresources-operator/pkg/hash"
 	nrosched "github.com/openshift-kni/numaresources-operator/pkg/numaresourcesscheduler"
 	schedmanifests "github.com/openshift-kni/numaresources-operator/pkg/numaresourcesscheduler/manifests/sched"
@@ -62,6 +66,7 @@ func NewFakeNUMAResourcesSchedulerReconciler(initObjects ...runtime.Object) (*NU
 	return &NUMAResourcesSchedulerReconciler{
 		Client:             fakeClient,
 		Scheme:             scheme.Scheme,
+		Recorder:           record.NewFakeRecorder(bufferSize),
 		SchedulerManifests: schedMf,
 		Namespace:          testNamespace,
 	}, nil
@@ -132,6 +137,36 @@ var _ = ginkgo.Describe("Test NUMAResourcesScheduler Reconcile", func() {
 			gomega.Expect(reconciler.Client.Get(context.TODO(), key, dp)).ToNot(gomega.HaveOccurred())
 		})
 
+		ginkgo.It("should publish the plan without creating components when dry-run is enabled", func() {
+			nrs.Annotations = map[string]string{
+				annotations.DryRunAnnotation: annotations.DryRunAnnotationEnabled,
+			}
+			gomega.Expect(reconciler.Client.Update(context.TODO(), nrs)).To(gomega.Succeed())
+
+			key := client.ObjectKeyFromObject(nrs)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			key = client.ObjectKey{
+				Name:      "secondary-scheduler",
+				Namespace: testNamespace,
+			}
+			dp := &appsv1.Deployment{}
+			gomega.Expect(apierrors.IsNotFound(reconciler.Client.Get(context.TODO(), key, dp))).To(gomega.BeTrue())
+
+			changes := getDryRunPlan(reconciler.Client, nrs.Name)
+			gomega.Expect(changes).To(gomega.ContainElement(gomega.SatisfyAll(
+				gomega.HaveField("Action", apply.ActionCreate),
+				gomega.HaveField("Kind", appsv1.SchemeGroupVersion.WithKind("Deployment").String()),
+				gomega.HaveField("Name", key.Name),
+			)))
+
+			fakeRecorder, ok := reconciler.Recorder.(*record.FakeRecorder)
+			gomega.Expect(ok).To(gomega.BeTrue())
+			event := <-fakeRecorder.Events
+			gomega.Expect(event).To(gomega.ContainSubstring("DryRun"))
+		})
+
 		ginkgo.It("should have the correct schedulerName", func() {
 			key := client.ObjectKeyFromObject(nrs)
 			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
@@ -643,7 +678,7 @@ func expectCacheParams(cli client.Client, resyncMethod, foreignPodsDetect string
 	gomega.Expect(cfgs).To(gomega.HaveLen(1), "unexpected config params count: %d", len(cfgs))
 	cfg := cfgs[0]
 
-	klog.InfoS("config", dumpConfigCacheParams(cfg.Cache)...)
+	klog.InfoS("config", schedupdate.DumpConfigCacheParams(cfg.Cache)...)
 
 	gomega.Expect(cfg.Cache.ResyncMethod).ToNot(gomega.BeNil())
 	gomega.Expect(*cfg.Cache.ResyncMethod).To(gomega.Equal(resyncMethod))
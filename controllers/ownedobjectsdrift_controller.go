@@ -0,0 +1,243 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	"github.com/openshift-kni/numaresources-operator/internal/api/annotations"
+	"github.com/openshift-kni/numaresources-operator/internal/drift"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectstate"
+	"github.com/openshift-kni/numaresources-operator/pkg/status"
+)
+
+const (
+	defaultOwnedObjectsDriftCheckPeriod = 10 * time.Minute
+
+	// driftFieldsDepth is how deep the drifted fields are reported, enough to tell
+	// apart "metadata.labels" from "spec.template.spec" without listing every leaf.
+	driftFieldsDepth = 3
+)
+
+// OwnedObjectsDriftReconciler periodically compares the objects owned by the NUMAResourcesOperator
+// with their desired state, reports the ones which differ, and applies again their desired state
+// if the drift policy asks so.
+type OwnedObjectsDriftReconciler struct {
+	client.Client
+	Recorder record.EventRecorder
+	// Operator computes the desired state of the owned objects. Computing the desired state
+	// updates the manifests, so it must not share them with the NUMAResourcesOperator controller.
+	Operator    *NUMAResourcesOperatorReconciler
+	CheckPeriod time.Duration
+}
+
+//+kubebuilder:rbac:groups=nodetopology.openshift.io,resources=numaresourcesoperators/status,verbs=get;update;patch
+
+func (r *OwnedObjectsDriftReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	klog.V(3).InfoS("Starting owned objects drift check", "object", req.NamespacedName)
+	defer klog.V(3).InfoS("Finish owned objects drift check", "object", req.NamespacedName)
+
+	if req.Name != objectnames.DefaultNUMAResourcesOperatorCrName {
+		// the NUMAResourcesOperator controller will take care of this
+		return ctrl.Result{}, nil
+	}
+
+	instance := &nropv1.NUMAResourcesOperator{}
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	result := ctrl.Result{RequeueAfter: r.checkPeriod()}
+
+	if annotations.IsPauseReconciliationEnabled(instance.Annotations) {
+		klog.V(2).InfoS("Pause reconciliation enabled", "object", req.NamespacedName)
+		return result, nil
+	}
+	if annotations.IsDryRunEnabled(instance.Annotations) {
+		// the owned objects are not reconciled, so there is no desired state to drift from
+		klog.V(2).InfoS("Dry-run enabled", "object", req.NamespacedName)
+		return result, nil
+	}
+
+	policy := driftPolicy(instance)
+	drifted, err := r.findOwnedObjectsDrift(ctx, instance, policy == nropv1.DriftPolicyCorrect)
+	if err != nil {
+		klog.ErrorS(err, "failed to check owned objects drift", "controller", "ownedobjectsdrift")
+		return ctrl.Result{}, err
+	}
+
+	condStatus, reason, message := metav1.ConditionFalse, status.ReasonOwnedObjectsDriftNotDetected, ""
+	eventType := ""
+	if len(drifted) > 0 {
+		for _, obj := range drifted {
+			klog.InfoS("owned object drift detected", "kind", obj.Kind, "namespace", obj.Namespace, "name", obj.Name, "fields", obj.Fields, "corrected", obj.Corrected)
+		}
+		if policy == nropv1.DriftPolicyCorrect {
+			reason, eventType = status.ReasonOwnedObjectsDriftCorrected, corev1.EventTypeNormal
+			message = "owned objects drift corrected: " + strings.Join(driftedObjectNames(drifted), ",")
+		} else {
+			condStatus, reason, eventType = metav1.ConditionTrue, status.ReasonOwnedObjectsDriftDetected, corev1.EventTypeWarning
+			message = "owned objects differ from the desired state: " + strings.Join(driftedObjectNames(drifted), ",")
+		}
+	}
+
+	conditions, condUpdated := status.UpdateAuxiliaryCondition(instance.Status.Conditions, status.ConditionOwnedObjectsDrift, condStatus, reason, message)
+	// the check is periodic: report only the changes, not the same drift over and over
+	if condUpdated && eventType != "" {
+		r.Recorder.Event(instance, eventType, reason, message)
+	}
+	if !condUpdated && reflect.DeepEqual(instance.Status.DriftedObjects, drifted) {
+		return result, nil
+	}
+
+	instance.Status.Conditions = conditions
+	instance.Status.DriftedObjects = drifted
+	if err := r.Client.Status().Update(ctx, instance); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not update status for object %s: %w", client.ObjectKeyFromObject(instance), err)
+	}
+	return result, nil
+}
+
+func (r *OwnedObjectsDriftReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// the drift check is periodic: we only need to restart it when the spec changes,
+	// and we must not react on the status updates we do ourselves.
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("ownedobjectsdrift").
+		For(&nropv1.NUMAResourcesOperator{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
+
+func (r *OwnedObjectsDriftReconciler) checkPeriod() time.Duration {
+	if r.CheckPeriod == 0 {
+		return defaultOwnedObjectsDriftCheckPeriod
+	}
+	return r.CheckPeriod
+}
+
+// findOwnedObjectsDrift returns the existing owned objects which differ from their desired state, sorted by kind,
+// namespace and name. The missing objects are not reported: creating them is up to the NUMAResourcesOperator controller.
+func (r *OwnedObjectsDriftReconciler) findOwnedObjectsDrift(ctx context.Context, instance *nropv1.NUMAResourcesOperator, correct bool) ([]nropv1.DriftedObject, error) {
+	trees, err := getTreesByNodeGroup(ctx, r.Client, instance.Spec.NodeGroups, r.Operator.Platform)
+	if err != nil {
+		return nil, err
+	}
+	normalizeTreesConfig(trees)
+
+	objStates, err := r.Operator.ownedObjectStates(ctx, instance, trees)
+	if err != nil {
+		return nil, err
+	}
+
+	var drifted []nropv1.DriftedObject
+	for _, objState := range objStates {
+		if !objState.IsCreateOrUpdate() || objState.Error != nil || objState.Existing == nil {
+			continue
+		}
+		fields, err := driftedFields(objState)
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		gvk, err := apiutil.GVKForObject(objState.Desired, r.Scheme())
+		if err != nil {
+			return nil, err
+		}
+		obj := nropv1.DriftedObject{
+			Kind:      gvk.Kind,
+			Namespace: objState.Desired.GetNamespace(),
+			Name:      objState.Desired.GetName(),
+			Fields:    fields,
+		}
+		if correct {
+			if _, _, err := applyObject(ctx, r.Client, r.Operator.ServerSideApply, r.Recorder, instance, objState); err != nil {
+				return nil, fmt.Errorf("failed to correct the drift of %s %s/%s: %w", obj.Kind, obj.Namespace, obj.Name, err)
+			}
+			obj.Corrected = true
+		}
+		drifted = append(drifted, obj)
+	}
+
+	sort.Slice(drifted, func(i, j int) bool {
+		if drifted[i].Kind != drifted[j].Kind {
+			return drifted[i].Kind < drifted[j].Kind
+		}
+		if drifted[i].Namespace != drifted[j].Namespace {
+			return drifted[i].Namespace < drifted[j].Namespace
+		}
+		return drifted[i].Name < drifted[j].Name
+	})
+	return drifted, nil
+}
+
+// driftedFields returns the fields of the existing object which would change applying the desired state.
+// The desired state must be contained in the existing object: the fields the API server defaults and the
+// status are never set in the desired state, so their differences are not drifts.
+func driftedFields(objState objectstate.ObjectState) ([]string, error) {
+	merged, err := objState.Merge(objState.Existing, objState.Desired)
+	if err != nil {
+		return nil, fmt.Errorf("could not merge object %s/%s with existing: %w", objState.Desired.GetNamespace(), objState.Desired.GetName(), err)
+	}
+	if equality.Semantic.DeepDerivative(merged, objState.Existing) {
+		return nil, nil
+	}
+	return drift.Fields(objState.Existing, merged, driftFieldsDepth)
+}
+
+func driftPolicy(instance *nropv1.NUMAResourcesOperator) nropv1.DriftPolicy {
+	if instance.Spec.DriftPolicy == nil {
+		return nropv1.DriftPolicyReport
+	}
+	return *instance.Spec.DriftPolicy
+}
+
+func driftedObjectNames(drifted []nropv1.DriftedObject) []string {
+	names := make([]string, 0, len(drifted))
+	for _, obj := range drifted {
+		name := obj.Name
+		if obj.Namespace != "" {
+			name = obj.Namespace + "/" + name
+		}
+		names = append(names, obj.Kind+" "+name)
+	}
+	return names
+}
@@ -0,0 +1,213 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	machineconfigv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	"github.com/openshift-kni/numaresources-operator/internal/api/annotations"
+	testobjs "github.com/openshift-kni/numaresources-operator/internal/objects"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
+	"github.com/openshift-kni/numaresources-operator/pkg/status"
+)
+
+func NewFakeOwnedObjectsDriftReconciler(nroReconciler *NUMAResourcesOperatorReconciler) *OwnedObjectsDriftReconciler {
+	operator := *nroReconciler
+	operator.APIManifests = nroReconciler.APIManifests.Clone()
+	operator.RTEManifests = nroReconciler.RTEManifests.Clone()
+	return &OwnedObjectsDriftReconciler{
+		Client:      nroReconciler.Client,
+		Recorder:    record.NewFakeRecorder(bufferSize),
+		Operator:    &operator,
+		CheckPeriod: time.Minute,
+	}
+}
+
+var _ = Describe("Test OwnedObjectsDrift Reconcile", func() {
+	var nro *nropv1.NUMAResourcesOperator
+	var mcp *machineconfigv1.MachineConfigPool
+	var reconciler *OwnedObjectsDriftReconciler
+	var dsKey client.ObjectKey
+	pn := "test"
+
+	reconcileAndGet := func() *nropv1.NUMAResourcesOperator {
+		GinkgoHelper()
+		key := client.ObjectKeyFromObject(nro)
+		result, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(reconcile.Result{RequeueAfter: time.Minute}))
+
+		updated := &nropv1.NUMAResourcesOperator{}
+		Expect(reconciler.Client.Get(context.TODO(), key, updated)).To(Succeed())
+		return updated
+	}
+
+	changeDaemonSetImage := func(image string) {
+		GinkgoHelper()
+		ds := &appsv1.DaemonSet{}
+		Expect(reconciler.Client.Get(context.TODO(), dsKey, ds)).To(Succeed())
+		ds.Spec.Template.Spec.Containers[0].Image = image
+		Expect(reconciler.Client.Update(context.TODO(), ds)).To(Succeed())
+	}
+
+	setDriftPolicy := func(policy nropv1.DriftPolicy) {
+		GinkgoHelper()
+		updated := &nropv1.NUMAResourcesOperator{}
+		Expect(reconciler.Client.Get(context.TODO(), client.ObjectKeyFromObject(nro), updated)).To(Succeed())
+		updated.Spec.DriftPolicy = &policy
+		Expect(reconciler.Client.Update(context.TODO(), updated)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		labSel := metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				pn: pn,
+			},
+		}
+		mcp = testobjs.NewMachineConfigPool(pn, labSel.MatchLabels, &labSel, &labSel)
+		nro = testobjs.NewNUMAResourcesOperatorWithNodeGroupConfig(objectnames.DefaultNUMAResourcesOperatorCrName, pn, nil)
+		reconciler = NewFakeOwnedObjectsDriftReconciler(reconcileObjectsOpenshift(nro, mcp))
+		dsKey = client.ObjectKey{
+			Name:      objectnames.GetComponentName(nro.Name, pn),
+			Namespace: testNamespace,
+		}
+	})
+
+	It("should report no drift right after the reconciliation", func() {
+		updated := reconcileAndGet()
+		Expect(updated.Status.DriftedObjects).To(BeEmpty())
+		cond := status.FindCondition(updated.Status.Conditions, status.ConditionOwnedObjectsDrift)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(status.ReasonOwnedObjectsDriftNotDetected))
+	})
+
+	It("should not report the fields set by the API server as drift", func() {
+		ds := &appsv1.DaemonSet{}
+		Expect(reconciler.Client.Get(context.TODO(), dsKey, ds)).To(Succeed())
+		revisionHistoryLimit := int32(10)
+		ds.Spec.RevisionHistoryLimit = &revisionHistoryLimit
+		ds.Spec.UpdateStrategy.Type = appsv1.RollingUpdateDaemonSetStrategyType
+		ds.Spec.Template.Spec.DNSPolicy = corev1.DNSClusterFirst
+		ds.Spec.Template.Spec.SchedulerName = corev1.DefaultSchedulerName
+		for idx := range ds.Spec.Template.Spec.Containers {
+			ds.Spec.Template.Spec.Containers[idx].TerminationMessagePath = corev1.TerminationMessagePathDefault
+		}
+		Expect(reconciler.Client.Update(context.TODO(), ds)).To(Succeed())
+		ds.Status.DesiredNumberScheduled = 3
+		ds.Status.NumberReady = 3
+		Expect(reconciler.Client.Status().Update(context.TODO(), ds)).To(Succeed())
+
+		Expect(reconciler.Client.Get(context.TODO(), dsKey, ds)).To(Succeed())
+		Expect(ds.Status.NumberReady).To(Equal(int32(3)))
+		Expect(ds.Spec.Template.Spec.SchedulerName).To(Equal(corev1.DefaultSchedulerName))
+
+		updated := reconcileAndGet()
+		Expect(updated.Status.DriftedObjects).To(BeEmpty())
+		cond := status.FindCondition(updated.Status.Conditions, status.ConditionOwnedObjectsDrift)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+	})
+
+	It("should report the drifted DaemonSet without correcting it by default", func() {
+		changeDaemonSetImage("madeup-image:1")
+
+		updated := reconcileAndGet()
+		Expect(updated.Status.DriftedObjects).To(ConsistOf(nropv1.DriftedObject{
+			Kind:      "DaemonSet",
+			Namespace: dsKey.Namespace,
+			Name:      dsKey.Name,
+			Fields:    []string{"spec.template.spec"},
+		}))
+		cond := status.FindCondition(updated.Status.Conditions, status.ConditionOwnedObjectsDrift)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(status.ReasonOwnedObjectsDriftDetected))
+		Expect(cond.Message).To(ContainSubstring(dsKey.Name))
+
+		ds := &appsv1.DaemonSet{}
+		Expect(reconciler.Client.Get(context.TODO(), dsKey, ds)).To(Succeed())
+		Expect(ds.Spec.Template.Spec.Containers[0].Image).To(Equal("madeup-image:1"))
+
+		fakeRecorder, ok := reconciler.Recorder.(*record.FakeRecorder)
+		Expect(ok).To(BeTrue())
+		event := <-fakeRecorder.Events
+		Expect(event).To(ContainSubstring(status.ReasonOwnedObjectsDriftDetected))
+
+		// the same drift must not be reported again on the next periodic check
+		reconcileAndGet()
+		Expect(fakeRecorder.Events).To(BeEmpty())
+	})
+
+	It("should correct the drifted DaemonSet if the policy asks so", func() {
+		setDriftPolicy(nropv1.DriftPolicyCorrect)
+		changeDaemonSetImage("madeup-image:1")
+
+		updated := reconcileAndGet()
+		Expect(updated.Status.DriftedObjects).To(ConsistOf(nropv1.DriftedObject{
+			Kind:      "DaemonSet",
+			Namespace: dsKey.Namespace,
+			Name:      dsKey.Name,
+			Fields:    []string{"spec.template.spec"},
+			Corrected: true,
+		}))
+		cond := status.FindCondition(updated.Status.Conditions, status.ConditionOwnedObjectsDrift)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(status.ReasonOwnedObjectsDriftCorrected))
+
+		ds := &appsv1.DaemonSet{}
+		Expect(reconciler.Client.Get(context.TODO(), dsKey, ds)).To(Succeed())
+		Expect(ds.Spec.Template.Spec.Containers[0].Image).To(Equal(testImageSpec))
+
+		By("checking the drift is gone in the next scan")
+		updated = reconcileAndGet()
+		Expect(updated.Status.DriftedObjects).To(BeEmpty())
+		cond = status.FindCondition(updated.Status.Conditions, status.ConditionOwnedObjectsDrift)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Reason).To(Equal(status.ReasonOwnedObjectsDriftNotDetected))
+	})
+
+	It("should not check the drift when the reconciliation is paused", func() {
+		updated := &nropv1.NUMAResourcesOperator{}
+		Expect(reconciler.Client.Get(context.TODO(), client.ObjectKeyFromObject(nro), updated)).To(Succeed())
+		if updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
+		updated.Annotations[annotations.PauseReconciliationAnnotation] = annotations.PauseReconciliationAnnotationEnabled
+		Expect(reconciler.Client.Update(context.TODO(), updated)).To(Succeed())
+		changeDaemonSetImage("madeup-image:1")
+
+		updated = reconcileAndGet()
+		Expect(updated.Status.DriftedObjects).To(BeEmpty())
+		Expect(status.FindCondition(updated.Status.Conditions, status.ConditionOwnedObjectsDrift)).To(BeNil())
+	})
+})
@@ -0,0 +1,145 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	kubeletconfigv1beta1 "k8s.io/kubelet/config/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	"github.com/openshift-kni/numaresources-operator/pkg/kubeletconfig"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectstate"
+	apistate "github.com/openshift-kni/numaresources-operator/pkg/objectstate/api"
+	rtestate "github.com/openshift-kni/numaresources-operator/pkg/objectstate/rte"
+	"github.com/openshift-kni/numaresources-operator/pkg/validation"
+)
+
+// The Render* functions compute the objects the reconciliation loops would apply, without changing
+// anything. The reconcilers clients are only used to read the objects the desired state depends on,
+// like the MachineConfigPools. The rendered objects have no owner references, because these depend
+// on the live objects.
+
+// RenderObjects returns the objects the reconciliation of the given NUMAResourcesOperator would apply.
+// The configMaps are the rendered RTE configurations, which the daemonsets track in place of the live ones.
+func (r *NUMAResourcesOperatorReconciler) RenderObjects(ctx context.Context, instance *nropv1.NUMAResourcesOperator, configMaps []*corev1.ConfigMap) ([]client.Object, error) {
+	if err := validation.NodeGroups(instance.Spec.NodeGroups, r.Platform); err != nil {
+		return nil, err
+	}
+
+	trees, err := getTreesByNodeGroup(ctx, r.Client, instance.Spec.NodeGroups, r.Platform)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Platform == platform.OpenShift {
+		if err := validation.MachineConfigPoolDuplicates(trees); err != nil {
+			return nil, err
+		}
+	}
+
+	normalizeTreesConfig(trees)
+
+	existingAPI := apistate.FromClient(ctx, r.Client, r.Platform, r.APIManifests)
+	objs := desiredObjects(existingAPI.State(r.APIManifests))
+
+	if r.Platform == platform.OpenShift {
+		existing := rtestate.FromClient(ctx, r.Client, r.Platform, r.RTEManifests, instance, trees, r.Namespace)
+		mcStates, _ := existing.MachineConfigsState(r.RTEManifests)
+		for _, objState := range mcStates {
+			if objState.Desired == nil {
+				continue
+			}
+			if err := validateMachineConfigLabels(objState.Desired, trees); err != nil {
+				return nil, err
+			}
+			objs = append(objs, objState.Desired)
+		}
+	}
+
+	objStates, dsPoolPairs, err := r.rteObjectStates(ctx, instance, trees, r.renderedConfigMap(configMaps))
+	if err != nil {
+		return nil, err
+	}
+	if len(dsPoolPairs) < len(trees) {
+		klog.Warningf("daemonset and tree size mismatch: expected %d got in daemonsets %d", len(trees), len(dsPoolPairs))
+	}
+	return append(objs, desiredObjects(objStates)...), nil
+}
+
+// RenderObjects returns the objects the reconciliation of the given NUMAResourcesScheduler would apply.
+func (r *NUMAResourcesSchedulerReconciler) RenderObjects(ctx context.Context, instance *nropv1.NUMAResourcesScheduler) ([]client.Object, error) {
+	_, objStates, err := r.schedulerObjectStates(ctx, instance)
+	if err != nil {
+		return nil, err
+	}
+	return desiredObjects(objStates), nil
+}
+
+// RenderConfigMap returns the RTE configuration the reconciliation of the given kubelet configuration
+// object would apply, taking into account all the kubelet configurations targeting the same pool.
+// Returns nil if the object does not contribute to the RTE configuration.
+func (r *KubeletConfigReconciler) RenderConfigMap(ctx context.Context, instance *nropv1.NUMAResourcesOperator, kcKey client.ObjectKey) (*corev1.ConfigMap, error) {
+	kcHandler, kubeletConfig, err := r.effectiveKubeletConfig(ctx, instance, kcKey)
+	if err != nil || kcHandler == nil {
+		return nil, err
+	}
+	return r.renderConfigMap(kubeletConfig, instance, kcHandler.poolName)
+}
+
+// effectiveKubeletConfig returns the handler and the parsed effective kubelet configuration of the pool the given
+// kubelet configuration object targets. Returns a nil handler if the object does not contribute to the RTE configuration.
+func (r *KubeletConfigReconciler) effectiveKubeletConfig(ctx context.Context, instance *nropv1.NUMAResourcesOperator, kcKey client.ObjectKey) (*kubeletConfigHandler, *kubeletconfigv1beta1.KubeletConfiguration, error) {
+	kcHandler, err := r.makeKCHandlerForPlatform(ctx, instance, kcKey)
+	if err != nil {
+		var klErr *InvalidKubeletConfig
+		if errors.As(err, &klErr) {
+			klog.InfoS("ignored kubelet config", "name", klErr.ObjectName)
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	if len(kcHandler.sources) == 0 {
+		return nil, nil, nil
+	}
+	if err := kcHandler.selectEffective(); err != nil {
+		return nil, nil, err
+	}
+	kubeletConfig, err := kubeletconfig.MCOKubeletConfToKubeletConf(kcHandler.mcoKc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse KubeletConfig object %s: %w", kcHandler.ownerObject.GetName(), err)
+	}
+	return kcHandler, kubeletConfig, nil
+}
+
+func desiredObjects(objStates []objectstate.ObjectState) []client.Object {
+	objs := make([]client.Object, 0, len(objStates))
+	for _, objState := range objStates {
+		if objState.Desired == nil {
+			continue
+		}
+		objs = append(objs, objState.Desired)
+	}
+	return objs
+}
@@ -0,0 +1,176 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeletconfigv1beta1 "k8s.io/kubelet/config/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
+	machineconfigv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	testobjs "github.com/openshift-kni/numaresources-operator/internal/objects"
+	"github.com/openshift-kni/numaresources-operator/pkg/hash"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
+)
+
+var _ = Describe("Test offline rendering", func() {
+	var label map[string]string
+	var mcp *machineconfigv1.MachineConfigPool
+
+	BeforeEach(func() {
+		label = map[string]string{
+			"test": "test",
+		}
+		mcp = testobjs.NewMachineConfigPool("test", label, &metav1.LabelSelector{MatchLabels: label}, &metav1.LabelSelector{MatchLabels: label})
+	})
+
+	It("should render the RTE objects the NUMAResourcesOperator reconciliation applies", func() {
+		nro := testobjs.NewNUMAResourcesOperator(objectnames.DefaultNUMAResourcesOperatorCrName, nropv1.NodeGroup{
+			MachineConfigPoolSelector: &metav1.LabelSelector{MatchLabels: label},
+		})
+
+		renderer, err := NewFakeNUMAResourcesOperatorReconciler(platform.OpenShift, defaultOCPVersion, nro.DeepCopy(), mcp.DeepCopy())
+		Expect(err).ToNot(HaveOccurred())
+		objs, err := renderer.RenderObjects(context.TODO(), nro.DeepCopy(), nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		reconciler := reconcileObjectsOpenshift(nro, mcp)
+
+		var dss int
+		for _, obj := range objs {
+			ds, ok := obj.(*appsv1.DaemonSet)
+			if !ok {
+				continue
+			}
+			dss++
+			Expect(ds.Name).To(Equal(objectnames.GetComponentName(nro.Name, mcp.Name)))
+			live := &appsv1.DaemonSet{}
+			Expect(reconciler.Client.Get(context.TODO(), client.ObjectKeyFromObject(ds), live)).To(Succeed())
+			Expect(ds.Spec).To(Equal(live.Spec))
+		}
+		Expect(dss).To(Equal(1))
+
+		// rendering must not touch the cluster state
+		ds := &appsv1.DaemonSet{}
+		key := client.ObjectKey{Namespace: testNamespace, Name: objectnames.GetComponentName(nro.Name, mcp.Name)}
+		Expect(renderer.Client.Get(context.TODO(), key, ds)).ToNot(Succeed())
+	})
+
+	It("should track the rendered RTE configuration in the daemonsets", func() {
+		nro := testobjs.NewNUMAResourcesOperator(objectnames.DefaultNUMAResourcesOperatorCrName, nropv1.NodeGroup{
+			MachineConfigPoolSelector: &metav1.LabelSelector{MatchLabels: label},
+		})
+
+		renderer, err := NewFakeNUMAResourcesOperatorReconciler(platform.OpenShift, defaultOCPVersion, nro.DeepCopy(), mcp.DeepCopy())
+		Expect(err).ToNot(HaveOccurred())
+		renderer.RTEManifests.ConfigMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: testNamespace,
+				Name:      "rte-config",
+			},
+			Data: map[string]string{
+				"data": "from-manifests",
+			},
+		}
+		rendered := renderer.RTEManifests.ConfigMap.DeepCopy()
+		rendered.Data["data"] = "rendered"
+
+		objs, err := renderer.RenderObjects(context.TODO(), nro.DeepCopy(), []*corev1.ConfigMap{rendered})
+		Expect(err).ToNot(HaveOccurred())
+
+		var dss int
+		for _, obj := range objs {
+			ds, ok := obj.(*appsv1.DaemonSet)
+			if !ok {
+				continue
+			}
+			dss++
+			Expect(ds.Spec.Template.Annotations).To(HaveKeyWithValue(hash.ConfigMapAnnotation, hash.ConfigMapData(rendered)))
+		}
+		Expect(dss).To(Equal(1))
+	})
+
+	It("should render the objects the NUMAResourcesScheduler reconciliation applies", func() {
+		nrs := testobjs.NewNUMAResourcesScheduler(objectnames.DefaultNUMAResourcesSchedulerCrName, "some/url:latest", testSchedulerName, 11*time.Second)
+
+		renderer, err := NewFakeNUMAResourcesSchedulerReconciler(nrs.DeepCopy())
+		Expect(err).ToNot(HaveOccurred())
+		objs, err := renderer.RenderObjects(context.TODO(), nrs.DeepCopy())
+		Expect(err).ToNot(HaveOccurred())
+
+		reconciler, err := NewFakeNUMAResourcesSchedulerReconciler(nrs)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(nrs)})
+		Expect(err).ToNot(HaveOccurred())
+
+		var found int
+		for _, obj := range objs {
+			switch rendered := obj.(type) {
+			case *appsv1.Deployment:
+				live := &appsv1.Deployment{}
+				Expect(reconciler.Client.Get(context.TODO(), client.ObjectKeyFromObject(rendered), live)).To(Succeed())
+				Expect(rendered.Spec).To(Equal(live.Spec))
+				found++
+			case *corev1.ConfigMap:
+				live := &corev1.ConfigMap{}
+				Expect(reconciler.Client.Get(context.TODO(), client.ObjectKeyFromObject(rendered), live)).To(Succeed())
+				Expect(rendered.Data).To(Equal(live.Data))
+				found++
+			}
+		}
+		Expect(found).To(Equal(2))
+	})
+
+	It("should render the RTE configuration the KubeletConfig reconciliation applies", func() {
+		nro := testobjs.NewNUMAResourcesOperator(objectnames.DefaultNUMAResourcesOperatorCrName, nropv1.NodeGroup{
+			MachineConfigPoolSelector: &metav1.LabelSelector{MatchLabels: label},
+		})
+		kubeletConfig := &kubeletconfigv1beta1.KubeletConfiguration{
+			TopologyManagerPolicy: "single-numa-node",
+		}
+		mcoKc := testobjs.NewKubeletConfig("test", label, mcp.Spec.MachineConfigSelector, kubeletConfig)
+		key := client.ObjectKeyFromObject(mcoKc)
+
+		renderer, err := NewFakeKubeletConfigReconciler(nro.DeepCopy(), mcp.DeepCopy(), mcoKc.DeepCopy())
+		Expect(err).ToNot(HaveOccurred())
+		rendered, err := renderer.RenderConfigMap(context.TODO(), nro.DeepCopy(), key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rendered).ToNot(BeNil())
+
+		reconciler, err := NewFakeKubeletConfigReconciler(nro, mcp, mcoKc)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+
+		live := &corev1.ConfigMap{}
+		Expect(reconciler.Client.Get(context.TODO(), client.ObjectKeyFromObject(rendered), live)).To(Succeed())
+		Expect(rendered.Data).To(Equal(live.Data))
+		Expect(rendered.Labels).To(Equal(live.Labels))
+	})
+})
@@ -0,0 +1,227 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+	mcov1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	"github.com/openshift-kni/numaresources-operator/internal/api/annotations"
+	"github.com/openshift-kni/numaresources-operator/internal/drift"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
+	"github.com/openshift-kni/numaresources-operator/pkg/status"
+	rteconfig "github.com/openshift-kni/numaresources-operator/rte/pkg/config"
+)
+
+const (
+	defaultTopologyManagerDriftCheckPeriod = 5 * time.Minute
+)
+
+// TopologyManagerDriftReconciler periodically compares the topology manager configuration
+// rendered for each pool with the attributes published in the NRT objects of the nodes
+// belonging to that pool, and reports the nodes which don't match.
+type TopologyManagerDriftReconciler struct {
+	client.Client
+	Recorder    record.EventRecorder
+	Namespace   string
+	Platform    platform.Platform
+	CheckPeriod time.Duration
+}
+
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=topology.node.k8s.io,resources=noderesourcetopologies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=nodetopology.openshift.io,resources=numaresourcesoperators/status,verbs=get;update;patch
+
+func (r *TopologyManagerDriftReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	klog.V(3).InfoS("Starting TopologyManager drift check", "object", req.NamespacedName)
+	defer klog.V(3).InfoS("Finish TopologyManager drift check", "object", req.NamespacedName)
+
+	if req.Name != objectnames.DefaultNUMAResourcesOperatorCrName {
+		// the NUMAResourcesOperator controller will take care of this
+		return ctrl.Result{}, nil
+	}
+
+	instance := &nropv1.NUMAResourcesOperator{}
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	result := ctrl.Result{RequeueAfter: r.checkPeriod()}
+
+	if annotations.IsPauseReconciliationEnabled(instance.Annotations) {
+		klog.V(2).InfoS("Pause reconciliation enabled", "object", req.NamespacedName)
+		return result, nil
+	}
+
+	drifted, err := r.findTopologyManagerDrift(ctx, instance)
+	if err != nil {
+		klog.ErrorS(err, "failed to check topology manager drift", "controller", "topologymanagerdrift")
+		return ctrl.Result{}, err
+	}
+
+	condStatus, reason, message := metav1.ConditionFalse, status.ReasonTopologyManagerDriftNotDetected, ""
+	if len(drifted) > 0 {
+		condStatus, reason = metav1.ConditionTrue, status.ReasonTopologyManagerDriftDetected
+		message = "topology manager configuration mismatch on nodes: " + strings.Join(drift.NodeNames(drifted), ",")
+		for _, node := range drifted {
+			klog.InfoS("topology manager drift detected", "node", node.NodeName, "pool", node.PoolName, "expected", node.Expected, "detected", node.Detected)
+		}
+	}
+
+	conditions, ok := status.UpdateAuxiliaryCondition(instance.Status.Conditions, status.ConditionTopologyManagerDrift, condStatus, reason, message)
+	if !ok {
+		// the check is periodic: report only the changes, not the same drift over and over
+		return result, nil
+	}
+	if len(drifted) > 0 {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, status.ReasonTopologyManagerDriftDetected, message)
+	}
+
+	instance.Status.Conditions = conditions
+	if err := r.Client.Status().Update(ctx, instance); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not update status for object %s: %w", client.ObjectKeyFromObject(instance), err)
+	}
+	return result, nil
+}
+
+func (r *TopologyManagerDriftReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// the drift check is periodic: we only need to restart it when the spec changes,
+	// and we must not react on the status updates we do ourselves.
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("topologymanagerdrift").
+		For(&nropv1.NUMAResourcesOperator{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
+
+func (r *TopologyManagerDriftReconciler) checkPeriod() time.Duration {
+	if r.CheckPeriod == 0 {
+		return defaultTopologyManagerDriftCheckPeriod
+	}
+	return r.CheckPeriod
+}
+
+func (r *TopologyManagerDriftReconciler) findTopologyManagerDrift(ctx context.Context, instance *nropv1.NUMAResourcesOperator) ([]drift.TopologyManagerNode, error) {
+	// the RTE configmaps, rendered by the kubeletconfig controller, are the source of truth
+	// for the topology manager configuration we expect on each pool.
+	cms := corev1.ConfigMapList{}
+	err := r.List(ctx, &cms, client.InNamespace(r.Namespace), client.MatchingLabels{rteconfig.LabelOperatorName: instance.Name})
+	if err != nil {
+		return nil, err
+	}
+	if len(cms.Items) == 0 {
+		return nil, nil
+	}
+
+	nrtList := nrtv1alpha2.NodeResourceTopologyList{}
+	err = r.List(ctx, &nrtList)
+	if err != nil {
+		return nil, err
+	}
+	nrts := make(map[string]*nrtv1alpha2.NodeResourceTopology, len(nrtList.Items))
+	for idx := range nrtList.Items {
+		nrt := &nrtList.Items[idx]
+		nrts[nrt.Name] = nrt
+	}
+
+	var drifted []drift.TopologyManagerNode
+	for idx := range cms.Items {
+		cm := &cms.Items[idx]
+		poolName, ok := cm.Labels[rteconfig.LabelNodeGroupName+"/"+rteconfig.LabelNodeGroupKindMachineConfigPool]
+		if !ok {
+			continue
+		}
+
+		data, err := rteconfig.UnpackConfigMap(cm)
+		if err != nil {
+			klog.InfoS("cannot unpack RTE config", "configmap", client.ObjectKeyFromObject(cm), "error", err)
+			continue
+		}
+		conf, err := rteconfig.Unrender(data)
+		if err != nil {
+			klog.InfoS("cannot unrender RTE config", "configmap", client.ObjectKeyFromObject(cm), "error", err)
+			continue
+		}
+
+		nodeNames, err := r.getPoolNodeNames(ctx, poolName)
+		if err != nil {
+			return nil, err
+		}
+
+		drifted = append(drifted, drift.TopologyManager(poolName, conf.Kubelet, nodeNames, nrts)...)
+	}
+	return drifted, nil
+}
+
+func (r *TopologyManagerDriftReconciler) getPoolNodeNames(ctx context.Context, poolName string) ([]string, error) {
+	var sel client.ListOption
+	switch r.Platform {
+	case platform.OpenShift:
+		mcp := mcov1.MachineConfigPool{}
+		err := r.Get(ctx, client.ObjectKey{Name: poolName}, &mcp)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if mcp.Spec.NodeSelector == nil {
+			return nil, nil
+		}
+		nodeSel, err := metav1.LabelSelectorAsSelector(mcp.Spec.NodeSelector)
+		if err != nil {
+			return nil, err
+		}
+		sel = client.MatchingLabelsSelector{Selector: nodeSel}
+	case platform.HyperShift:
+		sel = client.MatchingLabels{HyperShiftNodePoolLabel: poolName}
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", r.Platform)
+	}
+
+	nodes := corev1.NodeList{}
+	err := r.List(ctx, &nodes, sel)
+	if err != nil {
+		return nil, err
+	}
+	nodeNames := make([]string, 0, len(nodes.Items))
+	for idx := range nodes.Items {
+		nodeNames = append(nodeNames, nodes.Items[idx].Name)
+	}
+	return nodeNames, nil
+}
@@ -0,0 +1,225 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	kubeletconfigv1beta1 "k8s.io/kubelet/config/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	intnrt "github.com/openshift-kni/numaresources-operator/internal/noderesourcetopology"
+	testobjs "github.com/openshift-kni/numaresources-operator/internal/objects"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
+	"github.com/openshift-kni/numaresources-operator/pkg/status"
+	rteconfig "github.com/openshift-kni/numaresources-operator/rte/pkg/config"
+)
+
+func NewFakeTopologyManagerDriftReconciler(plat platform.Platform, initObjects ...runtime.Object) *TopologyManagerDriftReconciler {
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithStatusSubresource(&nropv1.NUMAResourcesOperator{}).WithRuntimeObjects(initObjects...).Build()
+	return &TopologyManagerDriftReconciler{
+		Client:      fakeClient,
+		Recorder:    record.NewFakeRecorder(bufferSize),
+		Namespace:   testNamespace,
+		Platform:    plat,
+		CheckPeriod: time.Minute,
+	}
+}
+
+var _ = Describe("Test TopologyManagerDrift Reconcile", func() {
+	var nro *nropv1.NUMAResourcesOperator
+	var ng *nropv1.NodeGroup
+	var poolLabels map[string]string
+	var nodeLabels map[string]string
+	var initObjects []runtime.Object
+
+	makeNode := func(name string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: nodeLabels,
+			},
+		}
+	}
+
+	makeNRT := func(name, policy, scope string) *nrtv1alpha2.NodeResourceTopology {
+		return &nrtv1alpha2.NodeResourceTopology{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Attributes: nrtv1alpha2.AttributeList{
+				{Name: intnrt.TopologyManagerPolicyAttribute, Value: policy},
+				{Name: intnrt.TopologyManagerScopeAttribute, Value: scope},
+			},
+		}
+	}
+
+	makeRTEConfigMap := func(poolName, policy, scope string) *corev1.ConfigMap {
+		data, err := rteconfig.Render(&kubeletconfigv1beta1.KubeletConfiguration{
+			TopologyManagerPolicy: policy,
+			TopologyManagerScope:  scope,
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		cm := rteconfig.CreateConfigMap(testNamespace, objectnames.GetComponentName(nro.Name, poolName), data)
+		return rteconfig.AddSoftRefLabels(cm, nro.Name, poolName)
+	}
+
+	reconcileAndGet := func(reconciler *TopologyManagerDriftReconciler) *nropv1.NUMAResourcesOperator {
+		GinkgoHelper()
+		key := client.ObjectKeyFromObject(nro)
+		result, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(reconcile.Result{RequeueAfter: time.Minute}))
+
+		updated := &nropv1.NUMAResourcesOperator{}
+		Expect(reconciler.Client.Get(context.TODO(), key, updated)).To(Succeed())
+		return updated
+	}
+
+	BeforeEach(func() {
+		poolLabels = map[string]string{"test-pool": "test-pool"}
+		nodeLabels = map[string]string{"node-role.kubernetes.io/test-pool": ""}
+		ng = &nropv1.NodeGroup{
+			MachineConfigPoolSelector: &metav1.LabelSelector{MatchLabels: poolLabels},
+		}
+		nro = testobjs.NewNUMAResourcesOperator(objectnames.DefaultNUMAResourcesOperatorCrName, *ng)
+		initObjects = []runtime.Object{
+			nro,
+			testobjs.NewMachineConfigPool("test-pool", poolLabels, &metav1.LabelSelector{MatchLabels: poolLabels}, &metav1.LabelSelector{MatchLabels: nodeLabels}),
+			makeNode("node-0"),
+			makeNode("node-1"),
+		}
+	})
+
+	It("should report no drift when the NRT attributes match the rendered configuration", func() {
+		initObjects = append(initObjects,
+			makeRTEConfigMap("test-pool", intnrt.SingleNUMANode, intnrt.Pod),
+			makeNRT("node-0", intnrt.SingleNUMANode, intnrt.Pod),
+			makeNRT("node-1", intnrt.SingleNUMANode, intnrt.Pod),
+		)
+		reconciler := NewFakeTopologyManagerDriftReconciler(platform.OpenShift, initObjects...)
+
+		updated := reconcileAndGet(reconciler)
+		cond := status.FindCondition(updated.Status.Conditions, status.ConditionTopologyManagerDrift)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+
+		fakeRecorder, ok := reconciler.Recorder.(*record.FakeRecorder)
+		Expect(ok).To(BeTrue())
+		Expect(fakeRecorder.Events).To(BeEmpty())
+	})
+
+	It("should report the nodes whose NRT attributes don't match the rendered configuration", func() {
+		initObjects = append(initObjects,
+			makeRTEConfigMap("test-pool", intnrt.SingleNUMANode, intnrt.Pod),
+			makeNRT("node-0", intnrt.SingleNUMANode, intnrt.Pod),
+			makeNRT("node-1", intnrt.BestEffort, intnrt.Container),
+		)
+		reconciler := NewFakeTopologyManagerDriftReconciler(platform.OpenShift, initObjects...)
+
+		updated := reconcileAndGet(reconciler)
+		cond := status.FindCondition(updated.Status.Conditions, status.ConditionTopologyManagerDrift)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(status.ReasonTopologyManagerDriftDetected))
+		Expect(cond.Message).To(ContainSubstring("node-1"))
+		Expect(cond.Message).ToNot(ContainSubstring("node-0"))
+
+		fakeRecorder, ok := reconciler.Recorder.(*record.FakeRecorder)
+		Expect(ok).To(BeTrue())
+		event := <-fakeRecorder.Events
+		Expect(event).To(ContainSubstring(status.ReasonTopologyManagerDriftDetected))
+		Expect(event).To(ContainSubstring("node-1"))
+
+		// the same drift must not be reported again on the next periodic check
+		reconcileAndGet(reconciler)
+		Expect(fakeRecorder.Events).To(BeEmpty())
+	})
+
+	It("should clear the drift condition once the NRT attributes match again", func() {
+		nrt := makeNRT("node-1", intnrt.BestEffort, intnrt.Container)
+		initObjects = append(initObjects,
+			makeRTEConfigMap("test-pool", intnrt.SingleNUMANode, intnrt.Pod),
+			makeNRT("node-0", intnrt.SingleNUMANode, intnrt.Pod),
+			nrt,
+		)
+		reconciler := NewFakeTopologyManagerDriftReconciler(platform.OpenShift, initObjects...)
+
+		updated := reconcileAndGet(reconciler)
+		cond := status.FindCondition(updated.Status.Conditions, status.ConditionTopologyManagerDrift)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+
+		Expect(reconciler.Client.Get(context.TODO(), client.ObjectKeyFromObject(nrt), nrt)).To(Succeed())
+		nrt.Attributes = makeNRT("node-1", intnrt.SingleNUMANode, intnrt.Pod).Attributes
+		Expect(reconciler.Client.Update(context.TODO(), nrt)).To(Succeed())
+
+		updated = reconcileAndGet(reconciler)
+		cond = status.FindCondition(updated.Status.Conditions, status.ConditionTopologyManagerDrift)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+	})
+
+	It("should preserve the other conditions", func() {
+		nro.Status.Conditions = status.NewConditions(status.ConditionAvailable, "", "")
+		initObjects = append(initObjects,
+			makeRTEConfigMap("test-pool", intnrt.SingleNUMANode, intnrt.Pod),
+			makeNRT("node-0", intnrt.Restricted, intnrt.Pod),
+		)
+		reconciler := NewFakeTopologyManagerDriftReconciler(platform.OpenShift, initObjects...)
+
+		updated := reconcileAndGet(reconciler)
+		cond := status.FindCondition(updated.Status.Conditions, status.ConditionAvailable)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		cond = status.FindCondition(updated.Status.Conditions, status.ConditionTopologyManagerDrift)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("should check the nodes of the node pool on HyperShift", func() {
+		nodeLabels = map[string]string{HyperShiftNodePoolLabel: "test-nodepool"}
+		initObjects = []runtime.Object{
+			nro,
+			makeNode("node-0"),
+			makeRTEConfigMap("test-nodepool", intnrt.SingleNUMANode, intnrt.Pod),
+			makeNRT("node-0", intnrt.None, intnrt.Container),
+		}
+		reconciler := NewFakeTopologyManagerDriftReconciler(platform.HyperShift, initObjects...)
+
+		updated := reconcileAndGet(reconciler)
+		cond := status.FindCondition(updated.Status.Conditions, status.ConditionTopologyManagerDrift)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Message).To(ContainSubstring("node-0"))
+	})
+})
@@ -26,6 +26,11 @@ const (
 
 	PauseReconciliationAnnotation        = "config.numa-operator.openshift.io/pause-reconciliation"
 	PauseReconciliationAnnotationEnabled = "enabled"
+
+	// DryRunAnnotation makes the reconciliation publish the changes it would make, instead of applying them.
+	// The annotation is on when it's set to "enabled", every other value is equivalent to disabled
+	DryRunAnnotation        = "config.numa-operator.openshift.io/dry-run"
+	DryRunAnnotationEnabled = "enabled"
 )
 
 func IsCustomPolicyEnabled(annot map[string]string) bool {
@@ -48,3 +53,10 @@ func IsPauseReconciliationEnabled(annot map[string]string) bool {
 	}
 	return false
 }
+
+func IsDryRunEnabled(annot map[string]string) bool {
+	if v, ok := annot[DryRunAnnotation]; ok && v == DryRunAnnotationEnabled {
+		return true
+	}
+	return false
+}
@@ -122,3 +122,38 @@ func TestIsPauseReconciliationEnabled(t *testing.T) {
 		})
 	}
 }
+
+func TestIsDryRunEnabled(t *testing.T) {
+	testcases := []struct {
+		description string
+		annotations map[string]string
+		expected    bool
+	}{
+		{
+			description: "empty map",
+			annotations: map[string]string{},
+			expected:    false,
+		},
+		{
+			description: "annotation set to anything but not \"enabled\" means it's disabled",
+			annotations: map[string]string{
+				DryRunAnnotation: "true",
+			},
+			expected: false,
+		},
+		{
+			description: "enabled dry run",
+			annotations: map[string]string{
+				DryRunAnnotation: DryRunAnnotationEnabled,
+			},
+			expected: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.description, func(t *testing.T) {
+			if got := IsDryRunEnabled(tc.annotations); got != tc.expected {
+				t.Errorf("expected %v got %v", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2024 Red Hat, Inc.
+ */
+
+package features
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	goversion "github.com/aquasecurity/go-version/pkg/version"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	"github.com/openshift-kni/numaresources-operator/internal/api/annotations"
+)
+
+type CapabilityKind string
+
+const (
+	// CapabilityField is a field of the operator API objects
+	CapabilityField CapabilityKind = "field"
+	// CapabilityAnnotation is an annotation of the operator API objects
+	CapabilityAnnotation CapabilityKind = "annotation"
+	// CapabilityPlatform is a platform the operator can be deployed on
+	CapabilityPlatform CapabilityKind = "platform"
+)
+
+// CapabilityValue is a supported value of an enumerated field or annotation
+type CapabilityValue struct {
+	Value string `json:"value"`
+	// semantic versioning vX.Y.Z of the operator which introduced the value
+	Since string `json:"since"`
+}
+
+type Capability struct {
+	// Name is the path of the field, like "NUMAResourcesOperator.spec.nodeGroups[].config.infoRefreshMode",
+	// the name of the annotation, or the name of the platform
+	Name string         `json:"name"`
+	Kind CapabilityKind `json:"kind"`
+	// semantic versioning vX.Y.Z of the operator which introduced the capability
+	Since string `json:"since"`
+	// Values are the supported values, if enumerated
+	Values []CapabilityValue `json:"values,omitempty"`
+	// Platforms are the platforms the capability applies to. Empty means all.
+	Platforms []platform.Platform `json:"platforms,omitempty"`
+}
+
+// FeatureInfo is the capability document of the operator. It extends the TopicInfo, and its JSON
+// is a superset of the TopicInfo one, so the tools consuming the active topics keep working.
+type FeatureInfo struct {
+	TopicInfo
+	Capabilities []Capability `json:"capabilities"`
+}
+
+func GetFeatures() FeatureInfo {
+	fi := FeatureInfo{
+		TopicInfo:    GetTopics(),
+		Capabilities: capabilities(),
+	}
+	fi.Metadata.Version = Version
+	return fi
+}
+
+// Validate checks the versions of the capabilities, which cannot be newer than the document
+func (fi FeatureInfo) Validate() error {
+	if err := fi.TopicInfo.Validate(); err != nil {
+		return err
+	}
+	ver, err := goversion.Parse(fi.Metadata.Version)
+	if err != nil {
+		return fmt.Errorf("metadata: malformed version: %w", err)
+	}
+	checkSince := func(what, since string) error {
+		sv, err := goversion.Parse(since)
+		if err != nil {
+			return fmt.Errorf("%s: malformed version %q: %w", what, since, err)
+		}
+		if sv.GreaterThan(ver) {
+			return fmt.Errorf("%s: version %q newer than %q", what, since, fi.Metadata.Version)
+		}
+		return nil
+	}
+
+	names := sets.New[string]()
+	for _, capa := range fi.Capabilities {
+		if names.Has(capa.Name) {
+			return fmt.Errorf("capability %q: duplicated", capa.Name)
+		}
+		names.Insert(capa.Name)
+		if err := checkSince("capability "+capa.Name, capa.Since); err != nil {
+			return err
+		}
+		for _, val := range capa.Values {
+			if err := checkSince("capability "+capa.Name+" value "+val.Value, val.Since); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Handler serves the capability document as JSON
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(GetFeatures())
+	})
+}
+
+func capabilities() []Capability {
+	return []Capability{
+		// platforms
+		{
+			Name:  platform.Kubernetes.String(),
+			Kind:  CapabilityPlatform,
+			Since: "v4.10.0",
+		},
+		{
+			Name:  platform.OpenShift.String(),
+			Kind:  CapabilityPlatform,
+			Since: "v4.10.0",
+		},
+		{
+			Name:  platform.HyperShift.String(),
+			Kind:  CapabilityPlatform,
+			Since: "v4.18.0",
+		},
+
+		// NUMAResourcesOperator
+		{
+			Name:  "NUMAResourcesOperator.spec.nodeGroups[].machineConfigPoolSelector",
+			Kind:  CapabilityField,
+			Since: "v4.10.0",
+			Platforms: []platform.Platform{
+				platform.OpenShift,
+			},
+		},
+		{
+			Name:  "NUMAResourcesOperator.spec.nodeGroups[].poolName",
+			Kind:  CapabilityField,
+			Since: "v4.18.0",
+			Platforms: []platform.Platform{
+				platform.OpenShift,
+				platform.HyperShift,
+			},
+		},
+		{
+			Name:  "NUMAResourcesOperator.spec.nodeGroups[].config.podsFingerprinting",
+			Kind:  CapabilityField,
+			Since: "v4.12.0",
+			Values: []CapabilityValue{
+				{Value: string(nropv1.PodsFingerprintingDisabled), Since: "v4.12.0"},
+				{Value: string(nropv1.PodsFingerprintingEnabled), Since: "v4.12.0"},
+				{Value: string(nropv1.PodsFingerprintingEnabledExclusiveResources), Since: "v4.16.0"},
+			},
+		},
+		{
+			Name:  "NUMAResourcesOperator.spec.nodeGroups[].config.infoRefreshMode",
+			Kind:  CapabilityField,
+			Since: "v4.12.0",
+			Values: []CapabilityValue{
+				{Value: string(nropv1.InfoRefreshPeriodic), Since: "v4.12.0"},
+				{Value: string(nropv1.InfoRefreshEvents), Since: "v4.12.0"},
+				{Value: string(nropv1.InfoRefreshPeriodicAndEvents), Since: "v4.12.0"},
+			},
+		},
+		{
+			Name:  "NUMAResourcesOperator.spec.nodeGroups[].config.infoRefreshPeriod",
+			Kind:  CapabilityField,
+			Since: "v4.12.0",
+		},
+		{
+			Name:  "NUMAResourcesOperator.spec.nodeGroups[].config.infoRefreshPause",
+			Kind:  CapabilityField,
+			Since: "v4.16.0",
+			Values: []CapabilityValue{
+				{Value: string(nropv1.InfoRefreshPauseDisabled), Since: "v4.16.0"},
+				{Value: string(nropv1.InfoRefreshPauseEnabled), Since: "v4.16.0"},
+			},
+		},
+		{
+			Name:  "NUMAResourcesOperator.spec.nodeGroups[].config.tolerations",
+			Kind:  CapabilityField,
+			Since: "v4.16.0",
+		},
+		{
+			Name:  "NUMAResourcesOperator.spec.imageSpec",
+			Kind:  CapabilityField,
+			Since: "v4.10.0",
+		},
+		{
+			Name:  "NUMAResourcesOperator.spec.logLevel",
+			Kind:  CapabilityField,
+			Since: "v4.10.0",
+		},
+		{
+			Name:  "NUMAResourcesOperator.spec.podExcludes",
+			Kind:  CapabilityField,
+			Since: "v4.11.0",
+		},
+		{
+			Name:  "NUMAResourcesOperator.spec.driftPolicy",
+			Kind:  CapabilityField,
+			Since: "v4.19.0",
+			Values: []CapabilityValue{
+				{Value: string(nropv1.DriftPolicyReport), Since: "v4.19.0"},
+				{Value: string(nropv1.DriftPolicyCorrect), Since: "v4.19.0"},
+			},
+		},
+		{
+			Name:  annotations.SELinuxPolicyConfigAnnotation,
+			Kind:  CapabilityAnnotation,
+			Since: "v4.12.0",
+			Values: []CapabilityValue{
+				{Value: annotations.SELinuxPolicyCustom, Since: "v4.12.0"},
+			},
+			Platforms: []platform.Platform{
+				platform.OpenShift,
+			},
+		},
+		{
+			Name:  annotations.MultiplePoolsPerTreeAnnotation,
+			Kind:  CapabilityAnnotation,
+			Since: "v4.18.0",
+			Values: []CapabilityValue{
+				{Value: annotations.MultiplePoolsPerTreeEnabled, Since: "v4.18.0"},
+			},
+			Platforms: []platform.Platform{
+				platform.OpenShift,
+			},
+		},
+		{
+			Name:  annotations.PauseReconciliationAnnotation,
+			Kind:  CapabilityAnnotation,
+			Since: "v4.18.0",
+			Values: []CapabilityValue{
+				{Value: annotations.PauseReconciliationAnnotationEnabled, Since: "v4.18.0"},
+			},
+		},
+		{
+			Name:  annotations.DryRunAnnotation,
+			Kind:  CapabilityAnnotation,
+			Since: "v4.19.0",
+			Values: []CapabilityValue{
+				{Value: annotations.DryRunAnnotationEnabled, Since: "v4.19.0"},
+			},
+		},
+
+		// NUMAResourcesScheduler
+		{
+			Name:  "NUMAResourcesScheduler.spec.imageSpec",
+			Kind:  CapabilityField,
+			Since: "v4.10.0",
+		},
+		{
+			Name:  "NUMAResourcesScheduler.spec.schedulerName",
+			Kind:  CapabilityField,
+			Since: "v4.10.0",
+		},
+		{
+			Name:  "NUMAResourcesScheduler.spec.logLevel",
+			Kind:  CapabilityField,
+			Since: "v4.11.0",
+		},
+		{
+			Name:  "NUMAResourcesScheduler.spec.cacheResyncPeriod",
+			Kind:  CapabilityField,
+			Since: "v4.12.0",
+		},
+		{
+			Name:  "NUMAResourcesScheduler.spec.cacheResyncDebug",
+			Kind:  CapabilityField,
+			Since: "v4.13.0",
+			Values: []CapabilityValue{
+				{Value: string(nropv1.CacheResyncDebugDisabled), Since: "v4.13.0"},
+				{Value: string(nropv1.CacheResyncDebugDumpJSONFile), Since: "v4.13.0"},
+			},
+		},
+		{
+			Name:  "NUMAResourcesScheduler.spec.schedulerInformer",
+			Kind:  CapabilityField,
+			Since: "v4.14.0",
+			Values: []CapabilityValue{
+				{Value: string(nropv1.SchedulerInformerShared), Since: "v4.14.0"},
+				{Value: string(nropv1.SchedulerInformerDedicated), Since: "v4.14.0"},
+			},
+		},
+		{
+			Name:  "NUMAResourcesScheduler.spec.cacheResyncDetection",
+			Kind:  CapabilityField,
+			Since: "v4.16.0",
+			Values: []CapabilityValue{
+				{Value: string(nropv1.CacheResyncDetectionRelaxed), Since: "v4.16.0"},
+				{Value: string(nropv1.CacheResyncDetectionAggressive), Since: "v4.16.0"},
+			},
+		},
+		{
+			Name:  "NUMAResourcesScheduler.spec.scoringStrategy",
+			Kind:  CapabilityField,
+			Since: "v4.16.0",
+			Values: []CapabilityValue{
+				{Value: string(nropv1.LeastAllocated), Since: "v4.16.0"},
+				{Value: string(nropv1.MostAllocated), Since: "v4.16.0"},
+				{Value: string(nropv1.BalancedAllocation), Since: "v4.16.0"},
+			},
+		},
+		{
+			Name:  "NUMAResourcesScheduler.spec.replicas",
+			Kind:  CapabilityField,
+			Since: "v4.17.0",
+		},
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGetFeaturesValidate(t *testing.T) {
+	fi := GetFeatures()
+	if err := fi.Validate(); err != nil {
+		t.Fatalf("invalid capability document: %v", err)
+	}
+
+	fi.Capabilities = append(fi.Capabilities, Capability{
+		Name:  "NUMAResourcesOperator.spec.fromTheFuture",
+		Kind:  CapabilityField,
+		Since: "v99.0.0",
+	})
+	if err := fi.Validate(); err == nil {
+		t.Errorf("capability newer than the document passed validation")
+	}
+}
+
+func TestFeaturesSupersetOfTopics(t *testing.T) {
+	data, err := json.Marshal(GetFeatures())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var tp TopicInfo
+	if err := json.Unmarshal(data, &tp); err != nil {
+		t.Fatalf("cannot decode the capability document as TopicInfo: %v", err)
+	}
+	if !reflect.DeepEqual(tp.Active, GetTopics().Active) {
+		t.Errorf("different topics found: %v, expected %v", tp.Active, GetTopics().Active)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	srv := httptest.NewServer(Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status: %v", resp.Status)
+	}
+	var fi FeatureInfo
+	if err := json.NewDecoder(resp.Body).Decode(&fi); err != nil {
+		t.Fatalf("cannot decode the capability document: %v", err)
+	}
+	if !reflect.DeepEqual(fi, GetFeatures()) {
+		t.Errorf("served document differs from the expected one")
+	}
+
+	resp, err = http.Post(srv.URL, "application/json", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("unexpected status for POST: %v", resp.Status)
+	}
+}
@@ -0,0 +1,371 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package capacity computes how many pods of a given resource shape the NUMA zones
+// of the cluster can still take, under each topology manager configuration.
+// Pods are assumed to be guaranteed and to request exclusive resources, which are
+// the pods the topology manager aligns.
+package capacity
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	"github.com/openshift-kni/numaresources-operator/internal/baseload"
+	intnrt "github.com/openshift-kni/numaresources-operator/internal/noderesourcetopology"
+	"github.com/openshift-kni/numaresources-operator/internal/resourcelist"
+)
+
+// Shape is the resource requests of the containers of a pod
+type Shape []corev1.ResourceList
+
+// ParseShape parses a shape expressed as the requests of each container, in the form
+// "cpu=16,memory=32Gi;cpu=2,memory=1Gi".
+func ParseShape(val string) (Shape, error) {
+	var shape Shape
+	for _, cnt := range strings.Split(val, ";") {
+		res := corev1.ResourceList{}
+		for _, item := range strings.Split(cnt, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			name, qty, ok := strings.Cut(item, "=")
+			if !ok {
+				return nil, fmt.Errorf("malformed request %q, expected name=quantity", item)
+			}
+			q, err := resource.ParseQuantity(qty)
+			if err != nil {
+				return nil, fmt.Errorf("malformed quantity for %q: %w", name, err)
+			}
+			if q.Sign() < 0 {
+				return nil, fmt.Errorf("negative quantity for %q", name)
+			}
+			res[corev1.ResourceName(name)] = q
+		}
+		if len(res) == 0 {
+			return nil, fmt.Errorf("empty container requests in %q", val)
+		}
+		shape = append(shape, res)
+	}
+	return shape, nil
+}
+
+// Total returns the requests of the whole pod
+func (sh Shape) Total() corev1.ResourceList {
+	return resourcelist.Accumulate(sh, resourcelist.AllowAll)
+}
+
+func (sh Shape) String() string {
+	var cnts []string
+	for _, res := range sh {
+		cnts = append(cnts, resourcelist.ToString(res))
+	}
+	return strings.Join(cnts, "; ")
+}
+
+// TopologyManager is a topology manager configuration
+type TopologyManager struct {
+	Policy string `json:"policy"`
+	Scope  string `json:"scope"`
+}
+
+func (tm TopologyManager) String() string {
+	return tm.Policy + "/" + tm.Scope
+}
+
+// TopologyManagers are all the configurations a report evaluates
+var TopologyManagers = []TopologyManager{
+	{Policy: intnrt.SingleNUMANode, Scope: intnrt.Pod},
+	{Policy: intnrt.SingleNUMANode, Scope: intnrt.Container},
+	{Policy: intnrt.Restricted, Scope: intnrt.Pod},
+	{Policy: intnrt.Restricted, Scope: intnrt.Container},
+	{Policy: intnrt.BestEffort, Scope: intnrt.Pod},
+	{Policy: intnrt.BestEffort, Scope: intnrt.Container},
+	{Policy: intnrt.None, Scope: intnrt.Pod},
+	{Policy: intnrt.None, Scope: intnrt.Container},
+}
+
+// Fit is how many pods fit under a topology manager configuration
+type Fit struct {
+	TopologyManager
+	Pods int `json:"pods"`
+}
+
+type ZoneAvailable struct {
+	Name      string              `json:"name"`
+	Available corev1.ResourceList `json:"available"`
+}
+
+type NodeReport struct {
+	Name string `json:"name"`
+	// Configured is the topology manager configuration the node publishes, if any
+	Configured *TopologyManager `json:"configured,omitempty"`
+	// Baseload is the load of the node deducted from each zone
+	Baseload corev1.ResourceList `json:"baseload,omitempty"`
+	// Zones are the resources available in each NUMA zone, after the baseload deduction
+	Zones []ZoneAvailable `json:"zones"`
+	Fits  []Fit           `json:"fits"`
+	// ConfiguredPods is how many pods fit under the configured topology manager configuration, if known
+	ConfiguredPods *int `json:"configuredPods,omitempty"`
+}
+
+type Report struct {
+	Shape Shape        `json:"shape"`
+	Nodes []NodeReport `json:"nodes"`
+	// Totals are how many pods fit in the cluster, if all the nodes had the same topology manager configuration
+	Totals []Fit `json:"totals"`
+	// ConfiguredPods is how many pods fit in the cluster as the nodes are configured now.
+	// Nodes not publishing their configuration are not accounted.
+	ConfiguredPods int `json:"configuredPods"`
+}
+
+// NewReport computes the capacity report for the given NRT objects. The loads, keyed by node name,
+// are deducted from every zone of their node, because which zone serves them is not known.
+func NewReport(shape Shape, nrts []nrtv1alpha2.NodeResourceTopology, loads map[string]baseload.Load) Report {
+	rep := Report{
+		Shape: shape,
+	}
+	totals := make([]Fit, len(TopologyManagers))
+	for idx, tm := range TopologyManagers {
+		totals[idx].TopologyManager = tm
+	}
+
+	nrts = append([]nrtv1alpha2.NodeResourceTopology{}, nrts...)
+	sort.Slice(nrts, func(i, j int) bool { return nrts[i].Name < nrts[j].Name })
+	for idx := range nrts {
+		load, ok := loads[nrts[idx].Name]
+		if !ok {
+			load = baseload.Load{Name: nrts[idx].Name}
+		}
+		nodeRep := NodeCapacity(shape, &nrts[idx], load)
+		for fidx := range nodeRep.Fits {
+			totals[fidx].Pods += nodeRep.Fits[fidx].Pods
+		}
+		if nodeRep.ConfiguredPods != nil {
+			rep.ConfiguredPods += *nodeRep.ConfiguredPods
+		}
+		rep.Nodes = append(rep.Nodes, nodeRep)
+	}
+	rep.Totals = totals
+	return rep
+}
+
+// NodeCapacity computes the capacity report of a single node
+func NodeCapacity(shape Shape, nrt *nrtv1alpha2.NodeResourceTopology, load baseload.Load) NodeReport {
+	nodeRep := NodeReport{
+		Name:     nrt.Name,
+		Baseload: load.Resources,
+	}
+	policy, scope := intnrt.TopologyManagerFromAttributes(nrt.Attributes)
+	if policy != "" {
+		if scope == "" {
+			scope = intnrt.Container // kubelet default
+		}
+		nodeRep.Configured = &TopologyManager{Policy: policy, Scope: scope}
+	}
+
+	zones := availableZones(nrt, load)
+	for _, zone := range zones {
+		nodeRep.Zones = append(nodeRep.Zones, ZoneAvailable{
+			Name:      zone.Name,
+			Available: zoneAvailable(zone),
+		})
+	}
+
+	// the host-level resources are never aligned, hence not accounted. Any other resource the node
+	// doesn't report, like a device it lacks, is not available, so no pod of the shape fits.
+	var units Shape
+	for _, res := range shape {
+		alignedRes := corev1.ResourceList{}
+		for resName, resQty := range res {
+			if !intnrt.IsHostLevelResource(resName) {
+				alignedRes[resName] = resQty
+			}
+		}
+		units = append(units, alignedRes)
+	}
+
+	for _, tm := range TopologyManagers {
+		fit := Fit{
+			TopologyManager: tm,
+			Pods:            countPods(zones, units, tm),
+		}
+		nodeRep.Fits = append(nodeRep.Fits, fit)
+		if nodeRep.Configured != nil && *nodeRep.Configured == tm {
+			pods := fit.Pods
+			nodeRep.ConfiguredPods = &pods
+		}
+	}
+	return nodeRep
+}
+
+// countPods returns how many pods of the shape fit in the zones under the topology manager configuration.
+// The restricted policy is handled like single-numa-node if each aligned request fits in the allocatable
+// resources of a zone, because the kubelet would reject any wider alignment, and like none otherwise.
+func countPods(zones nrtv1alpha2.ZoneList, shape Shape, tm TopologyManager) int {
+	units := shape
+	if tm.Scope == intnrt.Pod {
+		units = Shape{shape.Total()}
+	}
+	if !hasRequests(units) || len(zones) == 0 {
+		return 0
+	}
+
+	switch tm.Policy {
+	case intnrt.SingleNUMANode:
+		return pack(zones, units)
+	case intnrt.Restricted:
+		if fitsAllocatable(zones, units) {
+			return pack(zones, units)
+		}
+	}
+	// the resources can span zones: only the node-level availability matters
+	return pack(nrtv1alpha2.ZoneList{aggregateZones(zones)}, Shape{shape.Total()})
+}
+
+// pack places the pods one after another, each request of a pod in the first zone it fits in,
+// until a pod doesn't fit anymore, and returns how many pods were placed.
+func pack(zones nrtv1alpha2.ZoneList, units Shape) int {
+	zones = cloneZones(zones)
+	pods := 0
+	for {
+		for _, unit := range units {
+			idx := firstFit(zones, unit)
+			if idx < 0 {
+				return pods
+			}
+			take(&zones[idx], unit)
+		}
+		pods++
+	}
+}
+
+func firstFit(zones nrtv1alpha2.ZoneList, request corev1.ResourceList) int {
+	for idx := range zones {
+		if len(intnrt.ZoneShortages(zones[idx], request)) == 0 {
+			return idx
+		}
+	}
+	return -1
+}
+
+func fitsAllocatable(zones nrtv1alpha2.ZoneList, units Shape) bool {
+	allocZones := cloneZones(zones)
+	for zidx := range allocZones {
+		for ridx := range allocZones[zidx].Resources {
+			resInfo := &allocZones[zidx].Resources[ridx]
+			resInfo.Available = resInfo.Allocatable.DeepCopy()
+		}
+	}
+	for _, unit := range units {
+		if firstFit(allocZones, unit) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func take(zone *nrtv1alpha2.Zone, request corev1.ResourceList) {
+	for idx := range zone.Resources {
+		resInfo := &zone.Resources[idx]
+		if qty, ok := request[corev1.ResourceName(resInfo.Name)]; ok {
+			resInfo.Available.Sub(qty)
+		}
+	}
+}
+
+func hasRequests(units Shape) bool {
+	for _, res := range units {
+		for _, qty := range res {
+			if qty.Sign() > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// availableZones returns the NUMA zones of the NRT, sorted by name, with the load deducted from each of them
+func availableZones(nrt *nrtv1alpha2.NodeResourceTopology, load baseload.Load) nrtv1alpha2.ZoneList {
+	var zones nrtv1alpha2.ZoneList
+	for _, zone := range intnrt.SortedZoneList(nrt.Zones) {
+		if zone.Type != intnrt.ZoneTypeNode {
+			continue
+		}
+		for idx := range zone.Resources {
+			resInfo := &zone.Resources[idx]
+			qty, ok := load.Resources[corev1.ResourceName(resInfo.Name)]
+			if !ok {
+				continue
+			}
+			resInfo.Available.Sub(qty)
+			if resInfo.Available.Sign() < 0 {
+				resInfo.Available = *resource.NewQuantity(0, resInfo.Available.Format)
+			}
+		}
+		zones = append(zones, zone)
+	}
+	return zones
+}
+
+func aggregateZones(zones nrtv1alpha2.ZoneList) nrtv1alpha2.Zone {
+	node := nrtv1alpha2.Zone{
+		Name: "node",
+		Type: intnrt.ZoneTypeNode,
+	}
+	for _, zone := range zones {
+		for _, resInfo := range zone.Resources {
+			idx := -1
+			for ridx := range node.Resources {
+				if node.Resources[ridx].Name == resInfo.Name {
+					idx = ridx
+					break
+				}
+			}
+			if idx < 0 {
+				node.Resources = append(node.Resources, *resInfo.DeepCopy())
+				continue
+			}
+			node.Resources[idx].Capacity.Add(resInfo.Capacity)
+			node.Resources[idx].Allocatable.Add(resInfo.Allocatable)
+			node.Resources[idx].Available.Add(resInfo.Available)
+		}
+	}
+	return node
+}
+
+func zoneAvailable(zone nrtv1alpha2.Zone) corev1.ResourceList {
+	ret := corev1.ResourceList{}
+	for _, resInfo := range zone.Resources {
+		ret[corev1.ResourceName(resInfo.Name)] = resInfo.Available.DeepCopy()
+	}
+	return ret
+}
+
+func cloneZones(zones nrtv1alpha2.ZoneList) nrtv1alpha2.ZoneList {
+	ret := make(nrtv1alpha2.ZoneList, 0, len(zones))
+	for idx := range zones {
+		ret = append(ret, *zones[idx].DeepCopy())
+	}
+	return ret
+}
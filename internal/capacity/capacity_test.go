@@ -0,0 +1,238 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package capacity
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	"github.com/openshift-kni/numaresources-operator/internal/baseload"
+	intnrt "github.com/openshift-kni/numaresources-operator/internal/noderesourcetopology"
+)
+
+func TestParseShape(t *testing.T) {
+	testCases := []struct {
+		name          string
+		val           string
+		expectedCnts  int
+		expectedError bool
+	}{
+		{
+			name:         "single container",
+			val:          "cpu=16,memory=32Gi",
+			expectedCnts: 1,
+		},
+		{
+			name:         "multiple containers",
+			val:          "cpu=16,memory=32Gi;cpu=2",
+			expectedCnts: 2,
+		},
+		{
+			name:          "malformed request",
+			val:           "cpu16",
+			expectedError: true,
+		},
+		{
+			name:          "malformed quantity",
+			val:           "cpu=lots",
+			expectedError: true,
+		},
+		{
+			name:          "empty container",
+			val:           "cpu=2;",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shape, err := ParseShape(tc.val)
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(shape) != tc.expectedCnts {
+				t.Errorf("expected %d containers, got %d", tc.expectedCnts, len(shape))
+			}
+		})
+	}
+}
+
+func TestNodeCapacity(t *testing.T) {
+	// two zones with 20 available CPUs each: 40 CPUs overall
+	nrt := makeNRT("node-0", intnrt.SingleNUMANode, intnrt.Pod, "20", "20")
+
+	testCases := []struct {
+		name     string
+		shape    string
+		load     baseload.Load
+		expected map[TopologyManager]int
+	}{
+		{
+			name:  "pod fitting one zone",
+			shape: "cpu=8,memory=1Gi",
+			expected: map[TopologyManager]int{
+				{Policy: intnrt.SingleNUMANode, Scope: intnrt.Pod}:       4,
+				{Policy: intnrt.SingleNUMANode, Scope: intnrt.Container}: 4,
+				{Policy: intnrt.Restricted, Scope: intnrt.Pod}:           4,
+				{Policy: intnrt.None, Scope: intnrt.Pod}:                 5,
+			},
+		},
+		{
+			name:  "pod larger than a zone",
+			shape: "cpu=24",
+			expected: map[TopologyManager]int{
+				{Policy: intnrt.SingleNUMANode, Scope: intnrt.Pod}: 0,
+				{Policy: intnrt.Restricted, Scope: intnrt.Pod}:     1,
+				{Policy: intnrt.BestEffort, Scope: intnrt.Pod}:     1,
+			},
+		},
+		{
+			name:  "containers fitting zones separately",
+			shape: "cpu=12;cpu=12",
+			expected: map[TopologyManager]int{
+				{Policy: intnrt.SingleNUMANode, Scope: intnrt.Pod}:       0,
+				{Policy: intnrt.SingleNUMANode, Scope: intnrt.Container}: 1,
+				{Policy: intnrt.Restricted, Scope: intnrt.Container}:     1,
+			},
+		},
+		{
+			name:  "baseload deducted from each zone",
+			shape: "cpu=8",
+			load: baseload.Load{
+				Name: "node-0",
+				Resources: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("6"),
+				},
+			},
+			expected: map[TopologyManager]int{
+				{Policy: intnrt.SingleNUMANode, Scope: intnrt.Pod}: 2,
+				{Policy: intnrt.None, Scope: intnrt.Pod}:           3,
+			},
+		},
+		{
+			name:  "host-level resources are ignored",
+			shape: "cpu=10,ephemeral-storage=1Gi",
+			expected: map[TopologyManager]int{
+				{Policy: intnrt.SingleNUMANode, Scope: intnrt.Pod}: 4,
+			},
+		},
+		{
+			name:  "devices missing from the node are not available",
+			shape: "cpu=2;example.com/gpu=1",
+			expected: map[TopologyManager]int{
+				{Policy: intnrt.SingleNUMANode, Scope: intnrt.Pod}:       0,
+				{Policy: intnrt.SingleNUMANode, Scope: intnrt.Container}: 0,
+				{Policy: intnrt.Restricted, Scope: intnrt.Container}:     0,
+				{Policy: intnrt.None, Scope: intnrt.Pod}:                 0,
+				{Policy: intnrt.None, Scope: intnrt.Container}:           0,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shape, err := ParseShape(tc.shape)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			nodeRep := NodeCapacity(shape, nrt, tc.load)
+			if nodeRep.Configured == nil || nodeRep.ConfiguredPods == nil {
+				t.Fatalf("missing configured topology manager")
+			}
+			got := make(map[TopologyManager]int)
+			for _, fit := range nodeRep.Fits {
+				got[fit.TopologyManager] = fit.Pods
+			}
+			for tm, pods := range tc.expected {
+				if got[tm] != pods {
+					t.Errorf("%s: expected %d pods, got %d", tm, pods, got[tm])
+				}
+			}
+			if *nodeRep.ConfiguredPods != got[*nodeRep.Configured] {
+				t.Errorf("configured pods %d differ from %s pods %d", *nodeRep.ConfiguredPods, nodeRep.Configured, got[*nodeRep.Configured])
+			}
+		})
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	nrts := []nrtv1alpha2.NodeResourceTopology{
+		*makeNRT("node-1", intnrt.SingleNUMANode, intnrt.Pod, "16", "16"),
+		*makeNRT("node-0", "", "", "16", "8"),
+	}
+	shape, err := ParseShape("cpu=16")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rep := NewReport(shape, nrts, nil)
+	if len(rep.Nodes) != 2 || rep.Nodes[0].Name != "node-0" || rep.Nodes[1].Name != "node-1" {
+		t.Fatalf("unexpected nodes: %+v", rep.Nodes)
+	}
+	if rep.Nodes[0].Configured != nil {
+		t.Errorf("unexpected configuration on node without attributes: %v", rep.Nodes[0].Configured)
+	}
+	// only node-1 publishes its configuration
+	if rep.ConfiguredPods != 2 {
+		t.Errorf("expected 2 pods under the configured policies, got %d", rep.ConfiguredPods)
+	}
+	for _, fit := range rep.Totals {
+		if fit.TopologyManager == (TopologyManager{Policy: intnrt.SingleNUMANode, Scope: intnrt.Pod}) && fit.Pods != 3 {
+			t.Errorf("expected 3 pods in total under %s, got %d", fit.TopologyManager, fit.Pods)
+		}
+	}
+}
+
+func makeNRT(name, policy, scope string, cpus ...string) *nrtv1alpha2.NodeResourceTopology {
+	nrt := &nrtv1alpha2.NodeResourceTopology{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+	}
+	if policy != "" {
+		nrt.Attributes = nrtv1alpha2.AttributeList{
+			{Name: intnrt.TopologyManagerPolicyAttribute, Value: policy},
+			{Name: intnrt.TopologyManagerScopeAttribute, Value: scope},
+		}
+	}
+	for idx, cpu := range cpus {
+		nrt.Zones = append(nrt.Zones, nrtv1alpha2.Zone{
+			Name: "node-" + string(rune('0'+idx)),
+			Type: intnrt.ZoneTypeNode,
+			Resources: nrtv1alpha2.ResourceInfoList{
+				{
+					Name:        string(corev1.ResourceCPU),
+					Capacity:    resource.MustParse(cpu),
+					Allocatable: resource.MustParse(cpu),
+					Available:   resource.MustParse(cpu),
+				},
+				{
+					Name:        string(corev1.ResourceMemory),
+					Capacity:    resource.MustParse("32Gi"),
+					Allocatable: resource.MustParse("32Gi"),
+					Available:   resource.MustParse("32Gi"),
+				},
+			},
+		})
+	}
+	return nrt
+}
@@ -44,23 +44,15 @@ func DeleteUnusedDaemonSets(cli client.Client, ctx context.Context, instance *nr
 	klog.V(3).Info("Delete dangling Daemonsets start")
 	defer klog.V(3).Info("Delete dangling Daemonsets end")
 
-	var daemonSetList appsv1.DaemonSetList
-	if err := cli.List(ctx, &daemonSetList, &client.ListOptions{Namespace: instance.Namespace}); err != nil {
+	daemonSets, err := DaemonSets(cli, ctx, instance, trees)
+	if err != nil {
 		klog.ErrorS(err, "error while getting Daemonset list")
 		return err
 	}
 
-	expectedDaemonSetNames := buildDaemonSetNames(instance, trees)
-
 	var errs error
 	deleted := 0
-	for _, ds := range daemonSetList.Items {
-		if !isOwnedBy(ds.GetObjectMeta(), instance) {
-			continue
-		}
-		if expectedDaemonSetNames.Has(ds.Name) {
-			continue
-		}
+	for _, ds := range daemonSets {
 		if err := cli.Delete(ctx, &ds); err != nil {
 			klog.ErrorS(err, "error while deleting dangling daemonset", "DaemonSet", ds.Namespace+"/"+ds.Name)
 			errs = errors.Join(errs, err)
@@ -75,15 +67,62 @@ func DeleteUnusedDaemonSets(cli client.Client, ctx context.Context, instance *nr
 	return errs
 }
 
+// DaemonSets returns the DaemonSets owned by the instance which don't belong to any of the given trees
+func DaemonSets(cli client.Client, ctx context.Context, instance *nropv1.NUMAResourcesOperator, trees []nodegroupv1.Tree) ([]appsv1.DaemonSet, error) {
+	var daemonSetList appsv1.DaemonSetList
+	if err := cli.List(ctx, &daemonSetList, &client.ListOptions{Namespace: instance.Namespace}); err != nil {
+		return nil, err
+	}
+
+	expectedDaemonSetNames := buildDaemonSetNames(instance, trees)
+
+	var ret []appsv1.DaemonSet
+	for _, ds := range daemonSetList.Items {
+		if !isOwnedBy(ds.GetObjectMeta(), instance) {
+			continue
+		}
+		if expectedDaemonSetNames.Has(ds.Name) {
+			continue
+		}
+		ret = append(ret, ds)
+	}
+	return ret, nil
+}
+
 func DeleteUnusedMachineConfigs(cli client.Client, ctx context.Context, instance *nropv1.NUMAResourcesOperator, trees []nodegroupv1.Tree) error {
 	klog.V(3).Info("Delete dangling Machineconfigs start")
 	defer klog.V(3).Info("Delete dangling Machineconfigs end")
-	var machineConfigList machineconfigv1.MachineConfigList
-	if err := cli.List(ctx, &machineConfigList); err != nil {
+
+	machineConfigs, err := MachineConfigs(cli, ctx, instance, trees)
+	if err != nil {
 		klog.ErrorS(err, "error while getting MachineConfig list")
 		return err
 	}
 
+	var errs error
+	deleted := 0
+	for _, mc := range machineConfigs {
+		if err := cli.Delete(ctx, &mc); err != nil {
+			klog.ErrorS(err, "error while deleting dangling machineconfig", "MachineConfig", mc.Name)
+			errs = errors.Join(errs, err)
+			continue
+		}
+		klog.V(3).InfoS("dangling Machineconfig deleted", "name", mc.Name)
+		deleted += 1
+	}
+	if deleted > 0 {
+		klog.V(2).InfoS("Delete dangling Machineconfigs", "deletedCount", deleted)
+	}
+	return errs
+}
+
+// MachineConfigs returns the MachineConfigs owned by the instance which don't belong to any of the given trees
+func MachineConfigs(cli client.Client, ctx context.Context, instance *nropv1.NUMAResourcesOperator, trees []nodegroupv1.Tree) ([]machineconfigv1.MachineConfig, error) {
+	var machineConfigList machineconfigv1.MachineConfigList
+	if err := cli.List(ctx, &machineConfigList); err != nil {
+		return nil, err
+	}
+
 	expectedMachineConfigNames := sets.NewString()
 	for _, tree := range trees {
 		for _, mcp := range tree.MachineConfigPools {
@@ -91,8 +130,7 @@ func DeleteUnusedMachineConfigs(cli client.Client, ctx context.Context, instance
 		}
 	}
 
-	var errs error
-	deleted := 0
+	var ret []machineconfigv1.MachineConfig
 	for _, mc := range machineConfigList.Items {
 		if !isOwnedBy(mc.GetObjectMeta(), instance) {
 			continue
@@ -100,18 +138,9 @@ func DeleteUnusedMachineConfigs(cli client.Client, ctx context.Context, instance
 		if expectedMachineConfigNames.Has(mc.Name) {
 			continue
 		}
-		if err := cli.Delete(ctx, &mc); err != nil {
-			klog.ErrorS(err, "error while deleting dangling machineconfig", "MachineConfig", mc.Name)
-			errs = errors.Join(errs, err)
-			continue
-		}
-		klog.V(3).InfoS("dangling Machineconfig deleted", "name", mc.Name)
-		deleted += 1
+		ret = append(ret, mc)
 	}
-	if deleted > 0 {
-		klog.V(2).InfoS("Delete dangling Machineconfigs", "deletedCount", deleted)
-	}
-	return errs
+	return ret, nil
 }
 
 func isOwnedBy(element metav1.Object, owner metav1.Object) bool {
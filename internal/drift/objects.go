@@ -0,0 +1,122 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package drift
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Fields compares the existing object with the desired one, and returns the sorted paths of the fields
+// the existing object doesn't match, like "metadata.annotations" or "spec.template.spec". The paths are
+// truncated at the given depth, so they summarize the difference instead of enumerating every changed leaf.
+// Only the fields set in the desired object are compared: the fields the API server defaults, and the ones
+// added by other actors, are not drifts. The type information and the status are not compared, because
+// they are never applied.
+func Fields(existing, desired client.Object, depth int) ([]string, error) {
+	existingData, err := objectData(existing)
+	if err != nil {
+		return nil, err
+	}
+	desiredData, err := objectData(desired)
+	if err != nil {
+		return nil, err
+	}
+	paths := map[string]struct{}{}
+	diffFields(paths, nil, existingData, desiredData, depth)
+
+	ret := make([]string, 0, len(paths))
+	for path := range paths {
+		ret = append(ret, path)
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
+func diffFields(paths map[string]struct{}, prefix []string, existing, desired map[string]interface{}, depth int) {
+	for key, desiredVal := range desired {
+		existingVal := existing[key]
+		if contains(existingVal, desiredVal) {
+			continue
+		}
+		path := append(append([]string{}, prefix...), key)
+		existingMap, existingOk := existingVal.(map[string]interface{})
+		desiredMap, desiredOk := desiredVal.(map[string]interface{})
+		if len(path) < depth && existingOk && desiredOk {
+			diffFields(paths, path, existingMap, desiredMap, depth)
+			continue
+		}
+		paths[strings.Join(path, ".")] = struct{}{}
+	}
+}
+
+// contains tells if the existing value has all the fields set in the desired one, like
+// equality.Semantic.DeepDerivative does on the typed objects: the unset desired fields match anything,
+// and the lists match if the existing one starts with items containing the desired ones.
+func contains(existing, desired interface{}) bool {
+	switch desiredVal := desired.(type) {
+	case nil:
+		return true
+	case string:
+		return desiredVal == "" || desiredVal == existing
+	case map[string]interface{}:
+		existingVal, ok := existing.(map[string]interface{})
+		if !ok {
+			return len(desiredVal) == 0
+		}
+		for key, val := range desiredVal {
+			if !contains(existingVal[key], val) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		existingVal, ok := existing.([]interface{})
+		if !ok {
+			return len(desiredVal) == 0
+		}
+		if len(desiredVal) > len(existingVal) {
+			return false
+		}
+		for idx := range desiredVal {
+			if !contains(existingVal[idx], desiredVal[idx]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(existing, desired)
+}
+
+func objectData(obj client.Object) (map[string]interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "apiVersion")
+	delete(fields, "kind")
+	delete(fields, "status")
+	return fields, nil
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package drift
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestFields(t *testing.T) {
+	testCases := []struct {
+		name     string
+		existing client.Object
+		desired  client.Object
+		depth    int
+		expected []string
+	}{
+		{
+			name:     "equal daemonsets",
+			existing: makeDaemonSet("quay.io/rte:1", nil),
+			desired:  makeDaemonSet("quay.io/rte:1", nil),
+			depth:    3,
+			expected: []string{},
+		},
+		{
+			name:     "pod spec changed",
+			existing: makeDaemonSet("quay.io/rte:1", nil),
+			desired:  makeDaemonSet("quay.io/rte:2", nil),
+			depth:    3,
+			expected: []string{"spec.template.spec"},
+		},
+		{
+			name:     "pod spec changed, shallow summary",
+			existing: makeDaemonSet("quay.io/rte:1", nil),
+			desired:  makeDaemonSet("quay.io/rte:2", nil),
+			depth:    1,
+			expected: []string{"spec"},
+		},
+		{
+			name:     "annotation added and pod spec changed",
+			existing: makeDaemonSet("quay.io/rte:1", nil),
+			desired:  makeDaemonSet("quay.io/rte:2", map[string]string{"foo": "bar"}),
+			depth:    3,
+			expected: []string{"metadata.annotations", "spec.template.spec"},
+		},
+		{
+			name:     "status is ignored",
+			existing: withNumberReady(makeDaemonSet("quay.io/rte:1", nil), 3),
+			desired:  makeDaemonSet("quay.io/rte:1", nil),
+			depth:    3,
+			expected: []string{},
+		},
+		{
+			name:     "server defaults and extra annotations are ignored",
+			existing: withServerDefaults(makeDaemonSet("quay.io/rte:1", map[string]string{"foo": "bar", "deprecated.daemonset.template.generation": "2"})),
+			desired:  makeDaemonSet("quay.io/rte:1", map[string]string{"foo": "bar"}),
+			depth:    3,
+			expected: []string{},
+		},
+		{
+			name:     "pod spec changed over server defaults",
+			existing: withServerDefaults(makeDaemonSet("quay.io/rte:1", nil)),
+			desired:  makeDaemonSet("quay.io/rte:2", nil),
+			depth:    3,
+			expected: []string{"spec.template.spec"},
+		},
+		{
+			name: "configmap data changed",
+			existing: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cm"},
+				Data:       map[string]string{"config.yaml": "foo"},
+			},
+			desired: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cm"},
+				Data:       map[string]string{"config.yaml": "bar"},
+			},
+			depth:    3,
+			expected: []string{"data.config.yaml"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Fields(tc.existing, tc.desired, tc.depth)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("drifted fields mismatch: got=%v expected=%v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func makeDaemonSet(image string, annots map[string]string) *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "rte",
+			Annotations: annots,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "rte",
+							Image: image,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func withNumberReady(ds *appsv1.DaemonSet, numberReady int32) *appsv1.DaemonSet {
+	ds.Status.NumberReady = numberReady
+	return ds
+}
+
+// withServerDefaults sets some of the fields the API server defaults on creation
+func withServerDefaults(ds *appsv1.DaemonSet) *appsv1.DaemonSet {
+	revisionHistoryLimit := int32(10)
+	ds.Spec.RevisionHistoryLimit = &revisionHistoryLimit
+	ds.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{
+		Type: appsv1.RollingUpdateDaemonSetStrategyType,
+	}
+	ds.Spec.Template.Spec.DNSPolicy = corev1.DNSClusterFirst
+	ds.Spec.Template.Spec.SchedulerName = corev1.DefaultSchedulerName
+	ds.Spec.Template.Spec.Containers[0].TerminationMessagePath = corev1.TerminationMessagePathDefault
+	ds.Spec.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullIfNotPresent
+	return withNumberReady(ds, 3)
+}
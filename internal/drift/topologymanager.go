@@ -0,0 +1,91 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package drift
+
+import (
+	"fmt"
+	"sort"
+
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	intnrt "github.com/openshift-kni/numaresources-operator/internal/noderesourcetopology"
+	rteconfig "github.com/openshift-kni/numaresources-operator/rte/pkg/config"
+)
+
+// TopologyManagerNode reports a node whose NRT object publishes a topology manager
+// configuration which differs from the one rendered for the pool the node belongs to.
+type TopologyManagerNode struct {
+	NodeName string
+	PoolName string
+	Expected rteconfig.KubeletParams
+	Detected rteconfig.KubeletParams
+}
+
+func (tmn TopologyManagerNode) String() string {
+	return fmt.Sprintf("%s (pool %s): expected policy=%q scope=%q detected policy=%q scope=%q",
+		tmn.NodeName, tmn.PoolName,
+		tmn.Expected.TopologyManagerPolicy, tmn.Expected.TopologyManagerScope,
+		tmn.Detected.TopologyManagerPolicy, tmn.Detected.TopologyManagerScope)
+}
+
+// TopologyManager compares the topology manager configuration expected on the nodes of the given pool
+// with the attributes published in their NRT objects, and returns the nodes which don't match, sorted by name.
+// Nodes which don't have a NRT object yet are skipped, because there is nothing to compare yet.
+// Empty expected values mean the kubelet default is used, so they are not checked.
+func TopologyManager(poolName string, expected rteconfig.KubeletParams, nodeNames []string, nrts map[string]*nrtv1alpha2.NodeResourceTopology) []TopologyManagerNode {
+	var ret []TopologyManagerNode
+	for _, nodeName := range nodeNames {
+		nrt, ok := nrts[nodeName]
+		if !ok || nrt == nil {
+			continue
+		}
+
+		policy, scope := intnrt.TopologyManagerFromAttributes(nrt.Attributes)
+		detected := rteconfig.KubeletParams{
+			TopologyManagerPolicy: policy,
+			TopologyManagerScope:  scope,
+		}
+		if matchesValue(expected.TopologyManagerPolicy, detected.TopologyManagerPolicy) && matchesValue(expected.TopologyManagerScope, detected.TopologyManagerScope) {
+			continue
+		}
+
+		ret = append(ret, TopologyManagerNode{
+			NodeName: nodeName,
+			PoolName: poolName,
+			Expected: expected,
+			Detected: detected,
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].NodeName < ret[j].NodeName })
+	return ret
+}
+
+// NodeNames returns the names of the given drifted nodes, preserving their order
+func NodeNames(nodes []TopologyManagerNode) []string {
+	ret := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		ret = append(ret, node.NodeName)
+	}
+	return ret
+}
+
+func matchesValue(expected, detected string) bool {
+	if expected == "" {
+		return true
+	}
+	return expected == detected
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package drift
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	intnrt "github.com/openshift-kni/numaresources-operator/internal/noderesourcetopology"
+	rteconfig "github.com/openshift-kni/numaresources-operator/rte/pkg/config"
+)
+
+func TestTopologyManager(t *testing.T) {
+	snn := rteconfig.KubeletParams{
+		TopologyManagerPolicy: intnrt.SingleNUMANode,
+		TopologyManagerScope:  intnrt.Pod,
+	}
+
+	testCases := []struct {
+		name      string
+		expected  rteconfig.KubeletParams
+		nodeNames []string
+		nrts      map[string]*nrtv1alpha2.NodeResourceTopology
+		drifted   []string
+	}{
+		{
+			name:      "no NRTs",
+			expected:  snn,
+			nodeNames: []string{"node-0", "node-1"},
+		},
+		{
+			name:      "all matching",
+			expected:  snn,
+			nodeNames: []string{"node-0", "node-1"},
+			nrts: map[string]*nrtv1alpha2.NodeResourceTopology{
+				"node-0": makeNRT("node-0", intnrt.SingleNUMANode, intnrt.Pod),
+				"node-1": makeNRT("node-1", intnrt.SingleNUMANode, intnrt.Pod),
+			},
+		},
+		{
+			name:      "policy mismatch",
+			expected:  snn,
+			nodeNames: []string{"node-1", "node-0"},
+			nrts: map[string]*nrtv1alpha2.NodeResourceTopology{
+				"node-0": makeNRT("node-0", intnrt.Restricted, intnrt.Pod),
+				"node-1": makeNRT("node-1", intnrt.None, intnrt.Pod),
+			},
+			drifted: []string{"node-0", "node-1"},
+		},
+		{
+			name:      "scope mismatch",
+			expected:  snn,
+			nodeNames: []string{"node-0", "node-1"},
+			nrts: map[string]*nrtv1alpha2.NodeResourceTopology{
+				"node-0": makeNRT("node-0", intnrt.SingleNUMANode, intnrt.Pod),
+				"node-1": makeNRT("node-1", intnrt.SingleNUMANode, intnrt.Container),
+			},
+			drifted: []string{"node-1"},
+		},
+		{
+			name:      "missing attributes",
+			expected:  snn,
+			nodeNames: []string{"node-0"},
+			nrts: map[string]*nrtv1alpha2.NodeResourceTopology{
+				"node-0": makeNRT("node-0", "", ""),
+			},
+			drifted: []string{"node-0"},
+		},
+		{
+			name: "empty expected scope",
+			expected: rteconfig.KubeletParams{
+				TopologyManagerPolicy: intnrt.SingleNUMANode,
+			},
+			nodeNames: []string{"node-0"},
+			nrts: map[string]*nrtv1alpha2.NodeResourceTopology{
+				"node-0": makeNRT("node-0", intnrt.SingleNUMANode, intnrt.Container),
+			},
+		},
+		{
+			name:      "NRT of nodes outside the pool",
+			expected:  snn,
+			nodeNames: []string{"node-0"},
+			nrts: map[string]*nrtv1alpha2.NodeResourceTopology{
+				"node-0": makeNRT("node-0", intnrt.SingleNUMANode, intnrt.Pod),
+				"node-9": makeNRT("node-9", intnrt.None, intnrt.Container),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := TopologyManager("test-pool", tc.expected, tc.nodeNames, tc.nrts)
+			gotNames := NodeNames(got)
+			if len(gotNames) == 0 && len(tc.drifted) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(gotNames, tc.drifted) {
+				t.Errorf("drifted nodes mismatch: got=%v expected=%v", gotNames, tc.drifted)
+			}
+			for _, node := range got {
+				if node.PoolName != "test-pool" {
+					t.Errorf("unexpected pool name for node %q: %q", node.NodeName, node.PoolName)
+				}
+			}
+		})
+	}
+}
+
+func makeNRT(name, policy, scope string) *nrtv1alpha2.NodeResourceTopology {
+	nrt := nrtv1alpha2.NodeResourceTopology{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+	}
+	if policy != "" {
+		nrt.Attributes = append(nrt.Attributes, nrtv1alpha2.AttributeInfo{Name: intnrt.TopologyManagerPolicyAttribute, Value: policy})
+	}
+	if scope != "" {
+		nrt.Attributes = append(nrt.Attributes, nrtv1alpha2.AttributeInfo{Name: intnrt.TopologyManagerScopeAttribute, Value: scope})
+	}
+	return &nrt
+}
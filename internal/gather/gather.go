@@ -0,0 +1,425 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package gather collects the NUMA resources diagnostics data from a live cluster, organized per node,
+// to be consumed by must-gather. The collection is best effort: the failures are recorded in the index
+// and the collection carries on, so a partially broken cluster still produces useful data.
+package gather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	"github.com/openshift-kni/numaresources-operator/internal/baseload"
+	"github.com/openshift-kni/numaresources-operator/internal/pfpstatus"
+	"github.com/openshift-kni/numaresources-operator/internal/podlist"
+	"github.com/openshift-kni/numaresources-operator/internal/schedcache"
+	schedstate "github.com/openshift-kni/numaresources-operator/pkg/numaresourcesscheduler/objectstate/sched"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
+	rteconfig "github.com/openshift-kni/numaresources-operator/rte/pkg/config"
+)
+
+const (
+	IndexFileName = "index.json"
+
+	NodesDir      = "nodes"
+	RTEConfigsDir = "rteconfigs"
+	SchedulerDir  = "scheduler"
+	// PFPStatusDir holds the pod fingerprint status dumps
+	PFPStatusDir = "pfpstatus"
+
+	NodeResourceTopologyFileName = "noderesourcetopology.yaml"
+	BaseLoadFileName             = "baseload.json"
+	KubeletConfigzFileName       = "kubeletconfigz.json"
+	SchedulerConfigFileName      = "config.yaml"
+)
+
+// Env holds the clients used to collect the data
+type Env struct {
+	Ctx context.Context
+	Cli client.Client
+	// K8sCli reads the pod fingerprint status dumps and the kubelet configuration through the API server.
+	// If nil, the kubelet configuration is not collected, and the dumps are collected only if PFPStatus is set.
+	K8sCli kubernetes.Interface
+	// PFPStatus fetches the pod fingerprint status dumps over HTTPS. If nil, the dumps are read executing commands in the pods.
+	PFPStatus *pfpstatus.Client
+	Namespace string
+}
+
+// Index summarizes the collected data. All the paths are relative to the collection directory.
+type Index struct {
+	Timestamp time.Time   `json:"timestamp"`
+	Namespace string      `json:"namespace"`
+	Files     []string    `json:"files,omitempty"`
+	Nodes     []NodeIndex `json:"nodes,omitempty"`
+	Errors    []string    `json:"errors,omitempty"`
+}
+
+// NodeIndex lists the data collected for a node
+type NodeIndex struct {
+	Name string `json:"name"`
+	// TAS is true if the node runs the topology aware scheduling stack, which is if it publishes a NRT object
+	TAS   bool     `json:"tas"`
+	Files []string `json:"files,omitempty"`
+}
+
+type collector struct {
+	env   *Env
+	dir   string
+	index Index
+	nodes map[string]*NodeIndex
+}
+
+// Collect gathers the diagnostics data in the given directory, and returns the index of the collected data,
+// which is also saved in the directory. The error is about writing the data only: the collection failures
+// are reported in the index.
+func Collect(env *Env, dir string) (Index, error) {
+	col := collector{
+		env: env,
+		dir: dir,
+		index: Index{
+			Timestamp: time.Now().UTC(),
+			Namespace: env.Namespace,
+		},
+		nodes: make(map[string]*NodeIndex),
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return col.index, err
+	}
+
+	// the NRT objects must go first, because they tell which are the TAS nodes
+	steps := []func() error{
+		col.nodeResourceTopologies,
+		col.rteConfigs,
+		col.rteDumps,
+		col.scheduler,
+		col.baseLoads,
+		col.kubeletConfigz,
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return col.index, err
+		}
+	}
+
+	nodeNames := make([]string, 0, len(col.nodes))
+	for name := range col.nodes {
+		nodeNames = append(nodeNames, name)
+	}
+	sort.Strings(nodeNames)
+	for _, name := range nodeNames {
+		node := col.nodes[name]
+		sort.Strings(node.Files)
+		col.index.Nodes = append(col.index.Nodes, *node)
+	}
+	sort.Strings(col.index.Files)
+
+	data, err := json.MarshalIndent(col.index, "", "  ")
+	if err != nil {
+		return col.index, err
+	}
+	return col.index, os.WriteFile(filepath.Join(dir, IndexFileName), data, 0o644)
+}
+
+func (col *collector) nodeResourceTopologies() error {
+	nrts := nrtv1alpha2.NodeResourceTopologyList{}
+	if err := col.env.Cli.List(col.env.Ctx, &nrts); err != nil {
+		col.failed("listing NodeResourceTopologies", err)
+		return nil
+	}
+	for idx := range nrts.Items {
+		nrt := &nrts.Items[idx]
+		col.node(nrt.Name).TAS = true
+		if err := col.writeObject(nodePath(nrt.Name, NodeResourceTopologyFileName), nrt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rteConfigs saves the RTE configmaps rendered by the operator, and the RTE configuration they hold
+func (col *collector) rteConfigs() error {
+	cms := corev1.ConfigMapList{}
+	if err := col.env.Cli.List(col.env.Ctx, &cms, client.InNamespace(col.env.Namespace), client.HasLabels{rteconfig.LabelOperatorName}); err != nil {
+		col.failed("listing RTE configmaps", err)
+		return nil
+	}
+	for idx := range cms.Items {
+		cm := &cms.Items[idx]
+		if err := col.writeObject(path.Join(RTEConfigsDir, cm.Name+".yaml"), cm); err != nil {
+			return err
+		}
+		data, err := rteconfig.UnpackConfigMap(cm)
+		if err != nil {
+			col.failed("unpacking RTE configmap "+cm.Name, err)
+			continue
+		}
+		conf, err := rteconfig.Unrender(data)
+		if err != nil {
+			col.failed("decoding RTE configmap "+cm.Name, err)
+			continue
+		}
+		if err := col.writeJSON(path.Join(RTEConfigsDir, cm.Name+".config.json"), conf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rteDumps saves the pod fingerprint status dumps of the RTE pods, in the directory of the node they run on
+func (col *collector) rteDumps() error {
+	if !col.canGetDumps() {
+		return nil
+	}
+	nro := nropv1.NUMAResourcesOperator{}
+	if err := col.env.Cli.Get(col.env.Ctx, client.ObjectKey{Name: objectnames.DefaultNUMAResourcesOperatorCrName}, &nro); err != nil {
+		col.failed("getting NUMAResourcesOperator", err)
+		return nil
+	}
+	env := col.schedCacheEnv()
+	for _, dsName := range nro.Status.DaemonSets {
+		ds := appsv1.DaemonSet{}
+		if err := col.env.Cli.Get(col.env.Ctx, client.ObjectKey(dsName), &ds); err != nil {
+			col.failed("getting RTE daemonset "+dsName.String(), err)
+			continue
+		}
+		pods, err := podlist.With(col.env.Cli).ByDaemonset(col.env.Ctx, ds)
+		if err != nil {
+			col.failed("listing the pods of RTE daemonset "+dsName.String(), err)
+			continue
+		}
+		for idx := range pods {
+			pod := &pods[idx]
+			if pod.Spec.NodeName == "" {
+				continue
+			}
+			st, err := schedcache.GetUpdaterFingerprintStatus(env, pod.Namespace, pod.Name, manifests.ContainerNameRTE)
+			if err != nil {
+				col.failed("getting the pod fingerprint status of RTE pod "+pod.Name, err)
+				continue
+			}
+			if err := col.writeJSON(nodePath(pod.Spec.NodeName, PFPStatusDir, "rte-"+pod.Name+".json"), st); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// scheduler saves the configuration the scheduler deployment consumes, and the pod fingerprint status
+// each scheduler replica computed for the TAS nodes, in the directory of the node
+func (col *collector) scheduler() error {
+	nros := nropv1.NUMAResourcesScheduler{}
+	if err := col.env.Cli.Get(col.env.Ctx, client.ObjectKey{Name: objectnames.DefaultNUMAResourcesSchedulerCrName}, &nros); err != nil {
+		if !apierrors.IsNotFound(err) {
+			col.failed("getting NUMAResourcesScheduler", err)
+		}
+		return nil
+	}
+	if nros.Status.Deployment.Name == "" {
+		return nil
+	}
+	dp := appsv1.Deployment{}
+	if err := col.env.Cli.Get(col.env.Ctx, client.ObjectKey(nros.Status.Deployment), &dp); err != nil {
+		col.failed("getting scheduler deployment "+nros.Status.Deployment.String(), err)
+		return nil
+	}
+
+	if cmName := configMapNameFromDeployment(&dp); cmName != "" {
+		cm := corev1.ConfigMap{}
+		if err := col.env.Cli.Get(col.env.Ctx, client.ObjectKey{Namespace: dp.Namespace, Name: cmName}, &cm); err != nil {
+			col.failed("getting scheduler configmap "+cmName, err)
+		} else if err := col.writeFile(path.Join(SchedulerDir, SchedulerConfigFileName), []byte(cm.Data[schedstate.SchedulerConfigFileName])); err != nil {
+			return err
+		}
+	}
+
+	if !col.canGetDumps() {
+		return nil
+	}
+	pods, err := podlist.With(col.env.Cli).ByDeployment(col.env.Ctx, dp)
+	if err != nil {
+		col.failed("listing scheduler pods", err)
+		return nil
+	}
+	env := col.schedCacheEnv()
+	for idx := range pods {
+		pod := &pods[idx]
+		for _, nodeName := range col.tasNodeNames() {
+			st, err := schedcache.GetSchedulerFingerprintStatus(env, pod, nodeName)
+			if err != nil {
+				col.failed("getting the pod fingerprint status of scheduler pod "+pod.Name+" for node "+nodeName, err)
+				continue
+			}
+			if err := col.writeJSON(nodePath(nodeName, PFPStatusDir, "scheduler-"+pod.Name+".json"), st); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (col *collector) baseLoads() error {
+	for _, nodeName := range col.tasNodeNames() {
+		load, err := baseload.ForNode(col.env.Cli, col.env.Ctx, nodeName)
+		if err != nil {
+			col.failed("computing the base load of node "+nodeName, err)
+			continue
+		}
+		if err := col.writeJSON(nodePath(nodeName, BaseLoadFileName), load); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// kubeletConfigz saves the configuration the kubelet of each TAS node is running with
+func (col *collector) kubeletConfigz() error {
+	if col.env.K8sCli == nil {
+		return nil
+	}
+	for _, nodeName := range col.tasNodeNames() {
+		data, err := col.env.K8sCli.CoreV1().RESTClient().Get().Resource("nodes").Name(nodeName).SubResource("proxy").Suffix("configz").DoRaw(col.env.Ctx)
+		if err != nil {
+			col.failed("getting the kubelet configz of node "+nodeName, err)
+			continue
+		}
+		if err := col.writeFile(nodePath(nodeName, KubeletConfigzFileName), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (col *collector) canGetDumps() bool {
+	return col.env.K8sCli != nil || col.env.PFPStatus != nil
+}
+
+func (col *collector) schedCacheEnv() *schedcache.Env {
+	return &schedcache.Env{
+		Ctx:       col.env.Ctx,
+		Cli:       col.env.Cli,
+		K8sCli:    col.env.K8sCli,
+		Log:       klog.Background(),
+		PFPStatus: col.env.PFPStatus,
+	}
+}
+
+func (col *collector) node(name string) *NodeIndex {
+	node, ok := col.nodes[name]
+	if !ok {
+		node = &NodeIndex{Name: name}
+		col.nodes[name] = node
+	}
+	return node
+}
+
+func (col *collector) tasNodeNames() []string {
+	var names []string
+	for name, node := range col.nodes {
+		if node.TAS {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (col *collector) failed(what string, err error) {
+	klog.InfoS("collection failed", "what", what, "error", err)
+	col.index.Errors = append(col.index.Errors, fmt.Sprintf("%s: %v", what, err))
+}
+
+func (col *collector) writeObject(relPath string, obj client.Object) error {
+	// the objects read from the API server have no type information, which is needed to load them back
+	if gvk, err := apiutil.GVKForObject(obj, col.env.Cli.Scheme()); err == nil {
+		obj = obj.DeepCopyObject().(client.Object)
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return col.writeFile(relPath, data)
+}
+
+func (col *collector) writeJSON(relPath string, obj interface{}) error {
+	data, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return err
+	}
+	return col.writeFile(relPath, data)
+}
+
+// writeFile saves the data in the relPath, which uses forward slashes, and indexes it
+func (col *collector) writeFile(relPath string, data []byte) error {
+	fullPath := filepath.Join(col.dir, filepath.FromSlash(relPath))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(fullPath, data, 0o644); err != nil {
+		return err
+	}
+	if nodeName, ok := nodeNameFromPath(relPath); ok {
+		node := col.node(nodeName)
+		node.Files = append(node.Files, relPath)
+	} else {
+		col.index.Files = append(col.index.Files, relPath)
+	}
+	return nil
+}
+
+func nodePath(nodeName string, elems ...string) string {
+	return path.Join(append([]string{NodesDir, nodeName}, elems...)...)
+}
+
+func nodeNameFromPath(relPath string) (string, bool) {
+	dir, rest, ok := strings.Cut(relPath, "/")
+	if !ok || dir != NodesDir {
+		return "", false
+	}
+	nodeName, _, ok := strings.Cut(rest, "/")
+	return nodeName, ok
+}
+
+func configMapNameFromDeployment(dp *appsv1.Deployment) string {
+	for _, vol := range dp.Spec.Template.Spec.Volumes {
+		if vol.Name == schedstate.SchedulerConfigMapVolumeName && vol.ConfigMap != nil {
+			return vol.ConfigMap.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gather
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	kubeletconfigv1beta1 "k8s.io/kubelet/config/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	"github.com/openshift-kni/numaresources-operator/internal/baseload"
+	testobjs "github.com/openshift-kni/numaresources-operator/internal/objects"
+	schedstate "github.com/openshift-kni/numaresources-operator/pkg/numaresourcesscheduler/objectstate/sched"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
+	rteconfig "github.com/openshift-kni/numaresources-operator/rte/pkg/config"
+)
+
+const testNamespace = "numaresources"
+
+func TestCollect(t *testing.T) {
+	if err := nropv1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("cannot register the operator API: %v", err)
+	}
+	if err := nrtv1alpha2.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("cannot register the NRT API: %v", err)
+	}
+
+	rteData, err := rteconfig.Render(&kubeletconfigv1beta1.KubeletConfiguration{
+		TopologyManagerPolicy: "single-numa-node",
+		TopologyManagerScope:  "pod",
+	}, nil)
+	if err != nil {
+		t.Fatalf("cannot render the RTE configuration: %v", err)
+	}
+	rteCM := rteconfig.AddSoftRefLabels(rteconfig.CreateConfigMap(testNamespace, "rte-worker", rteData), objectnames.DefaultNUMAResourcesOperatorCrName, "worker")
+
+	nros := testobjs.NewNUMAResourcesScheduler(objectnames.DefaultNUMAResourcesSchedulerCrName, "quay.io/sched:latest", "topo-aware-scheduler", 0)
+	nros.Status.Deployment = nropv1.NamespacedName{Namespace: testNamespace, Name: "secondary-scheduler"}
+	schedDP := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "secondary-scheduler"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Volumes: []corev1.Volume{
+						{
+							Name: schedstate.SchedulerConfigMapVolumeName,
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "scheduler-config"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	schedCM := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "scheduler-config"},
+		Data: map[string]string{
+			schedstate.SchedulerConfigFileName: "kind: KubeSchedulerConfiguration\n",
+		},
+	}
+
+	objs := []runtime.Object{
+		&nrtv1alpha2.NodeResourceTopology{ObjectMeta: metav1.ObjectMeta{Name: "node-0"}},
+		&nrtv1alpha2.NodeResourceTopology{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		rteCM,
+		nros,
+		schedDP,
+		schedCM,
+		makePod("pod-0", "node-0", "1", "1Gi"),
+		makePod("pod-1", "node-0", "1", "1Gi"),
+		makePod("pod-2", "node-2", "4", "4Gi"),
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(objs...).WithIndex(&corev1.Pod{}, "spec.nodeName", func(obj client.Object) []string {
+		return []string{obj.(*corev1.Pod).Spec.NodeName}
+	}).Build()
+
+	dir := t.TempDir()
+	index, err := Collect(&Env{Ctx: context.TODO(), Cli: cli, Namespace: testNamespace}, dir)
+	if err != nil {
+		t.Fatalf("collection failed: %v", err)
+	}
+
+	expectedFiles := []string{
+		"rteconfigs/rte-worker.config.json",
+		"rteconfigs/rte-worker.yaml",
+		"scheduler/config.yaml",
+	}
+	if !reflect.DeepEqual(index.Files, expectedFiles) {
+		t.Errorf("cluster files mismatch: got=%v expected=%v", index.Files, expectedFiles)
+	}
+	expectedNodes := []NodeIndex{
+		{Name: "node-0", TAS: true, Files: []string{"nodes/node-0/baseload.json", "nodes/node-0/noderesourcetopology.yaml"}},
+		{Name: "node-1", TAS: true, Files: []string{"nodes/node-1/baseload.json", "nodes/node-1/noderesourcetopology.yaml"}},
+	}
+	if !reflect.DeepEqual(index.Nodes, expectedNodes) {
+		t.Errorf("nodes mismatch: got=%+v expected=%+v", index.Nodes, expectedNodes)
+	}
+	// without the clients to read the dumps, the RTE pods are not looked for
+	if len(index.Errors) > 0 {
+		t.Errorf("unexpected errors: %v", index.Errors)
+	}
+
+	var savedIndex Index
+	readJSON(t, filepath.Join(dir, IndexFileName), &savedIndex)
+	if !reflect.DeepEqual(savedIndex.Nodes, index.Nodes) {
+		t.Errorf("saved index mismatch: got=%+v expected=%+v", savedIndex.Nodes, index.Nodes)
+	}
+
+	var conf rteconfig.Config
+	readJSON(t, filepath.Join(dir, "rteconfigs", "rte-worker.config.json"), &conf)
+	if conf.Kubelet.TopologyManagerPolicy != "single-numa-node" || conf.Kubelet.TopologyManagerScope != "pod" {
+		t.Errorf("unexpected decoded RTE configuration: %+v", conf)
+	}
+
+	var load baseload.Load
+	readJSON(t, filepath.Join(dir, "nodes", "node-0", BaseLoadFileName), &load)
+	if cpus := load.Resources[corev1.ResourceCPU]; cpus.Cmp(resource.MustParse("2")) != 0 {
+		t.Errorf("unexpected node-0 CPU base load: %v", cpus.String())
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "nodes", "node-1", NodeResourceTopologyFileName))
+	if err != nil {
+		t.Fatalf("cannot read the NRT object: %v", err)
+	}
+	nrt := nrtv1alpha2.NodeResourceTopology{}
+	if _, _, err := scheme.Codecs.UniversalDeserializer().Decode(data, nil, &nrt); err != nil || nrt.Name != "node-1" {
+		t.Errorf("cannot decode the NRT object: name=%q err=%v", nrt.Name, err)
+	}
+}
+
+func makePod(name, nodeName, cpu, memory string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{
+				{
+					Name: "cnt",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse(cpu),
+							corev1.ResourceMemory: resource.MustParse(memory),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func readJSON(t *testing.T, path string, obj interface{}) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("cannot read %q: %v", path, err)
+	}
+	if err := json.Unmarshal(data, obj); err != nil {
+		t.Fatalf("cannot decode %q: %v", path, err)
+	}
+}
@@ -16,6 +16,11 @@
 
 package noderesourcetopology
 
+import (
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+	"github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2/helper/attribute"
+)
+
 // TopologyManager Attributes Names and values should follow the format
 // documented here
 // https://github.com/kubernetes-sigs/scheduler-plugins/blob/master/pkg/noderesourcetopology/README.md#topology-manager-configuration
@@ -46,3 +51,16 @@ const (
 	Container = "container"
 	Pod       = "pod"
 )
+
+// TopologyManagerFromAttributes returns the topology manager policy and scope
+// published in the given NRT attributes. Missing attributes are reported as empty strings.
+func TopologyManagerFromAttributes(attrs nrtv1alpha2.AttributeList) (string, string) {
+	var policy, scope string
+	if attr, ok := attribute.Get(attrs, TopologyManagerPolicyAttribute); ok {
+		policy = attr.Value
+	}
+	if attr, ok := attribute.Get(attrs, TopologyManagerScopeAttribute); ok {
+		scope = attr.Value
+	}
+	return policy, scope
+}
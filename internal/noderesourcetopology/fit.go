@@ -0,0 +1,88 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package noderesourcetopology
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+)
+
+const (
+	ZoneTypeNode = "Node"
+)
+
+// Shortage describes a resource a zone cannot provide in the requested amount
+type Shortage struct {
+	Name      corev1.ResourceName
+	Requested resource.Quantity
+	Available resource.Quantity
+}
+
+func (sh Shortage) String() string {
+	return fmt.Sprintf("%s (requested %s, available %s)", sh.Name, sh.Requested.String(), sh.Available.String())
+}
+
+// ZoneResourceNames returns the names of the resources reported by any zone of the given NRT,
+// which are the resources the node aligns on NUMA zones.
+func ZoneResourceNames(nrt nrtv1alpha2.NodeResourceTopology) sets.Set[string] {
+	ret := sets.New[string]()
+	for _, zone := range nrt.Zones {
+		for _, res := range zone.Resources {
+			ret.Insert(res.Name)
+		}
+	}
+	return ret
+}
+
+// IsHostLevelResource tells if the resource is provided by the node as a whole and never bound to
+// a NUMA zone, so the zones never report it.
+func IsHostLevelResource(resName corev1.ResourceName) bool {
+	return resName == corev1.ResourceEphemeralStorage || resName == corev1.ResourceStorage
+}
+
+// ZoneShortages returns the resources of the request the zone cannot provide, sorted by name.
+// Resources the zone does not report are considered not available.
+func ZoneShortages(zone nrtv1alpha2.Zone, request corev1.ResourceList) []Shortage {
+	var ret []Shortage
+	for resName, resQty := range request {
+		var avail resource.Quantity
+		for _, res := range zone.Resources {
+			if res.Name == string(resName) {
+				avail = res.Available
+				break
+			}
+		}
+		if avail.Cmp(resQty) >= 0 {
+			continue
+		}
+		ret = append(ret, Shortage{
+			Name:      resName,
+			Requested: resQty,
+			Available: avail,
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
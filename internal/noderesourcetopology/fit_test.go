@@ -0,0 +1,123 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package noderesourcetopology
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+)
+
+func TestZoneResourceNames(t *testing.T) {
+	nrt := nrtv1alpha2.NodeResourceTopology{
+		Zones: nrtv1alpha2.ZoneList{
+			{
+				Name: "node-0",
+				Type: ZoneTypeNode,
+				Resources: nrtv1alpha2.ResourceInfoList{
+					{Name: "cpu", Available: resource.MustParse("4")},
+					{Name: "memory", Available: resource.MustParse("8Gi")},
+				},
+			},
+			{
+				Name: "node-1",
+				Type: ZoneTypeNode,
+				Resources: nrtv1alpha2.ResourceInfoList{
+					{Name: "cpu", Available: resource.MustParse("4")},
+					{Name: "example.com/device", Available: resource.MustParse("1")},
+				},
+			},
+		},
+	}
+
+	got := ZoneResourceNames(nrt)
+	expected := sets.New[string]("cpu", "memory", "example.com/device")
+	if !got.Equal(expected) {
+		t.Errorf("got=%v expected=%v", sets.List(got), sets.List(expected))
+	}
+}
+
+func TestZoneShortages(t *testing.T) {
+	zone := nrtv1alpha2.Zone{
+		Name: "node-0",
+		Type: ZoneTypeNode,
+		Resources: nrtv1alpha2.ResourceInfoList{
+			{Name: "cpu", Available: resource.MustParse("4")},
+			{Name: "memory", Available: resource.MustParse("8Gi")},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		request  corev1.ResourceList
+		expected []Shortage
+	}{
+		{
+			name: "empty request",
+		},
+		{
+			name: "fits exactly",
+			request: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+		},
+		{
+			name: "not enough cpus",
+			request: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("6"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+			expected: []Shortage{
+				{Name: corev1.ResourceCPU, Requested: resource.MustParse("6"), Available: resource.MustParse("4")},
+			},
+		},
+		{
+			name: "resource not in zone",
+			request: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+				"example.com/device":  resource.MustParse("1"),
+			},
+			expected: []Shortage{
+				{Name: "example.com/device", Requested: resource.MustParse("1")},
+				{Name: corev1.ResourceMemory, Requested: resource.MustParse("16Gi"), Available: resource.MustParse("8Gi")},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ZoneShortages(zone, tc.request)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("got=%v expected=%v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestShortageString(t *testing.T) {
+	sh := Shortage{Name: corev1.ResourceCPU, Requested: resource.MustParse("6"), Available: resource.MustParse("4")}
+	expected := "cpu (requested 6, available 4)"
+	if got := sh.String(); got != expected {
+		t.Errorf("got=%q expected=%q", got, expected)
+	}
+}
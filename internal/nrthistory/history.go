@@ -0,0 +1,275 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package nrthistory records the changes of the NodeResourceTopology objects as compact diffs,
+// and reconstructs the objects as they were at any recorded time.
+package nrthistory
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	intnrt "github.com/openshift-kni/numaresources-operator/internal/noderesourcetopology"
+)
+
+type EventType string
+
+const (
+	// EventSnapshot records the full object. It is the base the following updates apply to.
+	EventSnapshot EventType = "snapshot"
+	// EventUpdate records only what changed since the previous record of the same node.
+	EventUpdate EventType = "update"
+	// EventDelete records the deletion of the object.
+	EventDelete EventType = "delete"
+)
+
+// Record is a change of the NodeResourceTopology object of a node
+type Record struct {
+	Timestamp time.Time `json:"ts"`
+	Node      string    `json:"node"`
+	Event     EventType `json:"event"`
+	// Attributes are the node attributes, set only if changed
+	Attributes *nrtv1alpha2.AttributeList `json:"attributes,omitempty"`
+	Zones      []ZoneChange               `json:"zones,omitempty"`
+}
+
+// ZoneChange is the change of a zone
+type ZoneChange struct {
+	Name string `json:"name"`
+	// Zone is the full zone, set if the zone was added, or if anything but its resources changed
+	Zone    *nrtv1alpha2.Zone `json:"zone,omitempty"`
+	Removed bool              `json:"removed,omitempty"`
+	// Resources are the resources added or changed
+	Resources        nrtv1alpha2.ResourceInfoList `json:"resources,omitempty"`
+	RemovedResources []string                     `json:"removedResources,omitempty"`
+}
+
+// Diff returns the record of the change from prev to curr, which happened at the given time, and false if nothing changed.
+// If prev is nil, the record is a snapshot of curr. Only zones, resources and attributes are compared.
+func Diff(ts time.Time, prev, curr *nrtv1alpha2.NodeResourceTopology) (Record, bool) {
+	if prev == nil {
+		return Snapshot(ts, curr), true
+	}
+	rec := Record{
+		Timestamp: ts,
+		Node:      curr.Name,
+		Event:     EventUpdate,
+	}
+	if attrs := sortedAttributes(curr.Attributes); !reflect.DeepEqual(sortedAttributes(prev.Attributes), attrs) {
+		rec.Attributes = &attrs
+	}
+
+	prevZones := make(map[string]*nrtv1alpha2.Zone, len(prev.Zones))
+	for idx := range prev.Zones {
+		prevZones[prev.Zones[idx].Name] = &prev.Zones[idx]
+	}
+	currZones := intnrt.SortedZoneList(curr.Zones)
+	for idx := range currZones {
+		zone := &currZones[idx]
+		prevZone, ok := prevZones[zone.Name]
+		delete(prevZones, zone.Name)
+		if !ok || !equalZoneMetadata(prevZone, zone) {
+			rec.Zones = append(rec.Zones, ZoneChange{Name: zone.Name, Zone: zone.DeepCopy()})
+			continue
+		}
+		if change, ok := diffResources(prevZone, zone); ok {
+			rec.Zones = append(rec.Zones, change)
+		}
+	}
+	var removed []string
+	for name := range prevZones {
+		removed = append(removed, name)
+	}
+	sort.Strings(removed)
+	for _, name := range removed {
+		rec.Zones = append(rec.Zones, ZoneChange{Name: name, Removed: true})
+	}
+
+	return rec, rec.Attributes != nil || len(rec.Zones) > 0
+}
+
+// Snapshot returns the record of the full object, as it is at the given time
+func Snapshot(ts time.Time, nrt *nrtv1alpha2.NodeResourceTopology) Record {
+	attrs := sortedAttributes(nrt.Attributes)
+	rec := Record{
+		Timestamp:  ts,
+		Node:       nrt.Name,
+		Event:      EventSnapshot,
+		Attributes: &attrs,
+	}
+	zones := intnrt.SortedZoneList(nrt.Zones)
+	for idx := range zones {
+		rec.Zones = append(rec.Zones, ZoneChange{Name: zones[idx].Name, Zone: zones[idx].DeepCopy()})
+	}
+	return rec
+}
+
+// Apply returns the object resulting from applying the record to nrt, which is not modified.
+// nrt may be nil, which is the case before the first snapshot of the node. The result is nil
+// if the object does not exist after the record.
+func Apply(nrt *nrtv1alpha2.NodeResourceTopology, rec Record) *nrtv1alpha2.NodeResourceTopology {
+	switch rec.Event {
+	case EventDelete:
+		return nil
+	case EventSnapshot:
+		nrt = &nrtv1alpha2.NodeResourceTopology{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: rec.Node,
+			},
+		}
+	case EventUpdate:
+		if nrt == nil {
+			// the base snapshot was lost, e.g. rotated away: nothing sensible to reconstruct
+			return nil
+		}
+		nrt = nrt.DeepCopy()
+	default:
+		return nrt
+	}
+
+	if rec.Attributes != nil {
+		nrt.Attributes = append(nrtv1alpha2.AttributeList{}, (*rec.Attributes)...)
+	}
+
+	for _, change := range rec.Zones {
+		idx := findZone(nrt.Zones, change.Name)
+		switch {
+		case change.Removed:
+			if idx >= 0 {
+				nrt.Zones = append(nrt.Zones[:idx], nrt.Zones[idx+1:]...)
+			}
+		case change.Zone != nil:
+			if idx >= 0 {
+				nrt.Zones[idx] = *change.Zone.DeepCopy()
+			} else {
+				nrt.Zones = append(nrt.Zones, *change.Zone.DeepCopy())
+			}
+		case idx >= 0:
+			nrt.Zones[idx].Resources = applyResources(nrt.Zones[idx].Resources, change)
+		}
+	}
+	nrt.Zones = intnrt.SortedZoneList(nrt.Zones)
+	return nrt
+}
+
+// At reconstructs the object of the node as it was at the given time, replaying the records,
+// which must be in chronological order. Returns nil if the object did not exist at that time,
+// or if the records don't go back enough.
+func At(records []Record, node string, ts time.Time) *nrtv1alpha2.NodeResourceTopology {
+	var nrt *nrtv1alpha2.NodeResourceTopology
+	for _, rec := range records {
+		if rec.Timestamp.After(ts) {
+			break
+		}
+		if rec.Node != node {
+			continue
+		}
+		nrt = Apply(nrt, rec)
+	}
+	return nrt
+}
+
+// Nodes returns the sorted names of the nodes found in the records
+func Nodes(records []Record) []string {
+	seen := make(map[string]struct{})
+	var names []string
+	for _, rec := range records {
+		if _, ok := seen[rec.Node]; ok {
+			continue
+		}
+		seen[rec.Node] = struct{}{}
+		names = append(names, rec.Node)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func diffResources(prevZone, zone *nrtv1alpha2.Zone) (ZoneChange, bool) {
+	change := ZoneChange{Name: zone.Name}
+	prevRes := make(map[string]nrtv1alpha2.ResourceInfo, len(prevZone.Resources))
+	for _, resInfo := range prevZone.Resources {
+		prevRes[resInfo.Name] = resInfo
+	}
+	for _, resInfo := range intnrt.SortedResourceInfoList(zone.Resources) {
+		prevResInfo, ok := prevRes[resInfo.Name]
+		delete(prevRes, resInfo.Name)
+		if ok {
+			if equal, _ := intnrt.EqualResourceInfo(prevResInfo, resInfo, false); equal {
+				continue
+			}
+		}
+		change.Resources = append(change.Resources, resInfo)
+	}
+	for name := range prevRes {
+		change.RemovedResources = append(change.RemovedResources, name)
+	}
+	sort.Strings(change.RemovedResources)
+	return change, len(change.Resources) > 0 || len(change.RemovedResources) > 0
+}
+
+func applyResources(resInfos nrtv1alpha2.ResourceInfoList, change ZoneChange) nrtv1alpha2.ResourceInfoList {
+	removed := make(map[string]struct{}, len(change.RemovedResources))
+	for _, name := range change.RemovedResources {
+		removed[name] = struct{}{}
+	}
+	updated := make(map[string]nrtv1alpha2.ResourceInfo, len(change.Resources))
+	for _, resInfo := range change.Resources {
+		updated[resInfo.Name] = resInfo
+	}
+
+	ret := nrtv1alpha2.ResourceInfoList{}
+	for _, resInfo := range resInfos {
+		if _, ok := removed[resInfo.Name]; ok {
+			continue
+		}
+		if upd, ok := updated[resInfo.Name]; ok {
+			resInfo = upd
+			delete(updated, resInfo.Name)
+		}
+		ret = append(ret, *resInfo.DeepCopy())
+	}
+	for _, resInfo := range updated {
+		ret = append(ret, *resInfo.DeepCopy())
+	}
+	return intnrt.SortedResourceInfoList(ret)
+}
+
+// equalZoneMetadata tells if everything but the resources of the zones is equal
+func equalZoneMetadata(zoneA, zoneB *nrtv1alpha2.Zone) bool {
+	return zoneA.Type == zoneB.Type && zoneA.Parent == zoneB.Parent &&
+		reflect.DeepEqual(zoneA.Costs, zoneB.Costs) &&
+		reflect.DeepEqual(sortedAttributes(zoneA.Attributes), sortedAttributes(zoneB.Attributes))
+}
+
+func findZone(zones nrtv1alpha2.ZoneList, name string) int {
+	for idx := range zones {
+		if zones[idx].Name == name {
+			return idx
+		}
+	}
+	return -1
+}
+
+func sortedAttributes(attrs nrtv1alpha2.AttributeList) nrtv1alpha2.AttributeList {
+	ret := append(nrtv1alpha2.AttributeList{}, attrs...)
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nrthistory
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	intnrt "github.com/openshift-kni/numaresources-operator/internal/noderesourcetopology"
+)
+
+func TestDiff(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	base := makeNRT("node-0", intnrt.SingleNUMANode, makeZone("node-0", "cpu", "4"), makeZone("node-1", "cpu", "4"))
+
+	testCases := []struct {
+		name           string
+		prev           *nrtv1alpha2.NodeResourceTopology
+		curr           *nrtv1alpha2.NodeResourceTopology
+		expectedChange bool
+		expectedEvent  EventType
+		expectedAttrs  bool
+		expectedZones  []ZoneChange
+	}{
+		{
+			name:           "first observation",
+			curr:           base,
+			expectedChange: true,
+			expectedEvent:  EventSnapshot,
+			expectedAttrs:  true,
+			expectedZones: []ZoneChange{
+				{Name: "node-0", Zone: &base.Zones[0]},
+				{Name: "node-1", Zone: &base.Zones[1]},
+			},
+		},
+		{
+			name: "no changes",
+			prev: base,
+			curr: base.DeepCopy(),
+		},
+		{
+			name:           "available resources changed",
+			prev:           base,
+			curr:           makeNRT("node-0", intnrt.SingleNUMANode, makeZone("node-0", "cpu", "2"), makeZone("node-1", "cpu", "4")),
+			expectedChange: true,
+			expectedEvent:  EventUpdate,
+			expectedZones: []ZoneChange{
+				{Name: "node-0", Resources: makeZone("node-0", "cpu", "2").Resources},
+			},
+		},
+		{
+			name:           "attributes changed",
+			prev:           base,
+			curr:           makeNRT("node-0", intnrt.Restricted, makeZone("node-0", "cpu", "4"), makeZone("node-1", "cpu", "4")),
+			expectedChange: true,
+			expectedEvent:  EventUpdate,
+			expectedAttrs:  true,
+		},
+		{
+			name:           "zone removed",
+			prev:           base,
+			curr:           makeNRT("node-0", intnrt.SingleNUMANode, makeZone("node-0", "cpu", "4")),
+			expectedChange: true,
+			expectedEvent:  EventUpdate,
+			expectedZones: []ZoneChange{
+				{Name: "node-1", Removed: true},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec, changed := Diff(ts, tc.prev, tc.curr)
+			if changed != tc.expectedChange {
+				t.Fatalf("change mismatch: got=%v expected=%v", changed, tc.expectedChange)
+			}
+			if !changed {
+				return
+			}
+			if rec.Event != tc.expectedEvent {
+				t.Errorf("event mismatch: got=%v expected=%v", rec.Event, tc.expectedEvent)
+			}
+			if (rec.Attributes != nil) != tc.expectedAttrs {
+				t.Errorf("attributes mismatch: got=%v expected set=%v", rec.Attributes, tc.expectedAttrs)
+			}
+			if len(rec.Zones) != len(tc.expectedZones) {
+				t.Fatalf("zones mismatch: got=%+v expected=%+v", rec.Zones, tc.expectedZones)
+			}
+			for idx, change := range rec.Zones {
+				expected := tc.expectedZones[idx]
+				if change.Name != expected.Name || change.Removed != expected.Removed || (change.Zone == nil) != (expected.Zone == nil) {
+					t.Errorf("zone %d mismatch: got=%+v expected=%+v", idx, change, expected)
+				}
+				if ok, err := intnrt.EqualResourceInfos(change.Resources, expected.Resources, false); !ok {
+					t.Errorf("zone %d resources mismatch: %v", idx, err)
+				}
+			}
+
+			// applying the diff to the previous object must give back the current one
+			got := Apply(tc.prev, rec)
+			if ok, err := intnrt.EqualZones(got.Zones, tc.curr.Zones, false); !ok {
+				t.Errorf("reconstructed zones mismatch: %v\ngot=%s\nexpected=%s", err, intnrt.ToString(*got), intnrt.ToString(*tc.curr))
+			}
+			if intnrt.ToString(*got) != intnrt.ToString(*tc.curr) {
+				t.Errorf("reconstructed object mismatch:\ngot=%s\nexpected=%s", intnrt.ToString(*got), intnrt.ToString(*tc.curr))
+			}
+		})
+	}
+}
+
+func TestAt(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	states := []*nrtv1alpha2.NodeResourceTopology{
+		makeNRT("node-0", intnrt.SingleNUMANode, makeZone("node-0", "cpu", "4")),
+		makeNRT("node-0", intnrt.SingleNUMANode, makeZone("node-0", "cpu", "3")),
+		makeNRT("node-0", intnrt.SingleNUMANode, makeZone("node-0", "cpu", "1")),
+	}
+	var records []Record
+	var prev *nrtv1alpha2.NodeResourceTopology
+	for idx, nrt := range states {
+		rec, _ := Diff(t0.Add(time.Duration(idx)*time.Minute), prev, nrt)
+		records = append(records, rec)
+		prev = nrt
+	}
+	records = append(records, Record{Timestamp: t0.Add(3 * time.Minute), Node: "node-0", Event: EventDelete})
+
+	if got := At(records, "node-0", t0.Add(-time.Second)); got != nil {
+		t.Errorf("unexpected object before the first record: %s", intnrt.ToString(*got))
+	}
+	for idx, expected := range states {
+		got := At(records, "node-0", t0.Add(time.Duration(idx)*time.Minute+30*time.Second))
+		if got == nil {
+			t.Fatalf("missing object at step %d", idx)
+		}
+		if intnrt.ToString(*got) != intnrt.ToString(*expected) {
+			t.Errorf("object mismatch at step %d:\ngot=%s\nexpected=%s", idx, intnrt.ToString(*got), intnrt.ToString(*expected))
+		}
+	}
+	if got := At(records, "node-0", t0.Add(time.Hour)); got != nil {
+		t.Errorf("unexpected object after the deletion: %s", intnrt.ToString(*got))
+	}
+	if got := At(records, "node-1", t0.Add(time.Hour)); got != nil {
+		t.Errorf("unexpected object for unknown node: %s", intnrt.ToString(*got))
+	}
+}
+
+func makeNRT(name, policy string, zones ...nrtv1alpha2.Zone) *nrtv1alpha2.NodeResourceTopology {
+	return &nrtv1alpha2.NodeResourceTopology{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Attributes: nrtv1alpha2.AttributeList{
+			{Name: intnrt.TopologyManagerPolicyAttribute, Value: policy},
+			{Name: intnrt.TopologyManagerScopeAttribute, Value: intnrt.Container},
+		},
+		Zones: zones,
+	}
+}
+
+func makeZone(name, resName, available string) nrtv1alpha2.Zone {
+	return nrtv1alpha2.Zone{
+		Name: name,
+		Type: "Node",
+		Resources: nrtv1alpha2.ResourceInfoList{
+			{
+				Name:        resName,
+				Capacity:    resource.MustParse("4"),
+				Allocatable: resource.MustParse("4"),
+				Available:   resource.MustParse(available),
+			},
+		},
+	}
+}
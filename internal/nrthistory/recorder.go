@@ -0,0 +1,188 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nrthistory
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+)
+
+// Recorder appends the records, one JSON object per line, to a rolling file. Once the file grows
+// past the maximum size, it is rotated to RotatedPath, and the new file starts with the snapshots
+// of all the known objects, so each file can be replayed on its own.
+type Recorder struct {
+	path    string
+	maxSize int64
+	file    *os.File
+	size    int64
+	last    map[string]*nrtv1alpha2.NodeResourceTopology
+}
+
+// RotatedPath is where the file at path is rotated to
+func RotatedPath(path string) string {
+	return path + ".1"
+}
+
+// NewRecorder appends the records to the file at path, which is rotated once bigger than maxSize bytes.
+// Use zero maxSize to never rotate the file.
+func NewRecorder(path string, maxSize int64) (*Recorder, error) {
+	rec := &Recorder{
+		path:    path,
+		maxSize: maxSize,
+		last:    make(map[string]*nrtv1alpha2.NodeResourceTopology),
+	}
+	if err := rec.open(); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// Observe records the changes of the object since the previous observation, if any.
+// The first observation of an object records a snapshot.
+func (rec *Recorder) Observe(ts time.Time, nrt *nrtv1alpha2.NodeResourceTopology) error {
+	record, changed := Diff(ts, rec.last[nrt.Name], nrt)
+	if !changed {
+		return nil
+	}
+	rec.last[nrt.Name] = nrt.DeepCopy()
+	return rec.write(record)
+}
+
+// Delete records the deletion of the object of the node
+func (rec *Recorder) Delete(ts time.Time, node string) error {
+	delete(rec.last, node)
+	return rec.write(Record{
+		Timestamp: ts,
+		Node:      node,
+		Event:     EventDelete,
+	})
+}
+
+func (rec *Recorder) Close() error {
+	return rec.file.Close()
+}
+
+func (rec *Recorder) write(record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	n, err := rec.file.Write(data)
+	rec.size += int64(n)
+	if err != nil {
+		return err
+	}
+	if rec.maxSize > 0 && rec.size >= rec.maxSize {
+		return rec.rotate(record.Timestamp)
+	}
+	return nil
+}
+
+func (rec *Recorder) rotate(ts time.Time) error {
+	if err := rec.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(rec.path, RotatedPath(rec.path)); err != nil {
+		return err
+	}
+	if err := rec.open(); err != nil {
+		return err
+	}
+
+	nodes := make([]string, 0, len(rec.last))
+	for node := range rec.last {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	for _, node := range nodes {
+		data, err := json.Marshal(Snapshot(ts, rec.last[node]))
+		if err != nil {
+			return err
+		}
+		n, err := rec.file.Write(append(data, '\n'))
+		rec.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rec *Recorder) open() error {
+	file, err := os.OpenFile(rec.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	st, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	rec.file = file
+	rec.size = st.Size()
+	return nil
+}
+
+// Read decodes the records, one JSON object per line
+func Read(r io.Reader) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return records, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// ReadFile decodes the records stored by a Recorder in the file at path, including the rotated ones, in chronological order
+func ReadFile(path string) ([]Record, error) {
+	var records []Record
+	for _, p := range []string{RotatedPath(path), path} {
+		file, err := os.Open(p)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		recs, err := Read(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading %q: %w", p, err)
+		}
+		records = append(records, recs...)
+	}
+	return records, nil
+}
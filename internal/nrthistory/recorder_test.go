@@ -0,0 +1,74 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nrthistory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	intnrt "github.com/openshift-kni/numaresources-operator/internal/noderesourcetopology"
+)
+
+func TestRecorderRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nrt-history.jsonl")
+	// small enough to rotate on each update
+	rec, err := NewRecorder(path, 256)
+	if err != nil {
+		t.Fatalf("cannot create the recorder: %v", err)
+	}
+
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	availables := []string{"4", "3", "2", "1"}
+	for idx, avail := range availables {
+		nrt := makeNRT("node-0", intnrt.SingleNUMANode, makeZone("node-0", "cpu", avail))
+		if err := rec.Observe(t0.Add(time.Duration(idx)*time.Minute), nrt); err != nil {
+			t.Fatalf("cannot observe step %d: %v", idx, err)
+		}
+		// unchanged objects are not recorded
+		if err := rec.Observe(t0.Add(time.Duration(idx)*time.Minute+time.Second), nrt); err != nil {
+			t.Fatalf("cannot observe step %d again: %v", idx, err)
+		}
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("cannot close the recorder: %v", err)
+	}
+
+	if _, err := os.Stat(RotatedPath(path)); err != nil {
+		t.Fatalf("the file was not rotated: %v", err)
+	}
+	records, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("cannot read the records: %v", err)
+	}
+
+	// the oldest states are rotated away, the latest ones are still there
+	latest := At(records, "node-0", t0.Add(time.Hour))
+	expected := makeNRT("node-0", intnrt.SingleNUMANode, makeZone("node-0", "cpu", "1"))
+	if latest == nil || intnrt.ToString(*latest) != intnrt.ToString(*expected) {
+		t.Errorf("latest object mismatch: got=%v expected=%s", latest, intnrt.ToString(*expected))
+	}
+	previous := At(records, "node-0", t0.Add(2*time.Minute+30*time.Second))
+	expected = makeNRT("node-0", intnrt.SingleNUMANode, makeZone("node-0", "cpu", "2"))
+	if previous == nil || intnrt.ToString(*previous) != intnrt.ToString(*expected) {
+		t.Errorf("previous object mismatch: got=%v expected=%s", previous, intnrt.ToString(*expected))
+	}
+	if nodes := Nodes(records); len(nodes) != 1 || nodes[0] != "node-0" {
+		t.Errorf("unexpected nodes: %v", nodes)
+	}
+}
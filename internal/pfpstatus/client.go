@@ -0,0 +1,197 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pfpstatus
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	"github.com/k8stopologyawareschedwg/podfingerprint"
+)
+
+const (
+	// serviceCAConfigMapName is the configmap OpenShift creates in every namespace with the service CA bundle
+	serviceCAConfigMapName = "openshift-service-ca.crt"
+	serviceCABundleKey     = "service-ca.crt"
+
+	tokenExpirationSeconds = 3600
+	// tokenRenewMargin is how long before the expiration the token is renewed
+	tokenRenewMargin = 5 * time.Minute
+)
+
+// Client fetches the dumps from the pods serving them. The pods must be reachable from the client,
+// so this is meant to run within the cluster. For each namespace of the pods, the client reads the
+// service CA bundle and requests a token for the reader service account, so the user running the client
+// needs to be allowed to get the configmaps and to create the serviceaccounts/token in that namespace.
+type Client struct {
+	cli     kubernetes.Interface
+	timeout time.Duration
+
+	lock  sync.Mutex
+	conns map[string]*conn
+}
+
+// conn holds the means to connect to the pods of a namespace
+type conn struct {
+	httpClient *http.Client
+	token      string
+	expiration time.Time
+}
+
+func (cn *conn) valid(now time.Time) bool {
+	return cn.expiration.IsZero() || now.Add(tokenRenewMargin).Before(cn.expiration)
+}
+
+// NewClient returns a Client using the default client configuration to setup the connections
+func NewClient() (*Client, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return NewClientFromConfig(cfg)
+}
+
+// NewClientFromConfig returns a Client using the REST config to setup the connections
+func NewClientFromConfig(cfg *rest.Config) (*Client, error) {
+	cli, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewClientFromClientset(cli), nil
+}
+
+// NewClientFromClientset returns a Client using the clientset to setup the connections
+func NewClientFromClientset(cli kubernetes.Interface) *Client {
+	return &Client{
+		cli:     cli,
+		timeout: 10 * time.Second,
+		conns:   make(map[string]*conn),
+	}
+}
+
+func (cl *Client) connFor(ctx context.Context, namespace string) (*conn, error) {
+	cl.lock.Lock()
+	defer cl.lock.Unlock()
+	if cn, ok := cl.conns[namespace]; ok && cn.valid(time.Now()) {
+		return cn, nil
+	}
+	cn, err := cl.newConn(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	cl.conns[namespace] = cn
+	return cn, nil
+}
+
+func (cl *Client) newConn(ctx context.Context, namespace string) (*conn, error) {
+	cm, err := cl.cli.CoreV1().ConfigMaps(namespace).Get(ctx, serviceCAConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the service CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(cm.Data[serviceCABundleKey])) {
+		return nil, fmt.Errorf("no certificates found in the service CA bundle %s/%s", namespace, serviceCAConfigMapName)
+	}
+
+	tr, err := cl.cli.CoreV1().ServiceAccounts(namespace).CreateToken(ctx, ReaderServiceAccountName, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			ExpirationSeconds: ptr.To[int64](tokenExpirationSeconds),
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get a token for %s/%s: %w", namespace, ReaderServiceAccountName, err)
+	}
+
+	return &conn{
+		httpClient: &http.Client{
+			Timeout: cl.timeout,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					MinVersion: tls.VersionTLS12,
+					RootCAs:    pool,
+					// the pods are reached by IP, but the certificate is issued for the service
+					ServerName: ServerName(namespace),
+				},
+			},
+		},
+		token:      tr.Status.Token,
+		expiration: tr.Status.ExpirationTimestamp.Time,
+	}, nil
+}
+
+// Get fetches the dump named fileName from the pod
+func (cl *Client) Get(ctx context.Context, pod *corev1.Pod, fileName string) (podfingerprint.Status, error) {
+	var st podfingerprint.Status
+	if pod.Status.PodIP == "" {
+		return st, fmt.Errorf("pod %s/%s has no IP", pod.Namespace, pod.Name)
+	}
+	cn, err := cl.connFor(ctx, pod.Namespace)
+	if err != nil {
+		return st, err
+	}
+	url := "https://" + net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(PodPort(pod)))) + PathPrefix + fileName
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return st, err
+	}
+	req.Header.Set("Authorization", "Bearer "+cn.token)
+
+	resp, err := cn.httpClient.Do(req)
+	if err != nil {
+		return st, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return st, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return st, fmt.Errorf("cannot get %q from pod %s/%s: %s: %s", fileName, pod.Namespace, pod.Name, resp.Status, strings.TrimSpace(string(data)))
+	}
+	err = json.Unmarshal(data, &st)
+	return st, err
+}
+
+// PodPort returns the port on which the pod serves the dumps, looking for the named container port
+func PodPort(pod *corev1.Pod) int32 {
+	for _, cnt := range pod.Spec.Containers {
+		for _, port := range cnt.Ports {
+			if port.Name == PortName {
+				return port.ContainerPort
+			}
+		}
+	}
+	return DefaultPort
+}
@@ -0,0 +1,249 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package pfpstatus serves the pod fingerprint status dumps written by the RTE
+// and by the scheduler over HTTPS, and fetches them. Clients must present a bearer
+// token which is allowed to get the served path, like the kube-apiserver does
+// for the non-resource URLs.
+//
+// The servers use the serving certificate the service CA issues for ServiceName,
+// and the clients verify the pods against that name. The clients authenticate
+// with a token of the ReaderServiceAccountName service account, never with the
+// credentials of the user running them.
+package pfpstatus
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// PathPrefix is the path under which the dumps are served. Clients need to be allowed to get it.
+	PathPrefix = "/pfpstatus/"
+
+	// DumpFileName is the name of the dump written by the RTE
+	DumpFileName = "dump.json"
+
+	DefaultPort = 8082
+	// PortEnvVar overrides the port on which the dumps are served.
+	PortEnvVar = "PFPSTATUS_PORT"
+	// PortName is the name of the container port on which the dumps are served.
+	PortName = "pfpstatus"
+
+	// ServiceName is the name of the service whose serving certificate the servers use.
+	// The service only exists to get the certificate from the service CA.
+	ServiceName = "numaresources-pfpstatus"
+	// TLSSecretName is the name of the secret the service CA stores the serving certificate in
+	TLSSecretName = "numaresources-pfpstatus-tls"
+	// CertsDir is the directory the servers read the serving certificate from
+	CertsDir    = "/etc/secrets/pfpstatus"
+	TLSCertFile = "tls.crt"
+	TLSKeyFile  = "tls.key"
+
+	// ReaderServiceAccountName is the service account whose tokens the clients use, which is allowed to get PathPrefix
+	ReaderServiceAccountName = "numaresources-pfpstatus-reader"
+)
+
+// FileNameForNode returns the name of the dump written by the scheduler for the given node
+func FileNameForNode(nodeName string) string {
+	return strings.ReplaceAll(nodeName, ".", "_") + ".json"
+}
+
+// Address returns the address to serve the dumps on, honoring PortEnvVar
+func Address() string {
+	port := strconv.Itoa(DefaultPort)
+	if val, ok := os.LookupEnv(PortEnvVar); ok && val != "" {
+		port = val
+	}
+	return ":" + port
+}
+
+// Authorizer tells if the bearer token is allowed to get the given path
+type Authorizer interface {
+	Authorize(ctx context.Context, token, path string) (bool, error)
+}
+
+type kubeAuthorizer struct {
+	cli kubernetes.Interface
+}
+
+// NewKubeAuthorizer returns an Authorizer delegating to the kube-apiserver using TokenReviews and SubjectAccessReviews
+func NewKubeAuthorizer(cli kubernetes.Interface) Authorizer {
+	return kubeAuthorizer{cli: cli}
+}
+
+func (ka kubeAuthorizer) Authorize(ctx context.Context, token, path string) (bool, error) {
+	tr, err := ka.cli.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token: token,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	if !tr.Status.Authenticated {
+		return false, nil
+	}
+
+	extra := make(map[string]authorizationv1.ExtraValue)
+	for key, val := range tr.Status.User.Extra {
+		extra[key] = authorizationv1.ExtraValue(val)
+	}
+	sar, err := ka.cli.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   tr.Status.User.Username,
+			UID:    tr.Status.User.UID,
+			Groups: tr.Status.User.Groups,
+			Extra:  extra,
+			NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+				Path: path,
+				Verb: "get",
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	return sar.Status.Allowed, nil
+}
+
+// AuthorizerRules returns the RBAC rules the servers need to use the kube Authorizer
+func AuthorizerRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{authenticationv1.GroupName},
+			Resources: []string{"tokenreviews"},
+			Verbs:     []string{"create"},
+		},
+		{
+			APIGroups: []string{authorizationv1.GroupName},
+			Resources: []string{"subjectaccessreviews"},
+			Verbs:     []string{"create"},
+		},
+	}
+}
+
+// NewHandler returns a handler serving the dumps found in dir
+func NewHandler(dir string, auth Authorizer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		fileName, ok := strings.CutPrefix(req.URL.Path, PathPrefix)
+		// the dumps are all in the top level directory, so we can easily reject path traversals
+		if !ok || fileName == "" || strings.Contains(fileName, "/") || filepath.Ext(fileName) != ".json" {
+			http.NotFound(w, req)
+			return
+		}
+
+		token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		allowed, err := auth.Authorize(req.Context(), token, req.URL.Path)
+		if err != nil {
+			klog.ErrorS(err, "cannot authorize the request", "path", req.URL.Path)
+			http.Error(w, "cannot authorize the request", http.StatusInternalServerError)
+			return
+		}
+		if !allowed {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, fileName))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				http.NotFound(w, req)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	})
+}
+
+// CertsVolume returns the volume with the serving certificate, which must be mounted on CertsDir.
+// The secret is optional, so the pods still start where the service CA is not available.
+func CertsVolume(name string) corev1.Volume {
+	return corev1.Volume{
+		Name: name,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: TLSSecretName,
+				Optional:   ptr.To(true),
+			},
+		},
+	}
+}
+
+// ServerName returns the name the serving certificate is issued for in the given namespace
+func ServerName(namespace string) string {
+	return ServiceName + "." + namespace + ".svc"
+}
+
+// Serve serves over HTTPS the dumps found in dir on addr, using the serving certificate found in certsDir.
+// It returns only on failure.
+func Serve(addr, dir, certsDir string, auth Authorizer) error {
+	certFile, keyFile := filepath.Join(certsDir, TLSCertFile), filepath.Join(certsDir, TLSKeyFile)
+	// fail early and clearly if the certificate is missing, like on clusters without the service CA
+	if _, err := tls.LoadX509KeyPair(certFile, keyFile); err != nil {
+		return fmt.Errorf("cannot load the serving certificate: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(PathPrefix, NewHandler(dir, auth))
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			// the service CA rotates the certificate, and the endpoint serves few requests, so just reload it every time
+			GetCertificate: func(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+				cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+				if err != nil {
+					return nil, err
+				}
+				return &cert, nil
+			},
+		},
+	}
+	klog.InfoS("serving the pod fingerprint status", "address", addr, "directory", dir, "certsDir", certsDir)
+	return srv.ListenAndServeTLS("", "")
+}
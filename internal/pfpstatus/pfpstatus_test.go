@@ -0,0 +1,276 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pfpstatus
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+type fakeAuthorizer struct {
+	allowed map[string]bool
+	err     error
+}
+
+func (fa fakeAuthorizer) Authorize(_ context.Context, token, _ string) (bool, error) {
+	return fa.allowed[token], fa.err
+}
+
+const testDump = `{"fingerprintExpected":"pfp0v001abc","fingerprintComputed":"pfp0v001abc","pods":[{"namespace":"ns","name":"pod-0"}],"nodeName":"worker-0.example.com"}`
+
+func TestHandler(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, FileNameForNode("worker-0.example.com")), []byte(testDump), 0644); err != nil {
+		t.Fatalf("cannot write the dump: %v", err)
+	}
+
+	hnd := NewHandler(dir, fakeAuthorizer{allowed: map[string]bool{"good": true}})
+
+	testCases := []struct {
+		name         string
+		method       string
+		path         string
+		token        string
+		expectedCode int
+	}{
+		{
+			name:         "allowed",
+			path:         PathPrefix + "worker-0_example_com.json",
+			token:        "good",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "missing token",
+			path:         PathPrefix + "worker-0_example_com.json",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "forbidden",
+			path:         PathPrefix + "worker-0_example_com.json",
+			token:        "bad",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "missing dump",
+			path:         PathPrefix + "worker-1.json",
+			token:        "good",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "not a dump",
+			path:         PathPrefix + "worker-0_example_com.txt",
+			token:        "good",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "path traversal",
+			path:         PathPrefix + "..%2Fetc%2Fpasswd.json",
+			token:        "good",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "method",
+			method:       http.MethodPost,
+			path:         PathPrefix + "worker-0_example_com.json",
+			token:        "good",
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, tc.path, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			rec := httptest.NewRecorder()
+			hnd.ServeHTTP(rec, req)
+			if rec.Code != tc.expectedCode {
+				t.Errorf("got=%d expected=%d body=%q", rec.Code, tc.expectedCode, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlerAuthorizerError(t *testing.T) {
+	hnd := NewHandler(t.TempDir(), fakeAuthorizer{err: errors.New("fake error")})
+	req := httptest.NewRequest(http.MethodGet, PathPrefix+DumpFileName, nil)
+	req.Header.Set("Authorization", "Bearer good")
+	rec := httptest.NewRecorder()
+	hnd.ServeHTTP(rec, req)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("unexpected code: %d", rec.Code)
+	}
+}
+
+func TestClientGet(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, DumpFileName), []byte(testDump), 0644); err != nil {
+		t.Fatalf("cannot write the dump: %v", err)
+	}
+	srv := httptest.NewTLSServer(NewHandler(dir, fakeAuthorizer{allowed: map[string]bool{"good": true}}))
+	defer srv.Close()
+
+	host, portStr, err := net.SplitHostPort(srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("cannot parse the server address: %v", err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		t.Fatalf("cannot parse the server port: %v", err)
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "pod-0",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "main",
+				},
+				{
+					Name: "sidecar",
+					Ports: []corev1.ContainerPort{
+						{
+							Name:          PortName,
+							ContainerPort: int32(port),
+						},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{
+			PodIP: host,
+		},
+	}
+
+	cli, err := NewClientFromConfig(&rest.Config{Host: "https://127.0.0.1:1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cli.conns["ns"] = &conn{httpClient: srv.Client(), token: "good"}
+	st, err := cli.Get(context.Background(), pod, DumpFileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if st.NodeName != "worker-0.example.com" || len(st.Pods) != 1 || st.FingerprintComputed != st.FingerprintExpected {
+		t.Errorf("unexpected status: %+v", st)
+	}
+
+	cli.conns["ns"].token = "bad"
+	if _, err := cli.Get(context.Background(), pod, DumpFileName); err == nil {
+		t.Errorf("unexpected success with a forbidden token")
+	}
+}
+
+func TestClientConn(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	var tokenRequests int
+	expiration := time.Now().Add(time.Hour)
+	// fakes the API server, serving just the service CA bundle and the tokens
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var obj interface{}
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/v1/namespaces/ns/configmaps/"+serviceCAConfigMapName:
+			obj = corev1.ConfigMap{
+				Data: map[string]string{
+					serviceCABundleKey: string(caBundle),
+				},
+			}
+		case req.Method == http.MethodPost && req.URL.Path == "/api/v1/namespaces/ns/serviceaccounts/"+ReaderServiceAccountName+"/token":
+			tokenRequests++
+			obj = authenticationv1.TokenRequest{
+				Status: authenticationv1.TokenRequestStatus{
+					Token:               "token-" + strconv.Itoa(tokenRequests),
+					ExpirationTimestamp: metav1.NewTime(expiration),
+				},
+			}
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(metav1.Status{Status: metav1.StatusFailure, Reason: metav1.StatusReasonNotFound, Code: http.StatusNotFound})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(obj)
+	}))
+	defer apiSrv.Close()
+
+	cli, err := NewClientFromConfig(&rest.Config{Host: apiSrv.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cn, err := cli.connFor(context.Background(), "ns")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cn.token != "token-1" {
+		t.Errorf("unexpected token: %q", cn.token)
+	}
+	tlsCfg := cn.httpClient.Transport.(*http.Transport).TLSClientConfig
+	if tlsCfg.ServerName != "numaresources-pfpstatus.ns.svc" || tlsCfg.RootCAs == nil {
+		t.Errorf("unexpected TLS configuration: %+v", tlsCfg)
+	}
+
+	if _, err := cli.connFor(context.Background(), "ns"); err != nil || tokenRequests != 1 {
+		t.Errorf("connection not reused: err=%v requests=%d", err, tokenRequests)
+	}
+	cli.conns["ns"].expiration = time.Now().Add(time.Minute)
+	if cn, err := cli.connFor(context.Background(), "ns"); err != nil || cn.token != "token-2" {
+		t.Errorf("token not renewed before the expiration: err=%v", err)
+	}
+
+	if _, err := cli.connFor(context.Background(), "other"); err == nil {
+		t.Errorf("unexpected success without the service CA bundle")
+	}
+}
+
+func TestServeMissingCertificate(t *testing.T) {
+	err := Serve("127.0.0.1:0", t.TempDir(), t.TempDir(), fakeAuthorizer{})
+	if err == nil {
+		t.Errorf("unexpected success without the serving certificate")
+	}
+}
+
+func TestFileNameForNode(t *testing.T) {
+	if got := FileNameForNode("worker-0.example.com"); got != "worker-0_example_com.json" {
+		t.Errorf("unexpected file name: %q", got)
+	}
+}
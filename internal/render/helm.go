@@ -0,0 +1,251 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package render
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	ChartFileName  = "Chart.yaml"
+	ValuesFileName = "values.yaml"
+	TemplatesDir   = "templates"
+)
+
+type HelmParams struct {
+	Name        string
+	Description string
+	// Version is the chart version, see ChartVersion
+	Version    string
+	AppVersion string
+	Namespace  string
+}
+
+type chart struct {
+	APIVersion  string `json:"apiVersion"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type"`
+	Version     string `json:"version"`
+	AppVersion  string `json:"appVersion,omitempty"`
+}
+
+// WorkloadValues are the scheduling constraints of a DaemonSet or a Deployment
+type WorkloadValues struct {
+	NodeSelector map[string]string   `json:"nodeSelector"`
+	Tolerations  []corev1.Toleration `json:"tolerations"`
+}
+
+type ImagesValues struct {
+	Exporter  string `json:"exporter,omitempty"`
+	Scheduler string `json:"scheduler,omitempty"`
+}
+
+// Values are the chart values. The workloads are keyed by name, because the DaemonSets
+// rendered for different pools have different node selectors.
+type Values struct {
+	Namespace string                    `json:"namespace"`
+	Images    ImagesValues              `json:"images"`
+	Workloads map[string]WorkloadValues `json:"workloads,omitempty"`
+}
+
+var (
+	semverRE = regexp.MustCompile(`^[0-9]+\.[0-9]+\.[0-9]+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+	// the templated fields are first replaced by markers, which can be safely serialized
+	valueMarkerRE = regexp.MustCompile(`'?@@helm-value:([A-Za-z.]+)@@'?`)
+	blockMarkerRE = regexp.MustCompile(`^(\s*)([A-Za-z]+): '?@@helm-block:([^:@]+):([A-Za-z]+)@@'?$`)
+)
+
+// ChartVersion returns the chart version matching the given operator version, which must be SemVer.
+func ChartVersion(version string) string {
+	ver := strings.TrimPrefix(version, "v")
+	if !semverRE.MatchString(ver) {
+		return "0.0.0"
+	}
+	return ver
+}
+
+// Helm writes the objects as a Helm chart in the given directory, one template per object.
+// The namespace, the images and the scheduling constraints of the workloads are taken from the values,
+// whose defaults reproduce the given objects.
+func Helm(dir string, objs []client.Object, params HelmParams) error {
+	values := Values{
+		Namespace: params.Namespace,
+		Workloads: make(map[string]WorkloadValues),
+	}
+	for _, obj := range objs {
+		switch wl := obj.(type) {
+		case *appsv1.DaemonSet:
+			values.Images.Exporter = firstImage(values.Images.Exporter, &wl.Spec.Template.Spec)
+			values.Workloads[wl.Name] = workloadValues(&wl.Spec.Template.Spec)
+		case *appsv1.Deployment:
+			values.Images.Scheduler = firstImage(values.Images.Scheduler, &wl.Spec.Template.Spec)
+			values.Workloads[wl.Name] = workloadValues(&wl.Spec.Template.Spec)
+		}
+	}
+
+	for _, obj := range objs {
+		name, err := fileName(obj)
+		if err != nil {
+			return err
+		}
+		data, err := helmTemplate(obj, values)
+		if err != nil {
+			return fmt.Errorf("cannot template %s: %w", name, err)
+		}
+		if err := writeFile(filepath.Join(dir, TemplatesDir, name), data); err != nil {
+			return err
+		}
+	}
+
+	valuesData, err := yaml.Marshal(values)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(filepath.Join(dir, ValuesFileName), valuesData); err != nil {
+		return err
+	}
+
+	chartData, err := yaml.Marshal(chart{
+		APIVersion:  "v2",
+		Name:        params.Name,
+		Description: params.Description,
+		Type:        "application",
+		Version:     params.Version,
+		AppVersion:  params.AppVersion,
+	})
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(dir, ChartFileName), chartData)
+}
+
+func firstImage(cur string, podSpec *corev1.PodSpec) string {
+	if cur != "" || len(podSpec.Containers) == 0 {
+		return cur
+	}
+	return podSpec.Containers[0].Image
+}
+
+func workloadValues(podSpec *corev1.PodSpec) WorkloadValues {
+	wv := WorkloadValues{
+		NodeSelector: podSpec.NodeSelector,
+		Tolerations:  podSpec.Tolerations,
+	}
+	if wv.NodeSelector == nil {
+		wv.NodeSelector = map[string]string{}
+	}
+	if wv.Tolerations == nil {
+		wv.Tolerations = []corev1.Toleration{}
+	}
+	return wv
+}
+
+func helmTemplate(obj client.Object, values Values) ([]byte, error) {
+	data, err := toUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	images := map[string]string{}
+	if values.Images.Exporter != "" {
+		images[values.Images.Exporter] = "images.exporter"
+	}
+	if values.Images.Scheduler != "" {
+		images[values.Images.Scheduler] = "images.scheduler"
+	}
+	markValues(data, values.Namespace, images)
+
+	if _, ok := values.Workloads[obj.GetName()]; ok {
+		if podSpec, ok := nestedMap(data, "spec", "template", "spec"); ok {
+			podSpec["nodeSelector"] = "@@helm-block:" + obj.GetName() + ":nodeSelector@@"
+			podSpec["tolerations"] = "@@helm-block:" + obj.GetName() + ":tolerations@@"
+		}
+	}
+
+	out, err := yaml.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return expandMarkers(out), nil
+}
+
+// markValues replaces the namespace and the images found in the object with the markers of their values
+func markValues(data interface{}, namespace string, images map[string]string) {
+	switch val := data.(type) {
+	case map[string]interface{}:
+		for key, item := range val {
+			str, ok := item.(string)
+			if !ok {
+				markValues(item, namespace, images)
+				continue
+			}
+			if key == "namespace" && str == namespace {
+				val[key] = "@@helm-value:namespace@@"
+			}
+			if key == "image" {
+				if path, ok := images[str]; ok {
+					val[key] = "@@helm-value:" + path + "@@"
+				}
+			}
+		}
+	case []interface{}:
+		for _, item := range val {
+			markValues(item, namespace, images)
+		}
+	}
+}
+
+func nestedMap(data map[string]interface{}, fields ...string) (map[string]interface{}, bool) {
+	cur := data
+	for _, field := range fields {
+		next, ok := cur[field].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		cur = next
+	}
+	return cur, true
+}
+
+func expandMarkers(data []byte) []byte {
+	lines := strings.Split(string(data), "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		match := blockMarkerRE.FindStringSubmatch(line)
+		if match == nil {
+			out = append(out, valueMarkerRE.ReplaceAllString(line, "{{ .Values.$1 | quote }}"))
+			continue
+		}
+		indent, key, workload, field := match[1], match[2], match[3], match[4]
+		out = append(out,
+			fmt.Sprintf("%s{{- with (index .Values.workloads %q).%s }}", indent, workload, field),
+			fmt.Sprintf("%s%s:", indent, key),
+			fmt.Sprintf("%s  {{- toYaml . | nindent %d }}", indent, len(indent)+2),
+			fmt.Sprintf("%s{{- end }}", indent),
+		)
+	}
+	return []byte(strings.Join(out, "\n"))
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package render
+
+import (
+	"bytes"
+	"path/filepath"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+)
+
+const KustomizationFileName = "kustomization.yaml"
+
+type KustomizeParams struct {
+	// Namespace is set in the kustomization, so the overlays can change it with a single edit
+	Namespace string
+}
+
+type kustomization struct {
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Namespace  string   `json:"namespace,omitempty"`
+	Resources  []string `json:"resources"`
+}
+
+// Kustomize writes the objects as a Kustomize base in the given directory, one object per file.
+func Kustomize(dir string, objs []client.Object, params KustomizeParams) error {
+	kust := kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Namespace:  params.Namespace,
+	}
+	for _, obj := range objs {
+		name, err := fileName(obj)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := manifests.SerializeObject(obj, &buf); err != nil {
+			return err
+		}
+		if err := writeFile(filepath.Join(dir, name), buf.Bytes()); err != nil {
+			return err
+		}
+		kust.Resources = append(kust.Resources, name)
+	}
+	data, err := yaml.Marshal(kust)
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(dir, KustomizationFileName), data)
+}
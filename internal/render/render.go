@@ -0,0 +1,98 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package render writes the rendered operator manifests in the formats the add-ons are distributed with:
+// a multi-document YAML stream, a Kustomize base or a Helm chart.
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+)
+
+type Format string
+
+const (
+	FormatYAML      Format = "yaml"
+	FormatHelm      Format = "helm"
+	FormatKustomize Format = "kustomize"
+)
+
+// Formats returns the supported output formats
+func Formats() []Format {
+	return []Format{FormatYAML, FormatHelm, FormatKustomize}
+}
+
+func ParseFormat(s string) (Format, error) {
+	for _, format := range Formats() {
+		if strings.EqualFold(s, string(format)) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported render format %q", s)
+}
+
+// YAML writes the objects as a multi-document YAML stream
+func YAML(w io.Writer, objs []client.Object) error {
+	for _, obj := range objs {
+		fmt.Fprintf(w, "---\n")
+		if err := manifests.SerializeObject(obj, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileName returns the name of the file holding the object in a chart or a base
+func fileName(obj client.Object) (string, error) {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if kind == "" {
+		return "", fmt.Errorf("missing type information for object %s/%s", obj.GetNamespace(), obj.GetName())
+	}
+	return strings.ToLower(kind) + "_" + obj.GetName() + ".yaml", nil
+}
+
+// toUnstructured converts the object the same way manifests.SerializeObject does
+func toUnstructured(obj client.Object) (map[string]interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var r unstructured.Unstructured
+	if err := json.Unmarshal(data, &r.Object); err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(r.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(r.Object, "spec", "template", "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(r.Object, "status")
+	return r.Object, nil
+}
+
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,313 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/google/go-cmp/cmp"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const testNamespace = "test-namespace"
+
+func testObjects() []client.Object {
+	return []client.Object{
+		&appsv1.DaemonSet{
+			TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: testNamespace,
+				Name:      "test-rte",
+			},
+			Spec: appsv1.DaemonSetSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{Name: "rte", Image: "quay.io/test/rte:1"},
+							{Name: "helper", Image: "quay.io/test/rte:1"},
+						},
+					},
+				},
+			},
+		},
+		&appsv1.Deployment{
+			TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: testNamespace,
+				Name:      "test-scheduler",
+			},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{Name: "scheduler", Image: "quay.io/test/sched:1"},
+						},
+						NodeSelector: map[string]string{
+							"node-role.kubernetes.io/control-plane": "",
+						},
+						Tolerations: []corev1.Toleration{
+							{Key: "node-role.kubernetes.io/control-plane", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+						},
+					},
+				},
+			},
+		},
+		&rbacv1.ClusterRoleBinding{
+			TypeMeta: metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-binding",
+			},
+			RoleRef: rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "test-role"},
+			Subjects: []rbacv1.Subject{
+				{Kind: "ServiceAccount", Namespace: testNamespace, Name: "test-sa"},
+			},
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		expected Format
+		wantErr  bool
+	}{
+		{value: "yaml", expected: FormatYAML},
+		{value: "helm", expected: FormatHelm},
+		{value: "Kustomize", expected: FormatKustomize},
+		{value: "json", wantErr: true},
+		{value: "", wantErr: true},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := ParseFormat(tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("got %q expected %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestChartVersion(t *testing.T) {
+	for _, tc := range []struct {
+		version  string
+		expected string
+	}{
+		{version: "v4.18.0", expected: "4.18.0"},
+		{version: "4.18.1-rc.1", expected: "4.18.1-rc.1"},
+		{version: "4.18", expected: "0.0.0"},
+		{version: "", expected: "0.0.0"},
+	} {
+		t.Run(tc.version, func(t *testing.T) {
+			if got := ChartVersion(tc.version); got != tc.expected {
+				t.Errorf("got %q expected %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestKustomize(t *testing.T) {
+	dir := t.TempDir()
+	objs := testObjects()
+	if err := Kustomize(dir, objs, KustomizeParams{Namespace: testNamespace}); err != nil {
+		t.Fatalf("Kustomize failed: %v", err)
+	}
+
+	var kust kustomization
+	readYAML(t, filepath.Join(dir, KustomizationFileName), &kust)
+	expected := kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Namespace:  testNamespace,
+		Resources: []string{
+			"daemonset_test-rte.yaml",
+			"deployment_test-scheduler.yaml",
+			"clusterrolebinding_test-binding.yaml",
+		},
+	}
+	if diff := cmp.Diff(expected, kust); diff != "" {
+		t.Errorf("unexpected kustomization: %s", diff)
+	}
+
+	for idx, res := range kust.Resources {
+		var got map[string]interface{}
+		readYAML(t, filepath.Join(dir, res), &got)
+		want, err := toUnstructured(objs[idx])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("unexpected resource %s: %s", res, diff)
+		}
+	}
+}
+
+func TestHelm(t *testing.T) {
+	dir := t.TempDir()
+	objs := testObjects()
+	err := Helm(dir, objs, HelmParams{
+		Name:       "test-chart",
+		Version:    "4.18.0",
+		AppVersion: "v4.18.0",
+		Namespace:  testNamespace,
+	})
+	if err != nil {
+		t.Fatalf("Helm failed: %v", err)
+	}
+
+	var ch chart
+	readYAML(t, filepath.Join(dir, ChartFileName), &ch)
+	if ch.APIVersion != "v2" || ch.Name != "test-chart" || ch.Version != "4.18.0" {
+		t.Errorf("unexpected chart: %+v", ch)
+	}
+
+	var values map[string]interface{}
+	readYAML(t, filepath.Join(dir, ValuesFileName), &values)
+
+	t.Run("default values", func(t *testing.T) {
+		for _, obj := range objs {
+			want, err := toUnstructured(obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := executeTemplate(t, dir, obj, values)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("unexpected %s: %s", obj.GetName(), diff)
+			}
+		}
+	})
+
+	t.Run("custom values", func(t *testing.T) {
+		var custom map[string]interface{}
+		readYAML(t, filepath.Join(dir, ValuesFileName), &custom)
+		custom["namespace"] = "custom-namespace"
+		custom["images"] = map[string]interface{}{
+			"exporter":  "quay.io/custom/rte:2",
+			"scheduler": "quay.io/custom/sched:2",
+		}
+		custom["workloads"].(map[string]interface{})["test-rte"] = map[string]interface{}{
+			"nodeSelector": map[string]interface{}{"custom": "true"},
+		}
+
+		ds := executeTemplate(t, dir, objs[0], custom)
+		expectField(t, ds, "custom-namespace", "metadata", "namespace")
+		expectField(t, ds, map[string]interface{}{"custom": "true"}, "spec", "template", "spec", "nodeSelector")
+		for _, cnt := range nested(t, ds, "spec", "template", "spec", "containers").([]interface{}) {
+			if img := cnt.(map[string]interface{})["image"]; img != "quay.io/custom/rte:2" {
+				t.Errorf("unexpected exporter image %v", img)
+			}
+		}
+
+		dp := executeTemplate(t, dir, objs[1], custom)
+		cnt := nested(t, dp, "spec", "template", "spec", "containers").([]interface{})[0]
+		if img := cnt.(map[string]interface{})["image"]; img != "quay.io/custom/sched:2" {
+			t.Errorf("unexpected scheduler image %v", img)
+		}
+
+		crb := executeTemplate(t, dir, objs[2], custom)
+		sub := nested(t, crb, "subjects").([]interface{})[0]
+		if ns := sub.(map[string]interface{})["namespace"]; ns != "custom-namespace" {
+			t.Errorf("unexpected subject namespace %v", ns)
+		}
+	})
+}
+
+func readYAML(t *testing.T, path string, out interface{}) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal(data, out); err != nil {
+		t.Fatalf("cannot decode %s: %v", path, err)
+	}
+}
+
+// executeTemplate renders the chart template of the object like helm does, using the functions the templates need
+func executeTemplate(t *testing.T, dir string, obj client.Object, values map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	name, err := fileName(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, TemplatesDir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	funcs := template.FuncMap{
+		"quote": func(v interface{}) string {
+			return fmt.Sprintf("%q", fmt.Sprint(v))
+		},
+		"toYaml": func(v interface{}) string {
+			out, err := yaml.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return strings.TrimSuffix(string(out), "\n")
+		},
+		"nindent": func(n int, s string) string {
+			pad := strings.Repeat(" ", n)
+			return "\n" + pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+		},
+	}
+	tmpl, err := template.New(name).Funcs(funcs).Parse(string(data))
+	if err != nil {
+		t.Fatalf("cannot parse template %s: %v", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]interface{}{"Values": values}); err != nil {
+		t.Fatalf("cannot execute template %s: %v", name, err)
+	}
+	var got map[string]interface{}
+	if err := yaml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("cannot decode rendered template %s: %v\n%s", name, err, buf.String())
+	}
+	return got
+}
+
+func nested(t *testing.T, data map[string]interface{}, fields ...string) interface{} {
+	t.Helper()
+	var cur interface{} = data
+	for _, field := range fields {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			t.Fatalf("missing field %s", strings.Join(fields, "."))
+		}
+		cur = m[field]
+	}
+	return cur
+}
+
+func expectField(t *testing.T, data map[string]interface{}, expected interface{}, fields ...string) {
+	t.Helper()
+	if diff := cmp.Diff(expected, nested(t, data, fields...)); diff != "" {
+		t.Errorf("unexpected %s: %s", strings.Join(fields, "."), diff)
+	}
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schedcache
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1qos "k8s.io/kubectl/pkg/util/qos"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/k8stopologyawareschedwg/podfingerprint"
+	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/podres/middleware/podexclude"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+)
+
+// PodReason is a likely explanation of why a pod is accounted only on one side, scheduler or RTE.
+type PodReason string
+
+const (
+	// PodReasonMissing means the pod is not known to the apiserver anymore.
+	PodReasonMissing PodReason = "missing"
+	// PodReasonTerminal means the pod is succeeded or failed.
+	PodReasonTerminal PodReason = "terminal"
+	// PodReasonExcluded means the pod matches the PodExcludes of the NUMAResourcesOperator, so RTE ignores it.
+	PodReasonExcluded PodReason = "excluded"
+	// PodReasonNonExclusive means the pod does not trigger a cache resync with the configured detection mode.
+	PodReasonNonExclusive PodReason = "non-exclusive"
+	// PodReasonForeign means the pod was not scheduled by the NUMA-aware scheduler.
+	PodReasonForeign PodReason = "foreign"
+)
+
+type PodDiff struct {
+	Name    string
+	Reasons []PodReason
+}
+
+func (pd PodDiff) String() string {
+	if len(pd.Reasons) == 0 {
+		return pd.Name + " (unexplained)"
+	}
+	reasons := make([]string, 0, len(pd.Reasons))
+	for _, reason := range pd.Reasons {
+		reasons = append(reasons, string(reason))
+	}
+	return pd.Name + " (" + strings.Join(reasons, ", ") + ")"
+}
+
+// NodeDiff reports the pods seen only by the scheduler or only by RTE on a node.
+type NodeDiff struct {
+	NodeName       string
+	RTEFingerprint string
+	SchedOnly      []PodDiff
+	RTEOnly        []PodDiff
+}
+
+type Classifier struct {
+	PodExcludes   podexclude.List
+	SchedulerName string
+	// Aggressive reflects the CacheResyncDetection mode: if set, any guaranteed QoS pod triggers a resync.
+	Aggressive bool
+}
+
+func NewClassifier(nroObj *nropv1.NUMAResourcesOperator, nroSched *nropv1.NUMAResourcesScheduler) Classifier {
+	cl := Classifier{}
+	if nroObj != nil {
+		for _, pex := range nroObj.Spec.PodExcludes {
+			cl.PodExcludes = append(cl.PodExcludes, podexclude.Item{
+				NamespacePattern: pex.Namespace,
+				NamePattern:      pex.Name,
+			})
+		}
+	}
+	if nroSched != nil {
+		cl.SchedulerName = nroSched.Status.SchedulerName
+		if cl.SchedulerName == "" {
+			cl.SchedulerName = nroSched.Spec.SchedulerName
+		}
+		detection := nroSched.Spec.CacheResyncDetection
+		cl.Aggressive = (detection != nil && *detection == nropv1.CacheResyncDetectionAggressive)
+	}
+	return cl
+}
+
+// Classify returns all the reasons which can explain a divergence about the given pod.
+// The pod object is nil if the pod was not found in the apiserver.
+func (cl Classifier) Classify(namespace, name string, pod *corev1.Pod) []PodReason {
+	var reasons []PodReason
+	if podexclude.ShouldExclude(cl.PodExcludes, namespace, name, false) {
+		reasons = append(reasons, PodReasonExcluded)
+	}
+	if pod == nil {
+		return append(reasons, PodReasonMissing)
+	}
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		reasons = append(reasons, PodReasonTerminal)
+	}
+	if cl.SchedulerName != "" && pod.Spec.SchedulerName != cl.SchedulerName {
+		reasons = append(reasons, PodReasonForeign)
+	}
+	if !cl.triggersResync(pod) {
+		reasons = append(reasons, PodReasonNonExclusive)
+	}
+	return reasons
+}
+
+func (cl Classifier) triggersResync(pod *corev1.Pod) bool {
+	if corev1qos.GetPodQOS(pod) != corev1.PodQOSGuaranteed {
+		return false
+	}
+	if cl.Aggressive {
+		return true
+	}
+	for _, cnt := range pod.Spec.InitContainers {
+		if requestsNUMAResources(cnt.Resources.Requests) {
+			return true
+		}
+	}
+	for _, cnt := range pod.Spec.Containers {
+		if requestsNUMAResources(cnt.Resources.Requests) {
+			return true
+		}
+	}
+	return false
+}
+
+func requestsNUMAResources(requests corev1.ResourceList) bool {
+	for name, qty := range requests {
+		switch {
+		case name == corev1.ResourceCPU:
+			if qty.MilliValue()%1000 == 0 {
+				return true
+			}
+		case name == corev1.ResourceMemory, name == corev1.ResourceEphemeralStorage:
+			continue
+		case qty.IsZero():
+			continue
+		default: // hugepages, devices
+			return true
+		}
+	}
+	return false
+}
+
+// DiffNode compares the pods the scheduler accounted on a node with the pods RTE reported,
+// and classifies each pod found only on one side.
+func DiffNode(env *Env, cl Classifier, nodeName string, podsBySched sets.Set[string], rteSt podfingerprint.Status) (NodeDiff, error) {
+	podsByRTE := sets.New[string]()
+	for _, nn := range rteSt.Pods {
+		podsByRTE.Insert(nn.String())
+	}
+
+	nd := NodeDiff{
+		NodeName:       nodeName,
+		RTEFingerprint: rteSt.FingerprintComputed,
+	}
+	var err error
+	nd.SchedOnly, err = classifyPods(env, cl, sets.List(podsBySched.Difference(podsByRTE)))
+	if err != nil {
+		return nd, err
+	}
+	nd.RTEOnly, err = classifyPods(env, cl, sets.List(podsByRTE.Difference(podsBySched)))
+	return nd, err
+}
+
+func classifyPods(env *Env, cl Classifier, names []string) ([]PodDiff, error) {
+	diffs := make([]PodDiff, 0, len(names))
+	for _, name := range names {
+		podNamespace, podName, _ := strings.Cut(name, "/")
+		var podPtr *corev1.Pod
+		pod := corev1.Pod{}
+		err := env.Cli.Get(env.Ctx, client.ObjectKey{Namespace: podNamespace, Name: podName}, &pod)
+		if err == nil {
+			podPtr = &pod
+		} else if !apierrors.IsNotFound(err) {
+			return diffs, err
+		}
+		diffs = append(diffs, PodDiff{
+			Name:    name,
+			Reasons: cl.Classify(podNamespace, podName, podPtr),
+		})
+	}
+	return diffs, nil
+}
@@ -0,0 +1,206 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schedcache
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/k8stopologyawareschedwg/podfingerprint"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+)
+
+const testSchedulerName = "topo-aware-scheduler"
+
+func makePod(namespace, name, schedulerName string, phase corev1.PodPhase, cpu, memory string) *corev1.Pod {
+	res := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Spec: corev1.PodSpec{
+			SchedulerName: schedulerName,
+			Containers: []corev1.Container{
+				{
+					Name: "cnt",
+					Resources: corev1.ResourceRequirements{
+						Requests: res,
+						Limits:   res,
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: phase,
+		},
+	}
+}
+
+func TestClassify(t *testing.T) {
+	aggressive := nropv1.CacheResyncDetectionAggressive
+	nroObj := &nropv1.NUMAResourcesOperator{
+		Spec: nropv1.NUMAResourcesOperatorSpec{
+			PodExcludes: []nropv1.NamespacedName{
+				{Namespace: "kube-*", Name: "*"},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		nroSched *nropv1.NUMAResourcesScheduler
+		pod      *corev1.Pod
+		podNS    string
+		podName  string
+		expected []PodReason
+	}{
+		{
+			name:     "exclusive pod has no explanation",
+			pod:      makePod("ns", "gu", testSchedulerName, corev1.PodRunning, "2", "1Gi"),
+			expected: nil,
+		},
+		{
+			name:     "missing pod",
+			podNS:    "ns",
+			podName:  "gone",
+			expected: []PodReason{PodReasonMissing},
+		},
+		{
+			name:     "missing excluded pod",
+			podNS:    "kube-system",
+			podName:  "gone",
+			expected: []PodReason{PodReasonExcluded, PodReasonMissing},
+		},
+		{
+			name:     "terminal pod",
+			pod:      makePod("ns", "done", testSchedulerName, corev1.PodSucceeded, "2", "1Gi"),
+			expected: []PodReason{PodReasonTerminal},
+		},
+		{
+			name:     "foreign pod",
+			pod:      makePod("ns", "other", "default-scheduler", corev1.PodRunning, "2", "1Gi"),
+			expected: []PodReason{PodReasonForeign},
+		},
+		{
+			name:     "fractional cpus with relaxed detection",
+			pod:      makePod("ns", "frac", testSchedulerName, corev1.PodRunning, "1500m", "1Gi"),
+			expected: []PodReason{PodReasonNonExclusive},
+		},
+		{
+			name: "fractional cpus with aggressive detection",
+			nroSched: &nropv1.NUMAResourcesScheduler{
+				Spec: nropv1.NUMAResourcesSchedulerSpec{
+					SchedulerName:        testSchedulerName,
+					CacheResyncDetection: &aggressive,
+				},
+			},
+			pod:      makePod("ns", "frac", testSchedulerName, corev1.PodRunning, "1500m", "1Gi"),
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			nroSched := tc.nroSched
+			if nroSched == nil {
+				nroSched = &nropv1.NUMAResourcesScheduler{
+					Status: nropv1.NUMAResourcesSchedulerStatus{
+						SchedulerName: testSchedulerName,
+					},
+				}
+			}
+			cl := NewClassifier(nroObj, nroSched)
+			podNS, podName := tc.podNS, tc.podName
+			if tc.pod != nil {
+				podNS, podName = tc.pod.Namespace, tc.pod.Name
+			}
+			got := cl.Classify(podNS, podName, tc.pod)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("got reasons %v expected %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDiffNode(t *testing.T) {
+	cli := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		makePod("ns", "both", testSchedulerName, corev1.PodRunning, "2", "1Gi"),
+		makePod("ns", "done", testSchedulerName, corev1.PodFailed, "2", "1Gi"),
+		makePod("ns", "other", "default-scheduler", corev1.PodRunning, "2", "1Gi"),
+	).Build()
+	env := Env{
+		Ctx: context.Background(),
+		Cli: cli,
+	}
+	cl := Classifier{
+		SchedulerName: testSchedulerName,
+	}
+
+	podsBySched := sets.New[string]("ns/both", "ns/done", "ns/gone")
+	rteSt := podfingerprint.Status{
+		NodeName:            "node-0",
+		FingerprintComputed: "pfp0v001",
+		Pods: []podfingerprint.NamespacedName{
+			{Namespace: "ns", Name: "both"},
+			{Namespace: "ns", Name: "other"},
+		},
+	}
+
+	got, err := DiffNode(&env, cl, "node-0", podsBySched, rteSt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := NodeDiff{
+		NodeName:       "node-0",
+		RTEFingerprint: "pfp0v001",
+		SchedOnly: []PodDiff{
+			{Name: "ns/done", Reasons: []PodReason{PodReasonTerminal}},
+			{Name: "ns/gone", Reasons: []PodReason{PodReasonMissing}},
+		},
+		RTEOnly: []PodDiff{
+			{Name: "ns/other", Reasons: []PodReason{PodReasonForeign}},
+		},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got diff %+v expected %+v", got, expected)
+	}
+}
+
+func TestPodDiffString(t *testing.T) {
+	pd := PodDiff{Name: "ns/pod", Reasons: []PodReason{PodReasonTerminal, PodReasonForeign}}
+	if got := pd.String(); got != "ns/pod (terminal, foreign)" {
+		t.Errorf("unexpected string %q", got)
+	}
+	pd = PodDiff{Name: "ns/pod"}
+	if got := pd.String(); got != "ns/pod (unexplained)" {
+		t.Errorf("unexpected string %q", got)
+	}
+}
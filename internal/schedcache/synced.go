@@ -21,7 +21,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
-	"strings"
 
 	"github.com/go-logr/logr"
 
@@ -36,6 +35,7 @@ import (
 	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
 	"github.com/openshift-kni/numaresources-operator/pkg/status"
 
+	"github.com/openshift-kni/numaresources-operator/internal/pfpstatus"
 	"github.com/openshift-kni/numaresources-operator/internal/podlist"
 	"github.com/openshift-kni/numaresources-operator/internal/remoteexec"
 )
@@ -49,6 +49,8 @@ type Env struct {
 	Cli    client.Client
 	K8sCli kubernetes.Interface
 	Log    logr.Logger
+	// PFPStatus fetches the status dumps over HTTPS. If nil, or if fetching fails, the dumps are read executing commands in the pods.
+	PFPStatus *pfpstatus.Client
 }
 
 func HasSynced(env *Env, nodeNames []string) (bool, map[string]sets.Set[string], error) {
@@ -110,12 +112,7 @@ func ReplicaHasSynced(env *Env, pod *corev1.Pod, nodeNames []string) (map[string
 
 func ReplicaHasSyncedForNode(env *Env, pod *corev1.Pod, nodeName string) (bool, sets.Set[string], error) {
 	detectedPods := sets.New[string]()
-	stdout, _, err := remoteexec.CommandOnPod(env.Ctx, env.K8sCli, pod, "/bin/cat", filepath.Join(TracingDirectory, nodeNameToFileName(nodeName)+".json"))
-	if err != nil {
-		return false, detectedPods, err
-	}
-	var status podfingerprint.Status
-	err = json.Unmarshal(stdout, &status)
+	status, err := GetSchedulerFingerprintStatus(env, pod, nodeName)
 	if err != nil {
 		return false, detectedPods, err
 	}
@@ -137,9 +134,32 @@ func ReplicaHasSyncedForNode(env *Env, pod *corev1.Pod, nodeName string) (bool,
 	return hasSync, detectedPods, nil
 }
 
+// GetSchedulerFingerprintStatus returns the fingerprint status the scheduler replica computed for the given node
+func GetSchedulerFingerprintStatus(env *Env, pod *corev1.Pod, nodeName string) (podfingerprint.Status, error) {
+	return getFingerprintStatus(env, pod, pod.Spec.Containers[0].Name, pfpstatus.FileNameForNode(nodeName))
+}
+
 func GetUpdaterFingerprintStatus(env *Env, podNamespace, podName, cntName string) (podfingerprint.Status, error) {
+	pod := corev1.Pod{}
+	err := env.Cli.Get(env.Ctx, client.ObjectKey{Namespace: podNamespace, Name: podName}, &pod)
+	if err != nil {
+		return podfingerprint.Status{}, err
+	}
+	return getFingerprintStatus(env, &pod, cntName, pfpstatus.DumpFileName)
+}
+
+func getFingerprintStatus(env *Env, pod *corev1.Pod, cntName, fileName string) (podfingerprint.Status, error) {
+	if env.PFPStatus != nil {
+		st, err := env.PFPStatus.Get(env.Ctx, pod, fileName)
+		if err == nil || env.K8sCli == nil {
+			return st, err
+		}
+		// like when running outside the cluster, where the pods are not reachable
+		klog.V(2).InfoS("cannot get the fingerprint status from the endpoint, executing commands in the pod", "pod", pod.Namespace+"/"+pod.Name, "error", err)
+	}
+
 	var st podfingerprint.Status
-	stdout, _, err := remoteexec.CommandOnPodByNames(env.Ctx, env.K8sCli, podNamespace, podName, cntName, "/bin/cat", filepath.Join(TracingDirectory, "dump.json"))
+	stdout, _, err := remoteexec.CommandOnPodByNames(env.Ctx, env.K8sCli, pod.Namespace, pod.Name, cntName, "/bin/cat", filepath.Join(TracingDirectory, fileName))
 	if err != nil {
 		return st, err
 	}
@@ -153,7 +173,3 @@ func mergeUnsynced(total, partial map[string]sets.Set[string]) {
 		total[nodeName] = existing.Union(detectedPods)
 	}
 }
-
-func nodeNameToFileName(name string) string {
-	return strings.ReplaceAll(name, ".", "_")
-}
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schedsim
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+)
+
+// MaxNodeScore is the highest score a node can get, like in the scheduler framework
+const MaxNodeScore int64 = 100
+
+// Weights are the weights of the resources in the score. Resources not listed weigh 1.
+type Weights map[corev1.ResourceName]int64
+
+func (rw Weights) weight(resName corev1.ResourceName) int64 {
+	w, ok := rw[resName]
+	if !ok || w < 1 {
+		return 1
+	}
+	return w
+}
+
+type scoreStrategyFn func(requested, available corev1.ResourceList, weights Weights) int64
+
+func scoreStrategy(strategy nropv1.ScoringStrategyType) scoreStrategyFn {
+	switch strategy {
+	case nropv1.MostAllocated:
+		return mostAllocatedScoreStrategy
+	case nropv1.BalancedAllocation:
+		return balancedAllocationScoreStrategy
+	default:
+		return leastAllocatedScoreStrategy
+	}
+}
+
+// scoreForEachNUMANode returns the lowest non-zero score of the zones, like the plugin does:
+// a zero score means the request doesn't fit, so the kubelet won't consider that zone.
+func scoreForEachNUMANode(requested corev1.ResourceList, zones []zoneState, score scoreStrategyFn, weights Weights) int64 {
+	minScore := int64(0)
+	for _, zone := range zones {
+		zoneScore := score(requested, zone.available, weights)
+		if minScore == 0 || (zoneScore != 0 && zoneScore < minScore) {
+			minScore = zoneScore
+		}
+	}
+	return minScore
+}
+
+func leastAllocatedScoreStrategy(requested, available corev1.ResourceList, weights Weights) int64 {
+	var zoneScore, weightSum int64
+	for resName, resQty := range requested {
+		// zones not providing the resource score 0 for that resource
+		weight := weights.weight(resName)
+		zoneScore += leastAllocatedScore(resQty, available[resName]) * weight
+		weightSum += weight
+	}
+	if weightSum == 0 {
+		return 0
+	}
+	return zoneScore / weightSum
+}
+
+func leastAllocatedScore(requested, capacity resource.Quantity) int64 {
+	if capacity.CmpInt64(0) == 0 || requested.Cmp(capacity) > 0 {
+		return 0
+	}
+	return ((capacity.Value() - requested.Value()) * MaxNodeScore) / capacity.Value()
+}
+
+func mostAllocatedScoreStrategy(requested, available corev1.ResourceList, weights Weights) int64 {
+	var zoneScore, weightSum int64
+	for resName, resQty := range requested {
+		weight := weights.weight(resName)
+		zoneScore += mostAllocatedScore(resQty, available[resName]) * weight
+		weightSum += weight
+	}
+	if weightSum == 0 {
+		return 0
+	}
+	return zoneScore / weightSum
+}
+
+func mostAllocatedScore(requested, capacity resource.Quantity) int64 {
+	if capacity.CmpInt64(0) == 0 || requested.Cmp(capacity) > 0 {
+		return 0
+	}
+	return (requested.Value() * MaxNodeScore) / capacity.Value()
+}
+
+// balancedAllocationScoreStrategy favors the zones whose resources would be consumed in the same proportion.
+// The weights are not used, like in the plugin.
+func balancedAllocationScoreStrategy(requested, available corev1.ResourceList, _ Weights) int64 {
+	var fractions []float64
+	for resName, resQty := range requested {
+		fraction := fractionOfCapacity(resQty, available[resName])
+		if fraction > 1 {
+			return 0
+		}
+		fractions = append(fractions, fraction)
+	}
+	return int64((1 - variance(fractions)) * float64(MaxNodeScore))
+}
+
+func fractionOfCapacity(requested, capacity resource.Quantity) float64 {
+	if capacity.Value() == 0 {
+		return 1
+	}
+	return float64(requested.Value()) / float64(capacity.Value())
+}
+
+// variance is the unbiased sample variance, which is what the plugin computes
+func variance(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	var mean float64
+	for _, val := range values {
+		mean += val
+	}
+	mean /= float64(len(values))
+	var ss float64
+	for _, val := range values {
+		ss += (val - mean) * (val - mean)
+	}
+	return ss / float64(len(values)-1)
+}
@@ -0,0 +1,439 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package schedsim simulates the placement of pods by the NodeResourceTopologyMatch scheduler plugin,
+// replaying its filter and score logic against NodeResourceTopology snapshots.
+// The simulation accounts only the NUMA-aligned resources: the host-level resources, and the other plugins
+// of the scheduler profile, are not considered. The resources a node doesn't report, like a device it lacks,
+// are not available on that node.
+// Nodes with equal scores are picked in name order, while the scheduler picks one at random.
+package schedsim
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1qos "k8s.io/kubectl/pkg/util/qos"
+
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	intnrt "github.com/openshift-kni/numaresources-operator/internal/noderesourcetopology"
+	"github.com/openshift-kni/numaresources-operator/internal/resourcelist"
+)
+
+type Config struct {
+	ScoringStrategy nropv1.ScoringStrategyType
+	Weights         Weights
+}
+
+// ConfigFromSpec returns the configuration the operator would set for the scheduler from the given spec
+func ConfigFromSpec(spec nropv1.NUMAResourcesSchedulerSpec) Config {
+	spec = spec.Normalize()
+	cfg := Config{
+		ScoringStrategy: spec.ScoringStrategy.Type,
+		Weights:         Weights{},
+	}
+	for _, res := range spec.ScoringStrategy.Resources {
+		cfg.Weights[corev1.ResourceName(res.Name)] = res.Weight
+	}
+	return cfg
+}
+
+// Placement is the predicted placement of a pod
+type Placement struct {
+	// Pod is the namespace/name of the pod
+	Pod  string `json:"pod"`
+	Node string `json:"node,omitempty"`
+	// Zones are the NUMA zones the pod is predicted to get its resources from: one for the pod scope,
+	// one per container for the container scope, and possibly many if the node doesn't align the pod.
+	// Empty if the pod has no resources to align.
+	Zones []string `json:"zones,omitempty"`
+	Score int64    `json:"score"`
+	// Reason tells why the pod is unschedulable, if it is
+	Reason string `json:"reason,omitempty"`
+}
+
+type zoneState struct {
+	name      string
+	available corev1.ResourceList
+}
+
+type nodeState struct {
+	name   string
+	policy string
+	scope  string
+	zones  []zoneState
+}
+
+// allocation is the request a zone provides
+type allocation struct {
+	zone    int
+	request corev1.ResourceList
+}
+
+type Simulator struct {
+	cfg   Config
+	nodes []*nodeState
+}
+
+// New creates a simulator of the scheduler with the given configuration, starting from the given NRT objects
+func New(cfg Config, nrts []nrtv1alpha2.NodeResourceTopology) *Simulator {
+	sim := &Simulator{
+		cfg: cfg,
+	}
+	for idx := range nrts {
+		sim.nodes = append(sim.nodes, newNodeState(&nrts[idx]))
+	}
+	sort.Slice(sim.nodes, func(i, j int) bool { return sim.nodes[i].name < sim.nodes[j].name })
+	return sim
+}
+
+// Run schedules the pods one after another, each seeing the resources consumed by the previous ones
+func (sim *Simulator) Run(pods []corev1.Pod) []Placement {
+	var ret []Placement
+	for idx := range pods {
+		ret = append(ret, sim.Schedule(&pods[idx]))
+	}
+	return ret
+}
+
+// Schedule predicts the placement of the pod, and accounts its resources on the selected node
+func (sim *Simulator) Schedule(pod *corev1.Pod) Placement {
+	pl := Placement{
+		Pod: pod.Namespace + "/" + pod.Name,
+	}
+	pod = withDefaultRequests(pod)
+	score := scoreStrategy(sim.cfg.ScoringStrategy)
+
+	var best *nodeState
+	var bestAllocs []allocation
+	var reasons []string
+	for _, node := range sim.nodes {
+		allocs, err := node.filter(pod)
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("%s: %v", node.name, err))
+			continue
+		}
+		nodeScore := node.score(pod, score, sim.cfg.Weights)
+		if best == nil || nodeScore > pl.Score {
+			best, bestAllocs, pl.Score = node, allocs, nodeScore
+		}
+	}
+	if best == nil {
+		pl.Reason = fmt.Sprintf("0/%d nodes are available", len(sim.nodes))
+		if len(reasons) > 0 {
+			pl.Reason += ": " + strings.Join(reasons, "; ")
+		}
+		return pl
+	}
+
+	pl.Node = best.name
+	seen := sets.New[int]()
+	for _, alloc := range bestAllocs {
+		best.take(alloc)
+		// for the pod scope, and when resources are spread, report each zone once
+		if best.scope == intnrt.Pod || best.policy != intnrt.SingleNUMANode {
+			if seen.Has(alloc.zone) {
+				continue
+			}
+			seen.Insert(alloc.zone)
+		}
+		pl.Zones = append(pl.Zones, best.zones[alloc.zone].name)
+	}
+	return pl
+}
+
+func newNodeState(nrt *nrtv1alpha2.NodeResourceTopology) *nodeState {
+	policy, scope := topologyManager(nrt)
+	node := &nodeState{
+		name:   nrt.Name,
+		policy: policy,
+		scope:  scope,
+	}
+	for _, zone := range intnrt.SortedZoneList(nrt.Zones) {
+		if zone.Type != intnrt.ZoneTypeNode {
+			continue
+		}
+		zs := zoneState{
+			name:      zone.Name,
+			available: corev1.ResourceList{},
+		}
+		for _, resInfo := range zone.Resources {
+			zs.available[corev1.ResourceName(resInfo.Name)] = resInfo.Available.DeepCopy()
+		}
+		node.zones = append(node.zones, zs)
+	}
+	return node
+}
+
+// topologyManager returns the topology manager policy and scope of the node, like the plugin reads them
+func topologyManager(nrt *nrtv1alpha2.NodeResourceTopology) (string, string) {
+	policy, scope := intnrt.TopologyManagerFromAttributes(nrt.Attributes)
+	if policy != "" {
+		if scope == "" {
+			scope = intnrt.Container // kubelet default
+		}
+		return policy, scope
+	}
+	// older exporters publish only the deprecated topology policies
+	if len(nrt.TopologyPolicies) == 0 {
+		return intnrt.None, intnrt.Container
+	}
+	switch nrtv1alpha2.TopologyManagerPolicy(nrt.TopologyPolicies[0]) {
+	case nrtv1alpha2.SingleNUMANodePodLevel:
+		return intnrt.SingleNUMANode, intnrt.Pod
+	case nrtv1alpha2.SingleNUMANodeContainerLevel:
+		return intnrt.SingleNUMANode, intnrt.Container
+	case nrtv1alpha2.RestrictedPodLevel:
+		return intnrt.Restricted, intnrt.Pod
+	case nrtv1alpha2.Restricted, nrtv1alpha2.RestrictedContainerLevel:
+		return intnrt.Restricted, intnrt.Container
+	case nrtv1alpha2.BestEffort, nrtv1alpha2.BestEffortContainerLevel:
+		return intnrt.BestEffort, intnrt.Container
+	case nrtv1alpha2.BestEffortPodLevel:
+		return intnrt.BestEffort, intnrt.Pod
+	}
+	return intnrt.None, intnrt.Container
+}
+
+// filter tells if the node can take the pod, and how the zones would provide the resources
+func (node *nodeState) filter(pod *corev1.Pod) ([]allocation, error) {
+	zones := cloneZones(node.zones)
+	if node.policy != intnrt.SingleNUMANode {
+		// the plugin doesn't filter: the resources just need to be available on the node
+		total := alignedRequests(pod, podRequests(pod))
+		allocs, ok := spread(zones, total)
+		if !ok {
+			return nil, fmt.Errorf("insufficient resources for %s", resourcelist.ToString(total))
+		}
+		return allocs, nil
+	}
+
+	if node.scope == intnrt.Pod {
+		total := alignedRequests(pod, podRequests(pod))
+		if len(total) == 0 {
+			return nil, nil
+		}
+		idx := firstFit(zones, total)
+		if idx < 0 {
+			return nil, fmt.Errorf("cannot align %s on a single NUMA zone", resourcelist.ToString(total))
+		}
+		return []allocation{{zone: idx, request: total}}, nil
+	}
+
+	// init containers run one at a time, and release their resources before the app containers start
+	for idx := range pod.Spec.InitContainers {
+		req := alignedRequests(pod, pod.Spec.InitContainers[idx].Resources.Requests)
+		if len(req) > 0 && firstFit(zones, req) < 0 {
+			return nil, fmt.Errorf("cannot align init container %q on a single NUMA zone", pod.Spec.InitContainers[idx].Name)
+		}
+	}
+	var allocs []allocation
+	for idx := range pod.Spec.Containers {
+		req := alignedRequests(pod, pod.Spec.Containers[idx].Resources.Requests)
+		if len(req) == 0 {
+			continue
+		}
+		zidx := firstFit(zones, req)
+		if zidx < 0 {
+			return nil, fmt.Errorf("cannot align container %q on a single NUMA zone", pod.Spec.Containers[idx].Name)
+		}
+		alloc := allocation{zone: zidx, request: req}
+		takeFrom(&zones[zidx], alloc.request)
+		allocs = append(allocs, alloc)
+	}
+	return allocs, nil
+}
+
+// score returns the score of the node for the pod, like the plugin computes it: the non-guaranteed pods
+// fit every node equally, and only the nodes with the single-numa-node policy are scored, the others score 0.
+// Like in the plugin, the score uses the plain requests, not just the ones the zones align.
+func (node *nodeState) score(pod *corev1.Pod, score scoreStrategyFn, weights Weights) int64 {
+	if corev1qos.GetPodQOS(pod) != corev1.PodQOSGuaranteed {
+		return MaxNodeScore
+	}
+	if node.policy != intnrt.SingleNUMANode {
+		return 0
+	}
+	if node.scope == intnrt.Pod {
+		return scoreForEachNUMANode(podRequests(pod), node.zones, score, weights)
+	}
+	// the score of the container scope is the mean of the scores of all the containers, init ones included
+	cnts := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	if len(cnts) == 0 {
+		return 0
+	}
+	var sum int64
+	for idx := range cnts {
+		sum += scoreForEachNUMANode(cnts[idx].Resources.Requests, node.zones, score, weights)
+	}
+	return sum / int64(len(cnts))
+}
+
+func (node *nodeState) take(alloc allocation) {
+	takeFrom(&node.zones[alloc.zone], alloc.request)
+}
+
+// alignedRequests returns the requests the topology manager aligns on NUMA zones. The zones report
+// only the exclusive resources: CPUs are exclusive only for integral requests of guaranteed pods,
+// and memory is exclusive only for guaranteed pods. The host-level resources are not aligned, while
+// the other resources not reported by the zones are kept, so the node cannot satisfy them.
+func alignedRequests(pod *corev1.Pod, res corev1.ResourceList) corev1.ResourceList {
+	guaranteed := corev1qos.GetPodQOS(pod) == corev1.PodQOSGuaranteed
+	ret := corev1.ResourceList{}
+	for resName, resQty := range res {
+		if resQty.IsZero() || intnrt.IsHostLevelResource(resName) {
+			continue
+		}
+		if resName == corev1.ResourceCPU && (!guaranteed || resQty.MilliValue()%1000 != 0) {
+			continue
+		}
+		if resName == corev1.ResourceMemory && !guaranteed {
+			continue
+		}
+		ret[resName] = resQty
+	}
+	return ret
+}
+
+// podRequests returns the requests of the whole pod: the sum of the app containers,
+// or the largest init container, if bigger
+func podRequests(pod *corev1.Pod) corev1.ResourceList {
+	var ress []corev1.ResourceList
+	for idx := range pod.Spec.Containers {
+		ress = append(ress, pod.Spec.Containers[idx].Resources.Requests)
+	}
+	ret := resourcelist.Accumulate(ress, resourcelist.AllowAll)
+	for idx := range pod.Spec.InitContainers {
+		for resName, resQty := range pod.Spec.InitContainers[idx].Resources.Requests {
+			if qty, ok := ret[resName]; !ok || resQty.Cmp(qty) > 0 {
+				ret[resName] = resQty.DeepCopy()
+			}
+		}
+	}
+	return ret
+}
+
+// withDefaultRequests returns a copy of the pod with the missing requests defaulted to the limits,
+// like the API server does, because the pods to simulate may come from files
+func withDefaultRequests(pod *corev1.Pod) *corev1.Pod {
+	pod = pod.DeepCopy()
+	for _, cnts := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for idx := range cnts {
+			res := &cnts[idx].Resources
+			for resName, resQty := range res.Limits {
+				if _, ok := res.Requests[resName]; ok {
+					continue
+				}
+				if res.Requests == nil {
+					res.Requests = corev1.ResourceList{}
+				}
+				res.Requests[resName] = resQty.DeepCopy()
+			}
+		}
+	}
+	return pod
+}
+
+func firstFit(zones []zoneState, request corev1.ResourceList) int {
+	for idx := range zones {
+		if fits(zones[idx], request) {
+			return idx
+		}
+	}
+	return -1
+}
+
+func fits(zone zoneState, request corev1.ResourceList) bool {
+	for resName, resQty := range request {
+		if avail := zone.available[resName]; avail.Cmp(resQty) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// spread places the request in the first zone it fits in, like the kubelet would try to,
+// and spreads it across the zones in order otherwise
+func spread(zones []zoneState, request corev1.ResourceList) ([]allocation, bool) {
+	if len(request) == 0 {
+		return nil, true
+	}
+	if idx := firstFit(zones, request); idx >= 0 {
+		return []allocation{{zone: idx, request: request}}, true
+	}
+
+	allocs := make(map[int]corev1.ResourceList)
+	for resName, resQty := range request {
+		left := resQty.DeepCopy()
+		for idx := range zones {
+			if left.Sign() <= 0 {
+				break
+			}
+			avail := zones[idx].available[resName]
+			if avail.Sign() <= 0 {
+				continue
+			}
+			qty := left.DeepCopy()
+			if avail.Cmp(left) < 0 {
+				qty = avail.DeepCopy()
+			}
+			left.Sub(qty)
+			if allocs[idx] == nil {
+				allocs[idx] = corev1.ResourceList{}
+			}
+			allocs[idx][resName] = qty
+		}
+		if left.Sign() > 0 {
+			return nil, false
+		}
+	}
+
+	var ret []allocation
+	for idx := range zones {
+		if req, ok := allocs[idx]; ok {
+			ret = append(ret, allocation{zone: idx, request: req})
+		}
+	}
+	return ret, true
+}
+
+func takeFrom(zone *zoneState, request corev1.ResourceList) {
+	for resName, resQty := range request {
+		avail := zone.available[resName]
+		avail.Sub(resQty)
+		if avail.Sign() < 0 {
+			avail = *resource.NewQuantity(0, avail.Format)
+		}
+		zone.available[resName] = avail
+	}
+}
+
+func cloneZones(zones []zoneState) []zoneState {
+	ret := make([]zoneState, 0, len(zones))
+	for _, zone := range zones {
+		ret = append(ret, zoneState{
+			name:      zone.name,
+			available: zone.available.DeepCopy(),
+		})
+	}
+	return ret
+}
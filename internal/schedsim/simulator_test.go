@@ -0,0 +1,416 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schedsim
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	intnrt "github.com/openshift-kni/numaresources-operator/internal/noderesourcetopology"
+)
+
+func TestConfigFromSpec(t *testing.T) {
+	cfg := ConfigFromSpec(nropv1.NUMAResourcesSchedulerSpec{})
+	if cfg.ScoringStrategy != nropv1.LeastAllocated {
+		t.Errorf("expected the default scoring strategy, got %q", cfg.ScoringStrategy)
+	}
+
+	cfg = ConfigFromSpec(nropv1.NUMAResourcesSchedulerSpec{
+		ScoringStrategy: &nropv1.ScoringStrategyParams{
+			Type: nropv1.MostAllocated,
+			Resources: []nropv1.ResourceSpecParams{
+				{Name: "cpu", Weight: 3},
+			},
+		},
+	})
+	expected := Config{
+		ScoringStrategy: nropv1.MostAllocated,
+		Weights:         Weights{corev1.ResourceCPU: 3},
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("expected %+v got %+v", expected, cfg)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	limitsOnlyPod := makePod("pod-0", "4")
+	limitsOnlyPod.Spec.Containers[0].Resources.Requests = nil
+	devicePod := makePod("pod-0", "4")
+	devicePod.Spec.Containers[0].Resources.Requests["example.com/gpu"] = resource.MustParse("1")
+	devicePod.Spec.Containers[0].Resources.Limits["example.com/gpu"] = resource.MustParse("1")
+	devicePod.Spec.Containers[0].Resources.Requests[corev1.ResourceEphemeralStorage] = resource.MustParse("1Gi")
+
+	testCases := []struct {
+		name     string
+		strategy nropv1.ScoringStrategyType
+		nrts     []nrtv1alpha2.NodeResourceTopology
+		pods     []corev1.Pod
+		expected []Placement
+	}{
+		{
+			name:     "least allocated prefers the emptier node",
+			strategy: nropv1.LeastAllocated,
+			nrts: []nrtv1alpha2.NodeResourceTopology{
+				makeNRT("node-a", intnrt.SingleNUMANode, intnrt.Pod, "8", "8"),
+				makeNRT("node-b", intnrt.SingleNUMANode, intnrt.Pod, "16", "16"),
+			},
+			pods: []corev1.Pod{
+				makePod("pod-0", "4"),
+			},
+			expected: []Placement{
+				{Pod: "ns/pod-0", Node: "node-b", Zones: []string{"node-0"}, Score: 84},
+			},
+		},
+		{
+			name:     "most allocated prefers the fuller node",
+			strategy: nropv1.MostAllocated,
+			nrts: []nrtv1alpha2.NodeResourceTopology{
+				makeNRT("node-a", intnrt.SingleNUMANode, intnrt.Pod, "8", "8"),
+				makeNRT("node-b", intnrt.SingleNUMANode, intnrt.Pod, "16", "16"),
+			},
+			pods: []corev1.Pod{
+				makePod("pod-0", "4"),
+			},
+			expected: []Placement{
+				{Pod: "ns/pod-0", Node: "node-a", Zones: []string{"node-0"}, Score: 28},
+			},
+		},
+		{
+			name:     "pods consume the zones",
+			strategy: nropv1.MostAllocated,
+			nrts: []nrtv1alpha2.NodeResourceTopology{
+				makeNRT("node-a", intnrt.SingleNUMANode, intnrt.Pod, "8", "8"),
+			},
+			pods: []corev1.Pod{
+				makePod("pod-0", "6"),
+				makePod("pod-1", "6"),
+				makePod("pod-2", "6"),
+			},
+			expected: []Placement{
+				{Pod: "ns/pod-0", Node: "node-a", Zones: []string{"node-0"}, Score: 40},
+				{Pod: "ns/pod-1", Node: "node-a", Zones: []string{"node-1"}, Score: 3},
+				{Pod: "ns/pod-2", Reason: "0/1 nodes are available: node-a: cannot align cpu=6, memory=1Gi on a single NUMA zone"},
+			},
+		},
+		{
+			name:     "container scope places containers separately",
+			strategy: nropv1.LeastAllocated,
+			nrts: []nrtv1alpha2.NodeResourceTopology{
+				makeNRT("node-a", intnrt.SingleNUMANode, intnrt.Container, "8", "8"),
+			},
+			pods: []corev1.Pod{
+				makePod("pod-0", "6", "6"),
+			},
+			expected: []Placement{
+				{Pod: "ns/pod-0", Node: "node-a", Zones: []string{"node-0", "node-1"}, Score: 59},
+			},
+		},
+		{
+			name:     "nodes not aligning resources spread the pod",
+			strategy: nropv1.LeastAllocated,
+			nrts: []nrtv1alpha2.NodeResourceTopology{
+				makeNRT("node-a", intnrt.SingleNUMANode, intnrt.Pod, "8", "8"),
+				makeNRT("node-b", intnrt.None, intnrt.Container, "8", "8"),
+			},
+			pods: []corev1.Pod{
+				makePod("pod-0", "12"),
+			},
+			expected: []Placement{
+				{Pod: "ns/pod-0", Node: "node-b", Zones: []string{"node-0", "node-1"}},
+			},
+		},
+		{
+			name:     "devices missing from the nodes are not available",
+			strategy: nropv1.LeastAllocated,
+			nrts: []nrtv1alpha2.NodeResourceTopology{
+				makeNRT("node-a", intnrt.SingleNUMANode, intnrt.Pod, "8", "8"),
+				makeNRT("node-b", intnrt.None, intnrt.Container, "8", "8"),
+			},
+			pods: []corev1.Pod{
+				devicePod,
+			},
+			expected: []Placement{
+				{Pod: "ns/pod-0", Reason: "0/2 nodes are available: node-a: cannot align cpu=4, example.com/gpu=1, memory=1Gi on a single NUMA zone; node-b: insufficient resources for cpu=4, example.com/gpu=1, memory=1Gi"},
+			},
+		},
+		{
+			name:     "requests default to limits",
+			strategy: nropv1.LeastAllocated,
+			nrts: []nrtv1alpha2.NodeResourceTopology{
+				makeNRT("node-a", intnrt.SingleNUMANode, intnrt.Pod, "8", "8"),
+			},
+			pods: []corev1.Pod{
+				limitsOnlyPod,
+			},
+			expected: []Placement{
+				{Pod: "ns/pod-0", Node: "node-a", Zones: []string{"node-0"}, Score: 71},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sim := New(Config{ScoringStrategy: tc.strategy}, tc.nrts)
+			got := sim.Run(tc.pods)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %+v got %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestScore pins the scores the plugin computes for a pod requesting 2 CPUs and 50Mi of memory per container.
+func TestScore(t *testing.T) {
+	req := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("2"),
+		corev1.ResourceMemory: resource.MustParse("50Mi"),
+	}
+	node1 := makeZonedNRT("node-1", intnrt.Container, [2]string{"4", "500Mi"}, [2]string{"2", "50Mi"})
+	node3 := makeZonedNRT("node-3", intnrt.Container, [2]string{"6", "60Mi"}, [2]string{"1", "6Mi"})
+
+	guaranteed := makeResPod(req)
+	burstable := makeResPod(req)
+	burstable.Spec.Containers[0].Resources.Limits = nil
+	withInit := makeResPod(req)
+	withInit.Spec.InitContainers = makeResPod(corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("4"),
+		corev1.ResourceMemory: resource.MustParse("50Mi"),
+	}).Spec.Containers
+	withStorage := makeResPod(req)
+	withStorage.Spec.Containers[0].Resources.Requests[corev1.ResourceEphemeralStorage] = resource.MustParse("1Gi")
+	withStorage.Spec.Containers[0].Resources.Limits[corev1.ResourceEphemeralStorage] = resource.MustParse("1Gi")
+
+	podScope := node1.DeepCopy()
+	podScope.Attributes[1].Value = intnrt.Pod
+	restricted := node1.DeepCopy()
+	restricted.Attributes[0].Value = intnrt.Restricted
+
+	testCases := []struct {
+		name     string
+		strategy nropv1.ScoringStrategyType
+		nrt      nrtv1alpha2.NodeResourceTopology
+		pod      corev1.Pod
+		expected int64
+	}{
+		{
+			name:     "least allocated",
+			strategy: nropv1.LeastAllocated,
+			nrt:      node1,
+			pod:      guaranteed,
+			expected: 70,
+		},
+		{
+			name:     "least allocated, zones partially fitting",
+			strategy: nropv1.LeastAllocated,
+			nrt:      node3,
+			pod:      guaranteed,
+			expected: 41,
+		},
+		{
+			name:     "most allocated",
+			strategy: nropv1.MostAllocated,
+			nrt:      node1,
+			pod:      guaranteed,
+			expected: 30,
+		},
+		{
+			name:     "most allocated, zones partially fitting",
+			strategy: nropv1.MostAllocated,
+			nrt:      node3,
+			pod:      guaranteed,
+			expected: 58,
+		},
+		{
+			name:     "balanced allocation",
+			strategy: nropv1.BalancedAllocation,
+			nrt:      node1,
+			pod:      guaranteed,
+			expected: 92,
+		},
+		{
+			name:     "non-guaranteed pods fit every node",
+			strategy: nropv1.LeastAllocated,
+			nrt:      node1,
+			pod:      burstable,
+			expected: MaxNodeScore,
+		},
+		{
+			name:     "container scope averages the init containers too",
+			strategy: nropv1.LeastAllocated,
+			nrt:      node1,
+			pod:      withInit,
+			expected: 57,
+		},
+		{
+			name:     "pod scope scores the largest of init and app containers",
+			strategy: nropv1.LeastAllocated,
+			nrt:      *podScope,
+			pod:      withInit,
+			expected: 45,
+		},
+		{
+			name:     "resources not reported by the zones score 0",
+			strategy: nropv1.LeastAllocated,
+			nrt:      node1,
+			pod:      withStorage,
+			expected: 46,
+		},
+		{
+			name:     "nodes without single-numa-node policy score 0",
+			strategy: nropv1.LeastAllocated,
+			nrt:      *restricted,
+			pod:      guaranteed,
+			expected: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			node := newNodeState(&tc.nrt)
+			got := node.score(&tc.pod, scoreStrategy(tc.strategy), Weights{})
+			if got != tc.expected {
+				t.Errorf("expected score %d got %d", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestTopologyManager(t *testing.T) {
+	nrt := nrtv1alpha2.NodeResourceTopology{
+		TopologyPolicies: []string{string(nrtv1alpha2.SingleNUMANodePodLevel)},
+	}
+	if policy, scope := topologyManager(&nrt); policy != intnrt.SingleNUMANode || scope != intnrt.Pod {
+		t.Errorf("unexpected configuration from the topology policies: %s/%s", policy, scope)
+	}
+	nrt.Attributes = nrtv1alpha2.AttributeList{
+		{Name: intnrt.TopologyManagerPolicyAttribute, Value: intnrt.Restricted},
+	}
+	if policy, scope := topologyManager(&nrt); policy != intnrt.Restricted || scope != intnrt.Container {
+		t.Errorf("unexpected configuration from the attributes: %s/%s", policy, scope)
+	}
+}
+
+func makeNRT(name, policy, scope string, cpus ...string) nrtv1alpha2.NodeResourceTopology {
+	nrt := nrtv1alpha2.NodeResourceTopology{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Attributes: nrtv1alpha2.AttributeList{
+			{Name: intnrt.TopologyManagerPolicyAttribute, Value: policy},
+			{Name: intnrt.TopologyManagerScopeAttribute, Value: scope},
+		},
+	}
+	for idx, cpu := range cpus {
+		nrt.Zones = append(nrt.Zones, nrtv1alpha2.Zone{
+			Name: "node-" + string(rune('0'+idx)),
+			Type: intnrt.ZoneTypeNode,
+			Resources: nrtv1alpha2.ResourceInfoList{
+				{
+					Name:        string(corev1.ResourceCPU),
+					Capacity:    resource.MustParse(cpu),
+					Allocatable: resource.MustParse(cpu),
+					Available:   resource.MustParse(cpu),
+				},
+				{
+					Name:        string(corev1.ResourceMemory),
+					Capacity:    resource.MustParse("16Gi"),
+					Allocatable: resource.MustParse("16Gi"),
+					Available:   resource.MustParse("16Gi"),
+				},
+			},
+		})
+	}
+	return nrt
+}
+
+// makePod creates a guaranteed pod, with a container with 1Gi of memory for each given amount of CPUs
+func makePod(name string, cpus ...string) corev1.Pod {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      name,
+		},
+	}
+	for idx, cpu := range cpus {
+		res := corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		}
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{
+			Name: "cnt-" + string(rune('0'+idx)),
+			Resources: corev1.ResourceRequirements{
+				Requests: res,
+				Limits:   res,
+			},
+		})
+	}
+	return pod
+}
+
+// makeZonedNRT creates a single-numa-node NRT object with a zone for each given pair of CPUs and memory
+func makeZonedNRT(name, scope string, zones ...[2]string) nrtv1alpha2.NodeResourceTopology {
+	nrt := makeNRT(name, intnrt.SingleNUMANode, scope)
+	for idx, zone := range zones {
+		nrt.Zones = append(nrt.Zones, nrtv1alpha2.Zone{
+			Name: "node-" + string(rune('0'+idx)),
+			Type: intnrt.ZoneTypeNode,
+			Resources: nrtv1alpha2.ResourceInfoList{
+				{
+					Name:        string(corev1.ResourceCPU),
+					Capacity:    resource.MustParse(zone[0]),
+					Allocatable: resource.MustParse(zone[0]),
+					Available:   resource.MustParse(zone[0]),
+				},
+				{
+					Name:        string(corev1.ResourceMemory),
+					Capacity:    resource.MustParse(zone[1]),
+					Allocatable: resource.MustParse(zone[1]),
+					Available:   resource.MustParse(zone[1]),
+				},
+			},
+		})
+	}
+	return nrt
+}
+
+// makeResPod creates a guaranteed pod with a single container with the given resources
+func makeResPod(res corev1.ResourceList) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "pod",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "cnt",
+					Resources: corev1.ResourceRequirements{
+						Requests: res.DeepCopy(),
+						Limits:   res.DeepCopy(),
+					},
+				},
+			},
+		},
+	}
+}
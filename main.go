@@ -17,11 +17,16 @@ limitations under the License.
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"runtime"
 	"time"
@@ -33,34 +38,45 @@ import (
 
 	securityv1 "github.com/openshift/api/security/v1"
 	machineconfigv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/klog/v2"
 	"k8s.io/klog/v2/textlogger"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
-	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
 	apimanifests "github.com/k8stopologyawareschedwg/deployer/pkg/manifests/api"
 	rtemanifests "github.com/k8stopologyawareschedwg/deployer/pkg/manifests/rte"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/options"
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
 
 	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
 	nropv1alpha1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1alpha1"
 	"github.com/openshift-kni/numaresources-operator/controllers"
 	"github.com/openshift-kni/numaresources-operator/internal/api/features"
+	"github.com/openshift-kni/numaresources-operator/internal/gather"
 	intkloglevel "github.com/openshift-kni/numaresources-operator/internal/kloglevel"
+	"github.com/openshift-kni/numaresources-operator/internal/pfpstatus"
+	intrender "github.com/openshift-kni/numaresources-operator/internal/render"
 	"github.com/openshift-kni/numaresources-operator/pkg/hash"
 	"github.com/openshift-kni/numaresources-operator/pkg/images"
 	"github.com/openshift-kni/numaresources-operator/pkg/numaresourcesscheduler/controlplane"
 	schedmanifests "github.com/openshift-kni/numaresources-operator/pkg/numaresourcesscheduler/manifests/sched"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
 	rteupdate "github.com/openshift-kni/numaresources-operator/pkg/objectupdate/rte"
 	schedupdate "github.com/openshift-kni/numaresources-operator/pkg/objectupdate/sched"
 	"github.com/openshift-kni/numaresources-operator/pkg/version"
@@ -72,10 +88,17 @@ const (
 )
 
 const (
-	defaultWebhookPort = 9443
-	defaultMetricsAddr = ":8080"
-	defaultProbeAddr   = ":8081"
-	defaultNamespace   = "numaresources-operator"
+	defaultWebhookPort  = 9443
+	defaultMetricsAddr  = ":8080"
+	defaultProbeAddr    = ":8081"
+	defaultFeaturesAddr = ":8082"
+	defaultNamespace    = "numaresources-operator"
+
+	// featuresEndpoint serves the supported features and capabilities on the features server
+	featuresEndpoint = "/features"
+
+	defaultTMDriftCheckPeriod    = 5 * time.Minute
+	defaultOwnedDriftCheckPeriod = 10 * time.Minute
 )
 
 var (
@@ -90,6 +113,7 @@ func init() {
 	utilruntime.Must(nropv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(machineconfigv1.Install(scheme))
 	utilruntime.Must(securityv1.Install(scheme))
+	utilruntime.Must(nrtv1alpha2.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -102,6 +126,9 @@ type RenderParams struct {
 	NRTCRD    bool
 	Namespace string
 	Image     ImageParams
+	From      string
+	Format    string
+	Dir       string
 }
 
 type Params struct {
@@ -109,6 +136,7 @@ type Params struct {
 	metricsAddr           string
 	enableLeaderElection  bool
 	probeAddr             string
+	featuresAddr          string
 	platformName          string
 	platformVersion       string
 	detectPlatformOnly    bool
@@ -123,28 +151,41 @@ type Params struct {
 	image                 ImageParams
 	inspectFeatures       bool
 	enableReplicasDetect  bool
+	tmDriftCheckPeriod    time.Duration
+	ownedDriftCheckPeriod time.Duration
+	pfpStatusDir          string
+	gatherDir             string
+	serverSideApply       bool
 }
 
 func (pa *Params) SetDefaults() {
 	pa.metricsAddr = defaultMetricsAddr
 	pa.probeAddr = defaultProbeAddr
+	pa.featuresAddr = defaultFeaturesAddr
 	pa.render.Namespace = defaultNamespace
+	pa.render.Format = string(intrender.FormatYAML)
 	pa.enableReplicasDetect = true
+	pa.tmDriftCheckPeriod = defaultTMDriftCheckPeriod
+	pa.ownedDriftCheckPeriod = defaultOwnedDriftCheckPeriod
 }
 
 func (pa *Params) FromFlags() {
 	flag.StringVar(&pa.metricsAddr, "metrics-bind-address", pa.metricsAddr, "The address the metric endpoint binds to.")
 	flag.StringVar(&pa.probeAddr, "health-probe-bind-address", pa.probeAddr, "The address the probe endpoint binds to.")
+	flag.StringVar(&pa.featuresAddr, "features-bind-address", pa.featuresAddr, "The address the supported features and capabilities endpoint binds to. Use 0 to disable.")
 	flag.BoolVar(&pa.enableLeaderElection, "leader-elect", pa.enableLeaderElection, "Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&pa.platformName, "platform", pa.platformName, "platform to deploy on - leave empty to autodetect")
 	flag.StringVar(&pa.platformVersion, "platform-version", pa.platformVersion, "platform version to deploy on - leave empty to autodetect")
 	flag.BoolVar(&pa.detectPlatformOnly, "detect-platform-only", pa.detectPlatformOnly, "detect and report the platform, then exits")
-	flag.BoolVar(&pa.inspectFeatures, "inspect-features", pa.inspectFeatures, "outputs the supported features as JSON, then exits")
+	flag.BoolVar(&pa.inspectFeatures, "inspect-features", pa.inspectFeatures, "outputs the supported features and capabilities as JSON, then exits")
 	flag.BoolVar(&pa.renderMode, "render", pa.renderMode, "outputs the rendered manifests, then exits")
 	flag.BoolVar(&pa.render.NRTCRD, "render-nrt-crd", pa.render.NRTCRD, "outputs only the rendered NodeResourceTopology CRD manifest, then exits")
 	flag.StringVar(&pa.render.Namespace, "render-namespace", pa.render.Namespace, "outputs the manifests rendered using the given namespace")
 	flag.StringVar(&pa.render.Image.Exporter, "render-image", pa.render.Image.Exporter, "outputs the manifests rendered using the given image")
 	flag.StringVar(&pa.render.Image.Scheduler, "render-image-scheduler", pa.render.Image.Scheduler, "outputs the manifests rendered using the given image for the scheduler")
+	flag.StringVar(&pa.render.From, "render-from", pa.render.From, "outputs the manifests the reconciliation of the NUMAResourcesOperator and NUMAResourcesScheduler objects found in the given file would produce. MachineConfigPools and KubeletConfigs the objects depend on are read from the same file.")
+	flag.StringVar(&pa.render.Format, "render-format", pa.render.Format, "outputs the rendered manifests in the given format: yaml (multi-document stream to stdout), helm (chart) or kustomize (base). Charts and bases are written in the directory set by --render-dir.")
+	flag.StringVar(&pa.render.Dir, "render-dir", pa.render.Dir, "writes the rendered helm chart or kustomize base in the given directory")
 	flag.BoolVar(&pa.showVersion, "version", pa.showVersion, "outputs the version and exit")
 	flag.BoolVar(&pa.enableScheduler, "enable-scheduler", pa.enableScheduler, "enable support for the NUMAResourcesScheduler object")
 	flag.BoolVar(&pa.enableWebhooks, "enable-webhooks", pa.enableWebhooks, "enable conversion webhooks")
@@ -155,6 +196,11 @@ func (pa *Params) FromFlags() {
 	flag.StringVar(&pa.image.Exporter, "image-exporter", pa.image.Exporter, "use this image as default for the RTE")
 	flag.StringVar(&pa.image.Scheduler, "image-scheduler", pa.image.Scheduler, "use this image as default for the scheduler")
 	flag.BoolVar(&pa.enableReplicasDetect, "detect-replicas", pa.enableReplicasDetect, "autodetect optimal replica count")
+	flag.StringVar(&pa.pfpStatusDir, "serve-pfpstatus", pa.pfpStatusDir, "serves the pod fingerprint status dumps found in the given directory, until killed. Used as scheduler sidecar.")
+	flag.StringVar(&pa.gatherDir, "gather-dir", pa.gatherDir, "collects the NUMA resources diagnostics data in the given directory, then exits. Used by must-gather.")
+	flag.BoolVar(&pa.serverSideApply, "server-side-apply", pa.serverSideApply, "apply the DaemonSets, Deployments, ConfigMaps and RBAC objects using server-side apply, reporting the fields conflicting with other managers as events")
+	flag.DurationVar(&pa.tmDriftCheckPeriod, "tm-drift-check-period", pa.tmDriftCheckPeriod, "how often to check the topology manager configuration published by RTEs. Use 0 to disable.")
+	flag.DurationVar(&pa.ownedDriftCheckPeriod, "owned-drift-check-period", pa.ownedDriftCheckPeriod, "how often to compare the objects owned by the operator with their desired state. Use 0 to disable.")
 
 	flag.Parse()
 
@@ -190,6 +236,14 @@ func main() {
 	config := textlogger.NewConfig(textlogger.Verbosity(int(klogV)))
 	ctrl.SetLogger(textlogger.NewLogger(config))
 
+	if params.pfpStatusDir != "" {
+		os.Exit(servePFPStatus(params.pfpStatusDir))
+	}
+
+	if params.gatherDir != "" {
+		os.Exit(manageGathering(params.gatherDir))
+	}
+
 	klog.InfoS("starting", "program", version.OperatorProgramName(), "version", bi.Version, "branch", bi.Branch, "gitcommit", bi.Commit, "golang", runtime.Version(), "vl", klogV, "auxv", config.Verbosity().String())
 
 	ctx := context.Background()
@@ -271,21 +325,58 @@ func main() {
 		ImagePullPolicy: pullPolicy,
 		Namespace:       namespace,
 		ForwardMCPConds: params.enableMCPCondsForward,
+		ServerSideApply: params.serverSideApply,
 	}).SetupWithManager(mgr); err != nil {
 		klog.ErrorS(err, "unable to create controller", "controller", "NUMAResourcesOperator")
 		os.Exit(1)
 	}
 	if err = (&controllers.KubeletConfigReconciler{
-		Client:    mgr.GetClient(),
-		Scheme:    mgr.GetScheme(),
-		Recorder:  mgr.GetEventRecorderFor("kubeletconfig-controller"),
-		Namespace: namespace,
-		Platform:  clusterPlatform,
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		Recorder:        mgr.GetEventRecorderFor("kubeletconfig-controller"),
+		Namespace:       namespace,
+		Platform:        clusterPlatform,
+		ServerSideApply: params.serverSideApply,
 	}).SetupWithManager(mgr); err != nil {
 		klog.ErrorS(err, "unable to create controller", "controller", "KubeletConfig")
 		os.Exit(1)
 	}
 
+	if params.tmDriftCheckPeriod > 0 {
+		if err = (&controllers.TopologyManagerDriftReconciler{
+			Client:      mgr.GetClient(),
+			Recorder:    mgr.GetEventRecorderFor("topologymanagerdrift-controller"),
+			Namespace:   namespace,
+			Platform:    clusterPlatform,
+			CheckPeriod: params.tmDriftCheckPeriod,
+		}).SetupWithManager(mgr); err != nil {
+			klog.ErrorS(err, "unable to create controller", "controller", "TopologyManagerDrift")
+			os.Exit(1)
+		}
+	}
+
+	if params.ownedDriftCheckPeriod > 0 {
+		if err = (&controllers.OwnedObjectsDriftReconciler{
+			Client:   mgr.GetClient(),
+			Recorder: mgr.GetEventRecorderFor("ownedobjectsdrift-controller"),
+			Operator: &controllers.NUMAResourcesOperatorReconciler{
+				Client:          mgr.GetClient(),
+				Scheme:          mgr.GetScheme(),
+				APIManifests:    apiManifests.Clone(),
+				RTEManifests:    rteManifestsRendered.Clone(),
+				Platform:        clusterPlatform,
+				Images:          imgs,
+				ImagePullPolicy: pullPolicy,
+				Namespace:       namespace,
+				ServerSideApply: params.serverSideApply,
+			},
+			CheckPeriod: params.ownedDriftCheckPeriod,
+		}).SetupWithManager(mgr); err != nil {
+			klog.ErrorS(err, "unable to create controller", "controller", "OwnedObjectsDrift")
+			os.Exit(1)
+		}
+	}
+
 	if params.enableScheduler {
 		info := controlplane.Defaults()
 		if params.enableReplicasDetect {
@@ -302,9 +393,12 @@ func main() {
 		if err = (&controllers.NUMAResourcesSchedulerReconciler{
 			Client:             mgr.GetClient(),
 			Scheme:             mgr.GetScheme(),
+			Recorder:           mgr.GetEventRecorderFor("numaresourcesscheduler-controller"),
 			SchedulerManifests: schedMf,
 			Namespace:          namespace,
 			AutodetectReplicas: info.NodeCount,
+			PFPStatusImage:     pfpStatusImage(imgs),
+			ServerSideApply:    params.serverSideApply,
 		}).SetupWithManager(mgr); err != nil {
 			klog.ErrorS(err, "unable to create controller", "controller", "NUMAResourcesScheduler")
 			os.Exit(1)
@@ -330,6 +424,12 @@ func main() {
 		klog.ErrorS(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if params.featuresAddr != "0" {
+		if err := mgr.Add(newFeaturesServer(params.featuresAddr)); err != nil {
+			klog.ErrorS(err, "unable to set up the features endpoint")
+			os.Exit(1)
+		}
+	}
 
 	klog.InfoS("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -338,10 +438,58 @@ func main() {
 	}
 }
 
+// newFeaturesServer serves the supported features and capabilities on every replica, leader or not
+func newFeaturesServer(addr string) *manager.Server {
+	mux := http.NewServeMux()
+	mux.Handle(featuresEndpoint, features.Handler())
+	return &manager.Server{
+		Name: "features",
+		Server: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// errReadOnly is returned by the client serving the objects to render on every write
+var errReadOnly = errors.New("the objects to render are read-only")
+
+// newReadOnlyClient returns a client serving the given objects, failing the writes:
+// rendering must never change the objects it reads.
+func newReadOnlyClient(objs ...client.Object) client.Client {
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(_ context.Context, _ client.WithWatch, _ client.Object, _ ...client.CreateOption) error {
+			return errReadOnly
+		},
+		Update: func(_ context.Context, _ client.WithWatch, _ client.Object, _ ...client.UpdateOption) error {
+			return errReadOnly
+		},
+		Patch: func(_ context.Context, _ client.WithWatch, _ client.Object, _ client.Patch, _ ...client.PatchOption) error {
+			return errReadOnly
+		},
+		Delete: func(_ context.Context, _ client.WithWatch, _ client.Object, _ ...client.DeleteOption) error {
+			return errReadOnly
+		},
+		DeleteAllOf: func(_ context.Context, _ client.WithWatch, _ client.Object, _ ...client.DeleteAllOfOption) error {
+			return errReadOnly
+		},
+		SubResourceCreate: func(_ context.Context, _ client.Client, _ string, _ client.Object, _ client.Object, _ ...client.SubResourceCreateOption) error {
+			return errReadOnly
+		},
+		SubResourceUpdate: func(_ context.Context, _ client.Client, _ string, _ client.Object, _ ...client.SubResourceUpdateOption) error {
+			return errReadOnly
+		},
+		SubResourcePatch: func(_ context.Context, _ client.Client, _ string, _ client.Object, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+			return errReadOnly
+		},
+	}).Build()
+}
+
 func manageIntrospection() int {
-	err := json.NewEncoder(os.Stdout).Encode(features.GetTopics())
+	err := json.NewEncoder(os.Stdout).Encode(features.GetFeatures())
 	if err != nil {
-		klog.ErrorS(err, "getting feature topics")
+		klog.ErrorS(err, "getting features")
 		return 1
 	}
 	return 0
@@ -349,13 +497,17 @@ func manageIntrospection() int {
 
 func manageRendering(render RenderParams, clusterPlatform platform.Platform, apiMf apimanifests.Manifests, rteMf rtemanifests.Manifests, namespace string, enableScheduler bool) int {
 	if render.NRTCRD {
-		if err := renderObjects(apiMf.ToObjects()); err != nil {
+		if err := renderObjects(render, apiMf.ToObjects()); err != nil {
 			klog.ErrorS(err, "unable to render manifests")
 			return 1
 		}
 		return 0
 	}
 
+	if render.From != "" {
+		return manageRenderingFromFile(render, clusterPlatform, apiMf, rteMf)
+	}
+
 	var objs []client.Object
 	if enableScheduler {
 		if render.Image.Scheduler == "" {
@@ -389,7 +541,7 @@ func manageRendering(render RenderParams, clusterPlatform platform.Platform, api
 	}
 	objs = append(objs, mf.ToObjects()...)
 
-	if err := renderObjects(objs); err != nil {
+	if err := renderObjects(render, objs); err != nil {
 		klog.ErrorS(err, "unable to render manifests")
 		return 1
 	}
@@ -397,15 +549,196 @@ func manageRendering(render RenderParams, clusterPlatform platform.Platform, api
 	return 0
 }
 
-func renderObjects(objs []client.Object) error {
-	for _, obj := range objs {
-		fmt.Printf("---\n")
-		if err := manifests.SerializeObject(obj, os.Stdout); err != nil {
-			return err
+// manageRenderingFromFile renders offline the objects the reconciliation loops would apply for the objects found in the file.
+func manageRenderingFromFile(render RenderParams, clusterPlatform platform.Platform, apiMf apimanifests.Manifests, rteMf rtemanifests.Manifests) int {
+	ctx := context.Background()
+
+	srcObjs, err := loadObjectsFromFile(render.From)
+	if err != nil {
+		klog.ErrorS(err, "unable to load objects", "path", render.From)
+		return 1
+	}
+
+	var nroObj *nropv1.NUMAResourcesOperator
+	var nrsObj *nropv1.NUMAResourcesScheduler
+	var kcKeys []client.ObjectKey
+	for _, obj := range srcObjs {
+		switch srcObj := obj.(type) {
+		case *nropv1.NUMAResourcesOperator:
+			nroObj = srcObj
+		case *nropv1.NUMAResourcesScheduler:
+			nrsObj = srcObj
+		case *machineconfigv1.KubeletConfig:
+			if clusterPlatform == platform.OpenShift {
+				kcKeys = append(kcKeys, client.ObjectKeyFromObject(srcObj))
+			}
+		case *corev1.ConfigMap:
+			if _, ok := srcObj.Labels[controllers.HypershiftKubeletConfigConfigMapLabel]; ok && clusterPlatform == platform.HyperShift {
+				kcKeys = append(kcKeys, client.ObjectKeyFromObject(srcObj))
+			}
+		}
+	}
+	if nroObj == nil && nrsObj == nil {
+		klog.Errorf("no NUMAResourcesOperator or NUMAResourcesScheduler objects found in %q", render.From)
+		return 1
+	}
+
+	cli := newReadOnlyClient(srcObjs...)
+	imgs := images.Data{
+		User:    render.Image.Exporter,
+		Builtin: images.SpecPath(),
+	}
+
+	var objs []client.Object
+	if nroObj != nil {
+		if nroObj.Name != objectnames.DefaultNUMAResourcesOperatorCrName {
+			klog.Errorf("incorrect NUMAResourcesOperator resource name: %s", nroObj.Name)
+			return 1
+		}
+
+		mf, err := renderRTEManifests(rteMf, render.Namespace, imgs)
+		if err != nil {
+			klog.ErrorS(err, "unable to render RTE manifests")
+			return 1
+		}
+
+		kcReconciler := controllers.KubeletConfigReconciler{
+			Client:    cli,
+			Scheme:    scheme,
+			Namespace: render.Namespace,
+			Platform:  clusterPlatform,
 		}
+		rendered := make(map[string]bool)
+		var cms []*corev1.ConfigMap
+		for _, kcKey := range kcKeys {
+			cm, err := kcReconciler.RenderConfigMap(ctx, nroObj, kcKey)
+			if err != nil {
+				klog.ErrorS(err, "unable to render RTE configuration", "kubeletconfig", kcKey.String())
+				return 1
+			}
+			if cm == nil || rendered[cm.Name] {
+				continue
+			}
+			rendered[cm.Name] = true
+			cms = append(cms, cm)
+			objs = append(objs, cm)
+		}
+
+		nroReconciler := controllers.NUMAResourcesOperatorReconciler{
+			Client:          cli,
+			Scheme:          scheme,
+			Platform:        clusterPlatform,
+			APIManifests:    apiMf,
+			RTEManifests:    mf,
+			Namespace:       render.Namespace,
+			Images:          imgs,
+			ImagePullPolicy: images.NullPolicy,
+		}
+		nroObjs, err := nroReconciler.RenderObjects(ctx, nroObj, cms)
+		if err != nil {
+			klog.ErrorS(err, "unable to render NUMAResourcesOperator objects")
+			return 1
+		}
+		objs = append(nroObjs, objs...)
 	}
 
-	return nil
+	if nrsObj != nil {
+		if nrsObj.Name != objectnames.DefaultNUMAResourcesSchedulerCrName {
+			klog.Errorf("incorrect NUMAResourcesScheduler resource name: %s", nrsObj.Name)
+			return 1
+		}
+
+		schedMf, err := schedmanifests.GetManifests(render.Namespace)
+		if err != nil {
+			klog.ErrorS(err, "unable to load the Scheduler manifests")
+			return 1
+		}
+
+		nrsReconciler := controllers.NUMAResourcesSchedulerReconciler{
+			Client:             cli,
+			Scheme:             scheme,
+			SchedulerManifests: schedMf,
+			Namespace:          render.Namespace,
+			AutodetectReplicas: controlplane.Defaults().NodeCount,
+			PFPStatusImage:     pfpStatusImage(imgs),
+		}
+		nrsObjs, err := nrsReconciler.RenderObjects(ctx, nrsObj)
+		if err != nil {
+			klog.ErrorS(err, "unable to render NUMAResourcesScheduler objects")
+			return 1
+		}
+		objs = append(objs, nrsObjs...)
+	}
+
+	if err := renderObjects(render, objs); err != nil {
+		klog.ErrorS(err, "unable to render manifests")
+		return 1
+	}
+	return 0
+}
+
+// loadObjectsFromFile decodes all the objects found in the given, possibly multi-document, YAML file.
+func loadObjectsFromFile(path string) ([]client.Object, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+	reader := k8syaml.NewYAMLReader(bufio.NewReader(fh))
+
+	var objs []client.Object
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		obj, _, err := decoder.Decode(doc, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		cobj, ok := obj.(client.Object)
+		if !ok {
+			return nil, fmt.Errorf("unsupported object %s", obj.GetObjectKind().GroupVersionKind().String())
+		}
+		objs = append(objs, cobj)
+	}
+}
+
+// renderObjects writes the objects in the requested format
+func renderObjects(render RenderParams, objs []client.Object) error {
+	format, err := intrender.ParseFormat(render.Format)
+	if err != nil {
+		return err
+	}
+	if format != intrender.FormatYAML && render.Dir == "" {
+		return fmt.Errorf("missing output directory for the %s format", format)
+	}
+
+	switch format {
+	case intrender.FormatHelm:
+		bi := version.GetBuildInfo()
+		return intrender.Helm(render.Dir, objs, intrender.HelmParams{
+			Name:        version.OperatorProgramName(),
+			Description: "NUMA-aware scheduling: the resource topology exporter and the secondary scheduler",
+			Version:     intrender.ChartVersion(bi.Version),
+			AppVersion:  bi.Version,
+			Namespace:   render.Namespace,
+		})
+	case intrender.FormatKustomize:
+		return intrender.Kustomize(render.Dir, objs, intrender.KustomizeParams{
+			Namespace: render.Namespace,
+		})
+	default:
+		return intrender.YAML(os.Stdout, objs)
+	}
 }
 
 // renderRTEManifests renders the reconciler manifests so they can be deployed on the cluster.
@@ -432,12 +765,83 @@ func renderRTEManifests(rteManifests rtemanifests.Manifests, namespace string, i
 	if err != nil {
 		return mf, err
 	}
+
+	rteupdate.ClusterRolePFPStatusRules(mf.ClusterRole)
+
+	err = rteupdate.DaemonSetHealthProbes(mf.DaemonSet)
+	if err != nil {
+		return mf, err
+	}
 	if mf.ConfigMap != nil {
-		rteupdate.DaemonSetHashAnnotation(mf.DaemonSet, hash.ConfigMapData(mf.ConfigMap))
+		cmHash, err := rteupdate.ConfigMapRestartHash(mf.ConfigMap)
+		if err != nil {
+			return mf, err
+		}
+		rteupdate.DaemonSetHashAnnotation(mf.DaemonSet, cmHash)
 	}
 	return mf, err
 }
 
+// pfpStatusImage returns the image to run the pod fingerprint status sidecar of the scheduler.
+// The sidecar runs the operator binary, so the user-provided RTE image is not suitable.
+func pfpStatusImage(imgs images.Data) string {
+	if imgs.Self != "" {
+		return imgs.Self
+	}
+	return imgs.Builtin
+}
+
+func servePFPStatus(dir string) int {
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		klog.ErrorS(err, "unable to get the client configuration")
+		return 1
+	}
+	cli, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		klog.ErrorS(err, "unable to create the kubernetes client")
+		return 1
+	}
+	err = pfpstatus.Serve(pfpstatus.Address(), dir, pfpstatus.CertsDir, pfpstatus.NewKubeAuthorizer(cli))
+	klog.ErrorS(err, "failed to serve the pod fingerprint status")
+	return 1
+}
+
+func manageGathering(dir string) int {
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		klog.ErrorS(err, "unable to get the client configuration")
+		return 1
+	}
+	cli, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		klog.ErrorS(err, "unable to create the client")
+		return 1
+	}
+	k8sCli, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		klog.ErrorS(err, "unable to create the kubernetes client")
+		return 1
+	}
+	namespace, ok := os.LookupEnv("NAMESPACE")
+	if !ok {
+		namespace = defaultNamespace
+	}
+
+	index, err := gather.Collect(&gather.Env{
+		Ctx:       context.Background(),
+		Cli:       cli,
+		K8sCli:    k8sCli,
+		Namespace: namespace,
+	}, dir)
+	if err != nil {
+		klog.ErrorS(err, "unable to save the diagnostics data", "dir", dir)
+		return 1
+	}
+	klog.InfoS("diagnostics data collected", "dir", dir, "nodes", len(index.Nodes), "errors", len(index.Errors))
+	return 0
+}
+
 func renderSchedulerManifests(schedManifests schedmanifests.Manifests, imageSpec string) (schedmanifests.Manifests, error) {
 	klog.InfoS("Updating scheduler manifests")
 	mf := schedManifests.Clone()
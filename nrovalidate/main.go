@@ -19,30 +19,45 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	securityv1 "github.com/openshift/api/security/v1"
 	machineconfigv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 
 	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
 
-	deployervalidator "github.com/k8stopologyawareschedwg/deployer/pkg/validator"
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
 
+	"github.com/openshift-kni/numaresources-operator/nrovalidate/monitor"
 	nrovalidator "github.com/openshift-kni/numaresources-operator/nrovalidate/validator"
 	"github.com/openshift-kni/numaresources-operator/pkg/version"
 )
 
+const (
+	exitCodeSuccess          = 0
+	exitCodeValidationFailed = 1
+	exitCodeError            = 2
+)
+
 var (
 	scheme = k8sruntime.NewScheme()
 )
@@ -54,40 +69,65 @@ func init() {
 	utilruntime.Must(nropv1.AddToScheme(scheme))
 	utilruntime.Must(machineconfigv1.Install(scheme))
 	utilruntime.Must(securityv1.Install(scheme))
+	utilruntime.Must(nrtv1alpha2.AddToScheme(scheme))
 }
 
 type ProgArgs struct {
 	Version     bool
 	Verbose     bool
 	Quiet       bool
-	JSON        bool
+	Format      string
 	Labels      string
+	FromDir     string
+	Serve       bool
+	ServeAddr   string
+	Interval    time.Duration
+	UseExec     bool
 	Validations sets.Set[string]
 }
 
+func (args ProgArgs) liveOptions() nrovalidator.LiveOptions {
+	return nrovalidator.LiveOptions{
+		PFPStatusExec: args.UseExec,
+	}
+}
+
 func main() {
 	parsedArgs, err := parseArgs(os.Args[1:]...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to parse args: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCodeError)
 	}
 
 	if parsedArgs.Version {
 		fmt.Println(version.ProgramName(), version.Get())
-		os.Exit(0)
+		os.Exit(exitCodeSuccess)
 	}
 
 	if len(parsedArgs.Validations) == 0 || parsedArgs.Validations.Has("help") {
 		fmt.Fprintf(os.Stderr, "available validators: %v\n", strings.Join(sets.List(nrovalidator.Available()), ","))
-		os.Exit(0)
+		os.Exit(exitCodeSuccess)
+	}
+
+	if parsedArgs.Serve {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		if err := serve(ctx, parsedArgs); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while serving the validation results: %v\n", err)
+			os.Exit(exitCodeError)
+		}
+		os.Exit(exitCodeSuccess)
 	}
 
 	ctx := context.Background()
 
-	err = validateCluster(ctx, parsedArgs)
+	succeeded, err := validateCluster(ctx, parsedArgs)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error while trying to validate cluster: %v\n", err)
-		os.Exit(2)
+		os.Exit(exitCodeError)
+	}
+	if !succeeded {
+		os.Exit(exitCodeValidationFailed)
 	}
 }
 
@@ -95,58 +135,176 @@ func parseArgs(args ...string) (ProgArgs, error) {
 	pArgs := ProgArgs{}
 
 	var validationsArg string
+	var jsonOutput bool
 
 	flags := flag.NewFlagSet(version.ProgramName(), flag.ExitOnError)
 
 	flags.BoolVar(&pArgs.Version, "version", false, "Output version and exit")
 	flags.BoolVar(&pArgs.Verbose, "verbose", false, "Verbose output")
-	flags.BoolVar(&pArgs.JSON, "json", false, "Output JSON, not free text")
+	flags.BoolVar(&jsonOutput, "json", false, "Output JSON, not free text. Shortcut for --format=json")
+	flags.StringVar(&pArgs.Format, "format", nrovalidator.FormatText, "Output format, one of: "+strings.Join(sets.List(nrovalidator.Formats()), ","))
 	flags.BoolVar(&pArgs.Quiet, "quiet", false, "Avoid all output. Overrides 'verbose'")
 	flags.StringVar(&validationsArg, "what", "all", "Validations to perform")
 	flags.StringVar(&pArgs.Labels, "labels", "", "Selector (label query) to filter on. e.g. -l key1=value1,key2=value2; autodetect with NRO if missing.")
+	flags.StringVar(&pArgs.FromDir, "from-dir", "", "Validate the data found in this must-gather directory instead of a live cluster.")
+	flags.BoolVar(&pArgs.Serve, "serve", false, "Validate the cluster periodically and serve the results as metrics and JSON over HTTP.")
+	flags.StringVar(&pArgs.ServeAddr, "serve-address", ":8080", "The address the results are served on. Requires 'serve'.")
+	flags.BoolVar(&pArgs.UseExec, "use-exec", false, "Read the scheduler cache status executing commands in the scheduler pods instead of using their HTTPS endpoint. The commands are executed anyway if the endpoint is not reachable, which requires the pods/exec permission.")
+	flags.DurationVar(&pArgs.Interval, "interval", 5*time.Minute, "The interval between validations. Requires 'serve'.")
 
 	err := flags.Parse(args)
 	if err != nil {
 		return pArgs, err
 	}
 
+	if jsonOutput {
+		pArgs.Format = nrovalidator.FormatJSON
+	}
+	if !nrovalidator.Formats().Has(pArgs.Format) {
+		return pArgs, fmt.Errorf("unknown format: %q", pArgs.Format)
+	}
+	if pArgs.Serve && pArgs.FromDir != "" {
+		return pArgs, fmt.Errorf("cannot serve the results of a must-gather directory")
+	}
+	if pArgs.Serve && pArgs.Interval <= 0 {
+		return pArgs, fmt.Errorf("invalid interval: %v", pArgs.Interval)
+	}
+
 	pArgs.Validations, err = nrovalidator.Requested(validationsArg)
 	return pArgs, err
 }
 
-func validateCluster(ctx context.Context, args ProgArgs) error {
-	cli, err := NewClientWithScheme(scheme)
-	if err != nil {
-		return err
-	}
-
+// validateCluster returns true if the validation succeeded. The error is set
+// if the validation could not be performed.
+func validateCluster(ctx context.Context, args ProgArgs) (bool, error) {
 	if !args.Quiet {
 		fmt.Fprintf(os.Stderr, "INFO>>>>: enabled validators: %s\n", strings.Join(sets.List(args.Validations), ","))
 	}
 
-	data, err := nrovalidator.Collect(ctx, cli, args.Labels, args.Validations)
+	data, err := collectData(ctx, args)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	rep, err := nrovalidator.Validate(data)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	if args.Quiet {
+		return rep.Succeeded, nil
+	}
+
+	return rep.Succeeded, printReport(rep, args.Format, args.Verbose)
+}
+
+func printReport(rep nrovalidator.Report, format string, verbose bool) error {
+	switch format {
+	case nrovalidator.FormatJSON:
+		return json.NewEncoder(os.Stdout).Encode(rep)
+	case nrovalidator.FormatJSONByNode:
+		return json.NewEncoder(os.Stdout).Encode(rep.ByNode())
+	case nrovalidator.FormatJUnit:
+		enc := xml.NewEncoder(os.Stdout)
+		enc.Indent("", "  ")
+		if _, err := fmt.Fprint(os.Stdout, xml.Header); err != nil {
+			return err
+		}
+		if err := enc.Encode(rep.JUnit(version.ProgramName())); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintln(os.Stdout)
+		return err
+	default:
+		printValidationResults(rep.Errors, verbose)
 		return nil
 	}
+}
+
+// serve validates the cluster periodically until the context is done. The data is read through
+// an informer cache, so the objects are watched rather than listed again on each run.
+func serve(ctx context.Context, args ProgArgs) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return err
+	}
+	informerCache, err := cache.New(cfg, cache.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	// the pod status collector selects the pods by node
+	err = informerCache.IndexField(ctx, &corev1.Pod{}, "spec.nodeName", func(obj client.Object) []string {
+		return []string{obj.(*corev1.Pod).Spec.NodeName}
+	})
+	if err != nil {
+		return err
+	}
+	cli, err := client.New(cfg, client.Options{
+		Scheme: scheme,
+		Cache: &client.CacheOptions{
+			Reader: informerCache,
+		},
+	})
+	if err != nil {
+		return err
+	}
 
-	if !args.JSON {
-		printValidationResults(rep.Errors, args.Verbose)
+	go func() {
+		if err := informerCache.Start(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING>: informer cache stopped: %v\n", err)
+		}
+	}()
+	if !informerCache.WaitForCacheSync(ctx) {
+		return fmt.Errorf("failed to sync the informer cache")
+	}
+
+	mon := monitor.New(func(ctx context.Context) (nrovalidator.Report, error) {
+		data, err := nrovalidator.CollectCached(ctx, cli, args.Labels, args.Validations, args.liveOptions())
+		if err != nil {
+			return nrovalidator.Report{}, err
+		}
+		return nrovalidator.Validate(data)
+	}, args.Interval)
+
+	srv := &http.Server{
+		Addr:              args.ServeAddr,
+		Handler:           mon.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Shutdown(context.Background())
+	}()
+
+	if !args.Quiet {
+		fmt.Fprintf(os.Stderr, "INFO>>>>: enabled validators: %s\n", strings.Join(sets.List(args.Validations), ","))
+		fmt.Fprintf(os.Stderr, "INFO>>>>: serving on %q every %v\n", args.ServeAddr, args.Interval)
+	}
+	go mon.Run(ctx)
+
+	err = srv.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
 		return nil
 	}
+	return err
+}
+
+func collectData(ctx context.Context, args ProgArgs) (nrovalidator.ValidatorData, error) {
+	if args.FromDir != "" {
+		if !args.Quiet {
+			fmt.Fprintf(os.Stderr, "INFO>>>>: loading data from %q\n", args.FromDir)
+		}
+		return nrovalidator.CollectFromDir(ctx, scheme, args.FromDir, args.Labels, args.Validations)
+	}
 
-	return json.NewEncoder(os.Stdout).Encode(rep)
+	cli, err := NewClientWithScheme(scheme)
+	if err != nil {
+		return nrovalidator.ValidatorData{}, err
+	}
+	return nrovalidator.Collect(ctx, cli, args.Labels, args.Validations, args.liveOptions())
 }
 
-func printValidationResults(items []deployervalidator.ValidationResult, verbose bool) {
+func printValidationResults(items []nrovalidator.Finding, verbose bool) {
 	if len(items) == 0 {
 		fmt.Printf("PASSED>>: cluster configuration looks ok!\n")
 		return
@@ -160,6 +318,9 @@ func printValidationResults(items []deployervalidator.ValidationResult, verbose
 
 	for idx, item := range items {
 		fmt.Printf("ERROR#%03d: %s\n", idx, item.String())
+		if item.Remediation != nil {
+			fmt.Printf("%s\n", item.Remediation.String())
+		}
 	}
 }
 
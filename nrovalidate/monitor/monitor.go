@@ -0,0 +1,214 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package monitor runs the validators periodically and exposes the outcome
+// as Prometheus metrics and as JSON over HTTP.
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	nrovalidator "github.com/openshift-kni/numaresources-operator/nrovalidate/validator"
+)
+
+const (
+	metricsNamespace = "nrovalidate"
+
+	labelValidator = "validator"
+	labelNode      = "node"
+)
+
+const (
+	PathMetrics = "/metrics"
+	PathReport  = "/report"
+	PathHealthz = "/healthz"
+)
+
+// RunFunc performs a validation pass. It returns an error if the validation could not be performed.
+type RunFunc func(ctx context.Context) (nrovalidator.Report, error)
+
+type Monitor struct {
+	run      RunFunc
+	interval time.Duration
+	registry *prometheus.Registry
+
+	validatorSucceeded *prometheus.GaugeVec
+	nodeSucceeded      *prometheus.GaugeVec
+	validationErrors   *prometheus.GaugeVec
+	lastRunTimestamp   prometheus.Gauge
+	runFailures        prometheus.Counter
+
+	lock    sync.RWMutex
+	report  *nrovalidator.Report
+	lastErr error
+}
+
+func New(run RunFunc, interval time.Duration) *Monitor {
+	mon := Monitor{
+		run:      run,
+		interval: interval,
+		registry: prometheus.NewRegistry(),
+		validatorSucceeded: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "validator_succeeded",
+			Help:      "1 if the validator reported no errors in the last run, 0 otherwise.",
+		}, []string{labelValidator}),
+		nodeSucceeded: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "node_succeeded",
+			Help:      "1 if the validator reported no errors for the node in the last run, 0 otherwise.",
+		}, []string{labelValidator, labelNode}),
+		validationErrors: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "validation_errors",
+			Help:      "Number of errors reported by the validator in the last run.",
+		}, []string{labelValidator}),
+		lastRunTimestamp: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "last_run_timestamp_seconds",
+			Help:      "Unix time of the last run which completed the validation.",
+		}),
+		runFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "run_failures_total",
+			Help:      "Number of runs which could not perform the validation.",
+		}),
+	}
+	mon.registry.MustRegister(
+		mon.validatorSucceeded,
+		mon.nodeSucceeded,
+		mon.validationErrors,
+		mon.lastRunTimestamp,
+		mon.runFailures,
+	)
+	return &mon
+}
+
+// Run performs a validation pass immediately and then once per interval, until the context is done.
+func (mon *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(mon.interval)
+	defer ticker.Stop()
+	for {
+		mon.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce performs a validation pass and updates the metrics. If the validation can't
+// be performed, the outcome of the previous run is kept.
+func (mon *Monitor) RunOnce(ctx context.Context) {
+	rep, err := mon.run(ctx)
+	mon.lock.Lock()
+	defer mon.lock.Unlock()
+	mon.lastErr = err
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING>: validation failed to run: %v\n", err)
+		mon.runFailures.Inc()
+		return
+	}
+	mon.report = &rep
+	mon.updateMetrics(rep, time.Now())
+}
+
+func (mon *Monitor) updateMetrics(rep nrovalidator.Report, now time.Time) {
+	// nodes may go away, so start from scratch on each run
+	mon.validatorSucceeded.Reset()
+	mon.nodeSucceeded.Reset()
+	mon.validationErrors.Reset()
+
+	for validatorName, results := range rep.Results {
+		mon.validatorSucceeded.WithLabelValues(validatorName).Set(boolToFloat(len(results) == 0))
+		mon.validationErrors.WithLabelValues(validatorName).Set(float64(len(results)))
+
+		failedNodes := make(map[string]bool)
+		for _, res := range results {
+			if res.Node != "" {
+				failedNodes[res.Node] = true
+			}
+		}
+		for _, nodeName := range rep.NodeNames {
+			mon.nodeSucceeded.WithLabelValues(validatorName, nodeName).Set(boolToFloat(!failedNodes[nodeName]))
+			delete(failedNodes, nodeName)
+		}
+		// errors may be reported about nodes not inspected, e.g. pods pending on non-TAS nodes
+		for nodeName := range failedNodes {
+			mon.nodeSucceeded.WithLabelValues(validatorName, nodeName).Set(0)
+		}
+	}
+	mon.lastRunTimestamp.Set(float64(now.Unix()))
+}
+
+// Handler serves the metrics, the last report as JSON and the health status.
+// The report is grouped by node if the format query parameter is json-by-node.
+func (mon *Monitor) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(PathMetrics, promhttp.HandlerFor(mon.registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc(PathReport, mon.serveReport)
+	mux.HandleFunc(PathHealthz, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return mux
+}
+
+func (mon *Monitor) serveReport(w http.ResponseWriter, req *http.Request) {
+	mon.lock.RLock()
+	defer mon.lock.RUnlock()
+
+	if mon.report == nil {
+		msg := "no validation run completed yet"
+		if mon.lastErr != nil {
+			msg += ": " + mon.lastErr.Error()
+		}
+		http.Error(w, msg, http.StatusServiceUnavailable)
+		return
+	}
+
+	var obj interface{}
+	switch format := req.URL.Query().Get("format"); format {
+	case "", nrovalidator.FormatJSON:
+		obj = mon.report
+	case nrovalidator.FormatJSONByNode:
+		obj = mon.report.ByNode()
+	default:
+		http.Error(w, fmt.Sprintf("unsupported format: %q", format), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(obj); err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING>: failed to send the report: %v\n", err)
+	}
+}
+
+func boolToFloat(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	deployervalidator "github.com/k8stopologyawareschedwg/deployer/pkg/validator"
+
+	nrovalidator "github.com/openshift-kni/numaresources-operator/nrovalidate/validator"
+)
+
+func TestMonitor(t *testing.T) {
+	podPending := nrovalidator.Finding{
+		ValidationResult: deployervalidator.ValidationResult{
+			Area:      deployervalidator.AreaKubelet,
+			Component: "pod",
+			Node:      "worker-1",
+			Setting:   "ns/pod",
+			Expected:  "Running",
+			Detected:  "Pending",
+		},
+		Validator: nrovalidator.ValidatorPodStatus,
+	}
+	rep := nrovalidator.Report{
+		Succeeded: false,
+		Errors:    []nrovalidator.Finding{podPending},
+		Results: map[string][]nrovalidator.Finding{
+			nrovalidator.ValidatorPodStatus:              {podPending},
+			nrovalidator.ValidatorNodeResourceTopologies: {},
+		},
+		NodeNames: []string{"worker-0", "worker-1"},
+	}
+
+	var runErr error
+	mon := New(func(_ context.Context) (nrovalidator.Report, error) {
+		return rep, runErr
+	}, time.Minute)
+	srv := httptest.NewServer(mon.Handler())
+	defer srv.Close()
+
+	runErr = errors.New("fake failure")
+	mon.RunOnce(context.Background())
+
+	code, body := httpGet(t, srv.URL+PathReport)
+	if code != http.StatusServiceUnavailable || !strings.Contains(body, "fake failure") {
+		t.Errorf("unexpected report response before the first run: %d %q", code, body)
+	}
+	_, body = httpGet(t, srv.URL+PathMetrics)
+	expectMetrics(t, body, "nrovalidate_run_failures_total 1")
+
+	runErr = nil
+	mon.RunOnce(context.Background())
+
+	code, body = httpGet(t, srv.URL+PathMetrics)
+	if code != http.StatusOK {
+		t.Fatalf("unexpected metrics response: %d %q", code, body)
+	}
+	expectMetrics(t, body,
+		`nrovalidate_validator_succeeded{validator="nrt"} 1`,
+		`nrovalidate_validator_succeeded{validator="podst"} 0`,
+		`nrovalidate_validation_errors{validator="podst"} 1`,
+		`nrovalidate_node_succeeded{node="worker-0",validator="podst"} 1`,
+		`nrovalidate_node_succeeded{node="worker-1",validator="podst"} 0`,
+		`nrovalidate_node_succeeded{node="worker-1",validator="nrt"} 1`,
+	)
+
+	code, body = httpGet(t, srv.URL+PathReport)
+	if code != http.StatusOK {
+		t.Fatalf("unexpected report response: %d %q", code, body)
+	}
+	got := nrovalidator.Report{}
+	if err := json.Unmarshal([]byte(body), &got); err != nil {
+		t.Fatalf("cannot decode the report: %v", err)
+	}
+	if got.Succeeded || len(got.Errors) != 1 || got.Errors[0].Node != "worker-1" {
+		t.Errorf("unexpected report: %+v", got)
+	}
+
+	code, body = httpGet(t, srv.URL+PathReport+"?format="+nrovalidator.FormatJSONByNode)
+	if code != http.StatusOK {
+		t.Fatalf("unexpected report response: %d %q", code, body)
+	}
+	gotByNode := nrovalidator.NodeReport{}
+	if err := json.Unmarshal([]byte(body), &gotByNode); err != nil {
+		t.Fatalf("cannot decode the report: %v", err)
+	}
+	if len(gotByNode.Nodes["worker-0"]) != 0 || len(gotByNode.Nodes["worker-1"]) != 1 {
+		t.Errorf("unexpected report by node: %+v", gotByNode)
+	}
+
+	code, _ = httpGet(t, srv.URL+PathReport+"?format="+nrovalidator.FormatJUnit)
+	if code != http.StatusBadRequest {
+		t.Errorf("unexpected response for unsupported format: %d", code)
+	}
+}
+
+func httpGet(t *testing.T, url string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("GET %s failed reading the body: %v", url, err)
+	}
+	return resp.StatusCode, string(data)
+}
+
+func expectMetrics(t *testing.T, body string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("missing metric %q in:\n%s", line, body)
+		}
+	}
+}
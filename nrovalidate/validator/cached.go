@@ -0,0 +1,51 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validator
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+)
+
+// CollectCached gathers the data for the requested validations from a live cluster like Collect,
+// but reads the NRT objects using the given client too. Meant to be used with a client backed by
+// an informer cache, to avoid listing all the objects again on each run.
+func CollectCached(ctx context.Context, cli client.Client, userLabels string, what sets.Set[string], opts LiveOptions) (ValidatorData, error) {
+	return collect(ctx, cli, cachedSource{liveSource{opts: opts}}, userLabels, what)
+}
+
+// cachedSource fetches the data from the cluster, reading the NRT objects using the controller-runtime client
+type cachedSource struct {
+	liveSource
+}
+
+func (cachedSource) nodeResourceTopologies(ctx context.Context, cli client.Client) (*nrtv1alpha2.NodeResourceTopologyList, bool, error) {
+	nrtList := nrtv1alpha2.NodeResourceTopologyList{}
+	err := cli.List(ctx, &nrtList)
+	if err != nil {
+		if meta.IsNoMatchError(err) || IsNodeResourceTopologyCRDMissing(err) {
+			return nil, true, nil
+		}
+		return nil, false, err
+	}
+	return &nrtList, false, nil
+}
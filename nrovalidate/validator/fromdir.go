@@ -0,0 +1,295 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validator
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/sets"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/apimachinery/pkg/version"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+	"github.com/k8stopologyawareschedwg/podfingerprint"
+)
+
+const (
+	// SchedCacheDumpDir is the name of the must-gather directories holding the scheduler cache
+	// state dumps (podfingerprint.Status JSON files), as found in the scheduler pods.
+	SchedCacheDumpDir = "pfpstatus"
+
+	nrtCRDName = "noderesourcetopologies.topology.node.k8s.io"
+)
+
+// CollectFromDir gathers the data for the requested validations from a must-gather
+// directory, instead of a live cluster. The directory is scanned recursively, and all
+// the YAML and JSON manifests of the kinds known to the given scheme are loaded, regardless
+// of the directory layout.
+func CollectFromDir(ctx context.Context, scheme *k8sruntime.Scheme, dir string, userLabels string, what sets.Set[string]) (ValidatorData, error) {
+	objs, err := LoadObjectsFromDir(scheme, dir)
+	if err != nil {
+		return ValidatorData{}, err
+	}
+	statuses, err := loadSchedCacheDumps(dir)
+	if err != nil {
+		return ValidatorData{}, err
+	}
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithIndex(&corev1.Pod{}, "spec.nodeName", func(obj client.Object) []string {
+			return []string{obj.(*corev1.Pod).Spec.NodeName}
+		}).
+		Build()
+
+	src := dirSource{
+		schedCacheStatuses: statuses,
+	}
+	return collect(ctx, cli, src, userLabels, what)
+}
+
+// LoadObjectsFromDir decodes all the objects known to the scheme from the manifests found in dir.
+// Lists are flattened. If the same object is found more than once, the last one found wins.
+func LoadObjectsFromDir(scheme *k8sruntime.Scheme, dir string) ([]client.Object, error) {
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+	objsByKey := make(map[string]client.Object)
+	var keys []string
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !isManifestFile(path) {
+			return nil
+		}
+		objs, err := decodeManifestFile(decoder, path)
+		if err != nil {
+			return err
+		}
+		for _, obj := range objs {
+			key := objectKey(obj)
+			if _, ok := objsByKey[key]; !ok {
+				keys = append(keys, key)
+			}
+			objsByKey[key] = obj
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	objs := make([]client.Object, 0, len(keys))
+	for _, key := range keys {
+		objs = append(objs, objsByKey[key])
+	}
+	return objs, nil
+}
+
+func isManifestFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
+
+func decodeManifestFile(decoder k8sruntime.Decoder, path string) ([]client.Object, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	var objs []client.Object
+	reader := k8syaml.NewYAMLReader(bufio.NewReader(fh))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %q: %w", path, err)
+		}
+		// must-gathers include plenty of data we don't care about, or can't decode; just skip it
+		objs = append(objs, decodeObjects(decoder, doc)...)
+	}
+}
+
+func decodeObjects(decoder k8sruntime.Decoder, data []byte) []client.Object {
+	obj, _, err := decoder.Decode(data, nil, nil)
+	if err != nil {
+		return nil
+	}
+	if !meta.IsListType(obj) {
+		cobj, ok := obj.(client.Object)
+		if !ok {
+			return nil
+		}
+		return []client.Object{cobj}
+	}
+
+	items, err := meta.ExtractList(obj)
+	if err != nil {
+		return nil
+	}
+	var objs []client.Object
+	for _, item := range items {
+		if unk, ok := item.(*k8sruntime.Unknown); ok {
+			objs = append(objs, decodeObjects(decoder, unk.Raw)...)
+			continue
+		}
+		if cobj, ok := item.(client.Object); ok {
+			objs = append(objs, cobj)
+		}
+	}
+	return objs
+}
+
+func objectKey(obj client.Object) string {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	return gvk.GroupKind().String() + "/" + obj.GetNamespace() + "/" + obj.GetName()
+}
+
+func loadSchedCacheDumps(dir string) ([]podfingerprint.Status, error) {
+	var statuses []podfingerprint.Status
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || filepath.Ext(path) != ".json" || !hasDirComponent(path, SchedCacheDumpDir) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var st podfingerprint.Status
+		if err := json.Unmarshal(data, &st); err != nil {
+			return fmt.Errorf("decoding scheduler cache dump %q: %w", path, err)
+		}
+		if st.NodeName == "" {
+			return nil // not a scheduler cache dump
+		}
+		statuses = append(statuses, st)
+		return nil
+	})
+	return statuses, err
+}
+
+func hasDirComponent(path, name string) bool {
+	for _, comp := range strings.Split(filepath.Dir(path), string(filepath.Separator)) {
+		if comp == name {
+			return true
+		}
+	}
+	return false
+}
+
+// dirSource provides the data from a must-gather directory
+type dirSource struct {
+	schedCacheStatuses []podfingerprint.Status
+}
+
+// serverVersion approximates the cluster version using the most recent kubelet version found,
+// which is the best we can do without the discovery endpoint.
+func (dirSource) serverVersion(ctx context.Context, cli client.Client) (*version.Info, error) {
+	nodeList := corev1.NodeList{}
+	if err := cli.List(ctx, &nodeList); err != nil {
+		return nil, err
+	}
+
+	var best *utilversion.Version
+	var gitVersion string
+	for idx := range nodeList.Items {
+		kubeletVersion := nodeList.Items[idx].Status.NodeInfo.KubeletVersion
+		ver, err := utilversion.ParseGeneric(kubeletVersion)
+		if err != nil {
+			continue
+		}
+		if best == nil || best.LessThan(ver) {
+			best = ver
+			gitVersion = kubeletVersion
+		}
+	}
+	if best == nil {
+		return nil, errors.New("cannot detect the cluster version: no node with valid kubelet version found")
+	}
+	return &version.Info{
+		Major:      fmt.Sprintf("%d", best.Major()),
+		Minor:      fmt.Sprintf("%d", best.Minor()),
+		GitVersion: gitVersion,
+	}, nil
+}
+
+func (dirSource) nodeResourceTopologies(ctx context.Context, cli client.Client) (*nrtv1alpha2.NodeResourceTopologyList, bool, error) {
+	nrtList := nrtv1alpha2.NodeResourceTopologyList{}
+	if err := cli.List(ctx, &nrtList); err != nil {
+		return nil, false, err
+	}
+	if len(nrtList.Items) > 0 {
+		return &nrtList, false, nil
+	}
+
+	crd := apiextensionsv1.CustomResourceDefinition{}
+	err := cli.Get(ctx, client.ObjectKey{Name: nrtCRDName}, &crd)
+	if err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			return nil, true, nil
+		}
+		return nil, false, err
+	}
+	return &nrtList, false, nil
+}
+
+func (ds dirSource) unsyncedSchedCaches(_ context.Context, _ client.Client, nodeNames []string) (map[string]sets.Set[string], error) {
+	wanted := sets.New[string](nodeNames...)
+	unsynced := make(map[string]sets.Set[string])
+	for _, st := range ds.schedCacheStatuses {
+		if !wanted.Has(st.NodeName) || st.FingerprintComputed == st.FingerprintExpected {
+			continue
+		}
+		detectedPods := unsynced[st.NodeName]
+		if detectedPods == nil {
+			detectedPods = sets.New[string]()
+		}
+		for _, nn := range st.Pods {
+			detectedPods.Insert(nn.String())
+		}
+		unsynced[st.NodeName] = detectedPods
+	}
+	return unsynced, nil
+}
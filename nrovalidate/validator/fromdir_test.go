@@ -0,0 +1,282 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+
+	mcov1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+
+	deployervalidator "github.com/k8stopologyawareschedwg/deployer/pkg/validator"
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+)
+
+func TestCollectFromDir(t *testing.T) {
+	dir := t.TempDir()
+	writeMustGatherFiles(t, dir, map[string]string{
+		"cluster-scoped-resources/core/nodes/worker-0.yaml":                                                    nodeWorker0,
+		"cluster-scoped-resources/core/nodes/worker-1.yaml":                                                    nodeWorker1,
+		"cluster-scoped-resources/machineconfiguration.openshift.io/machineconfigpools/worker-cnf.yaml":        mcpWorkerCNF,
+		"cluster-scoped-resources/machineconfiguration.openshift.io/kubeletconfigs/worker-cnf.yaml":            kcWorkerCNF,
+		"cluster-scoped-resources/nodetopology.openshift.io/numaresourcesoperators/numaresourcesoperator.yaml": nroInstance,
+		"cluster-scoped-resources/topology.node.k8s.io/noderesourcetopologies.yaml":                            nrtList,
+		"namespaces/openshift-numaresources/core/pods.yaml":                                                    podList,
+		"namespaces/openshift-numaresources/pfpstatus/secondary-scheduler-abcde/worker-0.json":                 pfpWorker0,
+		"namespaces/openshift-numaresources/pods/rte-xyz/rte/logs/current.log":                                 "this is not a manifest",
+		"namespaces/openshift-numaresources/core/events.yaml":                                                  "this is: [not valid",
+		"version": "numaresources-operator/must-gather",
+	})
+
+	what := sets.New[string](ValidatorNodeResourceTopologies, ValidatorPodStatus, ValidatorSchedCache, ValidatorKubeletConfig)
+	data, err := CollectFromDir(context.Background(), newTestScheme(t), dir, "", what)
+	if err != nil {
+		t.Fatalf("unexpected error collecting data: %v", err)
+	}
+
+	if got := sets.List(data.tasEnabledNodeNames); !reflect.DeepEqual(got, []string{"worker-0", "worker-1"}) {
+		t.Errorf("unexpected TAS enabled nodes: %v", got)
+	}
+	if data.versionInfo == nil || data.versionInfo.Major != "1" || data.versionInfo.Minor != "29" {
+		t.Errorf("unexpected version info: %+v", data.versionInfo)
+	}
+	if data.nrtCrdMissing || data.nrtList == nil || len(data.nrtList.Items) != 2 {
+		t.Errorf("unexpected NRT data: missing=%v list=%v", data.nrtCrdMissing, data.nrtList)
+	}
+	if len(data.kConfigs) != 2 || data.kConfigs["worker-0"].TopologyManagerPolicy != "single-numa-node" {
+		t.Errorf("unexpected kubelet configs: %v", data.kConfigs)
+	}
+
+	rep, err := Validate(data)
+	if err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	expectedPodStatus := deployervalidator.ValidationResult{
+		Area:      deployervalidator.AreaKubelet,
+		Component: "pod",
+		Node:      "worker-1",
+		Setting:   "openshift-numaresources/rte-bbbbb",
+		Expected:  "Running",
+		Detected:  "Pending",
+	}
+	expectedSchedCache := deployervalidator.ValidationResult{
+		Area:      deployervalidator.AreaCluster,
+		Component: "scheduler",
+		Node:      "worker-0",
+		Setting:   "cache",
+		Expected:  "clean",
+		Detected:  "unsync",
+	}
+	if !hasResult(validationResults(rep.Errors), expectedPodStatus) {
+		t.Errorf("missing pod status error in %v", rep.Errors)
+	}
+	if !hasResult(validationResults(rep.Errors), expectedSchedCache) {
+		t.Errorf("missing scheduler cache error in %v", rep.Errors)
+	}
+	for _, res := range rep.Errors {
+		if res.Component == "NodeResourceTopology" {
+			t.Errorf("unexpected NRT error: %v", res)
+		}
+	}
+}
+
+func TestCollectFromDirNRTCRDMissing(t *testing.T) {
+	dir := t.TempDir()
+	writeMustGatherFiles(t, dir, map[string]string{
+		"cluster-scoped-resources/core/nodes/worker-0.yaml": nodeWorker0,
+	})
+
+	what := sets.New[string](ValidatorNodeResourceTopologies)
+	data, err := CollectFromDir(context.Background(), newTestScheme(t), dir, "node-role.kubernetes.io/worker=", what)
+	if err != nil {
+		t.Fatalf("unexpected error collecting data: %v", err)
+	}
+	if !data.nrtCrdMissing {
+		t.Errorf("NRT CRD not detected as missing")
+	}
+}
+
+func validationResults(findings []Finding) []deployervalidator.ValidationResult {
+	var ret []deployervalidator.ValidationResult
+	for _, finding := range findings {
+		ret = append(ret, finding.ValidationResult)
+	}
+	return ret
+}
+
+func hasResult(results []deployervalidator.ValidationResult, expected deployervalidator.ValidationResult) bool {
+	for _, res := range results {
+		if reflect.DeepEqual(res, expected) {
+			return true
+		}
+	}
+	return false
+}
+
+func newTestScheme(t *testing.T) *k8sruntime.Scheme {
+	t.Helper()
+	scheme := k8sruntime.NewScheme()
+	for _, add := range []func(*k8sruntime.Scheme) error{
+		clientgoscheme.AddToScheme,
+		apiextensionsv1.AddToScheme,
+		nropv1.AddToScheme,
+		mcov1.Install,
+		nrtv1alpha2.AddToScheme,
+	} {
+		if err := add(scheme); err != nil {
+			t.Fatalf("cannot setup the scheme: %v", err)
+		}
+	}
+	return scheme
+}
+
+func writeMustGatherFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("creating %q: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("writing %q: %v", path, err)
+		}
+	}
+}
+
+const nodeWorker0 = `apiVersion: v1
+kind: Node
+metadata:
+  name: worker-0
+  labels:
+    node-role.kubernetes.io/worker: ""
+    node-role.kubernetes.io/worker-cnf: ""
+status:
+  nodeInfo:
+    kubeletVersion: v1.29.5+29c95f3
+`
+
+const nodeWorker1 = `apiVersion: v1
+kind: Node
+metadata:
+  name: worker-1
+  labels:
+    node-role.kubernetes.io/worker: ""
+    node-role.kubernetes.io/worker-cnf: ""
+status:
+  nodeInfo:
+    kubeletVersion: v1.29.5+29c95f3
+`
+
+const mcpWorkerCNF = `apiVersion: machineconfiguration.openshift.io/v1
+kind: MachineConfigPool
+metadata:
+  name: worker-cnf
+  labels:
+    machineconfiguration.openshift.io/role: worker-cnf
+spec:
+  nodeSelector:
+    matchLabels:
+      node-role.kubernetes.io/worker-cnf: ""
+`
+
+const kcWorkerCNF = `apiVersion: machineconfiguration.openshift.io/v1
+kind: KubeletConfig
+metadata:
+  name: worker-cnf
+spec:
+  machineConfigPoolSelector:
+    matchLabels:
+      machineconfiguration.openshift.io/role: worker-cnf
+  kubeletConfig:
+    cpuManagerPolicy: static
+    cpuManagerReconcilePeriod: 5s
+    memoryManagerPolicy: Static
+    topologyManagerPolicy: single-numa-node
+`
+
+const nroInstance = `apiVersion: nodetopology.openshift.io/v1
+kind: NUMAResourcesOperator
+metadata:
+  name: numaresourcesoperator
+spec:
+  nodeGroups:
+  - machineConfigPoolSelector:
+      matchLabels:
+        machineconfiguration.openshift.io/role: worker-cnf
+`
+
+const nrtList = `apiVersion: v1
+kind: List
+items:
+- apiVersion: topology.node.k8s.io/v1alpha2
+  kind: NodeResourceTopology
+  metadata:
+    name: worker-0
+  topologyPolicies: ["SingleNUMANodeContainerLevel"]
+- apiVersion: topology.node.k8s.io/v1alpha2
+  kind: NodeResourceTopology
+  metadata:
+    name: worker-1
+  topologyPolicies: ["SingleNUMANodeContainerLevel"]
+`
+
+const podList = `apiVersion: v1
+kind: PodList
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: rte-aaaaa
+    namespace: openshift-numaresources
+  spec:
+    nodeName: worker-0
+    containers:
+    - name: rte
+      image: quay.io/openshift-kni/numaresources-operator:test
+  status:
+    phase: Running
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: rte-bbbbb
+    namespace: openshift-numaresources
+  spec:
+    nodeName: worker-1
+    containers:
+    - name: rte
+      image: quay.io/openshift-kni/numaresources-operator:test
+  status:
+    phase: Pending
+`
+
+const pfpWorker0 = `{
+  "fingerprintExpected": "pfp0v001aaaaaaaaaaaaaaaa",
+  "fingerprintComputed": "pfp0v001bbbbbbbbbbbbbbbb",
+  "pods": [{"namespace": "default", "name": "foo"}],
+  "nodeName": "worker-0"
+}`
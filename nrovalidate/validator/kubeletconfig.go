@@ -40,6 +40,7 @@ func CollectKubeletConfig(ctx context.Context, cli client.Client, data *Validato
 	}
 
 	kConfigs := make(map[string]*kubeletconfigv1beta1.KubeletConfiguration)
+	kConfigNames := make(map[string]string)
 	for _, mcoKubeletConfig := range mcoKubeletConfigList.Items {
 		kubeletConfig, err := kubeletconfig.MCOKubeletConfToKubeletConf(&mcoKubeletConfig)
 		if err != nil {
@@ -68,11 +69,13 @@ func CollectKubeletConfig(ctx context.Context, cli client.Client, data *Validato
 					return fmt.Errorf("Found two KubeletConfigurations for node %q", nodeName)
 				}
 				kConfigs[nodeName] = kubeletConfig
+				kConfigNames[nodeName] = mcoKubeletConfig.Name
 			}
 		}
 	}
 
 	data.kConfigs = kConfigs
+	data.kConfigNames = kConfigNames
 	return nil
 }
 
@@ -18,13 +18,19 @@ package validator
 
 import (
 	"context"
+	"sort"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	mcov1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+
+	rtestate "github.com/openshift-kni/numaresources-operator/pkg/objectstate/rte"
 )
 
 func getNodeListFromMachineConfigPool(ctx context.Context, cli client.Client, mcp mcov1.MachineConfigPool) ([]corev1.Node, error) {
@@ -62,3 +68,52 @@ func getMachineConfigPoolListFromMCOKubeletConfig(ctx context.Context, cli clien
 	}
 	return result, nil
 }
+
+// findPoolsOfNodes returns the sorted names of the pools which include at least one of the given nodes
+func findPoolsOfNodes(ctx context.Context, cli client.Client, nodeNames sets.Set[string]) ([]string, error) {
+	mcps := &mcov1.MachineConfigPoolList{}
+	if err := cli.List(ctx, mcps); err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, mcp := range mcps.Items {
+		if mcp.Spec.NodeSelector == nil {
+			continue
+		}
+		nodes, err := getNodeListFromMachineConfigPool(ctx, cli, mcp)
+		if err != nil {
+			return nil, err
+		}
+		for _, nodeName := range getNodeNames(nodes) {
+			if nodeNames.Has(nodeName) {
+				result = append(result, mcp.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// getPoolNodeNames returns the names of the nodes of the given pool, which is a MachineConfigPool on OpenShift
+// and a NodePool on HyperShift, where the MachineConfigPools are not available.
+func getPoolNodeNames(ctx context.Context, cli client.Client, poolName string) (sets.Set[string], error) {
+	mcp := mcov1.MachineConfigPool{}
+	err := cli.Get(ctx, client.ObjectKey{Name: poolName}, &mcp)
+	if err == nil {
+		nodes, err := getNodeListFromMachineConfigPool(ctx, cli, mcp)
+		if err != nil {
+			return nil, err
+		}
+		return sets.New[string](getNodeNames(nodes)...), nil
+	}
+	if !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		return nil, err
+	}
+
+	nodeList := corev1.NodeList{}
+	if err := cli.List(ctx, &nodeList, client.MatchingLabels{rtestate.HyperShiftNodePoolLabel: poolName}); err != nil {
+		return nil, err
+	}
+	return sets.New[string](getNodeNames(nodeList.Items)...), nil
+}
@@ -27,6 +27,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
 	topologyclientset "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned"
 
 	deployervalidator "github.com/k8stopologyawareschedwg/deployer/pkg/validator"
@@ -36,23 +37,34 @@ const (
 	ValidatorNodeResourceTopologies = "nrt"
 )
 
-func CollectNodeResourceTopologies(ctx context.Context, _ client.Client, data *ValidatorData) error {
-	cli, err := getTopologyClient()
+func CollectNodeResourceTopologies(ctx context.Context, cli client.Client, data *ValidatorData) error {
+	nrtList, crdMissing, err := data.src.nodeResourceTopologies(ctx, cli)
 	if err != nil {
 		return err
 	}
+	if crdMissing {
+		data.nrtCrdMissing = true
+		return nil
+	}
+
+	data.nrtList = nrtList
+	return nil
+}
+
+func (liveSource) nodeResourceTopologies(ctx context.Context, _ client.Client) (*nrtv1alpha2.NodeResourceTopologyList, bool, error) {
+	cli, err := getTopologyClient()
+	if err != nil {
+		return nil, false, err
+	}
 
 	nrtList, err := cli.TopologyV1alpha2().NodeResourceTopologies().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		if IsNodeResourceTopologyCRDMissing(err) {
-			data.nrtCrdMissing = true
-			return nil
+			return nil, true, nil
 		}
-		return err
+		return nil, false, err
 	}
-
-	data.nrtList = nrtList
-	return nil
+	return nrtList, false, nil
 }
 
 func ValidateNodeResourceTopologies(data ValidatorData) ([]deployervalidator.ValidationResult, error) {
@@ -0,0 +1,392 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/flagcodec"
+	k8swgobjupdate "github.com/k8stopologyawareschedwg/deployer/pkg/objectupdate"
+	deployervalidator "github.com/k8stopologyawareschedwg/deployer/pkg/validator"
+	mcov1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	nodegroupv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1/helper/nodegroup"
+	"github.com/openshift-kni/numaresources-operator/internal/dangling"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
+	rteupdate "github.com/openshift-kni/numaresources-operator/pkg/objectupdate/rte"
+)
+
+const (
+	ValidatorOperator = "nro"
+)
+
+const (
+	componentOperator = "NUMAResourcesOperator"
+	componentRTE      = "RTE"
+
+	flagAbsent = "absent"
+)
+
+// operatorState holds the operator objects, as found in the cluster
+type operatorState struct {
+	instance *nropv1.NUMAResourcesOperator
+	// suggestedPools holds the pools of the TAS enabled nodes, set only if instance is missing
+	suggestedPools         []string
+	nodeGroups             []nodeGroupState
+	danglingDaemonSets     []string
+	danglingMachineConfigs []string
+}
+
+type nodeGroupState struct {
+	config nropv1.NodeGroupConfig
+	// pools is empty if the node group does not resolve to any pool
+	pools []poolState
+}
+
+type poolState struct {
+	name string
+	// daemonSetName is the name the RTE DaemonSet of the pool is expected to have
+	daemonSetName string
+	// daemonSet is nil if missing
+	daemonSet *appsv1.DaemonSet
+	owned     bool
+	nodeNames []string
+	// rtePodsReady tells, per node, if the RTE pod running there is ready
+	rtePodsReady map[string]bool
+}
+
+func CollectOperator(ctx context.Context, cli client.Client, data *ValidatorData) error {
+	instance := nropv1.NUMAResourcesOperator{}
+	err := cli.Get(ctx, client.ObjectKey{Name: objectnames.DefaultNUMAResourcesOperatorCrName}, &instance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			pools, err := findPoolsOfNodes(ctx, cli, data.tasEnabledNodeNames)
+			if err != nil {
+				return err
+			}
+			data.operator = &operatorState{
+				suggestedPools: pools,
+			}
+			return nil
+		}
+		return err
+	}
+
+	mcps := mcov1.MachineConfigPoolList{}
+	if err := cli.List(ctx, &mcps); err != nil {
+		return err
+	}
+
+	dsList := appsv1.DaemonSetList{}
+	if err := cli.List(ctx, &dsList); err != nil {
+		return err
+	}
+
+	state := operatorState{
+		instance: &instance,
+	}
+
+	var trees []nodegroupv1.Tree
+	for idx := range instance.Spec.NodeGroups {
+		ng := instance.Spec.NodeGroups[idx]
+		ngState := nodeGroupState{
+			config: ng.NormalizeConfig(),
+		}
+
+		// resolve each node group on its own, to report all the ones which don't resolve
+		ngTrees, err := nodegroupv1.FindTreesOpenshift(&mcps, []nropv1.NodeGroup{ng})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "INFO>>>>: %v\n", err)
+		}
+		trees = append(trees, ngTrees...)
+
+		for _, tree := range ngTrees {
+			for _, mcp := range tree.MachineConfigPools {
+				pool, err := collectPoolState(ctx, cli, &instance, *mcp, dsList.Items)
+				if err != nil {
+					return err
+				}
+				ngState.pools = append(ngState.pools, pool)
+			}
+		}
+		state.nodeGroups = append(state.nodeGroups, ngState)
+	}
+
+	danglingDSs, err := dangling.DaemonSets(cli, ctx, &instance, trees)
+	if err != nil {
+		return err
+	}
+	for _, ds := range danglingDSs {
+		state.danglingDaemonSets = append(state.danglingDaemonSets, ds.Namespace+"/"+ds.Name)
+	}
+
+	danglingMCs, err := dangling.MachineConfigs(cli, ctx, &instance, trees)
+	if err != nil {
+		return err
+	}
+	for _, mc := range danglingMCs {
+		state.danglingMachineConfigs = append(state.danglingMachineConfigs, mc.Name)
+	}
+
+	data.operator = &state
+	return nil
+}
+
+func collectPoolState(ctx context.Context, cli client.Client, instance *nropv1.NUMAResourcesOperator, mcp mcov1.MachineConfigPool, daemonSets []appsv1.DaemonSet) (poolState, error) {
+	pool := poolState{
+		name:          mcp.Name,
+		daemonSetName: objectnames.GetComponentName(instance.Name, mcp.Name),
+		rtePodsReady:  make(map[string]bool),
+	}
+
+	nodes, err := getNodeListFromMachineConfigPool(ctx, cli, mcp)
+	if err != nil {
+		return pool, err
+	}
+	pool.nodeNames = getNodeNames(nodes)
+	sort.Strings(pool.nodeNames)
+
+	for idx := range daemonSets {
+		ds := &daemonSets[idx]
+		if ds.Name != pool.daemonSetName {
+			continue
+		}
+		pool.daemonSet = ds
+		pool.owned = isOwnedByUID(ds, instance)
+		break
+	}
+	if pool.daemonSet == nil {
+		return pool, nil
+	}
+
+	sel, err := metav1.LabelSelectorAsSelector(pool.daemonSet.Spec.Selector)
+	if err != nil {
+		return pool, err
+	}
+	podList := corev1.PodList{}
+	if err := cli.List(ctx, &podList, &client.ListOptions{Namespace: pool.daemonSet.Namespace, LabelSelector: sel}); err != nil {
+		return pool, err
+	}
+	for idx := range podList.Items {
+		pod := &podList.Items[idx]
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		pool.rtePodsReady[pod.Spec.NodeName] = pool.rtePodsReady[pod.Spec.NodeName] || isPodReady(pod)
+	}
+	return pool, nil
+}
+
+// ValidateOperator checks the operator objects match the NUMAResourcesOperator spec:
+// each node group resolves to pools, each pool has its RTE DaemonSet configured as the
+// node group config requires, the RTE pods run and are ready on all the pool nodes,
+// and no objects are left over from node groups which were removed.
+func ValidateOperator(data ValidatorData) ([]deployervalidator.ValidationResult, error) {
+	var ret []deployervalidator.ValidationResult
+	if data.operator == nil {
+		return ret, nil
+	}
+	if data.operator.instance == nil {
+		ret = append(ret, deployervalidator.ValidationResult{
+			Area:      deployervalidator.AreaCluster,
+			Component: componentOperator,
+			Setting:   "installed",
+			Expected:  "true",
+			Detected:  "false",
+		})
+		return ret, nil
+	}
+
+	for idx, ngState := range data.operator.nodeGroups {
+		if len(ngState.pools) == 0 {
+			ret = append(ret, deployervalidator.ValidationResult{
+				Area:      deployervalidator.AreaCluster,
+				Component: componentOperator,
+				Setting:   fmt.Sprintf("nodeGroups[%d] pools", idx),
+				Expected:  "> 0",
+				Detected:  "0",
+			})
+			continue
+		}
+		for _, pool := range ngState.pools {
+			res, err := validatePool(pool, ngState.config)
+			if err != nil {
+				return ret, err
+			}
+			ret = append(ret, res...)
+		}
+	}
+
+	for _, dsName := range data.operator.danglingDaemonSets {
+		ret = append(ret, deployervalidator.ValidationResult{
+			Area:      deployervalidator.AreaCluster,
+			Component: componentOperator,
+			Setting:   "DaemonSet " + dsName,
+			Expected:  "deleted",
+			Detected:  "dangling",
+		})
+	}
+	for _, mcName := range data.operator.danglingMachineConfigs {
+		ret = append(ret, deployervalidator.ValidationResult{
+			Area:      deployervalidator.AreaCluster,
+			Component: componentOperator,
+			Setting:   "MachineConfig " + mcName,
+			Expected:  "deleted",
+			Detected:  "dangling",
+		})
+	}
+	return ret, nil
+}
+
+func validatePool(pool poolState, conf nropv1.NodeGroupConfig) ([]deployervalidator.ValidationResult, error) {
+	var ret []deployervalidator.ValidationResult
+	dsSetting := fmt.Sprintf("pool %s DaemonSet %s", pool.name, pool.daemonSetName)
+	if pool.daemonSet == nil {
+		ret = append(ret, deployervalidator.ValidationResult{
+			Area:      deployervalidator.AreaCluster,
+			Component: componentRTE,
+			Setting:   dsSetting,
+			Expected:  "present",
+			Detected:  "missing",
+		})
+		return ret, nil
+	}
+	if !pool.owned {
+		ret = append(ret, deployervalidator.ValidationResult{
+			Area:      deployervalidator.AreaCluster,
+			Component: componentRTE,
+			Setting:   dsSetting + " owner",
+			Expected:  componentOperator,
+			Detected:  "other",
+		})
+	}
+
+	expectedDS := pool.daemonSet.DeepCopy()
+	if err := rteupdate.DaemonSetArgs(expectedDS, conf); err != nil {
+		// not one of our DaemonSets: no point in checking the pods
+		ret = append(ret, deployervalidator.ValidationResult{
+			Area:      deployervalidator.AreaCluster,
+			Component: componentRTE,
+			Setting:   dsSetting + " container",
+			Expected:  rteupdate.MainContainerName,
+			Detected:  "missing",
+		})
+		return ret, nil
+	}
+	ret = append(ret, compareRTEArgs(dsSetting, getRTEArgs(pool.daemonSet), getRTEArgs(expectedDS))...)
+
+	for _, nodeName := range pool.nodeNames {
+		ready, ok := pool.rtePodsReady[nodeName]
+		if ready {
+			continue
+		}
+		detected := "not ready"
+		if !ok {
+			detected = "missing"
+		}
+		ret = append(ret, deployervalidator.ValidationResult{
+			Area:      deployervalidator.AreaKubelet,
+			Component: componentRTE,
+			Node:      nodeName,
+			Setting:   "pod",
+			Expected:  "ready",
+			Detected:  detected,
+		})
+	}
+	return ret, nil
+}
+
+func getRTEArgs(ds *appsv1.DaemonSet) map[string]string {
+	ret := make(map[string]string)
+	cnt := k8swgobjupdate.FindContainerByName(ds.Spec.Template.Spec.Containers, rteupdate.MainContainerName)
+	if cnt == nil {
+		return ret
+	}
+	flags := flagcodec.ParseArgvKeyValue(cnt.Args, flagcodec.WithFlagNormalization)
+	for _, arg := range flags.Args() {
+		name, _, _ := strings.Cut(arg, "=")
+		ret[name] = arg
+	}
+	return ret
+}
+
+func compareRTEArgs(dsSetting string, detected, expected map[string]string) []deployervalidator.ValidationResult {
+	names := make(map[string]struct{})
+	for name := range detected {
+		names[name] = struct{}{}
+	}
+	for name := range expected {
+		names[name] = struct{}{}
+	}
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+
+	var ret []deployervalidator.ValidationResult
+	for _, name := range sortedNames {
+		exp, ok := expected[name]
+		if !ok {
+			exp = flagAbsent
+		}
+		det, ok := detected[name]
+		if !ok {
+			det = flagAbsent
+		}
+		if exp == det {
+			continue
+		}
+		ret = append(ret, deployervalidator.ValidationResult{
+			Area:      deployervalidator.AreaCluster,
+			Component: componentRTE,
+			Setting:   dsSetting + " arg " + name,
+			Expected:  exp,
+			Detected:  det,
+		})
+	}
+	return ret
+}
+
+func isOwnedByUID(obj metav1.Object, owner metav1.Object) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == owner.GetUID() {
+			return true
+		}
+	}
+	return false
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
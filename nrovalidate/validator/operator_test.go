@@ -0,0 +1,406 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+
+	deployervalidator "github.com/k8stopologyawareschedwg/deployer/pkg/validator"
+	mcov1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	rteupdate "github.com/openshift-kni/numaresources-operator/pkg/objectupdate/rte"
+)
+
+const testNROUID = types.UID("a8a2d8f3-5d7c-4d0c-9d0e-8f0e6d7c6b5a")
+
+func TestValidateOperator(t *testing.T) {
+	defaultConf := nropv1.DefaultNodeGroupConfig()
+	goodDS := makeRTEDaemonSet(t, "numaresourcesoperator-worker-cnf", &defaultConf)
+
+	slowConf := nropv1.DefaultNodeGroupConfig()
+	slowConf.InfoRefreshPeriod = &metav1.Duration{Duration: 30 * time.Second}
+
+	readyPool := func() poolState {
+		return poolState{
+			name:          "worker-cnf",
+			daemonSetName: "numaresourcesoperator-worker-cnf",
+			daemonSet:     goodDS,
+			owned:         true,
+			nodeNames:     []string{"worker-0", "worker-1"},
+			rtePodsReady:  map[string]bool{"worker-0": true, "worker-1": true},
+		}
+	}
+	dsSetting := "pool worker-cnf DaemonSet numaresourcesoperator-worker-cnf"
+
+	testCases := []struct {
+		name     string
+		state    *operatorState
+		expected []deployervalidator.ValidationResult
+	}{
+		{
+			name: "not requested",
+		},
+		{
+			name:  "operator not installed",
+			state: &operatorState{},
+			expected: []deployervalidator.ValidationResult{
+				{
+					Area:      deployervalidator.AreaCluster,
+					Component: componentOperator,
+					Setting:   "installed",
+					Expected:  "true",
+					Detected:  "false",
+				},
+			},
+		},
+		{
+			name: "all good",
+			state: &operatorState{
+				instance: &nropv1.NUMAResourcesOperator{},
+				nodeGroups: []nodeGroupState{
+					{config: defaultConf, pools: []poolState{readyPool()}},
+				},
+			},
+		},
+		{
+			name: "node group without pools",
+			state: &operatorState{
+				instance: &nropv1.NUMAResourcesOperator{},
+				nodeGroups: []nodeGroupState{
+					{config: defaultConf, pools: []poolState{readyPool()}},
+					{config: defaultConf},
+				},
+			},
+			expected: []deployervalidator.ValidationResult{
+				{
+					Area:      deployervalidator.AreaCluster,
+					Component: componentOperator,
+					Setting:   "nodeGroups[1] pools",
+					Expected:  "> 0",
+					Detected:  "0",
+				},
+			},
+		},
+		{
+			name: "stale refresh period",
+			state: &operatorState{
+				instance: &nropv1.NUMAResourcesOperator{},
+				nodeGroups: []nodeGroupState{
+					{config: slowConf, pools: []poolState{readyPool()}},
+				},
+			},
+			expected: []deployervalidator.ValidationResult{
+				{
+					Area:      deployervalidator.AreaCluster,
+					Component: componentRTE,
+					Setting:   dsSetting + " arg --sleep-interval",
+					Expected:  "--sleep-interval=30s",
+					Detected:  "--sleep-interval=10s",
+				},
+			},
+		},
+		{
+			name: "missing DaemonSet",
+			state: &operatorState{
+				instance: &nropv1.NUMAResourcesOperator{},
+				nodeGroups: []nodeGroupState{
+					{
+						config: defaultConf,
+						pools: []poolState{
+							{
+								name:          "worker-cnf",
+								daemonSetName: "numaresourcesoperator-worker-cnf",
+								nodeNames:     []string{"worker-0"},
+							},
+						},
+					},
+				},
+			},
+			expected: []deployervalidator.ValidationResult{
+				{
+					Area:      deployervalidator.AreaCluster,
+					Component: componentRTE,
+					Setting:   dsSetting,
+					Expected:  "present",
+					Detected:  "missing",
+				},
+			},
+		},
+		{
+			name: "foreign DaemonSet and pods not ready",
+			state: &operatorState{
+				instance: &nropv1.NUMAResourcesOperator{},
+				nodeGroups: []nodeGroupState{
+					{
+						config: defaultConf,
+						pools: []poolState{
+							{
+								name:          "worker-cnf",
+								daemonSetName: "numaresourcesoperator-worker-cnf",
+								daemonSet:     goodDS,
+								nodeNames:     []string{"worker-0", "worker-1"},
+								rtePodsReady:  map[string]bool{"worker-0": false},
+							},
+						},
+					},
+				},
+			},
+			expected: []deployervalidator.ValidationResult{
+				{
+					Area:      deployervalidator.AreaCluster,
+					Component: componentRTE,
+					Setting:   dsSetting + " owner",
+					Expected:  componentOperator,
+					Detected:  "other",
+				},
+				{
+					Area:      deployervalidator.AreaKubelet,
+					Component: componentRTE,
+					Node:      "worker-0",
+					Setting:   "pod",
+					Expected:  "ready",
+					Detected:  "not ready",
+				},
+				{
+					Area:      deployervalidator.AreaKubelet,
+					Component: componentRTE,
+					Node:      "worker-1",
+					Setting:   "pod",
+					Expected:  "ready",
+					Detected:  "missing",
+				},
+			},
+		},
+		{
+			name: "dangling objects",
+			state: &operatorState{
+				instance:               &nropv1.NUMAResourcesOperator{},
+				danglingDaemonSets:     []string{"openshift-numaresources/numaresourcesoperator-worker-old"},
+				danglingMachineConfigs: []string{"51-numaresourcesoperator-worker-old"},
+			},
+			expected: []deployervalidator.ValidationResult{
+				{
+					Area:      deployervalidator.AreaCluster,
+					Component: componentOperator,
+					Setting:   "DaemonSet openshift-numaresources/numaresourcesoperator-worker-old",
+					Expected:  "deleted",
+					Detected:  "dangling",
+				},
+				{
+					Area:      deployervalidator.AreaCluster,
+					Component: componentOperator,
+					Setting:   "MachineConfig 51-numaresourcesoperator-worker-old",
+					Expected:  "deleted",
+					Detected:  "dangling",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ValidateOperator(ValidatorData{operator: tc.state})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tc.expected) || (len(got) > 0 && !reflect.DeepEqual(got, tc.expected)) {
+				t.Errorf("got=%#v expected=%#v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestCollectOperator(t *testing.T) {
+	defaultConf := nropv1.DefaultNodeGroupConfig()
+	ds := makeRTEDaemonSet(t, "numaresourcesoperator-worker-cnf", &defaultConf)
+	danglingDS := makeRTEDaemonSet(t, "numaresourcesoperator-worker-old", &defaultConf)
+	foreignDS := makeRTEDaemonSet(t, "someone-else", &defaultConf)
+	foreignDS.OwnerReferences = nil
+	danglingMC := mcov1.MachineConfig{
+		TypeMeta: metav1.TypeMeta{APIVersion: "machineconfiguration.openshift.io/v1", Kind: "MachineConfig"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "51-numaresourcesoperator-worker-old",
+			OwnerReferences: []metav1.OwnerReference{nroOwnerRef()},
+		},
+	}
+
+	dir := t.TempDir()
+	writeMustGatherFiles(t, dir, map[string]string{
+		"cluster-scoped-resources/core/nodes/worker-0.yaml":                                                    nodeWorker0,
+		"cluster-scoped-resources/core/nodes/worker-1.yaml":                                                    nodeWorker1,
+		"cluster-scoped-resources/machineconfiguration.openshift.io/machineconfigpools/worker-cnf.yaml":        mcpWorkerCNF,
+		"cluster-scoped-resources/machineconfiguration.openshift.io/machineconfigs/51-worker-old.yaml":         toYAML(t, danglingMC),
+		"cluster-scoped-resources/nodetopology.openshift.io/numaresourcesoperators/numaresourcesoperator.yaml": nroInstanceWithUID,
+		"namespaces/openshift-numaresources/apps/daemonsets/worker-cnf.yaml":                                   toYAML(t, ds),
+		"namespaces/openshift-numaresources/apps/daemonsets/worker-old.yaml":                                   toYAML(t, danglingDS),
+		"namespaces/openshift-numaresources/apps/daemonsets/someone-else.yaml":                                 toYAML(t, foreignDS),
+		"namespaces/openshift-numaresources/core/pods.yaml":                                                    rtePodList,
+	})
+
+	what := sets.New[string](ValidatorOperator)
+	data, err := CollectFromDir(context.Background(), newTestScheme(t), dir, "", what)
+	if err != nil {
+		t.Fatalf("unexpected error collecting data: %v", err)
+	}
+
+	rep, err := Validate(data)
+	if err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+	expected := []deployervalidator.ValidationResult{
+		{
+			Area:      deployervalidator.AreaKubelet,
+			Component: componentRTE,
+			Node:      "worker-1",
+			Setting:   "pod",
+			Expected:  "ready",
+			Detected:  "not ready",
+		},
+		{
+			Area:      deployervalidator.AreaCluster,
+			Component: componentOperator,
+			Setting:   "DaemonSet openshift-numaresources/numaresourcesoperator-worker-old",
+			Expected:  "deleted",
+			Detected:  "dangling",
+		},
+		{
+			Area:      deployervalidator.AreaCluster,
+			Component: componentOperator,
+			Setting:   "MachineConfig 51-numaresourcesoperator-worker-old",
+			Expected:  "deleted",
+			Detected:  "dangling",
+		},
+	}
+	if got := validationResults(rep.Errors); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got=%#v expected=%#v", got, expected)
+	}
+}
+
+func makeRTEDaemonSet(t *testing.T, name string, conf *nropv1.NodeGroupConfig) *appsv1.DaemonSet {
+	t.Helper()
+	ds := &appsv1.DaemonSet{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "openshift-numaresources",
+			OwnerReferences: []metav1.OwnerReference{nroOwnerRef()},
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"name": name},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"name": name},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  rteupdate.MainContainerName,
+							Image: "quay.io/openshift-kni/numaresources-operator:test",
+							Args:  []string{"--sysfs=/host-sys", "--podresources-socket=unix:///host-podresources/kubelet.sock"},
+						},
+					},
+				},
+			},
+		},
+	}
+	if err := rteupdate.DaemonSetArgs(ds, *conf); err != nil {
+		t.Fatalf("cannot set the DaemonSet args: %v", err)
+	}
+	return ds
+}
+
+func nroOwnerRef() metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: "nodetopology.openshift.io/v1",
+		Kind:       "NUMAResourcesOperator",
+		Name:       "numaresourcesoperator",
+		UID:        testNROUID,
+	}
+}
+
+func toYAML(t *testing.T, obj any) string {
+	t.Helper()
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		t.Fatalf("cannot marshal %v: %v", obj, err)
+	}
+	return string(data)
+}
+
+const nroInstanceWithUID = `apiVersion: nodetopology.openshift.io/v1
+kind: NUMAResourcesOperator
+metadata:
+  name: numaresourcesoperator
+  uid: a8a2d8f3-5d7c-4d0c-9d0e-8f0e6d7c6b5a
+spec:
+  nodeGroups:
+  - machineConfigPoolSelector:
+      matchLabels:
+        machineconfiguration.openshift.io/role: worker-cnf
+`
+
+const rtePodList = `apiVersion: v1
+kind: PodList
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: numaresourcesoperator-worker-cnf-aaaaa
+    namespace: openshift-numaresources
+    labels:
+      name: numaresourcesoperator-worker-cnf
+  spec:
+    nodeName: worker-0
+    containers:
+    - name: resource-topology-exporter
+      image: quay.io/openshift-kni/numaresources-operator:test
+  status:
+    phase: Running
+    conditions:
+    - type: Ready
+      status: "True"
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: numaresourcesoperator-worker-cnf-bbbbb
+    namespace: openshift-numaresources
+    labels:
+      name: numaresourcesoperator-worker-cnf
+  spec:
+    nodeName: worker-1
+    containers:
+    - name: resource-topology-exporter
+      image: quay.io/openshift-kni/numaresources-operator:test
+  status:
+    phase: Running
+    conditions:
+    - type: Ready
+      status: "False"
+`
@@ -0,0 +1,259 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	deployervalidator "github.com/k8stopologyawareschedwg/deployer/pkg/validator"
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	intnrt "github.com/openshift-kni/numaresources-operator/internal/noderesourcetopology"
+	"github.com/openshift-kni/numaresources-operator/internal/resourcelist"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
+)
+
+const (
+	ValidatorPlacement = "placement"
+)
+
+const (
+	componentPlacement = "pod placement"
+
+	// set by the kubelet when the topology manager rejects a pod at admission time
+	podReasonTopologyAffinityError = "TopologyAffinityError"
+
+	// minPendingAge is how long a pod must be unschedulable before being reported,
+	// to give the scheduler the time to retry once the resources are released.
+	minPendingAge = time.Minute
+)
+
+// misplacedPod is a pod which the NUMA-aware scheduler could not place,
+// or which the kubelet rejected because of its topology affinity
+type misplacedPod struct {
+	namespace string
+	name      string
+	nodeName  string
+	phase     corev1.PodPhase
+	reason    string
+	request   corev1.ResourceList
+}
+
+type placementData struct {
+	schedulerName string
+	pods          []misplacedPod
+	nrts          map[string]nrtv1alpha2.NodeResourceTopology
+}
+
+func CollectPlacement(ctx context.Context, cli client.Client, data *ValidatorData) error {
+	nrs := nropv1.NUMAResourcesScheduler{}
+	err := cli.Get(ctx, client.ObjectKey{Name: objectnames.DefaultNUMAResourcesSchedulerCrName}, &nrs)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			fmt.Fprintf(os.Stderr, "INFO>>>>: NUMA-aware scheduler not deployed, skipping placement audit\n")
+			return nil
+		}
+		return err
+	}
+
+	schedulerName := nrs.Status.SchedulerName
+	if schedulerName == "" {
+		schedulerName = nrs.Spec.SchedulerName
+	}
+
+	podList := corev1.PodList{}
+	if err := cli.List(ctx, &podList); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var pods []misplacedPod
+	for idx := range podList.Items {
+		pod := &podList.Items[idx]
+		if pod.Spec.SchedulerName != schedulerName {
+			continue
+		}
+		reason, ok := misplacementReason(pod, now)
+		if !ok {
+			continue
+		}
+		pods = append(pods, misplacedPod{
+			namespace: pod.Namespace,
+			name:      pod.Name,
+			nodeName:  pod.Spec.NodeName,
+			phase:     pod.Status.Phase,
+			reason:    reason,
+			request:   resourcelist.FromGuaranteedPod(*pod),
+		})
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].namespace != pods[j].namespace {
+			return pods[i].namespace < pods[j].namespace
+		}
+		return pods[i].name < pods[j].name
+	})
+
+	// collect in a scratch area to not interfere with the nrt validator, which may or may not run
+	scratch := ValidatorData{
+		src: data.src,
+	}
+	if err := CollectNodeResourceTopologies(ctx, cli, &scratch); err != nil {
+		return err
+	}
+
+	nrts := make(map[string]nrtv1alpha2.NodeResourceTopology)
+	if scratch.nrtList != nil {
+		for _, nrt := range scratch.nrtList.Items {
+			nrts[nrt.Name] = nrt
+		}
+	}
+
+	data.placement = &placementData{
+		schedulerName: schedulerName,
+		pods:          pods,
+		nrts:          nrts,
+	}
+	return nil
+}
+
+// misplacementReason tells if the pod failed the kubelet topology admission
+// or is pending since at least minPendingAge because the scheduler found no node to run it.
+// The pods with scheduling gates are not considered by the scheduler yet, so they are skipped.
+func misplacementReason(pod *corev1.Pod, now time.Time) (string, bool) {
+	switch pod.Status.Phase {
+	case corev1.PodFailed:
+		if pod.Status.Reason == podReasonTopologyAffinityError {
+			return pod.Status.Reason, true
+		}
+	case corev1.PodPending:
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type != corev1.PodScheduled || cond.Status != corev1.ConditionFalse {
+				continue
+			}
+			if cond.Reason == corev1.PodReasonSchedulingGated {
+				return "", false
+			}
+			since := cond.LastTransitionTime
+			if since.IsZero() {
+				since = pod.CreationTimestamp
+			}
+			if now.Sub(since.Time) < minPendingAge {
+				return "", false
+			}
+			return cond.Reason, true
+		}
+	}
+	return "", false
+}
+
+// ValidatePlacement reports the pods the NUMA-aware scheduler failed to place, explaining
+// which NUMA zone lacks which resource according to the NRT data at validation time.
+// Pods rejected by the kubelet are checked against the NRT of their node, pending pods
+// against the NRTs of all the nodes. The check is done against the whole pod requests,
+// which is exact with the pod topology manager scope and strictest with the container scope.
+func ValidatePlacement(data ValidatorData) ([]deployervalidator.ValidationResult, error) {
+	var ret []deployervalidator.ValidationResult
+	if data.placement == nil {
+		return ret, nil
+	}
+
+	nodeNames := make([]string, 0, len(data.placement.nrts))
+	for nodeName := range data.placement.nrts {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+
+	for _, pod := range data.placement.pods {
+		res := deployervalidator.ValidationResult{
+			Area:      deployervalidator.AreaCluster,
+			Component: componentPlacement,
+			Node:      pod.nodeName,
+			Setting:   pod.namespace + "/" + pod.name,
+			Expected:  string(corev1.PodRunning),
+		}
+
+		var explanation string
+		if pod.nodeName != "" {
+			res.Area = deployervalidator.AreaKubelet
+			nrt, ok := data.placement.nrts[pod.nodeName]
+			if !ok {
+				explanation = "no NodeResourceTopology data"
+			} else {
+				explanation = explainZonesFit(nrt, pod.request)
+			}
+		} else {
+			var items []string
+			for _, nodeName := range nodeNames {
+				items = append(items, fmt.Sprintf("%s: [%s]", nodeName, explainZonesFit(data.placement.nrts[nodeName], pod.request)))
+			}
+			explanation = strings.Join(items, ", ")
+			if explanation == "" {
+				explanation = "no NodeResourceTopology data"
+			}
+		}
+
+		res.Detected = fmt.Sprintf("%s (%s): %s", pod.phase, pod.reason, explanation)
+		ret = append(ret, res)
+	}
+	return ret, nil
+}
+
+// explainZonesFit describes what each NUMA zone of the node lacks to fit the request.
+// Only the resources the node aligns on NUMA zones are considered.
+func explainZonesFit(nrt nrtv1alpha2.NodeResourceTopology, request corev1.ResourceList) string {
+	zoneResNames := intnrt.ZoneResourceNames(nrt)
+	numaRequest := corev1.ResourceList{}
+	for resName, resQty := range request {
+		if zoneResNames.Has(string(resName)) {
+			numaRequest[resName] = resQty
+		}
+	}
+	if len(numaRequest) == 0 {
+		return "no NUMA-aligned resources requested"
+	}
+
+	var items []string
+	for _, zone := range intnrt.SortedZoneList(nrt.Zones) {
+		if zone.Type != intnrt.ZoneTypeNode {
+			continue
+		}
+		shortages := intnrt.ZoneShortages(zone, numaRequest)
+		if len(shortages) == 0 {
+			items = append(items, fmt.Sprintf("zone %s fits", zone.Name))
+			continue
+		}
+		lacks := make([]string, 0, len(shortages))
+		for _, sh := range shortages {
+			lacks = append(lacks, sh.String())
+		}
+		items = append(items, fmt.Sprintf("zone %s lacks %s", zone.Name, strings.Join(lacks, " and ")))
+	}
+	if len(items) == 0 {
+		return "no NUMA zones"
+	}
+	return strings.Join(items, "; ")
+}
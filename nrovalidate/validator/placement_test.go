@@ -0,0 +1,437 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	deployervalidator "github.com/k8stopologyawareschedwg/deployer/pkg/validator"
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+)
+
+func TestValidatePlacement(t *testing.T) {
+	nrts := map[string]nrtv1alpha2.NodeResourceTopology{
+		"worker-0": makeNRT("worker-0", "2", "6"),
+		"worker-1": makeNRT("worker-1", "4", "1"),
+	}
+	request := corev1.ResourceList{
+		corev1.ResourceCPU:              resource.MustParse("4"),
+		corev1.ResourceMemory:           resource.MustParse("4Gi"),
+		corev1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+	}
+
+	testCases := []struct {
+		name     string
+		data     *placementData
+		expected []deployervalidator.ValidationResult
+	}{
+		{
+			name: "scheduler not deployed",
+		},
+		{
+			name: "nothing to report",
+			data: &placementData{nrts: nrts},
+		},
+		{
+			name: "rejected by the kubelet",
+			data: &placementData{
+				nrts: nrts,
+				pods: []misplacedPod{
+					{
+						namespace: "ns",
+						name:      "pod-0",
+						nodeName:  "worker-0",
+						phase:     corev1.PodFailed,
+						reason:    podReasonTopologyAffinityError,
+						request:   request,
+					},
+				},
+			},
+			expected: []deployervalidator.ValidationResult{
+				{
+					Area:      deployervalidator.AreaKubelet,
+					Component: componentPlacement,
+					Node:      "worker-0",
+					Setting:   "ns/pod-0",
+					Expected:  "Running",
+					Detected:  "Failed (TopologyAffinityError): zone node-0 lacks cpu (requested 4, available 2); zone node-1 fits",
+				},
+			},
+		},
+		{
+			name: "rejected by the kubelet on a node without NRT",
+			data: &placementData{
+				nrts: nrts,
+				pods: []misplacedPod{
+					{
+						namespace: "ns",
+						name:      "pod-0",
+						nodeName:  "worker-2",
+						phase:     corev1.PodFailed,
+						reason:    podReasonTopologyAffinityError,
+						request:   request,
+					},
+				},
+			},
+			expected: []deployervalidator.ValidationResult{
+				{
+					Area:      deployervalidator.AreaKubelet,
+					Component: componentPlacement,
+					Node:      "worker-2",
+					Setting:   "ns/pod-0",
+					Expected:  "Running",
+					Detected:  "Failed (TopologyAffinityError): no NodeResourceTopology data",
+				},
+			},
+		},
+		{
+			name: "unschedulable",
+			data: &placementData{
+				nrts: nrts,
+				pods: []misplacedPod{
+					{
+						namespace: "ns",
+						name:      "pod-1",
+						phase:     corev1.PodPending,
+						reason:    corev1.PodReasonUnschedulable,
+						request: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("6"),
+							corev1.ResourceMemory: resource.MustParse("4Gi"),
+						},
+					},
+				},
+			},
+			expected: []deployervalidator.ValidationResult{
+				{
+					Area:      deployervalidator.AreaCluster,
+					Component: componentPlacement,
+					Setting:   "ns/pod-1",
+					Expected:  "Running",
+					Detected: "Pending (Unschedulable): " +
+						"worker-0: [zone node-0 lacks cpu (requested 6, available 2); zone node-1 lacks cpu (requested 6, available 4)], " +
+						"worker-1: [zone node-0 lacks cpu (requested 6, available 4) and memory (requested 4Gi, available 1Gi); zone node-1 lacks cpu (requested 6, available 4)]",
+				},
+			},
+		},
+		{
+			name: "no NUMA-aligned resources",
+			data: &placementData{
+				nrts: map[string]nrtv1alpha2.NodeResourceTopology{
+					"worker-0": makeNRT("worker-0", "2", "6"),
+				},
+				pods: []misplacedPod{
+					{
+						namespace: "ns",
+						name:      "pod-2",
+						phase:     corev1.PodPending,
+						reason:    corev1.PodReasonUnschedulable,
+					},
+				},
+			},
+			expected: []deployervalidator.ValidationResult{
+				{
+					Area:      deployervalidator.AreaCluster,
+					Component: componentPlacement,
+					Setting:   "ns/pod-2",
+					Expected:  "Running",
+					Detected:  "Pending (Unschedulable): worker-0: [no NUMA-aligned resources requested]",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ValidatePlacement(ValidatorData{placement: tc.data})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tc.expected) || (len(got) > 0 && !reflect.DeepEqual(got, tc.expected)) {
+				t.Errorf("got=%#v expected=%#v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestMisplacementReason(t *testing.T) {
+	now := time.Now()
+	longAgo := metav1.NewTime(now.Add(-10 * time.Minute))
+	justNow := metav1.NewTime(now.Add(-5 * time.Second))
+
+	testCases := []struct {
+		name           string
+		created        metav1.Time
+		status         corev1.PodStatus
+		expectedReason string
+		expectedOK     bool
+	}{
+		{
+			name:   "running",
+			status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		{
+			name:   "failed for other reasons",
+			status: corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"},
+		},
+		{
+			name:           "topology affinity error",
+			status:         corev1.PodStatus{Phase: corev1.PodFailed, Reason: "TopologyAffinityError"},
+			expectedReason: "TopologyAffinityError",
+			expectedOK:     true,
+		},
+		{
+			name: "pending but scheduled",
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.PodScheduled, Status: corev1.ConditionTrue},
+				},
+			},
+		},
+		{
+			name: "unschedulable",
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable, LastTransitionTime: longAgo},
+				},
+			},
+			expectedReason: "Unschedulable",
+			expectedOK:     true,
+		},
+		{
+			name: "unschedulable just now",
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable, LastTransitionTime: justNow},
+				},
+			},
+		},
+		{
+			name:    "unschedulable without transition time falls back to the creation time",
+			created: longAgo,
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable},
+				},
+			},
+			expectedReason: "Unschedulable",
+			expectedOK:     true,
+		},
+		{
+			name: "scheduling gated",
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonSchedulingGated, LastTransitionTime: longAgo},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: tc.created},
+				Status:     tc.status,
+			}
+			reason, ok := misplacementReason(&pod, now)
+			if reason != tc.expectedReason || ok != tc.expectedOK {
+				t.Errorf("got=(%q, %v) expected=(%q, %v)", reason, ok, tc.expectedReason, tc.expectedOK)
+			}
+		})
+	}
+}
+
+func TestCollectPlacement(t *testing.T) {
+	dir := t.TempDir()
+	writeMustGatherFiles(t, dir, map[string]string{
+		"cluster-scoped-resources/core/nodes/worker-0.yaml":                                                      nodeWorker0,
+		"cluster-scoped-resources/nodetopology.openshift.io/numaresourcesschedulers/numaresourcesscheduler.yaml": nrsInstance,
+		"cluster-scoped-resources/topology.node.k8s.io/noderesourcetopologies.yaml":                              nrtListWithZones,
+		"namespaces/workload/core/pods.yaml":                                                                     workloadPodList,
+	})
+
+	what := sets.New[string](ValidatorPlacement)
+	data, err := CollectFromDir(context.Background(), newTestScheme(t), dir, "node-role.kubernetes.io/worker=", what)
+	if err != nil {
+		t.Fatalf("unexpected error collecting data: %v", err)
+	}
+	if data.placement == nil {
+		t.Fatalf("missing placement data")
+	}
+	if data.placement.schedulerName != "topo-aware-scheduler" {
+		t.Errorf("unexpected scheduler name: %q", data.placement.schedulerName)
+	}
+
+	rep, err := Validate(data)
+	if err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+	expected := []deployervalidator.ValidationResult{
+		{
+			Area:      deployervalidator.AreaKubelet,
+			Component: componentPlacement,
+			Node:      "worker-0",
+			Setting:   "workload/rejected",
+			Expected:  "Running",
+			Detected:  "Failed (TopologyAffinityError): zone node-0 lacks cpu (requested 4, available 2); zone node-1 lacks cpu (requested 4, available 2)",
+		},
+	}
+	if got := validationResults(rep.Errors); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got=%#v expected=%#v", got, expected)
+	}
+}
+
+func TestCollectPlacementSchedulerMissing(t *testing.T) {
+	dir := t.TempDir()
+	writeMustGatherFiles(t, dir, map[string]string{
+		"cluster-scoped-resources/core/nodes/worker-0.yaml": nodeWorker0,
+		"namespaces/workload/core/pods.yaml":                workloadPodList,
+	})
+
+	what := sets.New[string](ValidatorPlacement)
+	data, err := CollectFromDir(context.Background(), newTestScheme(t), dir, "node-role.kubernetes.io/worker=", what)
+	if err != nil {
+		t.Fatalf("unexpected error collecting data: %v", err)
+	}
+	if data.placement != nil {
+		t.Errorf("unexpected placement data: %+v", data.placement)
+	}
+}
+
+func makeNRT(name, cpusZone0, memZone0 string) nrtv1alpha2.NodeResourceTopology {
+	return nrtv1alpha2.NodeResourceTopology{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Zones: nrtv1alpha2.ZoneList{
+			{
+				Name: "node-1",
+				Type: "Node",
+				Resources: nrtv1alpha2.ResourceInfoList{
+					{Name: "cpu", Available: resource.MustParse("4")},
+					{Name: "memory", Available: resource.MustParse("8Gi")},
+				},
+			},
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: nrtv1alpha2.ResourceInfoList{
+					{Name: "cpu", Available: resource.MustParse(cpusZone0)},
+					{Name: "memory", Available: resource.MustParse(memZone0 + "Gi")},
+				},
+			},
+		},
+	}
+}
+
+const nrsInstance = `apiVersion: nodetopology.openshift.io/v1
+kind: NUMAResourcesScheduler
+metadata:
+  name: numaresourcesscheduler
+spec:
+  imageSpec: quay.io/openshift-kni/scheduler-plugins:test
+status:
+  schedulerName: topo-aware-scheduler
+`
+
+const nrtListWithZones = `apiVersion: v1
+kind: List
+items:
+- apiVersion: topology.node.k8s.io/v1alpha2
+  kind: NodeResourceTopology
+  metadata:
+    name: worker-0
+  zones:
+  - name: node-0
+    type: Node
+    resources:
+    - name: cpu
+      capacity: "8"
+      allocatable: "6"
+      available: "2"
+  - name: node-1
+    type: Node
+    resources:
+    - name: cpu
+      capacity: "8"
+      allocatable: "6"
+      available: "2"
+`
+
+const workloadPodList = `apiVersion: v1
+kind: PodList
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: rejected
+    namespace: workload
+  spec:
+    schedulerName: topo-aware-scheduler
+    nodeName: worker-0
+    containers:
+    - name: app
+      image: quay.io/example/app:test
+      resources:
+        limits:
+          cpu: "4"
+          memory: 1Gi
+  status:
+    phase: Failed
+    reason: TopologyAffinityError
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: running
+    namespace: workload
+  spec:
+    schedulerName: topo-aware-scheduler
+    nodeName: worker-0
+    containers:
+    - name: app
+      image: quay.io/example/app:test
+  status:
+    phase: Running
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: default-scheduler
+    namespace: workload
+  spec:
+    schedulerName: default-scheduler
+    containers:
+    - name: app
+      image: quay.io/example/app:test
+  status:
+    phase: Pending
+    conditions:
+    - type: PodScheduled
+      status: "False"
+      reason: Unschedulable
+`
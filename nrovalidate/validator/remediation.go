@@ -0,0 +1,317 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	deployervalidator "github.com/k8stopologyawareschedwg/deployer/pkg/validator"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	"github.com/openshift-kni/numaresources-operator/internal/api/annotations"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
+)
+
+// Remediation tells how to fix a validation error
+type Remediation struct {
+	Hint string `json:"hint"`
+	// Target is the object to patch, as kind/name. If empty, Patch is a manifest to create.
+	Target string `json:"target,omitempty"`
+	// Patch is a JSON merge patch for Target, or a manifest to create. Empty if no ready-to-apply fix is known.
+	Patch string `json:"patch,omitempty"`
+}
+
+func (rem Remediation) String() string {
+	var sb strings.Builder
+	sb.WriteString("HINT: " + rem.Hint)
+	if rem.Patch == "" {
+		return sb.String()
+	}
+	if rem.Target != "" {
+		fmt.Fprintf(&sb, "\nPATCH: oc patch %s --type=merge -p '%s'", rem.Target, rem.Patch)
+		return sb.String()
+	}
+	sb.WriteString("\nAPPLY:\n" + rem.Patch)
+	return sb.String()
+}
+
+// RemediationKey selects the remediation of a result. An empty Setting matches all the settings of the component.
+type RemediationKey struct {
+	Validator string
+	Component string
+	Setting   string
+}
+
+type RemediationFunc func(data ValidatorData, res deployervalidator.ValidationResult) *Remediation
+
+func Remediations() map[RemediationKey]RemediationFunc {
+	return map[RemediationKey]RemediationFunc{
+		{ValidatorKubeletConfig, deployervalidator.ComponentConfiguration, ""}:              remediateKubeletConfigMissing,
+		{ValidatorKubeletConfig, deployervalidator.ComponentConfiguration, "CPU"}:           remediateKubeletConfigReservedCPUs,
+		{ValidatorKubeletConfig, deployervalidator.ComponentConfiguration, "memory"}:        remediateKubeletConfigReservedMemory,
+		{ValidatorKubeletConfig, deployervalidator.ComponentCPUManager, "policy"}:           remediateKubeletConfigCPUManagerPolicy,
+		{ValidatorKubeletConfig, deployervalidator.ComponentCPUManager, "reconcile period"}: remediateKubeletConfigCPUManagerReconcilePeriod,
+		{ValidatorKubeletConfig, deployervalidator.ComponentMemoryManager, "policy"}:        remediateKubeletConfigMemoryManagerPolicy,
+		{ValidatorKubeletConfig, deployervalidator.ComponentTopologyManager, "policy"}:      remediateKubeletConfigTopologyManagerPolicy,
+		{ValidatorNodeResourceTopologies, componentNRT, "installed"}:                        remediateNRTInstalled,
+		{ValidatorNodeResourceTopologies, componentNRT, "items"}:                            remediateNRTItems,
+		{ValidatorPodStatus, "pod", ""}:                                                     remediatePodStatus,
+		{ValidatorSchedCache, "scheduler", "cache"}:                                         remediateSchedCache,
+		{ValidatorTopologyManager, componentRTEConfig, ""}:                                  remediateTopologyManagerRTEConfig,
+		{ValidatorTopologyManager, componentNRT, ""}:                                        remediateTopologyManagerNRT,
+		{ValidatorPlacement, componentPlacement, ""}:                                        remediatePlacement,
+		{ValidatorOperator, componentOperator, "installed"}:                                 remediateOperatorInstalled,
+		{ValidatorOperator, componentOperator, ""}:                                          remediateOperator,
+		{ValidatorOperator, componentRTE, ""}:                                               remediateRTE,
+		{ValidatorScheduler, componentScheduler, ""}:                                        remediateScheduler,
+	}
+}
+
+// Remediate returns the remediation of a result reported by the given validator, or nil if none is known.
+// The remediations registered for the exact setting take precedence over the ones registered for the component.
+func Remediate(data ValidatorData, validatorName string, res deployervalidator.ValidationResult) *Remediation {
+	remediations := Remediations()
+	key := RemediationKey{
+		Validator: validatorName,
+		Component: res.Component,
+		Setting:   res.Setting,
+	}
+	if fn, ok := remediations[key]; ok {
+		return fn(data, res)
+	}
+	key.Setting = ""
+	if fn, ok := remediations[key]; ok {
+		return fn(data, res)
+	}
+	return nil
+}
+
+func remediateKubeletConfigMissing(data ValidatorData, res deployervalidator.ValidationResult) *Remediation {
+	return &Remediation{
+		Hint: fmt.Sprintf("create a KubeletConfig for the MachineConfigPool of node %q setting cpuManagerPolicy=%s, memoryManagerPolicy=%s, topologyManagerPolicy=%s and the reserved resources",
+			res.Node, deployervalidator.ExpectedCPUManagerPolicy, deployervalidator.ExpectedMemoryManagerPolicy, deployervalidator.ExpectedTopologyManagerPolicy),
+	}
+}
+
+func remediateKubeletConfigReservedCPUs(data ValidatorData, res deployervalidator.ValidationResult) *Remediation {
+	return &Remediation{
+		Hint:   "reserve CPUs for the system daemons setting reservedSystemCPUs in the KubeletConfig",
+		Target: kubeletConfigTarget(data, res.Node),
+	}
+}
+
+func remediateKubeletConfigReservedMemory(data ValidatorData, res deployervalidator.ValidationResult) *Remediation {
+	return &Remediation{
+		Hint:   "reserve memory on each NUMA zone setting reservedMemory in the KubeletConfig; the total must match the kube, system and eviction reserved memory",
+		Target: kubeletConfigTarget(data, res.Node),
+	}
+}
+
+func remediateKubeletConfigCPUManagerPolicy(data ValidatorData, res deployervalidator.ValidationResult) *Remediation {
+	return kubeletConfigRemediation(data, res.Node, "enable the static CPU manager policy in the KubeletConfig", map[string]interface{}{
+		"cpuManagerPolicy": deployervalidator.ExpectedCPUManagerPolicy,
+	})
+}
+
+func remediateKubeletConfigCPUManagerReconcilePeriod(data ValidatorData, res deployervalidator.ValidationResult) *Remediation {
+	return kubeletConfigRemediation(data, res.Node, "set the CPU manager reconcile period in the expected range in the KubeletConfig", map[string]interface{}{
+		"cpuManagerReconcilePeriod": deployervalidator.CPUManagerReconcilePeriodMax.String(),
+	})
+}
+
+func remediateKubeletConfigMemoryManagerPolicy(data ValidatorData, res deployervalidator.ValidationResult) *Remediation {
+	return kubeletConfigRemediation(data, res.Node, "enable the static memory manager policy in the KubeletConfig; this policy requires reservedMemory to be set", map[string]interface{}{
+		"memoryManagerPolicy": deployervalidator.ExpectedMemoryManagerPolicy,
+	})
+}
+
+func remediateKubeletConfigTopologyManagerPolicy(data ValidatorData, res deployervalidator.ValidationResult) *Remediation {
+	return kubeletConfigRemediation(data, res.Node, "enable the single-numa-node topology manager policy in the KubeletConfig", map[string]interface{}{
+		"topologyManagerPolicy": deployervalidator.ExpectedTopologyManagerPolicy,
+	})
+}
+
+func remediateNRTInstalled(data ValidatorData, res deployervalidator.ValidationResult) *Remediation {
+	return &Remediation{
+		Hint: "install the NodeResourceTopology CRD creating the NUMAResourcesOperator object; run the nro validator for details",
+	}
+}
+
+func remediateNRTItems(data ValidatorData, res deployervalidator.ValidationResult) *Remediation {
+	return &Remediation{
+		Hint: "check the RTE pods are running on all the TAS enabled nodes; run the nro validator for details",
+	}
+}
+
+func remediatePodStatus(data ValidatorData, res deployervalidator.ValidationResult) *Remediation {
+	namespace, name, _ := strings.Cut(res.Setting, "/")
+	return &Remediation{
+		Hint: fmt.Sprintf("check why the pod is not running: oc describe pod -n %s %s", namespace, name),
+	}
+}
+
+func remediateSchedCache(data ValidatorData, res deployervalidator.ValidationResult) *Remediation {
+	return &Remediation{
+		Hint: fmt.Sprintf("the scheduler cache of node %q resyncs once the NRT object reflects the pods running on the node; check the RTE pod of the node is running and updating the NRT object", res.Node),
+	}
+}
+
+func remediateTopologyManagerRTEConfig(data ValidatorData, res deployervalidator.ValidationResult) *Remediation {
+	if res.Area == deployervalidator.AreaCluster {
+		return &Remediation{
+			Hint: "the RTE configuration can't be decoded: the operator renders it from the KubeletConfig, check the operator logs and the KubeletConfig of the pool",
+		}
+	}
+	if res.Setting == "rendered" {
+		return &Remediation{
+			Hint: "the operator renders the RTE configuration from the KubeletConfig: check the pool of the node is selected by a NUMAResourcesOperator node group; run the nro validator for details",
+		}
+	}
+	return &Remediation{
+		Hint: "the RTE configuration is stale: the operator renders it again when the KubeletConfig changes, check the operator logs",
+	}
+}
+
+func remediateTopologyManagerNRT(data ValidatorData, res deployervalidator.ValidationResult) *Remediation {
+	return &Remediation{
+		Hint: fmt.Sprintf("the RTE reports the topology manager settings at startup: restart the RTE pod running on node %q", res.Node),
+	}
+}
+
+func remediatePlacement(data ValidatorData, res deployervalidator.ValidationResult) *Remediation {
+	if res.Area == deployervalidator.AreaKubelet {
+		return &Remediation{
+			Hint: fmt.Sprintf("the kubelet on node %q rejected the pod because it does not fit a single NUMA zone: recreate the pod once enough resources are free, or lower its requests", res.Node),
+		}
+	}
+	return &Remediation{
+		Hint: "no node can fit the pod in a single NUMA zone: free resources on the nodes, add nodes or lower the pod requests",
+	}
+}
+
+func remediateOperatorInstalled(data ValidatorData, res deployervalidator.ValidationResult) *Remediation {
+	rem := Remediation{
+		Hint: "create the NUMAResourcesOperator object with a node group for each MachineConfigPool of the TAS enabled nodes",
+	}
+	if data.operator == nil || len(data.operator.suggestedPools) == 0 {
+		return &rem
+	}
+	var sb strings.Builder
+	sb.WriteString("apiVersion: " + nropv1.GroupVersion.String() + "\n")
+	sb.WriteString("kind: NUMAResourcesOperator\n")
+	sb.WriteString("metadata:\n")
+	sb.WriteString("  name: " + objectnames.DefaultNUMAResourcesOperatorCrName + "\n")
+	sb.WriteString("spec:\n")
+	sb.WriteString("  nodeGroups:\n")
+	for _, poolName := range data.operator.suggestedPools {
+		sb.WriteString("  - poolName: " + poolName + "\n")
+	}
+	rem.Patch = sb.String()
+	return &rem
+}
+
+func remediateOperator(data ValidatorData, res deployervalidator.ValidationResult) *Remediation {
+	switch {
+	case strings.HasPrefix(res.Setting, "nodeGroups["):
+		return &Remediation{
+			Hint: "fix the node group to select exactly one existing MachineConfigPool, using either poolName or machineConfigPoolSelector",
+		}
+	case strings.HasPrefix(res.Setting, "DaemonSet "):
+		namespace, name, _ := strings.Cut(strings.TrimPrefix(res.Setting, "DaemonSet "), "/")
+		return &Remediation{
+			Hint: fmt.Sprintf("the operator deletes the objects of the pools no longer selected on reconcile; if it does not, delete it: oc delete daemonset -n %s %s", namespace, name),
+		}
+	case strings.HasPrefix(res.Setting, "MachineConfig "):
+		return &Remediation{
+			Hint: fmt.Sprintf("the operator deletes the objects of the pools no longer selected on reconcile; if it does not, delete it: oc delete machineconfig %s", strings.TrimPrefix(res.Setting, "MachineConfig ")),
+		}
+	}
+	return nil
+}
+
+func remediateRTE(data ValidatorData, res deployervalidator.ValidationResult) *Remediation {
+	if res.Setting == "pod" {
+		return &Remediation{
+			Hint: fmt.Sprintf("check the events and the logs of the RTE pod running on node %q", res.Node),
+		}
+	}
+	rem := Remediation{
+		Hint: "the operator reconciles the RTE DaemonSets: check the operator logs and that the reconciliation is not paused",
+	}
+	if data.operator == nil || data.operator.instance == nil || !annotations.IsPauseReconciliationEnabled(data.operator.instance.Annotations) {
+		return &rem
+	}
+	rem.Hint = "the reconciliation of the NUMAResourcesOperator is paused: resume it to let the operator fix the RTE DaemonSets"
+	rem.Target = "numaresourcesoperator/" + data.operator.instance.Name
+	rem.Patch = mustMarshalPatch(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				annotations.PauseReconciliationAnnotation: nil,
+			},
+		},
+	})
+	return &rem
+}
+
+func remediateScheduler(data ValidatorData, res deployervalidator.ValidationResult) *Remediation {
+	if strings.HasPrefix(res.Setting, "ConfigMap ") && strings.HasSuffix(res.Setting, " data") {
+		return &Remediation{
+			Hint: "the scheduler ConfigMap was edited after deployment and will be overwritten: set the desired values in the NUMAResourcesScheduler spec instead",
+		}
+	}
+	return &Remediation{
+		Hint: "the operator reconciles the scheduler objects from the NUMAResourcesScheduler spec: check the operator logs",
+	}
+}
+
+func kubeletConfigTarget(data ValidatorData, nodeName string) string {
+	name, ok := data.kConfigNames[nodeName]
+	if !ok {
+		return ""
+	}
+	return "kubeletconfig/" + name
+}
+
+// kubeletConfigRemediation returns a remediation patching the KubeletConfig of the node, if known, with the given settings
+func kubeletConfigRemediation(data ValidatorData, nodeName, hint string, settings map[string]interface{}) *Remediation {
+	target := kubeletConfigTarget(data, nodeName)
+	if target == "" {
+		return &Remediation{
+			Hint: hint,
+		}
+	}
+	return &Remediation{
+		Hint:   hint,
+		Target: target,
+		Patch: mustMarshalPatch(map[string]interface{}{
+			"spec": map[string]interface{}{
+				"kubeletConfig": settings,
+			},
+		}),
+	}
+}
+
+func mustMarshalPatch(patch map[string]interface{}) string {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		// can't happen: the patches are built from plain maps of strings
+		panic(err)
+	}
+	return string(data)
+}
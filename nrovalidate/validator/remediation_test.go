@@ -0,0 +1,207 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validator
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	deployervalidator "github.com/k8stopologyawareschedwg/deployer/pkg/validator"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	"github.com/openshift-kni/numaresources-operator/internal/api/annotations"
+)
+
+func TestRemediate(t *testing.T) {
+	data := ValidatorData{
+		kConfigNames: map[string]string{
+			"worker-0": "worker-cnf",
+		},
+		operator: &operatorState{
+			instance: &nropv1.NUMAResourcesOperator{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "numaresourcesoperator",
+					Annotations: map[string]string{
+						annotations.PauseReconciliationAnnotation: annotations.PauseReconciliationAnnotationEnabled,
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		validatorName string
+		res           deployervalidator.ValidationResult
+		expected      *Remediation
+	}{
+		{
+			name:          "unknown component",
+			validatorName: ValidatorKubeletConfig,
+			res: deployervalidator.ValidationResult{
+				Component: deployervalidator.ComponentFeatureGates,
+			},
+		},
+		{
+			name:          "kubelet config patch",
+			validatorName: ValidatorKubeletConfig,
+			res: deployervalidator.ValidationResult{
+				Node:      "worker-0",
+				Component: deployervalidator.ComponentTopologyManager,
+				Setting:   "policy",
+			},
+			expected: &Remediation{
+				Hint:   "enable the single-numa-node topology manager policy in the KubeletConfig",
+				Target: "kubeletconfig/worker-cnf",
+				Patch:  `{"spec":{"kubeletConfig":{"topologyManagerPolicy":"single-numa-node"}}}`,
+			},
+		},
+		{
+			name:          "kubelet config unknown",
+			validatorName: ValidatorKubeletConfig,
+			res: deployervalidator.ValidationResult{
+				Node:      "worker-1",
+				Component: deployervalidator.ComponentCPUManager,
+				Setting:   "policy",
+			},
+			expected: &Remediation{
+				Hint: "enable the static CPU manager policy in the KubeletConfig",
+			},
+		},
+		{
+			name:          "component fallback",
+			validatorName: ValidatorPodStatus,
+			res: deployervalidator.ValidationResult{
+				Node:      "worker-1",
+				Component: "pod",
+				Setting:   "ns/pod",
+			},
+			expected: &Remediation{
+				Hint: "check why the pod is not running: oc describe pod -n ns pod",
+			},
+		},
+		{
+			name:          "dangling daemonset",
+			validatorName: ValidatorOperator,
+			res: deployervalidator.ValidationResult{
+				Component: componentOperator,
+				Setting:   "DaemonSet ns/ds",
+			},
+			expected: &Remediation{
+				Hint: "the operator deletes the objects of the pools no longer selected on reconcile; if it does not, delete it: oc delete daemonset -n ns ds",
+			},
+		},
+		{
+			name:          "reconciliation paused",
+			validatorName: ValidatorOperator,
+			res: deployervalidator.ValidationResult{
+				Component: componentRTE,
+				Setting:   "pool worker-cnf DaemonSet numaresourcesoperator-worker-cnf arg --sleep-interval",
+			},
+			expected: &Remediation{
+				Hint:   "the reconciliation of the NUMAResourcesOperator is paused: resume it to let the operator fix the RTE DaemonSets",
+				Target: "numaresourcesoperator/numaresourcesoperator",
+				Patch:  `{"metadata":{"annotations":{"config.numa-operator.openshift.io/pause-reconciliation":null}}}`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Remediate(data, tc.validatorName, tc.res)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("got=%#v expected=%#v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestRemediationString(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rem      Remediation
+		expected string
+	}{
+		{
+			name:     "hint only",
+			rem:      Remediation{Hint: "do this"},
+			expected: "HINT: do this",
+		},
+		{
+			name:     "patch",
+			rem:      Remediation{Hint: "do this", Target: "kubeletconfig/foo", Patch: `{"spec":{}}`},
+			expected: "HINT: do this\nPATCH: oc patch kubeletconfig/foo --type=merge -p '{\"spec\":{}}'",
+		},
+		{
+			name:     "manifest",
+			rem:      Remediation{Hint: "do this", Patch: "kind: Foo\n"},
+			expected: "HINT: do this\nAPPLY:\nkind: Foo\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.rem.String(); got != tc.expected {
+				t.Errorf("got=%q expected=%q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestRemediationOperatorMissing(t *testing.T) {
+	dir := t.TempDir()
+	writeMustGatherFiles(t, dir, map[string]string{
+		"cluster-scoped-resources/core/nodes/worker-0.yaml":                                             nodeWorker0,
+		"cluster-scoped-resources/core/nodes/worker-1.yaml":                                             nodeWorker1,
+		"cluster-scoped-resources/machineconfiguration.openshift.io/machineconfigpools/worker-cnf.yaml": mcpWorkerCNF,
+		"cluster-scoped-resources/machineconfiguration.openshift.io/kubeletconfigs/worker-cnf.yaml":     kcWorkerCNF,
+		"cluster-scoped-resources/topology.node.k8s.io/noderesourcetopologies.yaml":                     nrtList,
+	})
+
+	what := sets.New[string](ValidatorKubeletConfig, ValidatorOperator)
+	// the TAS enabled nodes are selected by labels because the NUMAResourcesOperator object is missing
+	data, err := CollectFromDir(context.Background(), newTestScheme(t), dir, "node-role.kubernetes.io/worker-cnf", what)
+	if err != nil {
+		t.Fatalf("unexpected error collecting data: %v", err)
+	}
+	if !reflect.DeepEqual(data.kConfigNames, map[string]string{"worker-0": "worker-cnf", "worker-1": "worker-cnf"}) {
+		t.Errorf("unexpected kubelet config names: %v", data.kConfigNames)
+	}
+
+	rep, err := Validate(data)
+	if err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	var rem *Remediation
+	for _, finding := range rep.Errors {
+		if finding.Validator == ValidatorOperator && finding.Setting == "installed" {
+			rem = finding.Remediation
+		}
+	}
+	if rem == nil {
+		t.Fatalf("missing remediation in %v", rep.Errors)
+	}
+	if rem.Target != "" || !strings.Contains(rem.Patch, "kind: NUMAResourcesOperator\n") || !strings.HasSuffix(rem.Patch, "  nodeGroups:\n  - poolName: worker-cnf\n") {
+		t.Errorf("unexpected remediation: %#v", rem)
+	}
+}
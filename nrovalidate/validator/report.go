@@ -0,0 +1,178 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validator
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	FormatText       = "text"
+	FormatJSON       = "json"
+	FormatJSONByNode = "json-by-node"
+	FormatJUnit      = "junit"
+)
+
+const (
+	// ClusterTestCase names the JUnit testcases holding the results not bound to any node
+	ClusterTestCase = "cluster"
+)
+
+func Formats() sets.Set[string] {
+	return sets.New[string](
+		FormatText,
+		FormatJSON,
+		FormatJSONByNode,
+		FormatJUnit,
+	)
+}
+
+// NodeReport is the validation report with the results grouped by node.
+// Nodes which passed all the validations are included with no results.
+type NodeReport struct {
+	Succeeded bool                 `json:"succeeded"`
+	Cluster   []Finding            `json:"cluster,omitempty"`
+	Nodes     map[string][]Finding `json:"nodes"`
+}
+
+func (rep Report) ByNode() NodeReport {
+	ret := NodeReport{
+		Succeeded: rep.Succeeded,
+		Nodes:     make(map[string][]Finding),
+	}
+	for _, nodeName := range rep.NodeNames {
+		ret.Nodes[nodeName] = []Finding{}
+	}
+	for _, res := range rep.Errors {
+		if res.Node == "" {
+			ret.Cluster = append(ret.Cluster, res)
+			continue
+		}
+		ret.Nodes[res.Node] = append(ret.Nodes[res.Node], res)
+	}
+	return ret
+}
+
+type JUnitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []JUnitTestSuite `xml:"testsuite"`
+}
+
+type JUnitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []JUnitTestCase `xml:"testcase"`
+}
+
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *JUnitFailure `xml:"failure,omitempty"`
+}
+
+type JUnitFailure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+// JUnit renders the report as JUnit test suites: one suite per validator,
+// with one testcase per inspected node plus one for the cluster-wide results.
+func (rep Report) JUnit(name string) JUnitTestSuites {
+	ret := JUnitTestSuites{
+		Name: name,
+	}
+
+	validatorNames := make([]string, 0, len(rep.Results))
+	for validatorName := range rep.Results {
+		validatorNames = append(validatorNames, validatorName)
+	}
+	sort.Strings(validatorNames)
+
+	caseNames := append([]string{ClusterTestCase}, rep.NodeNames...)
+
+	for _, validatorName := range validatorNames {
+		resultsByCase := make(map[string][]Finding)
+		for _, res := range rep.Results[validatorName] {
+			caseName := res.Node
+			if caseName == "" {
+				caseName = ClusterTestCase
+			}
+			resultsByCase[caseName] = append(resultsByCase[caseName], res)
+		}
+
+		suite := JUnitTestSuite{
+			Name: validatorName,
+		}
+		for _, caseName := range caseNames {
+			suite.TestCases = append(suite.TestCases, makeJUnitTestCase(validatorName, caseName, resultsByCase[caseName]))
+			delete(resultsByCase, caseName)
+		}
+		// results for nodes not inspected, e.g. the placement of pods on other nodes
+		leftovers := make([]string, 0, len(resultsByCase))
+		for caseName := range resultsByCase {
+			leftovers = append(leftovers, caseName)
+		}
+		sort.Strings(leftovers)
+		for _, caseName := range leftovers {
+			suite.TestCases = append(suite.TestCases, makeJUnitTestCase(validatorName, caseName, resultsByCase[caseName]))
+		}
+
+		suite.Tests = len(suite.TestCases)
+		for _, tc := range suite.TestCases {
+			if tc.Failure != nil {
+				suite.Failures++
+			}
+		}
+		ret.Tests += suite.Tests
+		ret.Failures += suite.Failures
+		ret.Suites = append(ret.Suites, suite)
+	}
+	return ret
+}
+
+func makeJUnitTestCase(validatorName, caseName string, results []Finding) JUnitTestCase {
+	tc := JUnitTestCase{
+		Name:      caseName,
+		ClassName: validatorName,
+	}
+	if len(results) == 0 {
+		return tc
+	}
+	lines := make([]string, 0, len(results))
+	for _, res := range results {
+		lines = append(lines, res.String())
+		if res.Remediation != nil {
+			lines = append(lines, res.Remediation.String())
+		}
+	}
+	tc.Failure = &JUnitFailure{
+		Message:  fmt.Sprintf("%d validation errors", len(results)),
+		Type:     "ValidationError",
+		Contents: strings.Join(lines, "\n"),
+	}
+	return tc
+}
@@ -0,0 +1,188 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validator
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	deployervalidator "github.com/k8stopologyawareschedwg/deployer/pkg/validator"
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+)
+
+var (
+	resNRTItems = deployervalidator.ValidationResult{
+		Area:      deployervalidator.AreaCluster,
+		Component: "NodeResourceTopology",
+		Setting:   "items",
+		Expected:  "2",
+		Detected:  "1",
+	}
+	resPodWorker1 = deployervalidator.ValidationResult{
+		Area:      deployervalidator.AreaKubelet,
+		Component: "pod",
+		Node:      "worker-1",
+		Setting:   "ns/pod",
+		Expected:  "Running",
+		Detected:  "Pending",
+	}
+
+	findNRTItems = Finding{
+		ValidationResult: resNRTItems,
+		Validator:        ValidatorNodeResourceTopologies,
+		Remediation: &Remediation{
+			Hint: "check the RTE pods are running on all the TAS enabled nodes; run the nro validator for details",
+		},
+	}
+	findPodWorker1 = Finding{
+		ValidationResult: resPodWorker1,
+		Validator:        ValidatorPodStatus,
+		Remediation: &Remediation{
+			Hint: "check why the pod is not running: oc describe pod -n ns pod",
+		},
+	}
+)
+
+func TestValidateResults(t *testing.T) {
+	data := ValidatorData{
+		tasEnabledNodeNames: sets.New[string]("worker-1", "worker-0"),
+		nrtList:             &nrtv1alpha2.NodeResourceTopologyList{Items: []nrtv1alpha2.NodeResourceTopology{{}}},
+		nonRunningPodsByNode: map[string]map[string]corev1.PodPhase{
+			"worker-1": {"ns/pod": "Pending"},
+		},
+		what: sets.New[string](ValidatorNodeResourceTopologies, ValidatorPodStatus),
+	}
+
+	rep, err := Validate(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rep.Succeeded {
+		t.Errorf("unexpected success")
+	}
+	if !reflect.DeepEqual(rep.NodeNames, []string{"worker-0", "worker-1"}) {
+		t.Errorf("unexpected node names: %v", rep.NodeNames)
+	}
+	expected := map[string][]Finding{
+		ValidatorNodeResourceTopologies: {findNRTItems},
+		ValidatorPodStatus:              {findPodWorker1},
+	}
+	if !reflect.DeepEqual(rep.Results, expected) {
+		t.Errorf("got=%v expected=%v", rep.Results, expected)
+	}
+	if !reflect.DeepEqual(rep.Errors, []Finding{findNRTItems, findPodWorker1}) {
+		t.Errorf("unexpected errors: %v", rep.Errors)
+	}
+}
+
+func TestReportByNode(t *testing.T) {
+	rep := Report{
+		Succeeded: false,
+		Errors:    []Finding{findNRTItems, findPodWorker1},
+		NodeNames: []string{"worker-0", "worker-1"},
+	}
+
+	got := rep.ByNode()
+	expected := NodeReport{
+		Succeeded: false,
+		Cluster:   []Finding{findNRTItems},
+		Nodes: map[string][]Finding{
+			"worker-0": {},
+			"worker-1": {findPodWorker1},
+		},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got=%+v expected=%+v", got, expected)
+	}
+}
+
+func TestReportJUnit(t *testing.T) {
+	rep := Report{
+		Succeeded: false,
+		Errors:    []Finding{findNRTItems, findPodWorker1},
+		Results: map[string][]Finding{
+			ValidatorPodStatus:              {findPodWorker1},
+			ValidatorNodeResourceTopologies: {findNRTItems},
+		},
+		NodeNames: []string{"worker-0", "worker-1"},
+	}
+
+	got := rep.JUnit("nrovalidate")
+	if got.Tests != 6 || got.Failures != 2 {
+		t.Errorf("unexpected totals: tests=%d failures=%d", got.Tests, got.Failures)
+	}
+	if len(got.Suites) != 2 || got.Suites[0].Name != ValidatorNodeResourceTopologies || got.Suites[1].Name != ValidatorPodStatus {
+		t.Fatalf("unexpected suites: %+v", got.Suites)
+	}
+
+	failed := map[string]string{}
+	for _, suite := range got.Suites {
+		var names []string
+		for _, tc := range suite.TestCases {
+			names = append(names, tc.Name)
+			if tc.Failure != nil {
+				failed[suite.Name+"/"+tc.Name] = tc.Failure.Contents
+			}
+		}
+		if !reflect.DeepEqual(names, []string{ClusterTestCase, "worker-0", "worker-1"}) {
+			t.Errorf("suite %q: unexpected testcases: %v", suite.Name, names)
+		}
+	}
+	expectedFailed := map[string]string{
+		"nrt/cluster":    resNRTItems.String() + "\n" + findNRTItems.Remediation.String(),
+		"podst/worker-1": resPodWorker1.String() + "\n" + findPodWorker1.Remediation.String(),
+	}
+	if !reflect.DeepEqual(failed, expectedFailed) {
+		t.Errorf("got=%v expected=%v", failed, expectedFailed)
+	}
+
+	out, err := xml.Marshal(got)
+	if err != nil {
+		t.Fatalf("cannot marshal: %v", err)
+	}
+	if !strings.HasPrefix(string(out), `<testsuites name="nrovalidate" tests="6" failures="2"><testsuite name="nrt" tests="3" failures="1">`) {
+		t.Errorf("unexpected XML: %s", out)
+	}
+}
+
+func TestReportJUnitNodeNotInspected(t *testing.T) {
+	res := findPodWorker1
+	res.Node = "worker-9"
+	rep := Report{
+		Results: map[string][]Finding{
+			ValidatorPlacement: {res},
+		},
+		NodeNames: []string{"worker-0"},
+	}
+
+	got := rep.JUnit("nrovalidate")
+	var names []string
+	for _, tc := range got.Suites[0].TestCases {
+		names = append(names, tc.Name)
+	}
+	if !reflect.DeepEqual(names, []string{ClusterTestCase, "worker-0", "worker-9"}) {
+		t.Errorf("unexpected testcases: %v", names)
+	}
+	if got.Failures != 1 {
+		t.Errorf("unexpected failures: %d", got.Failures)
+	}
+}
@@ -24,9 +24,12 @@ import (
 	"github.com/k8stopologyawareschedwg/deployer/pkg/clientutil/nodes"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer"
 	deployervalidator "github.com/k8stopologyawareschedwg/deployer/pkg/validator"
+	"github.com/openshift-kni/numaresources-operator/internal/pfpstatus"
 	"github.com/openshift-kni/numaresources-operator/internal/schedcache"
 	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
 
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -35,31 +38,44 @@ const (
 )
 
 func CollectSchedCache(ctx context.Context, cli client.Client, data *ValidatorData) error {
-	k8sCli, err := clientutil.NewK8s()
+	workers, err := nodes.GetWorkers(&deployer.Environment{Cli: cli, Ctx: ctx})
 	if err != nil {
 		return err
 	}
 
-	workers, err := nodes.GetWorkers(&deployer.Environment{Cli: cli, Ctx: ctx})
+	unsynced, err := data.src.unsyncedSchedCaches(ctx, cli, objectnames.Nodes(workers))
 	if err != nil {
 		return err
 	}
 
+	data.unsynchedCaches = unsynced
+
+	return nil
+}
+
+func (ls liveSource) unsyncedSchedCaches(ctx context.Context, cli client.Client, nodeNames []string) (map[string]sets.Set[string], error) {
+	k8sCli, err := clientutil.NewK8s()
+	if err != nil {
+		return nil, err
+	}
+
 	env := schedcache.Env{
 		Ctx:    ctx,
 		Cli:    cli,
 		K8sCli: k8sCli,
 		Log:    logr.Discard(),
 	}
-
-	_, unsynced, err := schedcache.HasSynced(&env, objectnames.Nodes(workers))
-	if err != nil {
-		return err
+	if !ls.opts.PFPStatusExec {
+		env.PFPStatus, err = pfpstatus.NewClient()
+		if err != nil {
+			// the dumps can still be read executing commands in the pods
+			klog.InfoS("cannot create the pod fingerprint status client, executing commands in the pods", "error", err)
+			env.PFPStatus = nil
+		}
 	}
 
-	data.unsynchedCaches = unsynced
-
-	return nil
+	_, unsynced, err := schedcache.HasSynced(&env, nodeNames)
+	return unsynced, err
 }
 
 func ValidateSchedCache(data ValidatorData) ([]deployervalidator.ValidationResult, error) {
@@ -0,0 +1,242 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	k8swgmanifests "github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+	k8swgobjupdate "github.com/k8stopologyawareschedwg/deployer/pkg/objectupdate"
+	deployervalidator "github.com/k8stopologyawareschedwg/deployer/pkg/validator"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	"github.com/openshift-kni/numaresources-operator/pkg/hash"
+	schedstate "github.com/openshift-kni/numaresources-operator/pkg/numaresourcesscheduler/objectstate/sched"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
+	schedupdate "github.com/openshift-kni/numaresources-operator/pkg/objectupdate/sched"
+)
+
+const (
+	ValidatorScheduler = "sched"
+)
+
+const (
+	componentScheduler = "scheduler"
+
+	valueUnset = "unset"
+)
+
+// schedulerState holds the scheduler objects, as found in the cluster.
+// The objects are nil if missing.
+type schedulerState struct {
+	instance   *nropv1.NUMAResourcesScheduler
+	deployment *appsv1.Deployment
+	configMap  *corev1.ConfigMap
+}
+
+func CollectScheduler(ctx context.Context, cli client.Client, data *ValidatorData) error {
+	instance := nropv1.NUMAResourcesScheduler{}
+	err := cli.Get(ctx, client.ObjectKey{Name: objectnames.DefaultNUMAResourcesSchedulerCrName}, &instance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			fmt.Fprintf(os.Stderr, "INFO>>>>: NUMA-aware scheduler not deployed, skipping scheduler validation\n")
+			return nil
+		}
+		return err
+	}
+
+	state := schedulerState{
+		instance: &instance,
+	}
+	data.scheduler = &state
+
+	if instance.Status.Deployment.Name == "" {
+		return nil
+	}
+	dp := appsv1.Deployment{}
+	err = cli.Get(ctx, client.ObjectKey(instance.Status.Deployment), &dp)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	state.deployment = &dp
+
+	cmName := configMapNameFromDeployment(&dp)
+	if cmName == "" {
+		return nil
+	}
+	cm := corev1.ConfigMap{}
+	err = cli.Get(ctx, client.ObjectKey{Namespace: dp.Namespace, Name: cmName}, &cm)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	state.configMap = &cm
+	return nil
+}
+
+// ValidateScheduler checks the scheduler Deployment and the configuration it consumes
+// match the NUMAResourcesScheduler spec. Differences in the configuration are usually
+// caused by manual edits of the ConfigMap, which the pods don't pick up until restarted.
+func ValidateScheduler(data ValidatorData) ([]deployervalidator.ValidationResult, error) {
+	var ret []deployervalidator.ValidationResult
+	if data.scheduler == nil {
+		return ret, nil
+	}
+
+	instance := data.scheduler.instance
+	spec := instance.Spec.Normalize()
+
+	dp := data.scheduler.deployment
+	if dp == nil {
+		ret = append(ret, schedulerResult("Deployment "+instance.Status.Deployment.String(), "present", "missing"))
+		return ret, nil
+	}
+
+	if spec.SchedulerImage != "" {
+		image := valueUnset
+		if cnt := k8swgobjupdate.FindContainerByName(dp.Spec.Template.Spec.Containers, schedupdate.MainContainerName); cnt != nil {
+			image = cnt.Image
+		}
+		if image != spec.SchedulerImage {
+			ret = append(ret, schedulerResult("Deployment "+dp.Namespace+"/"+dp.Name+" image", spec.SchedulerImage, image))
+		}
+	}
+
+	cm := data.scheduler.configMap
+	if cm == nil {
+		ret = append(ret, schedulerResult("Deployment "+dp.Namespace+"/"+dp.Name+" ConfigMap", "present", "missing"))
+		return ret, nil
+	}
+	cmSetting := "ConfigMap " + cm.Namespace + "/" + cm.Name
+
+	if deployedHash, ok := dp.Spec.Template.Annotations[hash.ConfigMapAnnotation]; ok {
+		if currentHash := hash.ConfigMapData(cm); currentHash != deployedHash {
+			ret = append(ret, schedulerResult(cmSetting+" data", "as deployed ("+deployedHash+")", "edited after deployment ("+currentHash+")"))
+		}
+	}
+
+	profiles, err := k8swgmanifests.DecodeSchedulerProfilesFromData([]byte(cm.Data[schedstate.SchedulerConfigFileName]))
+	if err != nil {
+		return ret, err
+	}
+
+	schedulerName := instance.Status.SchedulerName
+	if schedulerName == "" {
+		schedulerName = spec.SchedulerName
+	}
+	profile := k8swgmanifests.FindSchedulerProfileByName(profiles, schedulerName)
+	if profile == nil {
+		names := make([]string, 0, len(profiles))
+		for _, prof := range profiles {
+			names = append(names, prof.ProfileName)
+		}
+		ret = append(ret, schedulerResult(cmSetting+" profile", schedulerName, "["+strings.Join(names, ",")+"]"))
+		return ret, nil
+	}
+	profSetting := cmSetting + " profile " + schedulerName
+
+	expected := schedupdate.ConfigParamsFromSpec(spec, spec.CacheResyncPeriod.Round(time.Second), dp.Namespace)
+	detectedCache := profile.Cache
+	if detectedCache == nil {
+		detectedCache = &k8swgmanifests.ConfigCacheParams{}
+	}
+	ret = appendIfDiffers(ret, profSetting+" cacheResyncPeriodSeconds", int64PtrToString(expected.Cache.ResyncPeriodSeconds), int64PtrToString(detectedCache.ResyncPeriodSeconds))
+	ret = appendIfDiffers(ret, profSetting+" cache.resyncMethod", stringPtrToString(expected.Cache.ResyncMethod), stringPtrToString(detectedCache.ResyncMethod))
+	ret = appendIfDiffers(ret, profSetting+" cache.foreignPodsDetect", stringPtrToString(expected.Cache.ForeignPodsDetectMode), stringPtrToString(detectedCache.ForeignPodsDetectMode))
+	ret = appendIfDiffers(ret, profSetting+" cache.informerMode", stringPtrToString(expected.Cache.InformerMode), stringPtrToString(detectedCache.InformerMode))
+
+	detectedScoring := profile.ScoringStrategy
+	if detectedScoring == nil {
+		detectedScoring = &k8swgmanifests.ScoringStrategyParams{}
+	}
+	ret = appendIfDiffers(ret, profSetting+" scoringStrategy.type", expected.ScoringStrategy.Type, detectedScoring.Type)
+	ret = appendIfDiffers(ret, profSetting+" scoringStrategy.resources", resourceSpecsToString(expected.ScoringStrategy.Resources), resourceSpecsToString(detectedScoring.Resources))
+
+	replicas := int32(1)
+	if dp.Spec.Replicas != nil {
+		replicas = *dp.Spec.Replicas
+	}
+	detectedLeaderElect := valueUnset
+	if profile.LeaderElection != nil {
+		detectedLeaderElect = fmt.Sprintf("%v", profile.LeaderElection.LeaderElect)
+	}
+	ret = appendIfDiffers(ret, fmt.Sprintf("%s leaderElection.leaderElect (replicas=%d)", cmSetting, replicas), fmt.Sprintf("%v", replicas > 1), detectedLeaderElect)
+
+	return ret, nil
+}
+
+func configMapNameFromDeployment(dp *appsv1.Deployment) string {
+	for _, vol := range dp.Spec.Template.Spec.Volumes {
+		if vol.Name == schedstate.SchedulerConfigMapVolumeName && vol.ConfigMap != nil {
+			return vol.ConfigMap.Name
+		}
+	}
+	return ""
+}
+
+func schedulerResult(setting, expected, detected string) deployervalidator.ValidationResult {
+	return deployervalidator.ValidationResult{
+		Area:      deployervalidator.AreaCluster,
+		Component: componentScheduler,
+		Setting:   setting,
+		Expected:  expected,
+		Detected:  detected,
+	}
+}
+
+func appendIfDiffers(ret []deployervalidator.ValidationResult, setting, expected, detected string) []deployervalidator.ValidationResult {
+	if expected == detected {
+		return ret
+	}
+	return append(ret, schedulerResult(setting, expected, detected))
+}
+
+func int64PtrToString(v *int64) string {
+	if v == nil {
+		return valueUnset
+	}
+	return fmt.Sprintf("%d", *v)
+}
+
+func stringPtrToString(v *string) string {
+	if v == nil {
+		return valueUnset
+	}
+	return *v
+}
+
+func resourceSpecsToString(resources []k8swgmanifests.ResourceSpecParams) string {
+	items := make([]string, 0, len(resources))
+	for _, res := range resources {
+		items = append(items, fmt.Sprintf("%s=%d", res.Name, res.Weight))
+	}
+	return "[" + strings.Join(items, ",") + "]"
+}
@@ -0,0 +1,282 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validator
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+
+	deployervalidator "github.com/k8stopologyawareschedwg/deployer/pkg/validator"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	"github.com/openshift-kni/numaresources-operator/pkg/hash"
+	schedstate "github.com/openshift-kni/numaresources-operator/pkg/numaresourcesscheduler/objectstate/sched"
+	schedupdate "github.com/openshift-kni/numaresources-operator/pkg/objectupdate/sched"
+)
+
+const (
+	testSchedNamespace = "openshift-numaresources"
+	testSchedImage     = "quay.io/openshift-kni/scheduler-plugins:test"
+	testSchedName      = "topo-aware-scheduler"
+)
+
+func TestValidateScheduler(t *testing.T) {
+	cmSetting := "ConfigMap openshift-numaresources/topo-aware-scheduler-config"
+	profSetting := cmSetting + " profile " + testSchedName
+
+	testCases := []struct {
+		name     string
+		mutate   func(state *schedulerState)
+		expected []deployervalidator.ValidationResult
+	}{
+		{
+			name: "all good",
+		},
+		{
+			name: "missing deployment",
+			mutate: func(state *schedulerState) {
+				state.deployment = nil
+			},
+			expected: []deployervalidator.ValidationResult{
+				schedulerResult("Deployment openshift-numaresources/secondary-scheduler", "present", "missing"),
+			},
+		},
+		{
+			name: "wrong image",
+			mutate: func(state *schedulerState) {
+				state.deployment.Spec.Template.Spec.Containers[0].Image = "quay.io/someone/scheduler:latest"
+			},
+			expected: []deployervalidator.ValidationResult{
+				schedulerResult("Deployment openshift-numaresources/secondary-scheduler image", testSchedImage, "quay.io/someone/scheduler:latest"),
+			},
+		},
+		{
+			name: "missing configmap",
+			mutate: func(state *schedulerState) {
+				state.configMap = nil
+			},
+			expected: []deployervalidator.ValidationResult{
+				schedulerResult("Deployment openshift-numaresources/secondary-scheduler ConfigMap", "present", "missing"),
+			},
+		},
+		{
+			name: "spec changed but not rendered",
+			mutate: func(state *schedulerState) {
+				state.instance.Spec.ScoringStrategy = &nropv1.ScoringStrategyParams{Type: nropv1.MostAllocated}
+				state.instance.Spec.SchedulerInformer = ptr.To(nropv1.SchedulerInformerShared)
+			},
+			expected: []deployervalidator.ValidationResult{
+				schedulerResult(profSetting+" cache.informerMode", "Shared", "Dedicated"),
+				schedulerResult(profSetting+" scoringStrategy.type", "MostAllocated", "LeastAllocated"),
+			},
+		},
+		{
+			name: "scaled up without leader election",
+			mutate: func(state *schedulerState) {
+				state.deployment.Spec.Replicas = ptr.To[int32](3)
+			},
+			expected: []deployervalidator.ValidationResult{
+				schedulerResult(cmSetting+" leaderElection.leaderElect (replicas=3)", "true", "false"),
+			},
+		},
+		{
+			name: "profile renamed",
+			mutate: func(state *schedulerState) {
+				state.instance.Status.SchedulerName = "other-scheduler"
+			},
+			expected: []deployervalidator.ValidationResult{
+				schedulerResult(cmSetting+" profile", "other-scheduler", "["+testSchedName+"]"),
+			},
+		},
+		{
+			name: "configmap edited manually",
+			mutate: func(state *schedulerState) {
+				data := state.configMap.Data[schedstate.SchedulerConfigFileName]
+				state.configMap.Data[schedstate.SchedulerConfigFileName] = strings.Replace(data, "cacheResyncPeriodSeconds: 5", "cacheResyncPeriodSeconds: 30", 1)
+			},
+			expected: []deployervalidator.ValidationResult{
+				schedulerResult(cmSetting+" data", "as deployed", "edited after deployment"),
+				schedulerResult(profSetting+" cacheResyncPeriodSeconds", "5", "30"),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			state := makeSchedulerState(t)
+			if tc.mutate != nil {
+				tc.mutate(state)
+			}
+			got, err := ValidateScheduler(ValidatorData{scheduler: state})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// the hashes are not interesting, and hard to predict
+			for idx := range got {
+				if strings.HasSuffix(got[idx].Setting, " data") {
+					got[idx].Expected, _, _ = strings.Cut(got[idx].Expected, " (")
+					got[idx].Detected, _, _ = strings.Cut(got[idx].Detected, " (")
+				}
+			}
+			if len(got) != len(tc.expected) || (len(got) > 0 && !reflect.DeepEqual(got, tc.expected)) {
+				t.Errorf("got=%#v expected=%#v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestValidateSchedulerNotDeployed(t *testing.T) {
+	got, err := ValidateScheduler(ValidatorData{})
+	if err != nil || len(got) != 0 {
+		t.Errorf("unexpected results=%v err=%v", got, err)
+	}
+}
+
+func TestCollectScheduler(t *testing.T) {
+	state := makeSchedulerState(t)
+	state.deployment.Spec.Template.Spec.Containers[0].Image = "quay.io/someone/scheduler:latest"
+
+	dir := t.TempDir()
+	writeMustGatherFiles(t, dir, map[string]string{
+		"cluster-scoped-resources/core/nodes/worker-0.yaml":                                                      nodeWorker0,
+		"cluster-scoped-resources/nodetopology.openshift.io/numaresourcesschedulers/numaresourcesscheduler.yaml": toYAML(t, state.instance),
+		"namespaces/openshift-numaresources/apps/deployments/secondary-scheduler.yaml":                           toYAML(t, state.deployment),
+		"namespaces/openshift-numaresources/core/configmaps.yaml":                                                toYAML(t, state.configMap),
+	})
+
+	what := sets.New[string](ValidatorScheduler)
+	data, err := CollectFromDir(context.Background(), newTestScheme(t), dir, "node-role.kubernetes.io/worker=", what)
+	if err != nil {
+		t.Fatalf("unexpected error collecting data: %v", err)
+	}
+
+	rep, err := Validate(data)
+	if err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+	expected := []deployervalidator.ValidationResult{
+		schedulerResult("Deployment openshift-numaresources/secondary-scheduler image", testSchedImage, "quay.io/someone/scheduler:latest"),
+	}
+	if got := validationResults(rep.Errors); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got=%#v expected=%#v", got, expected)
+	}
+}
+
+// makeSchedulerState returns the objects as the operator renders them out of a NUMAResourcesScheduler with the default settings
+func makeSchedulerState(t *testing.T) *schedulerState {
+	t.Helper()
+	instance := &nropv1.NUMAResourcesScheduler{
+		TypeMeta: metav1.TypeMeta{APIVersion: "nodetopology.openshift.io/v1", Kind: "NUMAResourcesScheduler"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "numaresourcesscheduler",
+		},
+		Spec: nropv1.NUMAResourcesSchedulerSpec{
+			SchedulerImage: testSchedImage,
+			SchedulerName:  testSchedName,
+		},
+		Status: nropv1.NUMAResourcesSchedulerStatus{
+			Deployment: nropv1.NamespacedName{
+				Namespace: testSchedNamespace,
+				Name:      "secondary-scheduler",
+			},
+			SchedulerName: testSchedName,
+		},
+	}
+
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testSchedNamespace,
+			Name:      "topo-aware-scheduler-config",
+		},
+		Data: map[string]string{
+			schedstate.SchedulerConfigFileName: baseSchedConfig,
+		},
+	}
+	spec := instance.Spec.Normalize()
+	params := schedupdate.ConfigParamsFromSpec(spec, spec.CacheResyncPeriod.Duration, testSchedNamespace)
+	if err := schedupdate.SchedulerConfig(cm, "base-scheduler", &params); err != nil {
+		t.Fatalf("cannot render the scheduler config: %v", err)
+	}
+
+	dp := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testSchedNamespace,
+			Name:      "secondary-scheduler",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To[int32](1),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						hash.ConfigMapAnnotation: hash.ConfigMapData(cm),
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  schedupdate.MainContainerName,
+							Image: testSchedImage,
+						},
+					},
+					Volumes: []corev1.Volume{
+						schedstate.NewSchedConfigVolume(schedstate.SchedulerConfigMapVolumeName, cm.Name),
+					},
+				},
+			},
+		},
+	}
+
+	return &schedulerState{
+		instance:   instance,
+		deployment: dp,
+		configMap:  cm,
+	}
+}
+
+const baseSchedConfig = `apiVersion: kubescheduler.config.k8s.io/v1
+kind: KubeSchedulerConfiguration
+leaderElection:
+  leaderElect: false
+profiles:
+- pluginConfig:
+  - args:
+      apiVersion: kubescheduler.config.k8s.io/v1
+      cacheResyncPeriodSeconds: 3
+      kind: NodeResourceTopologyMatchArgs
+    name: NodeResourceTopologyMatch
+  plugins:
+    filter:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    reserve:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    score:
+      enabled:
+      - name: NodeResourceTopologyMatch
+  schedulerName: base-scheduler
+`
@@ -0,0 +1,254 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validator
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	deployervalidator "github.com/k8stopologyawareschedwg/deployer/pkg/validator"
+
+	intnrt "github.com/openshift-kni/numaresources-operator/internal/noderesourcetopology"
+	rteconfig "github.com/openshift-kni/numaresources-operator/rte/pkg/config"
+)
+
+const (
+	ValidatorTopologyManager = "tm"
+)
+
+const (
+	componentRTEConfig = "RTE configuration"
+	componentNRT       = "NodeResourceTopology"
+
+	settingTopologyManagerPolicy = "topologyManagerPolicy"
+	settingTopologyManagerScope  = "topologyManagerScope"
+)
+
+// topologyManagerSources holds the topology manager settings of a node as found in each source.
+// nil means the source has no data for the node.
+type topologyManagerSources struct {
+	kubelet   *rteconfig.KubeletParams
+	rteConfig *rteconfig.KubeletParams
+	nrt       *rteconfig.KubeletParams
+	// rteConfigInvalid is true if the RTE configuration of the node pool can't be decoded
+	rteConfigInvalid bool
+}
+
+func CollectTopologyManager(ctx context.Context, cli client.Client, data *ValidatorData) error {
+	// collect in a scratch area to not interfere with the other collectors, which may or may not run
+	scratch := ValidatorData{
+		tasEnabledNodeNames: data.tasEnabledNodeNames,
+		src:                 data.src,
+	}
+	// the MCO KubeletConfigs are not served on HyperShift: the nodes are then skipped,
+	// like the nodes without kubelet configuration.
+	if err := CollectKubeletConfig(ctx, cli, &scratch); err != nil && !meta.IsNoMatchError(err) {
+		return err
+	}
+	if err := CollectNodeResourceTopologies(ctx, cli, &scratch); err != nil {
+		return err
+	}
+
+	rteConfigs, rteConfigErrs, err := getRTEConfigsByNode(ctx, cli, data.tasEnabledNodeNames)
+	if err != nil {
+		return err
+	}
+	invalidNodeNames := sets.New[string]()
+	for _, confErr := range rteConfigErrs {
+		invalidNodeNames = invalidNodeNames.Union(confErr.nodeNames)
+	}
+
+	tmSources := make(map[string]topologyManagerSources)
+	for _, nodeName := range sets.List(data.tasEnabledNodeNames) {
+		srcs := topologyManagerSources{}
+		if kc := scratch.kConfigs[nodeName]; kc != nil {
+			srcs.kubelet = &rteconfig.KubeletParams{
+				TopologyManagerPolicy: kc.TopologyManagerPolicy,
+				TopologyManagerScope:  kc.TopologyManagerScope,
+			}
+		}
+		if conf, ok := rteConfigs[nodeName]; ok {
+			srcs.rteConfig = &conf
+		}
+		srcs.rteConfigInvalid = invalidNodeNames.Has(nodeName)
+		tmSources[nodeName] = srcs
+	}
+
+	if scratch.nrtList != nil {
+		for idx := range scratch.nrtList.Items {
+			nrt := &scratch.nrtList.Items[idx]
+			srcs, ok := tmSources[nrt.Name]
+			if !ok {
+				continue
+			}
+			policy, scope := intnrt.TopologyManagerFromAttributes(nrt.Attributes)
+			srcs.nrt = &rteconfig.KubeletParams{
+				TopologyManagerPolicy: policy,
+				TopologyManagerScope:  scope,
+			}
+			tmSources[nrt.Name] = srcs
+		}
+	}
+
+	data.tmSources = tmSources
+	data.rteConfigErrors = rteConfigErrs
+	return nil
+}
+
+// rteConfigError is a RTE configuration which can't be decoded
+type rteConfigError struct {
+	poolName  string
+	configMap string
+	err       error
+	// nodeNames holds the inspected nodes of the pool
+	nodeNames sets.Set[string]
+}
+
+// getRTEConfigsByNode returns the topology manager settings from the RTE configuration rendered for the pools of the given nodes.
+// The configurations which can't be decoded are returned separately.
+func getRTEConfigsByNode(ctx context.Context, cli client.Client, nodeNames sets.Set[string]) (map[string]rteconfig.KubeletParams, []rteConfigError, error) {
+	poolLabel := rteconfig.LabelNodeGroupName + "/" + rteconfig.LabelNodeGroupKindMachineConfigPool
+
+	cmList := corev1.ConfigMapList{}
+	if err := cli.List(ctx, &cmList, client.HasLabels{poolLabel}); err != nil {
+		return nil, nil, err
+	}
+
+	confsByNode := make(map[string]rteconfig.KubeletParams)
+	var confErrs []rteConfigError
+	for idx := range cmList.Items {
+		cm := &cmList.Items[idx]
+		poolName := cm.Labels[poolLabel]
+
+		poolNodeNames, err := getPoolNodeNames(ctx, cli, poolName)
+		if err != nil {
+			return nil, nil, err
+		}
+		poolNodeNames = poolNodeNames.Intersection(nodeNames)
+
+		conf, err := unpackRTEConfig(cm)
+		if err != nil {
+			confErrs = append(confErrs, rteConfigError{
+				poolName:  poolName,
+				configMap: client.ObjectKeyFromObject(cm).String(),
+				err:       err,
+				nodeNames: poolNodeNames,
+			})
+			continue
+		}
+		for nodeName := range poolNodeNames {
+			confsByNode[nodeName] = conf.Kubelet
+		}
+	}
+	return confsByNode, confErrs, nil
+}
+
+func unpackRTEConfig(cm *corev1.ConfigMap) (rteconfig.Config, error) {
+	data, err := rteconfig.UnpackConfigMap(cm)
+	if err != nil {
+		return rteconfig.Config{}, err
+	}
+	return rteconfig.Unrender(data)
+}
+
+// ValidateTopologyManager checks the topology manager settings in the kubelet configuration,
+// in the RTE configuration and in the NRT attributes agree. The kubelet configuration is the
+// source of truth. Missing kubelet configurations and NRT objects are reported by the k8scfg
+// and nrt validators, so they are skipped here. The RTE configurations which can't be decoded
+// are reported once for their pool.
+func ValidateTopologyManager(data ValidatorData) ([]deployervalidator.ValidationResult, error) {
+	var ret []deployervalidator.ValidationResult
+	for _, confErr := range data.rteConfigErrors {
+		ret = append(ret, deployervalidator.ValidationResult{
+			Area:      deployervalidator.AreaCluster,
+			Component: componentRTEConfig,
+			Setting:   fmt.Sprintf("pool %s ConfigMap %s", confErr.poolName, confErr.configMap),
+			Expected:  "valid",
+			Detected:  confErr.err.Error(),
+		})
+	}
+	for _, nodeName := range sets.List(data.tasEnabledNodeNames) {
+		srcs := data.tmSources[nodeName]
+		if srcs.kubelet == nil {
+			continue
+		}
+		expected := normalizeTopologyManager(*srcs.kubelet)
+
+		switch {
+		case srcs.rteConfigInvalid:
+			// already reported for the pool
+		case srcs.rteConfig == nil:
+			ret = append(ret, deployervalidator.ValidationResult{
+				Area:      deployervalidator.AreaKubelet,
+				Component: componentRTEConfig,
+				Node:      nodeName,
+				Setting:   "rendered",
+				Expected:  "true",
+				Detected:  "false",
+			})
+		default:
+			ret = append(ret, compareTopologyManager(nodeName, componentRTEConfig, expected, normalizeTopologyManager(*srcs.rteConfig))...)
+		}
+
+		if srcs.nrt != nil {
+			ret = append(ret, compareTopologyManager(nodeName, componentNRT, expected, normalizeTopologyManager(*srcs.nrt))...)
+		}
+	}
+	return ret, nil
+}
+
+func compareTopologyManager(nodeName, component string, expected, detected rteconfig.KubeletParams) []deployervalidator.ValidationResult {
+	var ret []deployervalidator.ValidationResult
+	if expected.TopologyManagerPolicy != detected.TopologyManagerPolicy {
+		ret = append(ret, deployervalidator.ValidationResult{
+			Area:      deployervalidator.AreaKubelet,
+			Component: component,
+			Node:      nodeName,
+			Setting:   settingTopologyManagerPolicy,
+			Expected:  expected.TopologyManagerPolicy,
+			Detected:  detected.TopologyManagerPolicy,
+		})
+	}
+	if expected.TopologyManagerScope != detected.TopologyManagerScope {
+		ret = append(ret, deployervalidator.ValidationResult{
+			Area:      deployervalidator.AreaKubelet,
+			Component: component,
+			Node:      nodeName,
+			Setting:   settingTopologyManagerScope,
+			Expected:  expected.TopologyManagerScope,
+			Detected:  detected.TopologyManagerScope,
+		})
+	}
+	return ret
+}
+
+// normalizeTopologyManager replaces the unset values with the kubelet defaults,
+// which the RTE uses as well when the values are not set in its configuration.
+func normalizeTopologyManager(params rteconfig.KubeletParams) rteconfig.KubeletParams {
+	if params.TopologyManagerPolicy == "" {
+		params.TopologyManagerPolicy = intnrt.None
+	}
+	if params.TopologyManagerScope == "" {
+		params.TopologyManagerScope = intnrt.Container
+	}
+	return params
+}
@@ -0,0 +1,277 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	deployervalidator "github.com/k8stopologyawareschedwg/deployer/pkg/validator"
+
+	rteconfig "github.com/openshift-kni/numaresources-operator/rte/pkg/config"
+)
+
+func TestValidateTopologyManager(t *testing.T) {
+	snn := &rteconfig.KubeletParams{TopologyManagerPolicy: "single-numa-node", TopologyManagerScope: "pod"}
+
+	testCases := []struct {
+		name     string
+		srcs     topologyManagerSources
+		confErrs []rteConfigError
+		expected []deployervalidator.ValidationResult
+	}{
+		{
+			name: "all consistent",
+			srcs: topologyManagerSources{kubelet: snn, rteConfig: snn, nrt: snn},
+		},
+		{
+			name: "missing kubelet config is reported elsewhere",
+			srcs: topologyManagerSources{rteConfig: snn, nrt: &rteconfig.KubeletParams{}},
+		},
+		{
+			name: "defaults are equivalent to unset values",
+			srcs: topologyManagerSources{
+				kubelet:   &rteconfig.KubeletParams{},
+				rteConfig: &rteconfig.KubeletParams{TopologyManagerPolicy: "none"},
+				nrt:       &rteconfig.KubeletParams{TopologyManagerPolicy: "none", TopologyManagerScope: "container"},
+			},
+		},
+		{
+			name: "RTE config not rendered",
+			srcs: topologyManagerSources{kubelet: snn, nrt: snn},
+			expected: []deployervalidator.ValidationResult{
+				{
+					Area:      deployervalidator.AreaKubelet,
+					Component: componentRTEConfig,
+					Node:      "worker-0",
+					Setting:   "rendered",
+					Expected:  "true",
+					Detected:  "false",
+				},
+			},
+		},
+		{
+			name: "RTE config not decoded is reported for the pool",
+			srcs: topologyManagerSources{kubelet: snn, nrt: snn, rteConfigInvalid: true},
+			confErrs: []rteConfigError{
+				{
+					poolName:  "worker-cnf",
+					configMap: "openshift-numaresources/numaresourcesoperator-worker-cnf",
+					err:       errors.New("missing data"),
+					nodeNames: sets.New[string]("worker-0"),
+				},
+			},
+			expected: []deployervalidator.ValidationResult{
+				{
+					Area:      deployervalidator.AreaCluster,
+					Component: componentRTEConfig,
+					Setting:   "pool worker-cnf ConfigMap openshift-numaresources/numaresourcesoperator-worker-cnf",
+					Expected:  "valid",
+					Detected:  "missing data",
+				},
+			},
+		},
+		{
+			name: "stale RTE config and NRT",
+			srcs: topologyManagerSources{
+				kubelet:   snn,
+				rteConfig: &rteconfig.KubeletParams{TopologyManagerPolicy: "restricted", TopologyManagerScope: "pod"},
+				nrt:       &rteconfig.KubeletParams{TopologyManagerPolicy: "restricted", TopologyManagerScope: "container"},
+			},
+			expected: []deployervalidator.ValidationResult{
+				{
+					Area:      deployervalidator.AreaKubelet,
+					Component: componentRTEConfig,
+					Node:      "worker-0",
+					Setting:   settingTopologyManagerPolicy,
+					Expected:  "single-numa-node",
+					Detected:  "restricted",
+				},
+				{
+					Area:      deployervalidator.AreaKubelet,
+					Component: componentNRT,
+					Node:      "worker-0",
+					Setting:   settingTopologyManagerPolicy,
+					Expected:  "single-numa-node",
+					Detected:  "restricted",
+				},
+				{
+					Area:      deployervalidator.AreaKubelet,
+					Component: componentNRT,
+					Node:      "worker-0",
+					Setting:   settingTopologyManagerScope,
+					Expected:  "pod",
+					Detected:  "container",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := ValidatorData{
+				tasEnabledNodeNames: sets.New[string]("worker-0"),
+				tmSources: map[string]topologyManagerSources{
+					"worker-0": tc.srcs,
+				},
+				rteConfigErrors: tc.confErrs,
+			}
+			got, err := ValidateTopologyManager(data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("validation mismatch:\ngot=%#v\nexpected=%#v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestCollectTopologyManager(t *testing.T) {
+	dir := t.TempDir()
+	writeMustGatherFiles(t, dir, map[string]string{
+		"cluster-scoped-resources/core/nodes/worker-0.yaml":                                                    nodeWorker0,
+		"cluster-scoped-resources/core/nodes/worker-1.yaml":                                                    nodeWorker1,
+		"cluster-scoped-resources/machineconfiguration.openshift.io/machineconfigpools/worker-cnf.yaml":        mcpWorkerCNF,
+		"cluster-scoped-resources/machineconfiguration.openshift.io/kubeletconfigs/worker-cnf.yaml":            kcWorkerCNF,
+		"cluster-scoped-resources/nodetopology.openshift.io/numaresourcesoperators/numaresourcesoperator.yaml": nroInstance,
+		"cluster-scoped-resources/topology.node.k8s.io/noderesourcetopologies.yaml":                            nrtListWithAttributes,
+		"namespaces/openshift-numaresources/core/configmaps.yaml":                                              rteConfigMapList,
+	})
+
+	what := sets.New[string](ValidatorTopologyManager, ValidatorKubeletConfig)
+	data, err := CollectFromDir(context.Background(), newTestScheme(t), dir, "", what)
+	if err != nil {
+		t.Fatalf("unexpected error collecting data: %v", err)
+	}
+
+	rep, err := Validate(data)
+	if err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	var tmErrors []deployervalidator.ValidationResult
+	for _, res := range rep.Errors {
+		if res.Component == componentRTEConfig || res.Component == componentNRT {
+			tmErrors = append(tmErrors, res.ValidationResult)
+		}
+	}
+	expected := []deployervalidator.ValidationResult{
+		{
+			Area:      deployervalidator.AreaKubelet,
+			Component: componentNRT,
+			Node:      "worker-1",
+			Setting:   settingTopologyManagerPolicy,
+			Expected:  "single-numa-node",
+			Detected:  "restricted",
+		},
+	}
+	if !reflect.DeepEqual(tmErrors, expected) {
+		t.Errorf("validation mismatch:\ngot=%#v\nexpected=%#v", tmErrors, expected)
+	}
+}
+
+func TestGetRTEConfigsByNodeHyperShift(t *testing.T) {
+	nodePoolNode := func(name, nodePool string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{"hypershift.openshift.io/nodePool": nodePool},
+			},
+		}
+	}
+	rteConfigMap := func(nodePool string, data map[string]string) *corev1.ConfigMap {
+		cm := rteconfig.CreateConfigMap("openshift-numaresources", "numaresourcesoperator-"+nodePool, "")
+		cm.Data = data
+		return rteconfig.AddSoftRefLabels(cm, "numaresourcesoperator", nodePool)
+	}
+
+	// no MachineConfigPools: the pools are the node pools
+	cli := fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(
+		nodePoolNode("worker-0", "np-good"),
+		nodePoolNode("worker-1", "np-bad"),
+		nodePoolNode("worker-2", "np-good"),
+		rteConfigMap("np-good", map[string]string{rteconfig.Key: "kubelet:\n  topologyManagerPolicy: single-numa-node\n"}),
+		rteConfigMap("np-bad", nil),
+	).Build()
+
+	confs, confErrs, err := getRTEConfigsByNode(context.Background(), cli, sets.New[string]("worker-0", "worker-1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedConfs := map[string]rteconfig.KubeletParams{
+		"worker-0": {TopologyManagerPolicy: "single-numa-node"},
+	}
+	if !reflect.DeepEqual(confs, expectedConfs) {
+		t.Errorf("configurations mismatch:\ngot=%#v\nexpected=%#v", confs, expectedConfs)
+	}
+
+	if len(confErrs) != 1 {
+		t.Fatalf("expected 1 invalid configuration, got %d: %#v", len(confErrs), confErrs)
+	}
+	if confErrs[0].poolName != "np-bad" || confErrs[0].configMap != "openshift-numaresources/numaresourcesoperator-np-bad" {
+		t.Errorf("unexpected invalid configuration: %#v", confErrs[0])
+	}
+	if !confErrs[0].nodeNames.Equal(sets.New[string]("worker-1")) {
+		t.Errorf("unexpected nodes of the invalid configuration: %v", sets.List(confErrs[0].nodeNames))
+	}
+}
+
+const nrtListWithAttributes = `apiVersion: v1
+kind: List
+items:
+- apiVersion: topology.node.k8s.io/v1alpha2
+  kind: NodeResourceTopology
+  metadata:
+    name: worker-0
+  attributes:
+  - name: topologyManagerPolicy
+    value: single-numa-node
+  - name: topologyManagerScope
+    value: container
+- apiVersion: topology.node.k8s.io/v1alpha2
+  kind: NodeResourceTopology
+  metadata:
+    name: worker-1
+  attributes:
+  - name: topologyManagerPolicy
+    value: restricted
+  - name: topologyManagerScope
+    value: container
+`
+
+const rteConfigMapList = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: numaresourcesoperator-worker-cnf
+  namespace: openshift-numaresources
+  labels:
+    numaresourcesoperator.nodetopology.openshift.io/name: numaresourcesoperator
+    nodegroup.nodetopology.openshift.io/machineconfigpool: worker-cnf
+data:
+  config.yaml: |
+    kubelet:
+      topologyManagerPolicy: single-numa-node
+`
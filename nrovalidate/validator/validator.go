@@ -42,8 +42,19 @@ import (
 )
 
 type Report struct {
-	Succeeded bool                                 `json:"succeeded"`
-	Errors    []deployervalidator.ValidationResult `json:"errors,omitempty"`
+	Succeeded bool      `json:"succeeded"`
+	Errors    []Finding `json:"errors,omitempty"`
+	// Results holds the errors reported by each validator which ran
+	Results map[string][]Finding `json:"-"`
+	// NodeNames holds the names of the inspected nodes, sorted
+	NodeNames []string `json:"-"`
+}
+
+// Finding is a validation error along with the validator which reported it and, if known, how to fix it
+type Finding struct {
+	deployervalidator.ValidationResult
+	Validator   string       `json:"validator"`
+	Remediation *Remediation `json:"remediation,omitempty"`
 }
 
 func Requested(what string) (sets.Set[string], error) {
@@ -79,6 +90,10 @@ func Available() sets.Set[string] {
 		ValidatorNodeResourceTopologies,
 		ValidatorPodStatus,
 		ValidatorSchedCache,
+		ValidatorTopologyManager,
+		ValidatorPlacement,
+		ValidatorOperator,
+		ValidatorScheduler,
 	)
 }
 
@@ -86,11 +101,18 @@ type ValidatorData struct {
 	tasEnabledNodeNames  sets.Set[string]
 	nonRunningPodsByNode map[string]map[string]corev1.PodPhase
 	kConfigs             map[string]*kubeletconfigv1beta1.KubeletConfiguration
+	kConfigNames         map[string]string
 	unsynchedCaches      map[string]sets.Set[string]
 	nrtCrdMissing        bool
 	nrtList              *nrtv1alpha2.NodeResourceTopologyList
+	tmSources            map[string]topologyManagerSources
+	rteConfigErrors      []rteConfigError
+	placement            *placementData
+	operator             *operatorState
+	scheduler            *schedulerState
 	versionInfo          *version.Info
 	what                 sets.Set[string]
+	src                  source
 }
 
 type CollectFunc func(ctx context.Context, cli client.Client, data *ValidatorData) error
@@ -101,10 +123,26 @@ func Collectors() map[string]CollectFunc {
 		ValidatorNodeResourceTopologies: CollectNodeResourceTopologies,
 		ValidatorPodStatus:              CollectPodStatus,
 		ValidatorSchedCache:             CollectSchedCache,
+		ValidatorTopologyManager:        CollectTopologyManager,
+		ValidatorPlacement:              CollectPlacement,
+		ValidatorOperator:               CollectOperator,
+		ValidatorScheduler:              CollectScheduler,
 	}
 }
 
-func Collect(ctx context.Context, cli client.Client, userLabels string, what sets.Set[string]) (ValidatorData, error) {
+// LiveOptions tunes how the data is gathered from a live cluster
+type LiveOptions struct {
+	// PFPStatusExec reads the pod fingerprint status executing commands in the scheduler pods,
+	// instead of using the HTTPS endpoint, which requires the pods to be reachable.
+	PFPStatusExec bool
+}
+
+// Collect gathers the data for the requested validations from a live cluster.
+func Collect(ctx context.Context, cli client.Client, userLabels string, what sets.Set[string], opts LiveOptions) (ValidatorData, error) {
+	return collect(ctx, cli, liveSource{opts: opts}, userLabels, what)
+}
+
+func collect(ctx context.Context, cli client.Client, src source, userLabels string, what sets.Set[string]) (ValidatorData, error) {
 	collectors := Collectors()
 	colFns := []CollectFunc{}
 	for _, vd := range what.UnsortedList() {
@@ -117,9 +155,10 @@ func Collect(ctx context.Context, cli client.Client, userLabels string, what set
 
 	data := ValidatorData{
 		what: what,
+		src:  src,
 	}
 
-	ver, err := getClusterVersionInfo()
+	ver, err := src.serverVersion(ctx, cli)
 	if err != nil {
 		return data, err
 	}
@@ -201,32 +240,45 @@ func Validators() map[string]ValidateFunc {
 		ValidatorNodeResourceTopologies: ValidateNodeResourceTopologies,
 		ValidatorPodStatus:              ValidatePodStatus,
 		ValidatorSchedCache:             ValidateSchedCache,
+		ValidatorTopologyManager:        ValidateTopologyManager,
+		ValidatorPlacement:              ValidatePlacement,
+		ValidatorOperator:               ValidateOperator,
+		ValidatorScheduler:              ValidateScheduler,
 	}
 }
 
 func Validate(data ValidatorData) (Report, error) {
 	validators := Validators()
-	valFns := []ValidateFunc{}
 	for _, vd := range data.what.UnsortedList() {
-		fn, ok := validators[vd]
-		if !ok {
+		if _, ok := validators[vd]; !ok {
 			return Report{}, fmt.Errorf("unsupported validator: %q", vd)
 		}
-		valFns = append(valFns, fn)
 	}
 
-	var ret []validator.ValidationResult
-	for _, helper := range valFns {
-		res, err := helper(data)
+	var ret []Finding
+	results := make(map[string][]Finding)
+	for _, vd := range sets.List(data.what) {
+		res, err := validators[vd](data)
 		if err != nil {
 			return Report{}, err
 		}
-		ret = append(ret, res...)
+		findings := make([]Finding, 0, len(res))
+		for _, item := range res {
+			findings = append(findings, Finding{
+				ValidationResult: item,
+				Validator:        vd,
+				Remediation:      Remediate(data, vd, item),
+			})
+		}
+		results[vd] = findings
+		ret = append(ret, findings...)
 	}
 
 	return Report{
 		Succeeded: len(ret) == 0,
 		Errors:    ret,
+		Results:   results,
+		NodeNames: sets.List(data.tasEnabledNodeNames),
 	}, nil
 }
 
@@ -246,6 +298,24 @@ func GetKubeletConfigurationsFromTASEnabledNodes(ctx context.Context, cli client
 	return validatorData.kConfigs, nil
 }
 
+// source provides the data the collectors can't fetch using the controller-runtime client,
+// either because they require other clients or because they are not API objects.
+type source interface {
+	serverVersion(ctx context.Context, cli client.Client) (*version.Info, error)
+	// nodeResourceTopologies returns true if the NRT CRD is missing
+	nodeResourceTopologies(ctx context.Context, cli client.Client) (*nrtv1alpha2.NodeResourceTopologyList, bool, error)
+	unsyncedSchedCaches(ctx context.Context, cli client.Client, nodeNames []string) (map[string]sets.Set[string], error)
+}
+
+// liveSource fetches the data from the cluster
+type liveSource struct {
+	opts LiveOptions
+}
+
+func (liveSource) serverVersion(_ context.Context, _ client.Client) (*version.Info, error) {
+	return getClusterVersionInfo()
+}
+
 func getClusterVersionInfo() (*version.Info, error) {
 	cli, err := clientutil.NewDiscoveryClient()
 	if err != nil {
@@ -55,17 +55,21 @@ func TestRequested(t *testing.T) {
 			expectedValue: available,
 		},
 		{
-			what:          "k8scfg,podst,nrt,schedcache",
+			what:          "k8scfg,podst,nrt,schedcache,tm,placement,nro,sched",
 			expectedValue: available,
 		},
 		{
-			what:          "nrt,k8scfg,schedcache,podst",
+			what:          "nrt,k8scfg,placement,tm,nro,schedcache,sched,podst",
 			expectedValue: available,
 		},
 		{
-			what:          "     schedcache,nrt,  k8scfg ,   podst ",
+			what:          "     schedcache,nrt, tm, k8scfg ,   podst,placement, nro,sched ",
 			expectedValue: available,
 		},
+		{
+			what:          "nrt,tm",
+			expectedValue: "nrt,tm",
+		},
 	}
 
 	for _, tc := range testcases {
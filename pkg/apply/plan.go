@@ -0,0 +1,138 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/openshift-kni/numaresources-operator/pkg/objectstate"
+)
+
+type Action string
+
+const (
+	ActionNone   Action = "none"
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Change describes the change applying an object state would make to the cluster.
+type Change struct {
+	Action    Action `json:"action"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Patch is the strategic merge patch from the existing object to the updated one, set only for updates.
+	Patch json.RawMessage `json:"patch,omitempty"`
+}
+
+// PlanState computes the change ApplyState would make, without touching the cluster.
+// The scheme is used to identify the kind of the objects.
+func PlanState(scheme *runtime.Scheme, objState objectstate.ObjectState) (Change, error) {
+	if !objState.IsCreateOrUpdate() { // delete requested
+		if objState.IsNotFoundError() {
+			return Change{Action: ActionNone}, nil
+		}
+		if objState.Existing == nil {
+			return Change{}, fmt.Errorf("inconsistent internal state: delete desired, but nil existing object")
+		}
+		return PlanDelete(scheme, objState.Existing)
+	}
+
+	if objState.IsNotFoundError() {
+		return makeChange(scheme, ActionCreate, objState.Desired)
+	}
+
+	merged, err := objState.Merge(objState.Existing, objState.Desired)
+	if err != nil {
+		return Change{}, fmt.Errorf("could not merge object %s/%s with existing: %w", objState.Desired.GetNamespace(), objState.Desired.GetName(), err)
+	}
+	ok, err := objState.Compare(objState.Existing, merged)
+	if err != nil {
+		return Change{}, fmt.Errorf("could not compare object %s/%s with existing: %w", objState.Desired.GetNamespace(), objState.Desired.GetName(), err)
+	}
+	if ok {
+		return makeChange(scheme, ActionNone, merged)
+	}
+
+	patch, err := makePatch(objState.Existing, merged)
+	if err != nil {
+		return Change{}, fmt.Errorf("could not compute patch for object %s/%s: %w", objState.Desired.GetNamespace(), objState.Desired.GetName(), err)
+	}
+	// the status is not updated, so the update would be a no-op
+	if string(patch) == "{}" {
+		return makeChange(scheme, ActionNone, merged)
+	}
+
+	change, err := makeChange(scheme, ActionUpdate, merged)
+	change.Patch = patch
+	return change, err
+}
+
+// PlanDelete describes the deletion of an existing object.
+func PlanDelete(scheme *runtime.Scheme, obj k8sclient.Object) (Change, error) {
+	return makeChange(scheme, ActionDelete, obj)
+}
+
+func makeChange(scheme *runtime.Scheme, action Action, obj k8sclient.Object) (Change, error) {
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return Change{}, err
+	}
+	return Change{
+		Action:    action,
+		Kind:      gvk.String(),
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	}, nil
+}
+
+func makePatch(existing, updated k8sclient.Object) (json.RawMessage, error) {
+	existingData, err := specData(existing)
+	if err != nil {
+		return nil, err
+	}
+	updatedData, err := specData(updated)
+	if err != nil {
+		return nil, err
+	}
+	return strategicpatch.CreateTwoWayMergePatch(existingData, updatedData, existing)
+}
+
+// specData serializes the object leaving out the type information, which may be missing from the objects
+// read from the cluster, and the status, which is never updated when applying objects.
+func specData(obj k8sclient.Object) ([]byte, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "apiVersion")
+	delete(fields, "kind")
+	delete(fields, "status")
+	return json.Marshal(fields)
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/openshift-kni/numaresources-operator/pkg/objectstate"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectstate/compare"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectstate/merge"
+)
+
+func TestPlanState(t *testing.T) {
+	notFound := apierrors.NewNotFound(schema.GroupResource{}, "")
+
+	dsWithStatus := dsExist.DeepCopy()
+	dsWithStatus.Status.NumberReady = 3
+
+	testCases := []struct {
+		name          string
+		objState      objectstate.ObjectState
+		expectedKind  string
+		expected      Action
+		expectedPatch string
+	}{
+		{
+			name: "create",
+			objState: objectstate.ObjectState{
+				Error:   notFound,
+				Desired: cmExist.DeepCopy(),
+			},
+			expectedKind: "/v1, Kind=ConfigMap",
+			expected:     ActionCreate,
+		},
+		{
+			name: "update",
+			objState: objectstate.ObjectState{
+				Existing: cmExist.DeepCopy(),
+				Desired:  mutateCM(cmExist),
+				Compare:  compare.Object,
+				Merge:    merge.ObjectForUpdate,
+			},
+			expectedKind:  "/v1, Kind=ConfigMap",
+			expected:      ActionUpdate,
+			expectedPatch: `{"data":{"foo3":"new-data"}}`,
+		},
+		{
+			name: "unchanged",
+			objState: objectstate.ObjectState{
+				Existing: cmExist.DeepCopy(),
+				Desired:  cmExist.DeepCopy(),
+				Compare:  compare.Object,
+				Merge:    merge.ObjectForUpdate,
+			},
+			expectedKind: "/v1, Kind=ConfigMap",
+			expected:     ActionNone,
+		},
+		{
+			name: "status only differs",
+			objState: objectstate.ObjectState{
+				Existing: dsWithStatus,
+				Desired:  dsExist.DeepCopy(),
+				Compare:  compare.Object,
+				Merge:    merge.ObjectForUpdate,
+			},
+			expectedKind: "apps/v1, Kind=DaemonSet",
+			expected:     ActionNone,
+		},
+		{
+			name: "update daemonset",
+			objState: objectstate.ObjectState{
+				Existing: dsExist.DeepCopy(),
+				Desired:  mutateDS(dsExist),
+				Compare:  compare.Object,
+				Merge:    merge.ObjectForUpdate,
+			},
+			expectedKind:  "apps/v1, Kind=DaemonSet",
+			expected:      ActionUpdate,
+			expectedPatch: `{"spec":{"template":{"spec":{"$setElementOrder/containers":[{"name":"new-container-name"}],"containers":[{"name":"new-container-name","resources":{}},{"$patch":"delete","name":"test-container"}]}}}}`,
+		},
+		{
+			name: "delete",
+			objState: objectstate.ObjectState{
+				Existing: dsExist.DeepCopy(),
+			},
+			expectedKind: "apps/v1, Kind=DaemonSet",
+			expected:     ActionDelete,
+		},
+		{
+			name: "delete missing",
+			objState: objectstate.ObjectState{
+				Error: notFound,
+			},
+			expected: ActionNone,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			change, err := PlanState(scheme.Scheme, tc.objState)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if change.Action != tc.expected {
+				t.Errorf("got action %q expected %q", change.Action, tc.expected)
+			}
+			if change.Kind != tc.expectedKind {
+				t.Errorf("got kind %q expected %q", change.Kind, tc.expectedKind)
+			}
+			if string(change.Patch) != tc.expectedPatch {
+				t.Errorf("got patch %s expected %s", string(change.Patch), tc.expectedPatch)
+			}
+		})
+	}
+}
+
+func TestPlanStateInconsistent(t *testing.T) {
+	_, err := PlanState(scheme.Scheme, objectstate.ObjectState{})
+	if err == nil {
+		t.Errorf("expected error planning a delete without existing object")
+	}
+}
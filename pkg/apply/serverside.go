@@ -0,0 +1,102 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/openshift-kni/numaresources-operator/pkg/objectstate"
+)
+
+// FieldManager is the stable name the operator uses to own the fields it applies server side.
+const FieldManager = "numaresources-operator"
+
+// ConflictFunc is called when the fields of an object the operator applies are owned by other managers,
+// before forcing the ownership of these fields. The error describes the conflicting fields and managers.
+type ConflictFunc func(obj k8sclient.Object, err error)
+
+// SupportsServerSideApply returns true if the object kind is applied server side by ApplyObjectServerSide.
+func SupportsServerSideApply(obj k8sclient.Object) bool {
+	switch obj.(type) {
+	case *appsv1.DaemonSet, *appsv1.Deployment, *corev1.ConfigMap,
+		*rbacv1.Role, *rbacv1.RoleBinding, *rbacv1.ClusterRole, *rbacv1.ClusterRoleBinding:
+		return true
+	default:
+		return false
+	}
+}
+
+// ApplyObjectServerSide is like ApplyObject, but lets the API server merge the desired object
+// using server-side apply, so the fields set by other controllers are preserved. The operator
+// takes the ownership of the conflicting fields after reporting them through onConflict, which
+// can be nil. The kinds not supported by server-side apply are applied using ApplyObject.
+func ApplyObjectServerSide(ctx context.Context, cli k8sclient.Client, objState objectstate.ObjectState, onConflict ConflictFunc) (k8sclient.Object, bool, error) {
+	if !SupportsServerSideApply(objState.Desired) {
+		return ApplyObject(ctx, cli, objState)
+	}
+	objDesc, _ := describeObject(objState.Desired)
+
+	obj, err := applyConfiguration(cli, objState.Desired)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not prepare object %s: %w", objDesc, err)
+	}
+	klog.V(4).InfoS("applying", "object", objDesc, "fieldManager", FieldManager)
+	err = cli.Patch(ctx, obj, k8sclient.Apply, k8sclient.FieldOwner(FieldManager))
+	if apierrors.IsConflict(err) {
+		klog.InfoS("conflicting field managers, forcing ownership", "object", objDesc, "conflict", err.Error())
+		if onConflict != nil {
+			onConflict(objState.Desired, err)
+		}
+		obj, err = applyConfiguration(cli, objState.Desired)
+		if err != nil {
+			return nil, false, fmt.Errorf("could not prepare object %s: %w", objDesc, err)
+		}
+		err = cli.Patch(ctx, obj, k8sclient.Apply, k8sclient.FieldOwner(FieldManager), k8sclient.ForceOwnership)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("could not apply object %s: %w", objDesc, err)
+	}
+
+	updated := objState.IsNotFoundError() || objState.Existing == nil || obj.GetResourceVersion() != objState.Existing.GetResourceVersion()
+	if updated {
+		klog.InfoS("applied", "object", objDesc)
+	}
+	return obj, updated, nil
+}
+
+// applyConfiguration returns a copy of the desired object suitable to be sent as server-side apply patch,
+// which requires the type information and forbids the managed fields.
+func applyConfiguration(cli k8sclient.Client, desired k8sclient.Object) (k8sclient.Object, error) {
+	gvk, err := apiutil.GVKForObject(desired, cli.Scheme())
+	if err != nil {
+		return nil, err
+	}
+	obj := desired.DeepCopyObject().(k8sclient.Object)
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	obj.SetManagedFields(nil)
+	obj.SetResourceVersion("")
+	return obj, nil
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/openshift-kni/numaresources-operator/pkg/objectstate"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectstate/compare"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectstate/merge"
+)
+
+// fakeApplier emulates the server-side apply the fake client does not support
+type fakeApplier struct {
+	// conflict makes the non-forced patches fail with a conflict
+	conflict bool
+	// resourceVersion is set in the applied objects
+	resourceVersion string
+	patches         []client.PatchOptions
+}
+
+func (fa *fakeApplier) Patch(ctx context.Context, cli client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return cli.Patch(ctx, obj, patch, opts...)
+	}
+	po := client.PatchOptions{}
+	po.ApplyOptions(opts)
+	fa.patches = append(fa.patches, po)
+	if obj.GetObjectKind().GroupVersionKind().Empty() {
+		return apierrors.NewBadRequest("missing type information")
+	}
+	if fa.conflict && (po.Force == nil || !*po.Force) {
+		return apierrors.NewConflict(schema.GroupResource{Resource: "daemonsets"}, obj.GetName(), nil)
+	}
+	obj.SetResourceVersion(fa.resourceVersion)
+	return nil
+}
+
+func TestApplyObjectServerSide(t *testing.T) {
+	dsExisting := dsExist.DeepCopy()
+	dsExisting.ResourceVersion = "1"
+
+	type testCase struct {
+		name            string
+		objectState     objectstate.ObjectState
+		applier         fakeApplier
+		expectPatches   int
+		expectForced    bool
+		expectConflicts int
+		expectUpdated   bool
+	}
+
+	testCases := []testCase{
+		{
+			name: "daemonset create",
+			objectState: objectstate.ObjectState{
+				Error:   apierrors.NewNotFound(schema.GroupResource{Resource: "daemonsets"}, dsExist.Name),
+				Desired: dsExist.DeepCopy(),
+			},
+			applier:       fakeApplier{resourceVersion: "1"},
+			expectPatches: 1,
+			expectUpdated: true,
+		},
+		{
+			name: "daemonset update",
+			objectState: objectstate.ObjectState{
+				Existing: dsExisting,
+				Desired:  mutateDS(dsExisting),
+			},
+			applier:       fakeApplier{resourceVersion: "2"},
+			expectPatches: 1,
+			expectUpdated: true,
+		},
+		{
+			name: "daemonset unchanged",
+			objectState: objectstate.ObjectState{
+				Existing: dsExisting,
+				Desired:  dsExisting.DeepCopy(),
+			},
+			applier:       fakeApplier{resourceVersion: "1"},
+			expectPatches: 1,
+			expectUpdated: false,
+		},
+		{
+			name: "daemonset conflict",
+			objectState: objectstate.ObjectState{
+				Existing: dsExisting,
+				Desired:  mutateDS(dsExisting),
+			},
+			applier:         fakeApplier{conflict: true, resourceVersion: "2"},
+			expectPatches:   2,
+			expectForced:    true,
+			expectConflicts: 1,
+			expectUpdated:   true,
+		},
+		{
+			name: "serviceaccount is not applied server side",
+			objectState: objectstate.ObjectState{
+				Error: apierrors.NewNotFound(schema.GroupResource{Resource: "serviceaccounts"}, "test-sa"),
+				Desired: &corev1.ServiceAccount{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: testNamespace,
+						Name:      "test-sa",
+					},
+				},
+				Compare: compare.Object,
+				Merge:   merge.ServiceAccountForUpdate,
+			},
+			expectPatches: 0,
+			expectUpdated: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithInterceptorFuncs(interceptor.Funcs{
+				Patch: tc.applier.Patch,
+			}).Build()
+
+			conflicts := 0
+			onConflict := func(obj client.Object, err error) {
+				if !apierrors.IsConflict(err) {
+					t.Errorf("unexpected conflict error: %v", err)
+				}
+				conflicts++
+			}
+
+			obj, updated, err := ApplyObjectServerSide(context.TODO(), fakeClient, tc.objectState, onConflict)
+			if err != nil {
+				t.Fatalf("failed to apply object: %v", err)
+			}
+			if updated != tc.expectUpdated {
+				t.Errorf("updated: got=%v expected=%v", updated, tc.expectUpdated)
+			}
+			if conflicts != tc.expectConflicts {
+				t.Errorf("conflicts: got=%d expected=%d", conflicts, tc.expectConflicts)
+			}
+			if len(tc.applier.patches) != tc.expectPatches {
+				t.Fatalf("patches: got=%d expected=%d", len(tc.applier.patches), tc.expectPatches)
+			}
+			for _, po := range tc.applier.patches {
+				if po.FieldManager != FieldManager {
+					t.Errorf("field manager: got=%q expected=%q", po.FieldManager, FieldManager)
+				}
+			}
+			if tc.expectPatches > 0 {
+				last := tc.applier.patches[len(tc.applier.patches)-1]
+				forced := last.Force != nil && *last.Force
+				if forced != tc.expectForced {
+					t.Errorf("forced: got=%v expected=%v", forced, tc.expectForced)
+				}
+				if _, ok := obj.(*appsv1.DaemonSet); !ok {
+					t.Errorf("unexpected applied object type %T", obj)
+				}
+			}
+		})
+	}
+}
@@ -35,20 +35,29 @@ import (
 const ConfigMapAnnotation = "configmap.hash"
 
 func ComputeCurrentConfigMap(ctx context.Context, cli client.Reader, cm *corev1.ConfigMap) (string, error) {
+	updatedConfigMap, err := CurrentConfigMap(ctx, cli, cm)
+	if err != nil {
+		return "", err
+	}
+	cmHash := ConfigMapData(updatedConfigMap)
+	klog.InfoS("configmap hash calculated", "hash", cmHash)
+	return cmHash, nil
+}
+
+// CurrentConfigMap returns the ConfigMap as found in the cluster, falling back to the given one
+// if not created yet. Use it to compute hashes over a subset of the ConfigMap data.
+func CurrentConfigMap(ctx context.Context, cli client.Reader, cm *corev1.ConfigMap) (*corev1.ConfigMap, error) {
 	updatedConfigMap := &corev1.ConfigMap{}
 	key := client.ObjectKeyFromObject(cm)
 
 	if err := cli.Get(ctx, key, updatedConfigMap); err != nil {
 		// ConfigMap not created yet, use the data from the manifests
 		if apierrors.IsNotFound(err) {
-			updatedConfigMap = cm
-		} else {
-			return "", fmt.Errorf("could not calculate ConfigMap %q hash: %w", key.String(), err)
+			return cm, nil
 		}
+		return nil, fmt.Errorf("could not calculate ConfigMap %q hash: %w", key.String(), err)
 	}
-	cmHash := ConfigMapData(updatedConfigMap)
-	klog.InfoS("configmap hash calculated", "hash", cmHash)
-	return cmHash, nil
+	return updatedConfigMap, nil
 }
 
 func ConfigMapData(cm *corev1.ConfigMap) string {
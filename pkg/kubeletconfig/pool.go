@@ -0,0 +1,146 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubeletconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	mcov1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+)
+
+// MCNameSuffixAnnotation is set by the MCO on KubeletConfig objects to record the suffix
+// of the name of the MachineConfig generated out of them.
+const MCNameSuffixAnnotation = "machineconfiguration.openshift.io/mc-name-suffix"
+
+// Conflict reports a kubelet setting of a KubeletConfig which is not honored because
+// another KubeletConfig targeting the same pool takes precedence.
+type Conflict struct {
+	// Field is the name of the setting in the kubelet configuration
+	Field string
+	// Overridden is the name of the KubeletConfig whose setting is not honored
+	Overridden string
+	// Effective is the name of the KubeletConfig which takes precedence
+	Effective string
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("%s: setting from %q overridden by %q", c.Field, c.Overridden, c.Effective)
+}
+
+// GeneratedMachineConfigName returns the name of the MachineConfig the MCO generates
+// out of the given KubeletConfig for the given pool.
+func GeneratedMachineConfigName(poolName string, mcoKc *mcov1.KubeletConfig) string {
+	name := "99-" + poolName + "-generated-kubelet"
+	if suffix := mcoKc.Annotations[MCNameSuffixAnnotation]; suffix != "" {
+		name += "-" + suffix
+	}
+	return name
+}
+
+// SortForPool sorts in place the KubeletConfigs targeting the given pool in the order
+// the MCO applies them. The MCO merges the generated MachineConfigs in lexical order
+// of their names, so the last KubeletConfig takes precedence.
+// Ties are broken by creation time, then by name.
+func SortForPool(poolName string, mcoKcs []*mcov1.KubeletConfig) {
+	sort.SliceStable(mcoKcs, func(i, j int) bool {
+		nameI := GeneratedMachineConfigName(poolName, mcoKcs[i])
+		nameJ := GeneratedMachineConfigName(poolName, mcoKcs[j])
+		if nameI != nameJ {
+			return nameI < nameJ
+		}
+		tsI, tsJ := mcoKcs[i].CreationTimestamp, mcoKcs[j].CreationTimestamp
+		if !tsI.Equal(&tsJ) {
+			return tsI.Before(&tsJ)
+		}
+		return mcoKcs[i].Name < mcoKcs[j].Name
+	})
+}
+
+// EffectiveForPool returns the KubeletConfig which takes precedence among all the ones
+// targeting the given pool, and the settings of the other KubeletConfigs which are not
+// honored as result. The MCO renders each KubeletConfig on top of the default kubelet
+// configuration and never merges KubeletConfigs among themselves, so the settings of a
+// KubeletConfig are honored only if the one taking precedence sets the same value.
+// The given slice is sorted in place, see SortForPool.
+func EffectiveForPool(poolName string, mcoKcs []*mcov1.KubeletConfig) (*mcov1.KubeletConfig, []Conflict, error) {
+	if len(mcoKcs) == 0 {
+		return nil, nil, nil
+	}
+	SortForPool(poolName, mcoKcs)
+
+	effective := mcoKcs[len(mcoKcs)-1]
+	effectiveFields, err := settingsOf(effective)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var conflicts []Conflict
+	for _, mcoKc := range mcoKcs[:len(mcoKcs)-1] {
+		fields, err := settingsOf(mcoKc)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, field := range sortedKeys(fields) {
+			effectiveValue, ok := effectiveFields[field]
+			if ok && reflect.DeepEqual(fields[field], effectiveValue) {
+				continue
+			}
+			conflicts = append(conflicts, Conflict{
+				Field:      field,
+				Overridden: mcoKc.Name,
+				Effective:  effective.Name,
+			})
+		}
+	}
+	return effective, conflicts, nil
+}
+
+func settingsOf(mcoKc *mcov1.KubeletConfig) (map[string]interface{}, error) {
+	if mcoKc.Spec.KubeletConfig == nil {
+		return nil, fmt.Errorf("KubeletConfig %q: %w", mcoKc.Name, MissingPayloadError)
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(mcoKc.Spec.KubeletConfig.Raw, &fields); err != nil {
+		return nil, fmt.Errorf("KubeletConfig %q: %w", mcoKc.Name, err)
+	}
+	// metadata, not settings
+	delete(fields, "kind")
+	delete(fields, "apiVersion")
+	return fields, nil
+}
+
+func sortedKeys(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ConflictsString returns a compact human-readable representation of the given conflicts.
+func ConflictsString(conflicts []Conflict) string {
+	items := make([]string, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		items = append(items, conflict.String())
+	}
+	return strings.Join(items, "; ")
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubeletconfig
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	mcov1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newKubeletConfig(name, suffix string, created time.Time, data string) *mcov1.KubeletConfig {
+	kc := &mcov1.KubeletConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: mcov1.KubeletConfigSpec{
+			KubeletConfig: &runtime.RawExtension{
+				Raw: []byte(data),
+			},
+		},
+	}
+	if suffix != "" {
+		kc.Annotations = map[string]string{
+			MCNameSuffixAnnotation: suffix,
+		}
+	}
+	return kc
+}
+
+func TestGeneratedMachineConfigName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		suffix   string
+		expected string
+	}{
+		{
+			name:     "no suffix",
+			expected: "99-worker-generated-kubelet",
+		},
+		{
+			name:     "with suffix",
+			suffix:   "2",
+			expected: "99-worker-generated-kubelet-2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			kc := newKubeletConfig("kc", tc.suffix, time.Now(), "{}")
+			got := GeneratedMachineConfigName("worker", kc)
+			if got != tc.expected {
+				t.Errorf("got %q expected %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestSortForPool(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		kcs      []*mcov1.KubeletConfig
+		expected []string
+	}{
+		{
+			name: "by suffix",
+			kcs: []*mcov1.KubeletConfig{
+				newKubeletConfig("kc-b", "1", base, "{}"),
+				newKubeletConfig("kc-c", "", base.Add(time.Hour), "{}"),
+				newKubeletConfig("kc-a", "2", base, "{}"),
+			},
+			expected: []string{"kc-c", "kc-b", "kc-a"},
+		},
+		{
+			name: "suffix compared lexically like MCO does",
+			kcs: []*mcov1.KubeletConfig{
+				newKubeletConfig("kc-ten", "10", base, "{}"),
+				newKubeletConfig("kc-two", "2", base, "{}"),
+			},
+			expected: []string{"kc-ten", "kc-two"},
+		},
+		{
+			name: "by creation time, then name",
+			kcs: []*mcov1.KubeletConfig{
+				newKubeletConfig("kc-b", "", base.Add(time.Hour), "{}"),
+				newKubeletConfig("kc-c", "", base, "{}"),
+				newKubeletConfig("kc-a", "", base.Add(time.Hour), "{}"),
+			},
+			expected: []string{"kc-c", "kc-a", "kc-b"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SortForPool("worker", tc.kcs)
+			got := make([]string, 0, len(tc.kcs))
+			for _, kc := range tc.kcs {
+				got = append(got, kc.Name)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("got %v expected %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestEffectiveForPool(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name              string
+		kcs               []*mcov1.KubeletConfig
+		expectedEffective string
+		expectedConflicts []Conflict
+		expectedError     bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "single",
+			kcs: []*mcov1.KubeletConfig{
+				newKubeletConfig("kc-a", "", base, `{"topologyManagerPolicy":"single-numa-node"}`),
+			},
+			expectedEffective: "kc-a",
+		},
+		{
+			name: "same values",
+			kcs: []*mcov1.KubeletConfig{
+				newKubeletConfig("kc-a", "", base, `{"kind":"KubeletConfiguration","topologyManagerPolicy":"single-numa-node"}`),
+				newKubeletConfig("kc-b", "1", base, `{"topologyManagerPolicy":"single-numa-node","maxPods":100}`),
+			},
+			expectedEffective: "kc-b",
+		},
+		{
+			name: "overridden and dropped settings",
+			kcs: []*mcov1.KubeletConfig{
+				newKubeletConfig("kc-b", "1", base, `{"topologyManagerPolicy":"restricted","maxPods":100}`),
+				newKubeletConfig("kc-a", "", base, `{"topologyManagerPolicy":"single-numa-node","cpuManagerPolicy":"static"}`),
+			},
+			expectedEffective: "kc-b",
+			expectedConflicts: []Conflict{
+				{Field: "cpuManagerPolicy", Overridden: "kc-a", Effective: "kc-b"},
+				{Field: "topologyManagerPolicy", Overridden: "kc-a", Effective: "kc-b"},
+			},
+		},
+		{
+			name: "malformed payload",
+			kcs: []*mcov1.KubeletConfig{
+				newKubeletConfig("kc-a", "", base, `{"topologyManagerPolicy":`),
+				newKubeletConfig("kc-b", "1", base, `{}`),
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			effective, conflicts, err := EffectiveForPool("worker", tc.kcs)
+			if tc.expectedError {
+				if err == nil {
+					t.Fatalf("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			gotEffective := ""
+			if effective != nil {
+				gotEffective = effective.Name
+			}
+			if gotEffective != tc.expectedEffective {
+				t.Errorf("effective: got %q expected %q", gotEffective, tc.expectedEffective)
+			}
+			if !reflect.DeepEqual(conflicts, tc.expectedConflicts) {
+				t.Errorf("conflicts: got %v expected %v", conflicts, tc.expectedConflicts)
+			}
+		})
+	}
+}
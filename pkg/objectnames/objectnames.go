@@ -30,3 +30,7 @@ func GetMachineConfigName(instanceName, mcpName string) string {
 func GetComponentName(instanceName, mcpName string) string {
 	return fmt.Sprintf("%s-%s", instanceName, mcpName)
 }
+
+func GetDryRunPlanName(instanceName string) string {
+	return fmt.Sprintf("%s-dry-run-plan", instanceName)
+}
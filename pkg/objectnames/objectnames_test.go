@@ -37,3 +37,10 @@ func TestGetComponentName(t *testing.T) {
 		t.Errorf("generated empty ComponentName")
 	}
 }
+
+func TestGetDryRunPlanName(t *testing.T) {
+	got := GetDryRunPlanName("foo")
+	if len(got) == 0 {
+		t.Errorf("generated empty DryRunPlanName")
+	}
+}
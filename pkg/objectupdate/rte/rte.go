@@ -19,10 +19,14 @@ package rte
 import (
 	"fmt"
 	"path/filepath"
+	"strconv"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/klog/v2"
 
 	securityv1 "github.com/openshift/api/security/v1"
@@ -35,7 +39,10 @@ import (
 
 	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
 
+	"github.com/openshift-kni/numaresources-operator/internal/pfpstatus"
 	"github.com/openshift-kni/numaresources-operator/pkg/hash"
+	rteconfig "github.com/openshift-kni/numaresources-operator/rte/pkg/config"
+	rtehealth "github.com/openshift-kni/numaresources-operator/rte/pkg/health"
 )
 
 // these should be provided by a deployer API
@@ -43,8 +50,11 @@ const (
 	MainContainerName   = "resource-topology-exporter"
 	HelperContainerName = "shared-pool-container"
 
-	pfpStatusMountName = "run-pfpstatus"
-	pfpStatusDir       = "/run/pfpstatus"
+	healthPortName = "health-port"
+
+	pfpStatusMountName      = "run-pfpstatus"
+	pfpStatusDir            = "/run/pfpstatus"
+	pfpStatusCertsMountName = "pfpstatus-certs"
 )
 
 func DaemonSetUserImageSettings(ds *appsv1.DaemonSet, userImageSpec, builtinImageSpec string, builtinPullPolicy corev1.PullPolicy) error {
@@ -100,6 +110,87 @@ func DaemonSetPauseContainerSettings(ds *appsv1.DaemonSet) error {
 	return nil
 }
 
+// DaemonSetHealthProbes exposes the RTE health endpoints and makes the kubelet probe them.
+func DaemonSetHealthProbes(ds *appsv1.DaemonSet) error {
+	cnt := k8swgobjupdate.FindContainerByName(ds.Spec.Template.Spec.Containers, MainContainerName)
+	if cnt == nil {
+		return fmt.Errorf("cannot find container data for %q", MainContainerName)
+	}
+
+	setContainerEnv(cnt, rtehealth.PortEnvVar, strconv.Itoa(rtehealth.DefaultPort))
+	setContainerPort(cnt, corev1.ContainerPort{
+		Name:          healthPortName,
+		ContainerPort: int32(rtehealth.DefaultPort),
+		Protocol:      corev1.ProtocolTCP,
+	})
+
+	// the RTE reports not ready if it fails to publish for few refresh periods, so we can be quick here
+	cnt.ReadinessProbe = &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: rtehealth.ReadinessPath,
+				Port: intstr.FromString(healthPortName),
+			},
+		},
+		InitialDelaySeconds: 5,
+		PeriodSeconds:       10,
+		FailureThreshold:    3,
+	}
+	// restarts are disruptive, so we should be more conservative here
+	cnt.LivenessProbe = &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: rtehealth.LivenessPath,
+				Port: intstr.FromString(healthPortName),
+			},
+		},
+		InitialDelaySeconds: 30,
+		PeriodSeconds:       20,
+		FailureThreshold:    3,
+	}
+	return nil
+}
+
+func setContainerEnv(cnt *corev1.Container, name, value string) {
+	for idx := range cnt.Env {
+		if cnt.Env[idx].Name == name {
+			cnt.Env[idx].Value = value
+			return
+		}
+	}
+	cnt.Env = append(cnt.Env, corev1.EnvVar{Name: name, Value: value})
+}
+
+func setContainerPort(cnt *corev1.Container, port corev1.ContainerPort) {
+	for idx := range cnt.Ports {
+		if cnt.Ports[idx].Name == port.Name {
+			cnt.Ports[idx] = port
+			return
+		}
+	}
+	cnt.Ports = append(cnt.Ports, port)
+}
+
+func setContainerVolumeMount(cnt *corev1.Container, mnt corev1.VolumeMount) {
+	for idx := range cnt.VolumeMounts {
+		if cnt.VolumeMounts[idx].Name == mnt.Name {
+			cnt.VolumeMounts[idx] = mnt
+			return
+		}
+	}
+	cnt.VolumeMounts = append(cnt.VolumeMounts, mnt)
+}
+
+func setPodVolume(podSpec *corev1.PodSpec, vol corev1.Volume) {
+	for idx := range podSpec.Volumes {
+		if podSpec.Volumes[idx].Name == vol.Name {
+			podSpec.Volumes[idx] = vol
+			return
+		}
+	}
+	podSpec.Volumes = append(podSpec.Volumes, vol)
+}
+
 // UpdateDaemonSetRunAsIDs bump the ds container privileges to 0/0.
 // We need this in the operator-as-operand flow because the operator image itself
 // is built to run with non-root user/group, and we should keep it like this.
@@ -129,6 +220,23 @@ func DaemonSetHashAnnotation(ds *appsv1.DaemonSet, cmHash string) {
 	template.Annotations[hash.ConfigMapAnnotation] = cmHash
 }
 
+// ConfigMapRestartHash computes the hash of the RTE configuration data the RTE can't reload
+// at runtime. Annotating the DaemonSet with this hash, as opposed to the hash of the whole
+// ConfigMap, makes the RTE pods restart only when the changes can't be hot-reloaded.
+func ConfigMapRestartHash(cm *corev1.ConfigMap) (string, error) {
+	data, ok := cm.Data[rteconfig.Key]
+	if !ok {
+		return hash.ConfigMapData(cm), nil
+	}
+	restartData, err := rteconfig.RestartRequiredData(data)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse the RTE configuration in ConfigMap %s/%s: %w", cm.Namespace, cm.Name, err)
+	}
+	restartCM := cm.DeepCopy()
+	restartCM.Data[rteconfig.Key] = restartData
+	return hash.ConfigMapData(restartCM), nil
+}
+
 const _MiB = 1024 * 1024
 
 func DaemonSetArgs(ds *appsv1.DaemonSet, conf nropv1.NodeGroupConfig) error {
@@ -170,13 +278,27 @@ func DaemonSetArgs(ds *appsv1.DaemonSet, conf nropv1.NodeGroupConfig) error {
 	klog.V(2).InfoS("DaemonSet update: pod fingerprinting status", "daemonset", ds.Name, "enabled", pfpEnabled)
 	if pfpEnabled {
 		flags.SetToggle("--pods-fingerprint")
-		flags.SetOption("--pods-fingerprint-status-file", filepath.Join(pfpStatusDir, "dump.json"))
+		flags.SetOption("--pods-fingerprint-status-file", filepath.Join(pfpStatusDir, pfpstatus.DumpFileName))
 		flags.SetOption("--pods-fingerprint-method", pfpMethod)
 
 		podSpec := &ds.Spec.Template.Spec
 		// TODO: this doesn't really belong here, but OTOH adding the status file without having set
 		// the volume doesn't work either. We need a deeper refactoring in this area.
 		AddVolumeMountMemory(podSpec, cnt, pfpStatusMountName, pfpStatusDir, 8*_MiB)
+
+		// the RTE serves the status file when it is set
+		setPodVolume(podSpec, pfpstatus.CertsVolume(pfpStatusCertsMountName))
+		setContainerVolumeMount(cnt, corev1.VolumeMount{
+			Name:      pfpStatusCertsMountName,
+			MountPath: pfpstatus.CertsDir,
+			ReadOnly:  true,
+		})
+		setContainerEnv(cnt, pfpstatus.PortEnvVar, strconv.Itoa(pfpstatus.DefaultPort))
+		setContainerPort(cnt, corev1.ContainerPort{
+			Name:          pfpstatus.PortName,
+			ContainerPort: int32(pfpstatus.DefaultPort),
+			Protocol:      corev1.ProtocolTCP,
+		})
 	}
 
 	flags.SetOption("--add-nrt-owner", "false")
@@ -185,6 +307,25 @@ func DaemonSetArgs(ds *appsv1.DaemonSet, conf nropv1.NodeGroupConfig) error {
 	return nil
 }
 
+// ClusterRolePFPStatusRules adds the rules the RTE needs to authenticate and authorize
+// the clients of the pod fingerprint status endpoint.
+func ClusterRolePFPStatusRules(cr *rbacv1.ClusterRole) {
+	for _, rule := range pfpstatus.AuthorizerRules() {
+		if !hasPolicyRule(cr.Rules, rule) {
+			cr.Rules = append(cr.Rules, rule)
+		}
+	}
+}
+
+func hasPolicyRule(rules []rbacv1.PolicyRule, rule rbacv1.PolicyRule) bool {
+	for _, existing := range rules {
+		if equality.Semantic.DeepEqual(existing, rule) {
+			return true
+		}
+	}
+	return false
+}
+
 func DaemonSetTolerations(ds *appsv1.DaemonSet, userTolerations []corev1.Toleration) {
 	if len(userTolerations) == 0 {
 		return
@@ -24,6 +24,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
@@ -329,3 +330,165 @@ func getSetFromStringList(args []string) map[string]struct{} {
 	}
 	return argsSet
 }
+
+func TestConfigMapRestartHash(t *testing.T) {
+	makeCM := func(data string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "test-ns",
+				Name:      "test-cm",
+			},
+			Data: map[string]string{
+				"config.yaml": data,
+			},
+		}
+	}
+
+	ref, err := ConfigMapRestartHash(makeCM("kubelet:\n  topologyManagerPolicy: single-numa-node\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		name        string
+		cm          *corev1.ConfigMap
+		expectedErr bool
+		expectSame  bool
+	}{
+		{
+			name:       "pod excludes changed",
+			cm:         makeCM("kubelet:\n  topologyManagerPolicy: single-numa-node\npodExclude:\n- namespacePattern: foo\n  namePattern: bar\n"),
+			expectSame: true,
+		},
+		{
+			name:       "resource excludes changed",
+			cm:         makeCM("kubelet:\n  topologyManagerPolicy: single-numa-node\nresourceExclude:\n  node1: [memory]\n"),
+			expectSame: true,
+		},
+		{
+			name:       "topology manager policy changed",
+			cm:         makeCM("kubelet:\n  topologyManagerPolicy: restricted\n"),
+			expectSame: false,
+		},
+		{
+			name:        "malformed data",
+			cm:          makeCM("this is: [not valid"),
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ConfigMapRestartHash(tc.cm)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("unexpected error state: %v", err)
+			}
+			if tc.expectedErr {
+				return
+			}
+			if (got == ref) != tc.expectSame {
+				t.Errorf("unexpected hash %q (reference %q)", got, ref)
+			}
+		})
+	}
+}
+
+func TestDaemonSetHealthProbes(t *testing.T) {
+	ds := testDs.DeepCopy()
+	cnt := &ds.Spec.Template.Spec.Containers[0]
+	cnt.Ports = []corev1.ContainerPort{{Name: "metrics-port", ContainerPort: 2112}}
+
+	// must be idempotent
+	for i := 0; i < 2; i++ {
+		if err := DaemonSetHealthProbes(ds); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(cnt.Ports) != 2 || cnt.Ports[0].Name != "metrics-port" || cnt.Ports[1].Name != healthPortName {
+		t.Errorf("unexpected ports: %+v", cnt.Ports)
+	}
+	if len(cnt.Env) != 1 {
+		t.Errorf("unexpected env: %+v", cnt.Env)
+	}
+	if cnt.ReadinessProbe == nil || cnt.ReadinessProbe.HTTPGet == nil || cnt.ReadinessProbe.HTTPGet.Path != "/readyz" {
+		t.Errorf("unexpected readiness probe: %+v", cnt.ReadinessProbe)
+	}
+	if cnt.LivenessProbe == nil || cnt.LivenessProbe.HTTPGet == nil || cnt.LivenessProbe.HTTPGet.Path != "/healthz" {
+		t.Errorf("unexpected liveness probe: %+v", cnt.LivenessProbe)
+	}
+	if ds.Spec.Template.Spec.Containers[1].ReadinessProbe != nil {
+		t.Errorf("unexpected probe on the helper container")
+	}
+
+	noRTE := testDs.DeepCopy()
+	noRTE.Spec.Template.Spec.Containers = noRTE.Spec.Template.Spec.Containers[1:]
+	if err := DaemonSetHealthProbes(noRTE); err == nil {
+		t.Errorf("unexpected success without the RTE container")
+	}
+}
+
+func TestDaemonSetArgsPFPStatusEndpoint(t *testing.T) {
+	pfpEnabled := nropv1.PodsFingerprintingEnabled
+	pfpDisabled := nropv1.PodsFingerprintingDisabled
+
+	ds := testDs.DeepCopy()
+	if err := DaemonSetArgs(ds, nropv1.NodeGroupConfig{PodsFingerprinting: &pfpDisabled}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ds.Spec.Template.Spec.Containers[0].Ports) != 0 {
+		t.Errorf("unexpected ports with fingerprinting disabled: %+v", ds.Spec.Template.Spec.Containers[0].Ports)
+	}
+
+	ds = testDs.DeepCopy()
+	// must be idempotent
+	for i := 0; i < 2; i++ {
+		if err := DaemonSetArgs(ds, nropv1.NodeGroupConfig{PodsFingerprinting: &pfpEnabled}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	cnt := ds.Spec.Template.Spec.Containers[0]
+	if len(cnt.Ports) != 1 || cnt.Ports[0].Name != "pfpstatus" || cnt.Ports[0].ContainerPort != 8082 {
+		t.Errorf("unexpected ports: %+v", cnt.Ports)
+	}
+	if len(cnt.Env) != 1 || cnt.Env[0].Name != "PFPSTATUS_PORT" || cnt.Env[0].Value != "8082" {
+		t.Errorf("unexpected env: %+v", cnt.Env)
+	}
+	var certsMounts int
+	for _, mnt := range cnt.VolumeMounts {
+		if mnt.Name == pfpStatusCertsMountName && mnt.MountPath == "/etc/secrets/pfpstatus" && mnt.ReadOnly {
+			certsMounts++
+		}
+	}
+	var certsVolumes int
+	for _, vol := range ds.Spec.Template.Spec.Volumes {
+		if vol.Name == pfpStatusCertsMountName && vol.Secret != nil && vol.Secret.SecretName == "numaresources-pfpstatus-tls" {
+			certsVolumes++
+		}
+	}
+	if certsMounts != 1 || certsVolumes != 1 {
+		t.Errorf("unexpected serving certificate volumes=%d mounts=%d", certsVolumes, certsMounts)
+	}
+}
+
+func TestClusterRolePFPStatusRules(t *testing.T) {
+	cr := &rbacv1.ClusterRole{
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{"topology.node.k8s.io"},
+				Resources: []string{"noderesourcetopologies"},
+				Verbs:     []string{"create", "update", "get", "list"},
+			},
+		},
+	}
+	// must be idempotent
+	ClusterRolePFPStatusRules(cr)
+	ClusterRolePFPStatusRules(cr)
+
+	if len(cr.Rules) != 3 {
+		t.Fatalf("unexpected rules: %+v", cr.Rules)
+	}
+	if cr.Rules[1].Resources[0] != "tokenreviews" || cr.Rules[2].Resources[0] != "subjectaccessreviews" {
+		t.Errorf("unexpected rules: %+v", cr.Rules)
+	}
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sched
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	k8swgmanifests "github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	nrosched "github.com/openshift-kni/numaresources-operator/pkg/numaresourcesscheduler"
+)
+
+// CacheResyncPeriod returns the cache resync period to render in the scheduler configuration
+func CacheResyncPeriod(reconcilePeriod *metav1.Duration) time.Duration {
+	period := reconcilePeriod.Round(time.Second)
+	klog.InfoS("setting reconcile period", "computed", period, "supplied", reconcilePeriod)
+	return period
+}
+
+// ConfigParamsFromSpec returns the scheduler configuration parameters matching the given normalized spec
+func ConfigParamsFromSpec(schedSpec nropv1.NUMAResourcesSchedulerSpec, cacheResyncPeriod time.Duration, namespace string) k8swgmanifests.ConfigParams {
+	resyncPeriod := int64(cacheResyncPeriod.Seconds())
+	// if no actual replicas are required, leader election is unnecessary, so
+	// we force it to off to reduce the background noise.
+	// note: the api validation/normalization layer must ensure this value is != nil
+	leaderElect := (*schedSpec.Replicas > 1)
+
+	params := k8swgmanifests.ConfigParams{
+		ProfileName: schedSpec.SchedulerName,
+		Cache: &k8swgmanifests.ConfigCacheParams{
+			ResyncPeriodSeconds: &resyncPeriod,
+		},
+		ScoringStrategy: &k8swgmanifests.ScoringStrategyParams{},
+		LeaderElection: &k8swgmanifests.LeaderElectionParams{
+			// Make sure to always set explicitly the value and override the configmap defaults.
+			LeaderElect: leaderElect,
+			// unconditionally set those to make sure
+			// to play nice with the cluster and the main scheduler
+			ResourceNamespace: namespace,
+			ResourceName:      nrosched.LeaderElectionResourceName,
+		},
+	}
+
+	klog.V(2).InfoS("setting leader election parameters", dumpLeaderElectionParams(params.LeaderElection)...)
+
+	var foreignPodsDetect string
+	var resyncMethod string = k8swgmanifests.CacheResyncAutodetect
+	var informerMode string
+	var scoringStrategyType string
+	if *schedSpec.CacheResyncDetection == nropv1.CacheResyncDetectionRelaxed {
+		foreignPodsDetect = k8swgmanifests.ForeignPodsDetectOnlyExclusiveResources
+	} else {
+		foreignPodsDetect = k8swgmanifests.ForeignPodsDetectAll
+	}
+	if *schedSpec.SchedulerInformer == k8swgmanifests.CacheInformerDedicated {
+		informerMode = k8swgmanifests.CacheInformerDedicated
+	} else {
+		informerMode = k8swgmanifests.CacheInformerShared
+	}
+	if schedSpec.ScoringStrategy.Type == nropv1.LeastAllocated {
+		scoringStrategyType = k8swgmanifests.ScoringStrategyLeastAllocated
+	} else if schedSpec.ScoringStrategy.Type == nropv1.BalancedAllocation {
+		scoringStrategyType = k8swgmanifests.ScoringStrategyBalancedAllocation
+	} else if schedSpec.ScoringStrategy.Type == nropv1.MostAllocated {
+		scoringStrategyType = k8swgmanifests.ScoringStrategyMostAllocated
+	} else {
+		scoringStrategyType = k8swgmanifests.ScoringStrategyLeastAllocated
+	}
+	params.ScoringStrategy.Type = scoringStrategyType
+
+	var resources []k8swgmanifests.ResourceSpecParams
+	for _, resource := range schedSpec.ScoringStrategy.Resources {
+		resources = append(resources, k8swgmanifests.ResourceSpecParams{
+			Name:   resource.Name,
+			Weight: resource.Weight,
+		})
+	}
+	params.ScoringStrategy.Resources = resources
+	params.Cache.ResyncMethod = &resyncMethod
+	params.Cache.ForeignPodsDetectMode = &foreignPodsDetect
+	params.Cache.InformerMode = &informerMode
+	klog.V(2).InfoS("setting cache parameters", DumpConfigCacheParams(params.Cache)...)
+
+	return params
+}
+
+// DumpConfigCacheParams returns the cache parameters as key-value pairs suitable for structured logging
+func DumpConfigCacheParams(ccp *k8swgmanifests.ConfigCacheParams) []interface{} {
+	return []interface{}{
+		"resyncPeriod", strInt64Ptr(ccp.ResyncPeriodSeconds),
+		"resyncMethod", strStringPtr(ccp.ResyncMethod),
+		"foreignPodsDetectMode", strStringPtr(ccp.ForeignPodsDetectMode),
+		"informerMode", strStringPtr(ccp.InformerMode),
+	}
+}
+
+func dumpLeaderElectionParams(lep *k8swgmanifests.LeaderElectionParams) []interface{} {
+	return []interface{}{
+		"leaderElect", lep.LeaderElect,
+		"resourceNamespace", lep.ResourceNamespace,
+		"resourceName", lep.ResourceName,
+	}
+}
+
+func strInt64Ptr(ip *int64) string {
+	if ip == nil {
+		return "N/A"
+	}
+	return fmt.Sprintf("%d", *ip)
+}
+
+func strStringPtr(sp *string) string {
+	if sp == nil {
+		return "N/A"
+	}
+	return *sp
+}
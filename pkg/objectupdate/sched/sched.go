@@ -18,16 +18,20 @@ package sched
 
 import (
 	"fmt"
+	"strconv"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 
 	k8swgmanifests "github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
 	k8swgobjupdate "github.com/k8stopologyawareschedwg/deployer/pkg/objectupdate"
 	k8swgschedupdate "github.com/k8stopologyawareschedwg/deployer/pkg/objectupdate/sched"
 
 	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	"github.com/openshift-kni/numaresources-operator/internal/pfpstatus"
 	"github.com/openshift-kni/numaresources-operator/pkg/hash"
 	schedstate "github.com/openshift-kni/numaresources-operator/pkg/numaresourcesscheduler/objectstate/sched"
 )
@@ -35,13 +39,19 @@ import (
 // these should be provided by a deployer API
 
 const (
-	MainContainerName = "secondary-scheduler"
+	MainContainerName      = "secondary-scheduler"
+	PFPStatusContainerName = "pfpstatus"
 )
 
 const (
 	PFPStatusDumpEnvVar = "PFP_STATUS_DUMP"
 
 	PFPStatusDir = "/run/pfpstatus"
+
+	pfpStatusVolumeName      = "runpfpstatus"
+	pfpStatusCertsVolumeName = "pfpstatus-certs"
+	// pfpStatusServerCommand is the operator binary, which can serve the dumps
+	pfpStatusServerCommand = "/bin/numaresources-operator"
 )
 
 // TODO: we should inject also the mount point. As it is now, the information is split between the manifest
@@ -72,6 +82,81 @@ func DeploymentEnvVarSettings(dp *appsv1.Deployment, spec nropv1.NUMAResourcesSc
 	}
 }
 
+// DeploymentPFPStatusSidecar adds the sidecar serving the status dumps over HTTPS using the given image,
+// which must provide the operator binary, if the dumps are enabled. Otherwise, it removes the sidecar.
+func DeploymentPFPStatusSidecar(dp *appsv1.Deployment, spec nropv1.NUMAResourcesSchedulerSpec, imageSpec string) {
+	podSpec := &dp.Spec.Template.Spec
+	var cnts []corev1.Container
+	for _, cnt := range podSpec.Containers {
+		if cnt.Name == PFPStatusContainerName {
+			continue
+		}
+		cnts = append(cnts, cnt)
+	}
+	podSpec.Containers = cnts
+	var vols []corev1.Volume
+	for _, vol := range podSpec.Volumes {
+		if vol.Name == pfpStatusCertsVolumeName {
+			continue
+		}
+		vols = append(vols, vol)
+	}
+	podSpec.Volumes = vols
+
+	if *spec.CacheResyncDebug != nropv1.CacheResyncDebugDumpJSONFile {
+		return
+	}
+	if imageSpec == "" {
+		klog.InfoS("no image for the pod fingerprint status sidecar, skipped")
+		return
+	}
+
+	podSpec.Containers = append(podSpec.Containers, corev1.Container{
+		Name:    PFPStatusContainerName,
+		Image:   imageSpec,
+		Command: []string{pfpStatusServerCommand},
+		Args:    []string{"--serve-pfpstatus=" + PFPStatusDir},
+		Env: []corev1.EnvVar{
+			{
+				Name:  pfpstatus.PortEnvVar,
+				Value: strconv.Itoa(pfpstatus.DefaultPort),
+			},
+		},
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          pfpstatus.PortName,
+				ContainerPort: int32(pfpstatus.DefaultPort),
+				Protocol:      corev1.ProtocolTCP,
+			},
+		},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("50Mi"),
+			},
+		},
+		SecurityContext: &corev1.SecurityContext{
+			AllowPrivilegeEscalation: ptr.To(false),
+			Capabilities: &corev1.Capabilities{
+				Drop: []corev1.Capability{"ALL"},
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      pfpStatusVolumeName,
+				MountPath: PFPStatusDir,
+				ReadOnly:  true,
+			},
+			{
+				Name:      pfpStatusCertsVolumeName,
+				MountPath: pfpstatus.CertsDir,
+				ReadOnly:  true,
+			},
+		},
+	})
+	podSpec.Volumes = append(podSpec.Volumes, pfpstatus.CertsVolume(pfpStatusCertsVolumeName))
+}
+
 func setContainerEnvVar(cnt *corev1.Container, name, value string) {
 	if env := FindEnvVarByName(cnt.Env, name); env != nil {
 		klog.V(2).InfoS("overriding existing environment variable", "name", name, "oldValue", env.Value, "newValue", value)
@@ -415,6 +415,76 @@ func TestDeploymentEnvVarSettings(t *testing.T) {
 	}
 }
 
+func TestDeploymentPFPStatusSidecar(t *testing.T) {
+	cacheResyncDebugEnabled := nropv1.CacheResyncDebugDumpJSONFile
+	cacheResyncDebugDisabled := nropv1.CacheResyncDebugDisabled
+
+	testCases := []struct {
+		name           string
+		spec           nropv1.NUMAResourcesSchedulerSpec
+		imageSpec      string
+		expectedImages []string
+	}{
+		{
+			name: "status dump disabled",
+			spec: nropv1.NUMAResourcesSchedulerSpec{
+				CacheResyncDebug: &cacheResyncDebugDisabled,
+			},
+			imageSpec:      "quay.io/foo/operator:v1",
+			expectedImages: []string{"quay.io/bar/image:v1"},
+		},
+		{
+			name: "status dump enabled",
+			spec: nropv1.NUMAResourcesSchedulerSpec{
+				CacheResyncDebug: &cacheResyncDebugEnabled,
+			},
+			imageSpec:      "quay.io/foo/operator:v1",
+			expectedImages: []string{"quay.io/bar/image:v1", "quay.io/foo/operator:v1"},
+		},
+		{
+			name: "status dump enabled, missing image",
+			spec: nropv1.NUMAResourcesSchedulerSpec{
+				CacheResyncDebug: &cacheResyncDebugEnabled,
+			},
+			expectedImages: []string{"quay.io/bar/image:v1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dp := dpAllOptions.DeepCopy()
+			// must be idempotent, the deployment is updated on each reconcile
+			DeploymentPFPStatusSidecar(dp, tc.spec, tc.imageSpec)
+			DeploymentPFPStatusSidecar(dp, tc.spec, tc.imageSpec)
+
+			var images []string
+			for _, cnt := range dp.Spec.Template.Spec.Containers {
+				images = append(images, cnt.Image)
+			}
+			if !reflect.DeepEqual(images, tc.expectedImages) {
+				t.Fatalf("got=%v expected %v", images, tc.expectedImages)
+			}
+			if len(images) == 1 {
+				return
+			}
+			cnt := dp.Spec.Template.Spec.Containers[1]
+			if cnt.Name != PFPStatusContainerName || !reflect.DeepEqual(cnt.Args, []string{"--serve-pfpstatus=/run/pfpstatus"}) {
+				t.Errorf("unexpected sidecar: %s", toJSON(cnt))
+			}
+			if len(cnt.Ports) != 1 || cnt.Ports[0].Name != "pfpstatus" {
+				t.Errorf("unexpected sidecar ports: %s", toJSON(cnt.Ports))
+			}
+			if len(cnt.VolumeMounts) != 2 || cnt.VolumeMounts[0].MountPath != PFPStatusDir || cnt.VolumeMounts[1].MountPath != "/etc/secrets/pfpstatus" {
+				t.Errorf("unexpected sidecar volume mounts: %s", toJSON(cnt.VolumeMounts))
+			}
+			vols := dp.Spec.Template.Spec.Volumes
+			if last := vols[len(vols)-1]; last.Name != pfpStatusCertsVolumeName || last.Secret == nil || last.Secret.SecretName != "numaresources-pfpstatus-tls" {
+				t.Errorf("unexpected sidecar volumes: %s", toJSON(vols))
+			}
+		})
+	}
+}
+
 func toJSON(obj interface{}) string {
 	data, err := json.Marshal(obj)
 	if err != nil {
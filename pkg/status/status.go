@@ -46,6 +46,24 @@ const (
 	ConditionTypeIncorrectNUMAResourcesOperatorResourceName = "IncorrectNUMAResourcesOperatorResourceName"
 )
 
+// auxiliary conditions are reported alongside the base conditions, and are
+// managed independently from them.
+const (
+	ConditionTopologyManagerDrift = "TopologyManagerDrift"
+	ConditionOwnedObjectsDrift    = "OwnedObjectsDrift"
+)
+
+const (
+	ReasonTopologyManagerDriftDetected    = "TopologyManagerDriftDetected"
+	ReasonTopologyManagerDriftNotDetected = "TopologyManagerDriftNotDetected"
+)
+
+const (
+	ReasonOwnedObjectsDriftDetected    = "OwnedObjectsDriftDetected"
+	ReasonOwnedObjectsDriftNotDetected = "OwnedObjectsDriftNotDetected"
+	ReasonOwnedObjectsDriftCorrected   = "OwnedObjectsDriftCorrected"
+)
+
 const (
 	ConditionTypeIncorrectNUMAResourcesSchedulerResourceName = "IncorrectNUMAResourcesSchedulerResourceName"
 )
@@ -62,8 +80,15 @@ func IsUpdatedNUMAResourcesOperator(oldStatus, newStatus *nropv1.NUMAResourcesOp
 // UpdateConditions compute new conditions based on arguments, and then compare with given current conditions.
 // Returns the conditions to use, either current or newly computed, and a boolean flag which is `true` if conditions need
 // update - so if they are updated since the current conditions.
+// Auxiliary conditions found in the current conditions are preserved.
 func UpdateConditions(currentConditions []metav1.Condition, condition string, reason string, message string) ([]metav1.Condition, bool) {
 	conditions := NewConditions(condition, reason, message)
+	for _, cond := range currentConditions {
+		if isBaseCondition(cond.Type) {
+			continue
+		}
+		conditions = append(conditions, cond)
+	}
 
 	options := []cmp.Option{
 		cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime"),
@@ -76,6 +101,37 @@ func UpdateConditions(currentConditions []metav1.Condition, condition string, re
 	return conditions, true
 }
 
+// UpdateAuxiliaryCondition sets the auxiliary condition `condition` in the given current conditions, leaving
+// all the other conditions untouched. Returns the conditions to use, and a boolean flag which is `true` if
+// the conditions need update.
+func UpdateAuxiliaryCondition(currentConditions []metav1.Condition, condition string, condStatus metav1.ConditionStatus, reason string, message string) ([]metav1.Condition, bool) {
+	conditions := make([]metav1.Condition, 0, len(currentConditions)+1)
+	for _, cond := range currentConditions {
+		conditions = append(conditions, *cond.DeepCopy())
+	}
+	cond := FindCondition(conditions, condition)
+	if cond == nil {
+		conditions = append(conditions, metav1.Condition{
+			Type:               condition,
+			Status:             condStatus,
+			LastTransitionTime: metav1.Time{Time: time.Now()},
+			Reason:             reason,
+			Message:            message,
+		})
+		return conditions, true
+	}
+	if cond.Status == condStatus && cond.Reason == reason && cond.Message == message {
+		return currentConditions, false
+	}
+	if cond.Status != condStatus {
+		cond.LastTransitionTime = metav1.Time{Time: time.Now()}
+	}
+	cond.Status = condStatus
+	cond.Reason = reason
+	cond.Message = message
+	return conditions, true
+}
+
 func FindCondition(conditions []metav1.Condition, condition string) *metav1.Condition {
 	for idx := 0; idx < len(conditions); idx++ {
 		cond := &conditions[idx]
@@ -104,6 +160,10 @@ func NewConditions(condition string, reason string, message string) []metav1.Con
 	return conditions
 }
 
+func isBaseCondition(condition string) bool {
+	return condition == ConditionAvailable || condition == ConditionUpgradeable || condition == ConditionProgressing || condition == ConditionDegraded
+}
+
 func newBaseConditions() []metav1.Condition {
 	now := time.Now()
 	return []metav1.Condition{
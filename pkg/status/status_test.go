@@ -121,6 +121,58 @@ func TestUpdateIfNeeded(t *testing.T) {
 	}
 }
 
+func TestUpdateConditionsPreservesAuxiliary(t *testing.T) {
+	conds, _ := UpdateConditions(nil, ConditionAvailable, "", "")
+	conds, ok := UpdateAuxiliaryCondition(conds, ConditionTopologyManagerDrift, metav1.ConditionTrue, ReasonTopologyManagerDriftDetected, "node-0")
+	if !ok {
+		t.Fatalf("auxiliary condition update did not change status, but it should")
+	}
+
+	conds, ok = UpdateConditions(conds, ConditionAvailable, "", "")
+	if ok {
+		t.Errorf("Update did change status, but it should not")
+	}
+	cond := FindCondition(conds, ConditionTopologyManagerDrift)
+	if cond == nil {
+		t.Fatalf("auxiliary condition %q lost after base conditions update", ConditionTopologyManagerDrift)
+	}
+	if cond.Status != metav1.ConditionTrue || cond.Message != "node-0" {
+		t.Errorf("auxiliary condition unexpectedly changed: %+v", cond)
+	}
+
+	conds, ok = UpdateConditions(conds, ConditionProgressing, "testReason", "test message")
+	if !ok {
+		t.Errorf("Update did not change status, but it should")
+	}
+	if FindCondition(conds, ConditionTopologyManagerDrift) == nil {
+		t.Errorf("auxiliary condition %q lost after base conditions change", ConditionTopologyManagerDrift)
+	}
+}
+
+func TestUpdateAuxiliaryCondition(t *testing.T) {
+	conds, ok := UpdateAuxiliaryCondition(nil, ConditionTopologyManagerDrift, metav1.ConditionFalse, ReasonTopologyManagerDriftNotDetected, "")
+	if !ok || len(conds) != 1 {
+		t.Fatalf("unexpected auxiliary condition update: changed=%v conditions=%+v", ok, conds)
+	}
+
+	_, ok = UpdateAuxiliaryCondition(conds, ConditionTopologyManagerDrift, metav1.ConditionFalse, ReasonTopologyManagerDriftNotDetected, "")
+	if ok {
+		t.Errorf("Update did change status, but it should not")
+	}
+
+	updated, ok := UpdateAuxiliaryCondition(conds, ConditionTopologyManagerDrift, metav1.ConditionTrue, ReasonTopologyManagerDriftDetected, "node-0")
+	if !ok {
+		t.Fatalf("Update did not change status, but it should")
+	}
+	if conds[0].Status != metav1.ConditionFalse {
+		t.Errorf("UpdateAuxiliaryCondition mutated the input conditions")
+	}
+	cond := FindCondition(updated, ConditionTopologyManagerDrift)
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != ReasonTopologyManagerDriftDetected {
+		t.Errorf("unexpected condition after update: %+v", cond)
+	}
+}
+
 func TestIsUpdatedNUMAResourcesOperator(t *testing.T) {
 	type testCase struct {
 		name            string
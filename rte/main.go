@@ -18,7 +18,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strconv"
 	"time"
 
 	"k8s.io/klog/v2"
@@ -28,13 +30,17 @@ import (
 	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/metrics"
 	metricssrv "github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/metrics/server"
 	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/podres"
-	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/podres/middleware/podexclude"
 	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/podres/middleware/sharedcpuspool"
 	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/podres/middleware/terminalpods"
 	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/resourcemonitor"
 	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/resourcetopologyexporter"
 
+	"github.com/openshift-kni/numaresources-operator/internal/pfpstatus"
 	"github.com/openshift-kni/numaresources-operator/pkg/version"
+	rteconfig "github.com/openshift-kni/numaresources-operator/rte/pkg/config"
+	"github.com/openshift-kni/numaresources-operator/rte/pkg/exporter"
+	"github.com/openshift-kni/numaresources-operator/rte/pkg/health"
+	"github.com/openshift-kni/numaresources-operator/rte/pkg/podexclude"
 )
 
 const (
@@ -63,11 +69,31 @@ func main() {
 		_ = dump(&parsedArgs)
 	}
 
+	configPath, err := extraConfigPath(os.Args[1:])
+	if err != nil {
+		klog.Fatalf("failed to find the configuration path: %v", err)
+	}
+
+	tracker := health.NewTracker(configPath, parsedArgs.RTE.SleepInterval, health.DefaultMaxMissedPeriods)
+	go func() {
+		// if we can't serve the probes the kubelet will restart us, so no point in failing here
+		err := tracker.Serve(healthProbesAddress())
+		klog.Errorf("failed to serve health probes: %v", err)
+	}()
+
 	k8scli, err := k8shelpers.GetK8sClient(parsedArgs.Global.KubeConfig)
 	if err != nil {
 		klog.Fatalf("failed to get a kubernetes core client: %v", err)
 	}
 
+	if statusFile := parsedArgs.Resourcemonitor.PodSetFingerprintStatusFile; statusFile != "" {
+		go func() {
+			// not fatal: the status is a debug aid, the RTE can still work without serving it
+			err := pfpstatus.Serve(pfpstatus.Address(), filepath.Dir(statusFile), pfpstatus.CertsDir, pfpstatus.NewKubeAuthorizer(k8scli))
+			klog.Errorf("failed to serve the pod fingerprint status: %v", err)
+		}()
+	}
+
 	nrtcli, err := k8shelpers.GetTopologyClient(parsedArgs.Global.KubeConfig)
 	if err != nil {
 		klog.Fatalf("failed to get a noderesourcetopology client: %v", err)
@@ -79,12 +105,13 @@ func main() {
 	}
 	//nolint: errcheck
 	defer cleanup()
+	tracker.PodResourcesConnected()
 
 	cli = sharedcpuspool.NewFromLister(cli, parsedArgs.Global.Debug, parsedArgs.RTE.ReferenceContainer)
 
-	if len(parsedArgs.Resourcemonitor.PodExclude) > 0 {
-		cli = podexclude.NewFromLister(cli, parsedArgs.Global.Debug, parsedArgs.Resourcemonitor.PodExclude)
-	}
+	// always installed, even if initially empty, because the pod excludes can be reloaded at runtime
+	podExcludeCli := podexclude.NewFromLister(cli, parsedArgs.Global.Debug, parsedArgs.Resourcemonitor.PodExclude)
+	cli = podExcludeCli
 
 	if parsedArgs.Resourcemonitor.ExcludeTerminalPods {
 		klog.Infof("terminal pods are filtered from the PodResourcesLister client")
@@ -110,12 +137,65 @@ func main() {
 		},
 		NRTCli: nrtcli,
 	}
-	err = resourcetopologyexporter.Execute(hnd, parsedArgs.NRTupdater, parsedArgs.Resourcemonitor, parsedArgs.RTE)
+	exp, err := exporter.New(hnd, parsedArgs.NRTupdater, parsedArgs.Resourcemonitor, parsedArgs.RTE, tracker)
+	if err != nil {
+		klog.Fatalf("failed to setup the exporter: %v", err)
+	}
+
+	err = watchConfig(configPath, podExcludeCli, exp.ResObs)
+	if err != nil {
+		// not fatal: we can still work, just need a restart to pick up configuration changes
+		klog.Warningf("configuration hot reload disabled: %v", err)
+	}
+
+	err = exp.Run()
 	if err != nil {
 		klog.Fatalf("failed to execute: %v", err)
 	}
 }
 
+// watchConfig makes the RTE apply the changes of its configuration file at runtime,
+// publishing the updated NRT object without waiting for the next periodic update.
+// The topology manager settings can't be changed at runtime, because they are
+// reported as NRT attributes fixed at startup; the operator restarts the pods if they change.
+func watchConfig(configPath string, podExcludeCli *podexclude.Lister, resObs *exporter.ResourceObserver) error {
+	initialConf, err := rteconfig.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := rteconfig.NewWatcher(configPath, func(conf rteconfig.Config) {
+		if conf.Kubelet != initialConf.Kubelet {
+			klog.Warningf("topology manager settings changed from %+v to %+v: a restart is required to apply them", initialConf.Kubelet, conf.Kubelet)
+		}
+		podExcludeCli.SetPodExcludes(conf.PodExclude)
+		resObs.SetResourceExclude(conf.ResourceExclude)
+		resObs.Refresh()
+	})
+	if err != nil {
+		return err
+	}
+	klog.Infof("watching configuration file %q for changes", configPath)
+	go watcher.Run(context.Background())
+	return nil
+}
+
+// extraConfigPath returns the path of the configuration file rendered by the operator
+func extraConfigPath(args []string) (string, error) {
+	var pArgs rteconfiguration.ProgArgs
+	rteconfiguration.SetDefaults(&pArgs)
+	_, configPath, err := rteconfiguration.FromFlags(&pArgs, args...)
+	return configPath, err
+}
+
+func healthProbesAddress() string {
+	port := strconv.Itoa(health.DefaultPort)
+	if val, ok := os.LookupEnv(health.PortEnvVar); ok && val != "" {
+		port = val
+	}
+	return ":" + port
+}
+
 func parseArgs(args ...string) (rteconfiguration.ProgArgs, error) {
 	progArgs, err := rteconfiguration.LoadArgs(args...)
 	if err != nil {
@@ -17,12 +17,15 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"os"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubeletconfigv1beta1 "k8s.io/kubelet/config/v1beta1"
 
+	"k8s.io/klog/v2"
 	"sigs.k8s.io/yaml"
 
 	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/podres/middleware/podexclude"
@@ -77,6 +80,32 @@ func Unrender(data string) (Config, error) {
 	return conf, err
 }
 
+// ReadFile loads the configuration from the given path. The configuration is optional,
+// so a missing file is not an error and yields an empty configuration.
+func ReadFile(configPath string) (Config, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			klog.Infof("couldn't find configuration in %q", configPath)
+			return Config{}, nil
+		}
+		return Config{}, err
+	}
+	return Unrender(string(data))
+}
+
+// RestartRequiredData returns the subset of the given configuration data the RTE
+// can't apply at runtime, hence requiring a restart of the RTE pods to be applied.
+// The RTE reloads all the other settings when its configuration file changes.
+func RestartRequiredData(data string) (string, error) {
+	conf, err := Unrender(data)
+	if err != nil {
+		return "", err
+	}
+	out, err := yaml.Marshal(Config{Kubelet: conf.Kubelet})
+	return string(out), err
+}
+
 func CreateConfigMap(namespace, name, configData string) *corev1.ConfigMap {
 	cm := &corev1.ConfigMap{
 		// TODO: why is this needed?
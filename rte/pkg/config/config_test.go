@@ -17,16 +17,12 @@
 package config
 
 import (
-	"errors"
 	"os"
 	"testing"
-
-	"k8s.io/klog"
-	"sigs.k8s.io/yaml"
 )
 
 func TestReadNonExistent(t *testing.T) {
-	cfg, err := readFile("/does/not/exist")
+	cfg, err := ReadFile("/does/not/exist")
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -36,7 +32,7 @@ func TestReadNonExistent(t *testing.T) {
 }
 
 func TestReadMalformed(t *testing.T) {
-	_, err := readFile("/etc/services")
+	_, err := ReadFile("/etc/services")
 	if err == nil {
 		t.Errorf("unexpected success reading unrelated data")
 	}
@@ -56,7 +52,7 @@ func TestReadValidData(t *testing.T) {
 	if err := tmpfile.Close(); err != nil {
 		t.Errorf("closing the tempfile: %v", err)
 	}
-	cfg, err := readFile(tmpfile.Name())
+	cfg, err := ReadFile(tmpfile.Name())
 	if err != nil {
 		t.Errorf("unexpected error reading back the config: %v", err)
 	}
@@ -71,21 +67,6 @@ func TestReadValidData(t *testing.T) {
 	}
 }
 
-func readFile(configPath string) (Config, error) {
-	conf := Config{}
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		// config is optional
-		if errors.Is(err, os.ErrNotExist) {
-			klog.Warningf("Info: couldn't find configuration in %q", configPath)
-			return conf, nil
-		}
-		return conf, err
-	}
-	err = yaml.Unmarshal(data, &conf)
-	return conf, err
-}
-
 const testData string = `resources:
   reservedcpus: "0"
   resourcemapping:
@@ -97,3 +78,40 @@ resourceExclude:
   masternode: [memory, device/exampleA]
   workernode1: [memory, device/exampleB]
   workernode2: [cpu]`
+
+func TestRestartRequiredData(t *testing.T) {
+	data := `kubelet:
+  topologyManagerPolicy: single-numa-node
+  topologyManagerScope: pod
+podExclude:
+- namespacePattern: foo
+  namePattern: bar
+resourceExclude:
+  node1: [memory]
+`
+	ref, err := RestartRequiredData(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// changing only the reloadable settings must not change the restart-required data
+	got, err := RestartRequiredData("kubelet:\n  topologyManagerPolicy: single-numa-node\n  topologyManagerScope: pod\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != ref {
+		t.Errorf("restart-required data changed on reloadable settings change:\n%s\nvs\n%s", got, ref)
+	}
+
+	got, err = RestartRequiredData("kubelet:\n  topologyManagerPolicy: restricted\n  topologyManagerScope: pod\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == ref {
+		t.Errorf("restart-required data unchanged on topology manager settings change")
+	}
+
+	if _, err := RestartRequiredData("this is: [not valid"); err == nil {
+		t.Errorf("unexpected success on malformed data")
+	}
+}
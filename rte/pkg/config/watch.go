@@ -0,0 +1,106 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+
+	"k8s.io/klog/v2"
+)
+
+// Watcher notifies about the changes of the RTE configuration file.
+// The configuration is expected to be projected from a ConfigMap, whose
+// updates are delivered by atomically swapping a symlink in the mount
+// directory, so the Watcher watches the parent directory of the file and
+// reloads the configuration only when its content actually changes.
+type Watcher struct {
+	configPath string
+	onChange   func(Config)
+	watcher    *fsnotify.Watcher
+	lastData   []byte
+}
+
+func NewWatcher(configPath string, onChange func(Config)) (*Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the watcher: %w", err)
+	}
+	configDir := filepath.Dir(configPath)
+	if err := watcher.Add(configDir); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("failed to watch %q: %w", configDir, err)
+	}
+	// the initial configuration is consumed at startup, we care only about updates
+	data, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		watcher.Close()
+		return nil, err
+	}
+	return &Watcher{
+		configPath: configPath,
+		onChange:   onChange,
+		watcher:    watcher,
+		lastData:   data,
+	}, nil
+}
+
+// Run processes the filesystem events until the context is done.
+func (w *Watcher) Run(ctx context.Context) {
+	defer w.watcher.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			klog.V(4).InfoS("config directory event", "name", ev.Name, "op", ev.Op.String())
+			w.reload()
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			klog.ErrorS(err, "watching configuration", "path", w.configPath)
+		}
+	}
+}
+
+func (w *Watcher) reload() {
+	data, err := os.ReadFile(w.configPath)
+	if err != nil && !os.IsNotExist(err) {
+		klog.ErrorS(err, "reading configuration", "path", w.configPath)
+		return
+	}
+	if string(data) == string(w.lastData) {
+		return
+	}
+	conf, err := Unrender(string(data))
+	if err != nil {
+		// keep the last good configuration, we will retry on the next change
+		klog.ErrorS(err, "parsing configuration", "path", w.configPath)
+		return
+	}
+	w.lastData = data
+	klog.InfoS("configuration changed", "path", w.configPath)
+	w.onChange(conf)
+}
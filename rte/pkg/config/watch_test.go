@@ -0,0 +1,108 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcherConfigMapUpdate(t *testing.T) {
+	dir := t.TempDir()
+	// mimic the layout kubelet uses for the projected ConfigMap volumes
+	writeConfigMapVolume(t, dir, "..ts1", "kubelet:\n  topologyManagerPolicy: single-numa-node\n")
+	if err := os.Symlink(filepath.Join("..data", Key), filepath.Join(dir, Key)); err != nil {
+		t.Fatalf("creating config symlink: %v", err)
+	}
+
+	changes := make(chan Config, 4)
+	w, err := NewWatcher(filepath.Join(dir, Key), func(conf Config) {
+		changes <- conf
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating the watcher: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	writeConfigMapVolume(t, dir, "..ts2", "kubelet:\n  topologyManagerPolicy: single-numa-node\npodExclude:\n- namespacePattern: foo\n  namePattern: bar-*\n")
+
+	select {
+	case conf := <-changes:
+		if len(conf.PodExclude) != 1 || conf.PodExclude[0].NamespacePattern != "foo" || conf.PodExclude[0].NamePattern != "bar-*" {
+			t.Errorf("unexpected reloaded configuration: %#v", conf)
+		}
+		if conf.Kubelet.TopologyManagerPolicy != "single-numa-node" {
+			t.Errorf("unexpected reloaded configuration: %#v", conf)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("configuration change not detected")
+	}
+}
+
+func TestWatcherIgnoresUnchangedContent(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, Key)
+	data := []byte("kubelet:\n  topologyManagerScope: pod\n")
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		t.Fatalf("writing config: %v", err)
+	}
+
+	changes := make(chan Config, 4)
+	w, err := NewWatcher(configPath, func(conf Config) {
+		changes <- conf
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating the watcher: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	if err := os.WriteFile(filepath.Join(dir, "unrelated"), []byte("foo"), 0644); err != nil {
+		t.Fatalf("writing unrelated file: %v", err)
+	}
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		t.Fatalf("rewriting config: %v", err)
+	}
+
+	select {
+	case conf := <-changes:
+		t.Errorf("unexpected configuration change: %#v", conf)
+	case <-time.After(time.Second):
+	}
+}
+
+func writeConfigMapVolume(t *testing.T, dir, tsDir, data string) {
+	t.Helper()
+	if err := os.Mkdir(filepath.Join(dir, tsDir), 0755); err != nil {
+		t.Fatalf("creating data dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, tsDir, Key), []byte(data), 0644); err != nil {
+		t.Fatalf("writing config: %v", err)
+	}
+	if err := os.Symlink(tsDir, filepath.Join(dir, "..data_tmp")); err != nil {
+		t.Fatalf("creating data symlink: %v", err)
+	}
+	if err := os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")); err != nil {
+		t.Fatalf("swapping data symlink: %v", err)
+	}
+}
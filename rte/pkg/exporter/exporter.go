@@ -0,0 +1,185 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package exporter is a fork of the upstream resourcetopologyexporter.Execute loop and ResourceObserver.
+// Upstream builds the observer, and the resource monitor on top of the podresources lister, inside Execute,
+// with no hook to reach them: there is no way to replace the resource exclusion list at runtime,
+// to trigger a scan on demand, or to report the progress of the loop. The code is kept as close as
+// possible to upstream, to ease the rebases, and should be dropped once upstream grows such hooks.
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/kubeconf"
+	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/metrics"
+	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/notification"
+	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/nrtupdater"
+	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/podreadiness"
+	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/ratelimiter"
+	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/resourcemonitor"
+	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/resourcetopologyexporter"
+
+	"github.com/openshift-kni/numaresources-operator/rte/pkg/health"
+)
+
+// Exporter is the RTE main loop, equivalent to resourcetopologyexporter.Execute
+// but allowing to reconfigure the resource observation at runtime, and reporting
+// its progress to the health tracker.
+type Exporter struct {
+	ResObs *ResourceObserver
+
+	eventSource    notification.EventSource
+	updater        *nrtupdater.NRTUpdater
+	nrtupdaterArgs nrtupdater.Args
+	condChan       chan v1.PodCondition
+	health         *health.Tracker
+}
+
+// New creates a new Exporter. The health tracker is optional.
+func New(hnd resourcetopologyexporter.Handle, nrtupdaterArgs nrtupdater.Args, resourcemonitorArgs resourcemonitor.Args, rteArgs resourcetopologyexporter.Args, tracker *health.Tracker) (*Exporter, error) {
+	tmConf, err := getTopologyManagerSettings(rteArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodeGetter nrtupdater.NodeGetter
+	if rteArgs.AddNRTOwnerEnable {
+		nodeGetter, err = nrtupdater.NewCachedNodeGetter(hnd.ResMon.K8SCli, context.Background())
+		if err != nil {
+			klog.V(2).Info("Cannot enable 'add-nrt-owner'. Unable to get node info")
+			return nil, fmt.Errorf("cannot enable 'add-nrt-owner': %w", err)
+		}
+	} else {
+		nodeGetter = &nrtupdater.DisabledNodeGetter{}
+	}
+
+	ex := Exporter{
+		nrtupdaterArgs: nrtupdaterArgs,
+		health:         tracker,
+	}
+	if rteArgs.PodReadinessEnable {
+		ex.condChan = make(chan v1.PodCondition)
+		condIn, err := podreadiness.NewConditionInjector(hnd.ResMon.K8SCli)
+		if err != nil {
+			return nil, err
+		}
+		condIn.Run(ex.condChan)
+	}
+
+	ex.eventSource, err = createEventSource(&rteArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	ex.ResObs, err = NewResourceObserver(hnd.ResMon, resourcemonitorArgs)
+	if err != nil {
+		return nil, err
+	}
+	ex.ResObs.health = tracker
+
+	ex.updater, err = nrtupdater.NewNRTUpdater(nodeGetter, hnd.NRTCli, nrtupdaterArgs, tmConf)
+	if err != nil {
+		return nil, err
+	}
+	return &ex, nil
+}
+
+// Run starts the exporter. Never returns.
+func (ex *Exporter) Run() error {
+	go ex.ResObs.Run(ex.eventSource.Events(), ex.condChan)
+	go ex.runUpdater()
+	go ex.eventSource.Run()
+
+	ex.eventSource.Wait()  // will never return
+	ex.eventSource.Close() // still we try to clean after ourselves :)
+	return nil             // unreachable
+}
+
+// runUpdater is equivalent to NRTUpdater.Run, but reports the outcome of the updates to the health tracker
+func (ex *Exporter) runUpdater() {
+	for info := range ex.ResObs.Infos {
+		tsBegin := time.Now()
+		ex.health.UpdateStarted()
+		condStatus := v1.ConditionTrue
+		err := ex.updater.Update(info)
+		if err != nil {
+			klog.Warningf("failed to update: %v", err)
+			condStatus = v1.ConditionFalse
+		}
+		ex.health.UpdateDone(err)
+		tsEnd := time.Now()
+
+		tsDiff := tsEnd.Sub(tsBegin)
+		metrics.UpdateOperationDelayMetric("node_resource_object_update", nrtupdater.RTEUpdateReactive, float64(tsDiff.Milliseconds()))
+		if ex.nrtupdaterArgs.Oneshot {
+			continue
+		}
+		podreadiness.SetCondition(ex.condChan, podreadiness.NodeTopologyUpdated, condStatus)
+	}
+}
+
+func createEventSource(rteArgs *resourcetopologyexporter.Args) (notification.EventSource, error) {
+	eventSource, err := notification.NewUnlimitedEventSource()
+	if err != nil {
+		return nil, err
+	}
+
+	err = eventSource.SetInterval(rteArgs.SleepInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	err = eventSource.AddFile(rteArgs.NotifyFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// If rate limit parameters are configured set it up
+	if rteArgs.MaxEventsPerTimeUnit > 0 && rteArgs.TimeUnitToLimitEvents > 0 {
+		return ratelimiter.NewRateLimitedEventSource(eventSource, uint64(rteArgs.MaxEventsPerTimeUnit), rteArgs.TimeUnitToLimitEvents)
+	}
+	return eventSource, nil
+}
+
+func getTopologyManagerSettings(rteArgs resourcetopologyexporter.Args) (nrtupdater.TMConfig, error) {
+	if rteArgs.TopologyManagerPolicy != "" && rteArgs.TopologyManagerScope != "" {
+		tmConf := nrtupdater.TMConfig{
+			Policy: rteArgs.TopologyManagerPolicy,
+			Scope:  rteArgs.TopologyManagerScope,
+		}
+		klog.Infof("using given Topology Manager policy %q scope %q", tmConf.Policy, tmConf.Scope)
+		return tmConf, nil
+	}
+	if rteArgs.KubeletConfigFile != "" {
+		klConfig, err := kubeconf.GetKubeletConfigFromLocalFile(rteArgs.KubeletConfigFile)
+		if err != nil {
+			return nrtupdater.TMConfig{}, fmt.Errorf("error getting topology Manager Policy: %w", err)
+		}
+		tmConf := nrtupdater.TMConfig{
+			Policy: klConfig.TopologyManagerPolicy,
+			Scope:  klConfig.TopologyManagerScope,
+		}
+		klog.Infof("using detected Topology Manager policy %q scope %q", tmConf.Policy, tmConf.Scope)
+		return tmConf, nil
+	}
+	return nrtupdater.TMConfig{}, fmt.Errorf("cannot find the kubelet Topology Manager policy")
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package exporter
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/k8sannotations"
+	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/metrics"
+	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/notification"
+	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/nrtupdater"
+	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/podreadiness"
+	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/resourcemonitor"
+
+	"github.com/openshift-kni/numaresources-operator/rte/pkg/health"
+)
+
+// ResourceObserver scans the node resources on each event and feeds the results to the NRT updater.
+// Unlike the upstream ResourceObserver, the resource exclusion list can be replaced at runtime,
+// and a scan can be triggered on demand to publish the changes as soon as possible.
+type ResourceObserver struct {
+	Infos        <-chan nrtupdater.MonitorInfo
+	resMon       resourcemonitor.ResourceMonitor
+	infoChan     chan nrtupdater.MonitorInfo
+	stopChan     chan struct{}
+	refreshChan  chan struct{}
+	exposeTiming bool
+	health       *health.Tracker
+
+	lock            sync.RWMutex
+	resourceExclude resourcemonitor.ResourceExclude
+}
+
+func NewResourceObserver(hnd resourcemonitor.Handle, args resourcemonitor.Args) (*ResourceObserver, error) {
+	resMon, err := resourcemonitor.NewResourceMonitor(hnd, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize ResourceMonitor: %w", err)
+	}
+	return newResourceObserver(resMon, args), nil
+}
+
+func newResourceObserver(resMon resourcemonitor.ResourceMonitor, args resourcemonitor.Args) *ResourceObserver {
+	resObs := ResourceObserver{
+		resMon:          resMon,
+		resourceExclude: args.ResourceExclude,
+		stopChan:        make(chan struct{}),
+		infoChan:        make(chan nrtupdater.MonitorInfo),
+		refreshChan:     make(chan struct{}, 1),
+		exposeTiming:    args.ExposeTiming,
+	}
+	resObs.Infos = resObs.infoChan
+	return &resObs
+}
+
+func (rm *ResourceObserver) ResourceExclude() resourcemonitor.ResourceExclude {
+	rm.lock.RLock()
+	defer rm.lock.RUnlock()
+	return rm.resourceExclude
+}
+
+// SetResourceExclude replaces the resource exclusion list, which will be used from the next scan.
+func (rm *ResourceObserver) SetResourceExclude(resourceExclude resourcemonitor.ResourceExclude) {
+	rm.lock.Lock()
+	defer rm.lock.Unlock()
+	rm.resourceExclude = resourceExclude
+}
+
+// Refresh requests a scan as soon as possible. Multiple pending requests are coalesced.
+func (rm *ResourceObserver) Refresh() {
+	select {
+	case rm.refreshChan <- struct{}{}:
+	default: // a refresh is already pending
+	}
+}
+
+func (rm *ResourceObserver) Stop() {
+	rm.stopChan <- struct{}{}
+}
+
+func (rm *ResourceObserver) Run(eventsChan <-chan notification.Event, condChan chan<- v1.PodCondition) {
+	lastWakeup := time.Now()
+	for {
+		select {
+		case ev := <-eventsChan:
+			lastWakeup = rm.scan(ev, lastWakeup, condChan)
+		case <-rm.refreshChan:
+			klog.V(2).Infof("refresh requested at %v", time.Now())
+			lastWakeup = rm.scan(notification.Event{Timestamp: time.Now()}, lastWakeup, condChan)
+		case <-rm.stopChan:
+			klog.Infof("read stop at %v", time.Now())
+			return
+		}
+	}
+}
+
+func (rm *ResourceObserver) scan(ev notification.Event, lastWakeup time.Time, condChan chan<- v1.PodCondition) time.Time {
+	monInfo := nrtupdater.MonitorInfo{Timer: ev.IsTimer()}
+
+	tsWakeupDiff := ev.Timestamp.Sub(lastWakeup)
+	metrics.UpdateWakeupDelayMetric(monInfo.UpdateReason(), float64(tsWakeupDiff.Milliseconds()))
+
+	tsBegin := time.Now()
+	scanRes, err := rm.resMon.Scan(rm.ResourceExclude())
+	tsEnd := time.Now()
+	rm.health.ScanDone(err)
+
+	if err != nil {
+		klog.Warningf("failed to scan pod resources: %v\n", err)
+		podreadiness.SetCondition(condChan, podreadiness.PodresourcesFetched, v1.ConditionFalse)
+		return ev.Timestamp
+	}
+
+	monInfo.Annotations = scanRes.Annotations
+	monInfo.Attributes = scanRes.Attributes
+	monInfo.Zones = scanRes.Zones
+
+	if rm.exposeTiming {
+		if monInfo.Annotations == nil {
+			monInfo.Annotations = make(map[string]string)
+		}
+		monInfo.Annotations[k8sannotations.SleepDuration] = clampTime(tsWakeupDiff.Round(time.Second)).String()
+		monInfo.Annotations[k8sannotations.UpdateInterval] = clampTime(ev.TimerInterval).String()
+	}
+
+	rm.infoChan <- monInfo
+
+	tsDiff := tsEnd.Sub(tsBegin)
+	metrics.UpdateOperationDelayMetric("podresources_scan", monInfo.UpdateReason(), float64(tsDiff.Milliseconds()))
+	podreadiness.SetCondition(condChan, podreadiness.PodresourcesFetched, v1.ConditionTrue)
+	return ev.Timestamp
+}
+
+func clampTime(t time.Duration) time.Duration {
+	if t < 0 {
+		return 0
+	}
+	return t
+}
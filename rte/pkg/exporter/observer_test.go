@@ -0,0 +1,94 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package exporter
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/notification"
+	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/resourcemonitor"
+)
+
+type fakeResourceMonitor struct {
+	lock     sync.Mutex
+	excludes []resourcemonitor.ResourceExclude
+}
+
+func (frm *fakeResourceMonitor) Scan(excludeList resourcemonitor.ResourceExclude) (resourcemonitor.ScanResponse, error) {
+	frm.lock.Lock()
+	defer frm.lock.Unlock()
+	frm.excludes = append(frm.excludes, excludeList)
+	return resourcemonitor.ScanResponse{}, nil
+}
+
+func TestResourceObserverRefreshUsesUpdatedExcludes(t *testing.T) {
+	resMon := &fakeResourceMonitor{}
+	resObs := newResourceObserver(resMon, resourcemonitor.Args{
+		ResourceExclude: resourcemonitor.ResourceExclude{
+			"*": []string{"memory"},
+		},
+	})
+
+	events := make(chan notification.Event)
+	go resObs.Run(events, nil)
+	defer resObs.Stop()
+
+	events <- notification.Event{Timestamp: time.Now(), TimerInterval: time.Minute}
+	expectMonitorInfo(t, resObs, true)
+
+	updated := resourcemonitor.ResourceExclude{
+		"node-1": []string{"hugepages-1Gi"},
+	}
+	resObs.SetResourceExclude(updated)
+	resObs.Refresh()
+	expectMonitorInfo(t, resObs, false)
+
+	resMon.lock.Lock()
+	defer resMon.lock.Unlock()
+	if len(resMon.excludes) != 2 {
+		t.Fatalf("unexpected scans: %v", resMon.excludes)
+	}
+	if !reflect.DeepEqual(resMon.excludes[1], updated) {
+		t.Errorf("refresh scanned with stale excludes: %v", resMon.excludes[1])
+	}
+}
+
+func TestResourceObserverRefreshCoalesces(t *testing.T) {
+	resObs := newResourceObserver(&fakeResourceMonitor{}, resourcemonitor.Args{})
+	// no one is consuming, so only the first request can be queued
+	resObs.Refresh()
+	resObs.Refresh()
+	resObs.Refresh()
+	if len(resObs.refreshChan) != 1 {
+		t.Errorf("unexpected pending refreshes: %d", len(resObs.refreshChan))
+	}
+}
+
+func expectMonitorInfo(t *testing.T, resObs *ResourceObserver, timer bool) {
+	t.Helper()
+	select {
+	case info := <-resObs.Infos:
+		if info.Timer != timer {
+			t.Errorf("unexpected update reason: %q", info.UpdateReason())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no monitor info received")
+	}
+}
@@ -0,0 +1,200 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	rteconfig "github.com/openshift-kni/numaresources-operator/rte/pkg/config"
+)
+
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+
+	DefaultPort = 8081
+	// PortEnvVar overrides the port on which the health endpoints are served.
+	PortEnvVar = "HEALTH_PROBES_PORT"
+
+	// DefaultMaxMissedPeriods is how many refresh periods the RTE may go without publishing before being reported unhealthy.
+	DefaultMaxMissedPeriods = 3
+
+	// eventOnlyWindow is the grace period used when there is no periodic refresh,
+	// so the update loop may be legitimately idle for any amount of time.
+	eventOnlyWindow = 2 * time.Minute
+)
+
+// Tracker records the state of the RTE main loop and reports its health and readiness.
+// The zero value is not usable, use NewTracker. A nil Tracker is valid and ignores all the updates.
+type Tracker struct {
+	configPath    string
+	refreshPeriod time.Duration
+	maxMissed     int
+	now           func() time.Time
+
+	lock            sync.Mutex
+	podresConnected bool
+	podresErr       error
+	lastActivity    time.Time
+	lastPublish     time.Time
+	lastPublishErr  error
+	inflightSince   time.Time
+}
+
+func NewTracker(configPath string, refreshPeriod time.Duration, maxMissedPeriods int) *Tracker {
+	tr := &Tracker{
+		configPath:    configPath,
+		refreshPeriod: refreshPeriod,
+		maxMissed:     maxMissedPeriods,
+		now:           time.Now,
+	}
+	tr.lastActivity = tr.now()
+	return tr
+}
+
+// window is the maximum time the update loop is allowed to go without making progress.
+func (tr *Tracker) window() time.Duration {
+	if tr.refreshPeriod <= 0 {
+		return eventOnlyWindow
+	}
+	return time.Duration(tr.maxMissed) * tr.refreshPeriod
+}
+
+// PodResourcesConnected records the podresources API connection is established.
+func (tr *Tracker) PodResourcesConnected() {
+	if tr == nil {
+		return
+	}
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+	tr.podresConnected = true
+}
+
+// ScanDone records the outcome of a podresources scan.
+func (tr *Tracker) ScanDone(err error) {
+	if tr == nil {
+		return
+	}
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+	tr.podresErr = err
+}
+
+// UpdateStarted records the begin of a NRT update.
+func (tr *Tracker) UpdateStarted() {
+	if tr == nil {
+		return
+	}
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+	tr.inflightSince = tr.now()
+}
+
+// UpdateDone records the outcome of a NRT update.
+func (tr *Tracker) UpdateDone(err error) {
+	if tr == nil {
+		return
+	}
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+	ts := tr.now()
+	tr.inflightSince = time.Time{}
+	tr.lastActivity = ts
+	tr.lastPublishErr = err
+	if err == nil {
+		tr.lastPublish = ts
+	}
+}
+
+// Live returns error if the update loop is wedged.
+func (tr *Tracker) Live() error {
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+	ts := tr.now()
+	window := tr.window()
+	if !tr.inflightSince.IsZero() && ts.Sub(tr.inflightSince) > window {
+		return fmt.Errorf("NRT update in progress since %v", tr.inflightSince)
+	}
+	if tr.refreshPeriod > 0 && ts.Sub(tr.lastActivity) > window {
+		return fmt.Errorf("no NRT update attempted since %v (refresh period %v)", tr.lastActivity, tr.refreshPeriod)
+	}
+	return nil
+}
+
+// Ready returns error if the RTE is not publishing up to date information.
+func (tr *Tracker) Ready() error {
+	if _, err := rteconfig.ReadFile(tr.configPath); err != nil {
+		return fmt.Errorf("cannot read the configuration from %q: %w", tr.configPath, err)
+	}
+
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+	if !tr.podresConnected {
+		return errors.New("podresources API not connected")
+	}
+	if tr.podresErr != nil {
+		return fmt.Errorf("podresources scan failed: %w", tr.podresErr)
+	}
+	if tr.lastPublish.IsZero() {
+		if tr.lastPublishErr != nil {
+			return fmt.Errorf("NRT never published: %w", tr.lastPublishErr)
+		}
+		return errors.New("NRT not published yet")
+	}
+	if tr.refreshPeriod > 0 && tr.now().Sub(tr.lastPublish) > tr.window() {
+		if tr.lastPublishErr != nil {
+			return fmt.Errorf("NRT last published at %v (refresh period %v): %w", tr.lastPublish, tr.refreshPeriod, tr.lastPublishErr)
+		}
+		return fmt.Errorf("NRT last published at %v (refresh period %v)", tr.lastPublish, tr.refreshPeriod)
+	}
+	return nil
+}
+
+func (tr *Tracker) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(LivenessPath, probeHandler(tr.Live))
+	mux.HandleFunc(ReadinessPath, probeHandler(tr.Ready))
+	return mux
+}
+
+// Serve exposes the health endpoints on the given address. Never returns on success.
+func (tr *Tracker) Serve(addr string) error {
+	klog.Infof("serving health probes on %q", addr)
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           tr.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return srv.ListenAndServe()
+}
+
+func probeHandler(check func() error) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		if err := check(); err != nil {
+			klog.V(2).InfoS("probe failed", "err", err)
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	}
+}
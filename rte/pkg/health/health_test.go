@@ -0,0 +1,173 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	ts time.Time
+}
+
+func (fc *fakeClock) Now() time.Time {
+	return fc.ts
+}
+
+func (fc *fakeClock) Advance(d time.Duration) {
+	fc.ts = fc.ts.Add(d)
+}
+
+func newTestTracker(t *testing.T, refreshPeriod time.Duration) (*Tracker, *fakeClock) {
+	t.Helper()
+	clk := &fakeClock{ts: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	tr := NewTracker(filepath.Join(t.TempDir(), "config.yaml"), refreshPeriod, DefaultMaxMissedPeriods)
+	tr.now = clk.Now
+	tr.lastActivity = clk.Now()
+	return tr, clk
+}
+
+func TestReadiness(t *testing.T) {
+	tr, clk := newTestTracker(t, 10*time.Second)
+
+	if err := tr.Ready(); err == nil {
+		t.Errorf("ready before connecting to podresources")
+	}
+
+	tr.PodResourcesConnected()
+	if err := tr.Ready(); err == nil {
+		t.Errorf("ready before publishing")
+	}
+
+	tr.ScanDone(nil)
+	tr.UpdateStarted()
+	tr.UpdateDone(nil)
+	if err := tr.Ready(); err != nil {
+		t.Errorf("not ready after publishing: %v", err)
+	}
+
+	tr.ScanDone(errors.New("podresources unavailable"))
+	if err := tr.Ready(); err == nil {
+		t.Errorf("ready despite podresources scan failure")
+	}
+	tr.ScanDone(nil)
+
+	// publish keeps failing: ready until we miss too many refresh periods
+	clk.Advance(20 * time.Second)
+	tr.UpdateStarted()
+	tr.UpdateDone(errors.New("apiserver unavailable"))
+	if err := tr.Ready(); err != nil {
+		t.Errorf("not ready within the grace period: %v", err)
+	}
+	clk.Advance(20 * time.Second)
+	tr.UpdateStarted()
+	tr.UpdateDone(errors.New("apiserver unavailable"))
+	if err := tr.Ready(); err == nil {
+		t.Errorf("ready despite stale NRT data")
+	}
+}
+
+func TestReadinessUnreadableConfig(t *testing.T) {
+	tr, _ := newTestTracker(t, 10*time.Second)
+	tr.PodResourcesConnected()
+	tr.UpdateStarted()
+	tr.UpdateDone(nil)
+
+	if err := os.WriteFile(tr.configPath, []byte("this is: [not valid"), 0644); err != nil {
+		t.Fatalf("writing config: %v", err)
+	}
+	if err := tr.Ready(); err == nil {
+		t.Errorf("ready despite malformed configuration")
+	}
+}
+
+func TestLiveness(t *testing.T) {
+	tr, clk := newTestTracker(t, 10*time.Second)
+	if err := tr.Live(); err != nil {
+		t.Errorf("not live at startup: %v", err)
+	}
+
+	clk.Advance(25 * time.Second)
+	tr.UpdateStarted()
+	tr.UpdateDone(errors.New("apiserver unavailable"))
+	// failing updates are not a reason to restart, as long as the loop progresses
+	clk.Advance(25 * time.Second)
+	if err := tr.Live(); err != nil {
+		t.Errorf("not live while the loop is progressing: %v", err)
+	}
+
+	// update loop wedged
+	tr.UpdateStarted()
+	clk.Advance(31 * time.Second)
+	if err := tr.Live(); err == nil {
+		t.Errorf("live despite the update loop being wedged")
+	}
+}
+
+func TestLivenessEventsOnly(t *testing.T) {
+	tr, clk := newTestTracker(t, 0)
+	clk.Advance(time.Hour)
+	if err := tr.Live(); err != nil {
+		t.Errorf("not live while idle without periodic updates: %v", err)
+	}
+	tr.UpdateStarted()
+	clk.Advance(eventOnlyWindow + time.Second)
+	if err := tr.Live(); err == nil {
+		t.Errorf("live despite the update being stuck")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	tr, _ := newTestTracker(t, 10*time.Second)
+	srv := httptest.NewServer(tr.Handler())
+	defer srv.Close()
+
+	expectStatus(t, srv.URL+LivenessPath, http.StatusOK)
+	expectStatus(t, srv.URL+ReadinessPath, http.StatusServiceUnavailable)
+
+	tr.PodResourcesConnected()
+	tr.UpdateStarted()
+	tr.UpdateDone(nil)
+	expectStatus(t, srv.URL+ReadinessPath, http.StatusOK)
+}
+
+func TestNilTracker(t *testing.T) {
+	var tr *Tracker
+	// must not crash
+	tr.PodResourcesConnected()
+	tr.ScanDone(nil)
+	tr.UpdateStarted()
+	tr.UpdateDone(nil)
+}
+
+func expectStatus(t *testing.T, url string, code int) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != code {
+		t.Errorf("GET %s: got status %d expected %d", url, resp.StatusCode, code)
+	}
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package podexclude
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"k8s.io/klog/v2"
+	podresourcesapi "k8s.io/kubelet/pkg/apis/podresources/v1"
+
+	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/podres/middleware/podexclude"
+)
+
+// Lister is a PodResourcesListerClient middleware which filters out the pods
+// matching the exclusion list. Unlike the upstream podexclude middleware,
+// the exclusion list can be replaced at runtime.
+type Lister struct {
+	debug bool
+	cli   podresourcesapi.PodResourcesListerClient
+
+	lock        sync.RWMutex
+	podExcludes podexclude.List
+}
+
+func NewFromLister(cli podresourcesapi.PodResourcesListerClient, debug bool, podExcludes podexclude.List) *Lister {
+	klog.Infof("> POD excludes:\n%s", podExcludes.String())
+	return &Lister{
+		debug:       debug,
+		cli:         cli,
+		podExcludes: podExcludes.Clone(),
+	}
+}
+
+func (l *Lister) PodExcludes() podexclude.List {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	return l.podExcludes.Clone()
+}
+
+func (l *Lister) SetPodExcludes(podExcludes podexclude.List) {
+	klog.Infof("> POD excludes updated:\n%s", podExcludes.String())
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.podExcludes = podExcludes.Clone()
+}
+
+func (l *Lister) List(ctx context.Context, in *podresourcesapi.ListPodResourcesRequest, opts ...grpc.CallOption) (*podresourcesapi.ListPodResourcesResponse, error) {
+	resp, err := l.cli.List(ctx, in, opts...)
+	if err != nil {
+		return resp, err
+	}
+
+	podExcludes := l.PodExcludes()
+	if len(podExcludes) == 0 {
+		return resp, nil
+	}
+
+	retResp := podresourcesapi.ListPodResourcesResponse{
+		PodResources: make([]*podresourcesapi.PodResources, 0, len(resp.GetPodResources())),
+	}
+	for _, podRes := range resp.GetPodResources() {
+		if podexclude.ShouldExclude(podExcludes, podRes.GetNamespace(), podRes.GetName(), l.debug) {
+			continue
+		}
+		retResp.PodResources = append(retResp.PodResources, podRes)
+	}
+	return &retResp, nil
+}
+
+func (l *Lister) GetAllocatableResources(ctx context.Context, in *podresourcesapi.AllocatableResourcesRequest, opts ...grpc.CallOption) (*podresourcesapi.AllocatableResourcesResponse, error) {
+	return l.cli.GetAllocatableResources(ctx, in, opts...)
+}
+
+func (l *Lister) Get(ctx context.Context, in *podresourcesapi.GetPodResourcesRequest, opts ...grpc.CallOption) (*podresourcesapi.GetPodResourcesResponse, error) {
+	return l.cli.Get(ctx, in, opts...)
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package podexclude
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	podresourcesapi "k8s.io/kubelet/pkg/apis/podresources/v1"
+
+	"github.com/k8stopologyawareschedwg/resource-topology-exporter/pkg/podres/middleware/podexclude"
+)
+
+type fakeLister struct {
+	podresourcesapi.PodResourcesListerClient
+	pods []*podresourcesapi.PodResources
+}
+
+func (fl *fakeLister) List(ctx context.Context, in *podresourcesapi.ListPodResourcesRequest, opts ...grpc.CallOption) (*podresourcesapi.ListPodResourcesResponse, error) {
+	return &podresourcesapi.ListPodResourcesResponse{PodResources: fl.pods}, nil
+}
+
+func TestListerSetPodExcludes(t *testing.T) {
+	cli := &fakeLister{
+		pods: []*podresourcesapi.PodResources{
+			{Namespace: "ns1", Name: "pod-a"},
+			{Namespace: "ns1", Name: "pod-b"},
+			{Namespace: "ns2", Name: "pod-a"},
+		},
+	}
+
+	lister := NewFromLister(cli, false, nil)
+	if got := listNames(t, lister); !reflect.DeepEqual(got, []string{"ns1/pod-a", "ns1/pod-b", "ns2/pod-a"}) {
+		t.Errorf("unexpected pods with no excludes: %v", got)
+	}
+
+	lister.SetPodExcludes(podexclude.List{
+		{NamespacePattern: "ns1", NamePattern: "*"},
+	})
+	if got := listNames(t, lister); !reflect.DeepEqual(got, []string{"ns2/pod-a"}) {
+		t.Errorf("unexpected pods after the first update: %v", got)
+	}
+
+	lister.SetPodExcludes(podexclude.List{
+		{NamespacePattern: "*", NamePattern: "pod-a"},
+	})
+	if got := listNames(t, lister); !reflect.DeepEqual(got, []string{"ns1/pod-b"}) {
+		t.Errorf("unexpected pods after the second update: %v", got)
+	}
+
+	lister.SetPodExcludes(nil)
+	if got := listNames(t, lister); len(got) != 3 {
+		t.Errorf("unexpected pods after clearing the excludes: %v", got)
+	}
+}
+
+func listNames(t *testing.T, lister *Lister) []string {
+	t.Helper()
+	resp, err := lister.List(context.Background(), &podresourcesapi.ListPodResourcesRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, pr := range resp.GetPodResources() {
+		names = append(names, pr.GetNamespace()+"/"+pr.GetName())
+	}
+	return names
+}
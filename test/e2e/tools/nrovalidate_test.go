@@ -18,6 +18,7 @@ package tools
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -70,7 +71,7 @@ var _ = Describe("[tools][validation] nrovalidate tools", func() {
 			cmd := exec.Command(cmdline[0], cmdline[1:]...)
 			cmd.Stderr = GinkgoWriter
 			out, err := cmd.Output()
-			Expect(err).ToNot(HaveOccurred())
+			expectValidationFailed(err)
 
 			rep := nrovalidator.Report{}
 			err = json.Unmarshal(out, &rep)
@@ -127,7 +128,7 @@ var _ = Describe("[tools][validation] nrovalidate tools", func() {
 			cmd := exec.Command(cmdline[0], cmdline[1:]...)
 			cmd.Stderr = GinkgoWriter
 			out, err := cmd.Output()
-			Expect(err).ToNot(HaveOccurred())
+			expectValidationFailed(err)
 
 			rep := nrovalidator.Report{}
 			err = json.Unmarshal(out, &rep)
@@ -142,6 +143,14 @@ var _ = Describe("[tools][validation] nrovalidate tools", func() {
 	})
 })
 
+// expectValidationFailed checks nrovalidate ran successfully and reported validation errors
+func expectValidationFailed(err error) {
+	GinkgoHelper()
+	var exitErr *exec.ExitError
+	Expect(errors.As(err, &exitErr)).To(BeTrue(), "unexpected error: %v", err)
+	Expect(exitErr.ExitCode()).To(Equal(1))
+}
+
 func makeCRD(sink io.WriteCloser) error {
 	crd, err := manifests.APICRD()
 	if err != nil {
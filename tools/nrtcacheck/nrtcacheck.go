@@ -49,6 +49,7 @@ import (
 	"github.com/openshift-kni/numaresources-operator/pkg/version"
 
 	intkloglevel "github.com/openshift-kni/numaresources-operator/internal/kloglevel"
+	"github.com/openshift-kni/numaresources-operator/internal/pfpstatus"
 	"github.com/openshift-kni/numaresources-operator/internal/podlist"
 	"github.com/openshift-kni/numaresources-operator/internal/schedcache"
 )
@@ -69,6 +70,7 @@ func init() {
 type ProgArgs struct {
 	Version   bool
 	DumpNodes bool
+	UseExec   bool
 	NodeNames sets.Set[string]
 }
 
@@ -100,7 +102,14 @@ func main() {
 
 	ctx := context.Background()
 
-	rtePodsByNode, err := findRTEPodsByNodeName(ctx, cli)
+	nroObj := nropv1.NUMAResourcesOperator{}
+	err = cli.Get(ctx, client.ObjectKey{Name: objectnames.DefaultNUMAResourcesOperatorCrName}, &nroObj)
+	if err != nil {
+		klog.V(1).ErrorS(err, "getting the NUMAResourcesOperator object")
+		os.Exit(1)
+	}
+
+	rtePodsByNode, err := findRTEPodsByNodeName(ctx, cli, &nroObj)
 	if err != nil {
 		klog.V(1).ErrorS(err, "mapping RTE pods to nodes")
 		os.Exit(1)
@@ -122,6 +131,14 @@ func main() {
 		K8sCli: k8sCli,
 		Log:    textlogger.NewLogger(logCfg),
 	}
+	if !parsedArgs.UseExec {
+		env.PFPStatus, err = pfpstatus.NewClient()
+		if err != nil {
+			// the dumps can still be read executing commands in the pods
+			klog.V(1).ErrorS(err, "creating pfp status client, falling back to exec")
+			env.PFPStatus = nil
+		}
+	}
 
 	var nodeNames []string
 	if parsedArgs.NodeNames.Len() > 0 {
@@ -170,7 +187,15 @@ func main() {
 		os.Exit(0)
 	}
 
-	for nodeName, podsBySched := range unsynced {
+	nroSched := nropv1.NUMAResourcesScheduler{}
+	err = cli.Get(ctx, client.ObjectKey{Name: objectnames.DefaultNUMAResourcesSchedulerCrName}, &nroSched)
+	if err != nil {
+		klog.V(1).ErrorS(err, "getting the NUMAResourcesScheduler object")
+		os.Exit(1)
+	}
+	cl := schedcache.NewClassifier(&nroObj, &nroSched)
+
+	for _, nodeName := range sets.List(sets.KeySet(unsynced)) {
 		podnn, ok := rtePodsByNode[nodeName]
 		if !ok {
 			klog.Warningf("no RTE pod on %q?", nodeName)
@@ -183,39 +208,33 @@ func main() {
 			continue
 		}
 
-		podsByRTE := sets.New[string]()
-		for _, nn := range st.Pods {
-			podsByRTE.Insert(nn.String())
+		nd, err := schedcache.DiffNode(&env, cl, nodeName, unsynced[nodeName], st)
+		if err != nil {
+			klog.V(1).ErrorS(err, "cannot classify the unsynced pods", "node", nodeName)
+			continue
 		}
 
+		fmt.Printf("%s: RTE fingerprint %q\n", nodeName, nd.RTEFingerprint)
+
 		fmt.Printf("%s: pods on sched, not on RTE: [\n", nodeName)
-		for _, name := range sets.List(podsBySched.Difference(podsByRTE)) {
-			fmt.Printf(" - %s\n", name)
+		for _, pd := range nd.SchedOnly {
+			fmt.Printf(" - %s\n", pd.String())
 		}
 		fmt.Printf("]\n")
 
 		fmt.Printf("%s: pods on RTE, not on sched: [\n", nodeName)
-		for _, name := range sets.List(podsByRTE.Difference(podsBySched)) {
-			fmt.Printf(" - %s\n", name)
+		for _, pd := range nd.RTEOnly {
+			fmt.Printf(" - %s\n", pd.String())
 		}
 		fmt.Printf("]\n")
 	}
 }
 
-func findRTEPodsByNodeName(ctx context.Context, cli client.Client) (map[string]types.NamespacedName, error) {
-	nroNName := types.NamespacedName{
-		Name: objectnames.DefaultNUMAResourcesOperatorCrName,
-	}
-	nroObj := nropv1.NUMAResourcesOperator{}
-	err := cli.Get(context.TODO(), nroNName, &nroObj)
-	if err != nil {
-		return nil, err
-	}
-
+func findRTEPodsByNodeName(ctx context.Context, cli client.Client, nroObj *nropv1.NUMAResourcesOperator) (map[string]types.NamespacedName, error) {
 	podsByName := make(map[string]types.NamespacedName)
 	for _, ds := range nroObj.Status.DaemonSets {
 		dsObj := appsv1.DaemonSet{}
-		err = cli.Get(context.TODO(), types.NamespacedName{Namespace: ds.Namespace, Name: ds.Name}, &dsObj)
+		err := cli.Get(context.TODO(), types.NamespacedName{Namespace: ds.Namespace, Name: ds.Name}, &dsObj)
 		if err != nil {
 			return nil, err
 		}
@@ -243,6 +262,7 @@ func parseArgs(args ...string) (ProgArgs, error) {
 
 	flags.BoolVar(&pArgs.Version, "version", false, "Output version and exit")
 	flags.BoolVar(&pArgs.DumpNodes, "dump-nodes", false, "Force node PFP status dump")
+	flags.BoolVar(&pArgs.UseExec, "use-exec", false, "Read the PFP status executing commands in the pods instead of using their HTTPS endpoint. The commands are executed anyway if the endpoint is not reachable, which requires the pods/exec permission.")
 	flags.Usage = func() {
 		fmt.Fprintf(flags.Output(), "Usage: %s [options] [node0 [node1] ... [nodeN]]\noptions:\n", os.Args[0])
 		flags.PrintDefaults()
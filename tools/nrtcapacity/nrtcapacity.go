@@ -0,0 +1,166 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	"github.com/openshift-kni/numaresources-operator/internal/baseload"
+	"github.com/openshift-kni/numaresources-operator/internal/capacity"
+	"github.com/openshift-kni/numaresources-operator/internal/resourcelist"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+func main() {
+	shapeVal := flag.String("shape", "", "the requests of the containers of the pod, like \"cpu=16,memory=32Gi\". Separate the containers with ';'.")
+	output := flag.String("output", outputTable, "output format: \"table\" or \"json\"")
+	nodes := flag.String("nodes", "", "comma-separated names of the nodes to report. Defaults to all the nodes with a NRT object.")
+	noBaseload := flag.Bool("no-baseload", false, "don't deduct the resources requested by the running pods from each zone")
+	flag.Parse()
+
+	if *shapeVal == "" {
+		fmt.Fprintf(os.Stderr, "missing --shape\n")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *output != outputTable && *output != outputJSON {
+		fmt.Fprintf(os.Stderr, "unsupported output format %q\n", *output)
+		os.Exit(1)
+	}
+	shape, err := capacity.ParseShape(*shapeVal)
+	if err != nil {
+		klog.ErrorS(err, "parsing the pod shape")
+		os.Exit(1)
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(nrtv1alpha2.AddToScheme(scheme))
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		klog.ErrorS(err, "getting the config")
+		os.Exit(1)
+	}
+	cli, err := client.New(cfg, client.Options{
+		Scheme: scheme,
+	})
+	if err != nil {
+		klog.ErrorS(err, "creating the client")
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	nrtList := nrtv1alpha2.NodeResourceTopologyList{}
+	if err := cli.List(ctx, &nrtList); err != nil {
+		klog.ErrorS(err, "listing the NRT objects")
+		os.Exit(1)
+	}
+
+	nrts := nrtList.Items
+	if *nodes != "" {
+		wanted := sets.New[string](strings.Split(*nodes, ",")...)
+		nrts = nil
+		for _, nrt := range nrtList.Items {
+			if wanted.Has(nrt.Name) {
+				nrts = append(nrts, nrt)
+			}
+		}
+	}
+
+	loads := make(map[string]baseload.Load)
+	if !*noBaseload {
+		for _, nrt := range nrts {
+			load, err := baseload.ForNode(cli, ctx, nrt.Name)
+			if err != nil {
+				klog.ErrorS(err, "computing the baseload", "node", nrt.Name)
+				os.Exit(1)
+			}
+			loads[nrt.Name] = load
+		}
+	}
+
+	rep := capacity.NewReport(shape, nrts, loads)
+	if *output == outputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(rep)
+	} else {
+		err = writeTable(os.Stdout, rep)
+	}
+	if err != nil {
+		klog.ErrorS(err, "writing the report")
+		os.Exit(1)
+	}
+}
+
+func writeTable(w io.Writer, rep capacity.Report) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "pod shape: %s\n\n", rep.Shape.String())
+
+	fmt.Fprintf(tw, "NODE\tZONE\tAVAILABLE\n")
+	for _, nodeRep := range rep.Nodes {
+		for _, zone := range nodeRep.Zones {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", nodeRep.Name, zone.Name, resourcelist.ToString(zone.Available))
+		}
+	}
+	fmt.Fprintf(tw, "\n")
+
+	header := []string{"NODE", "CONFIGURED"}
+	for _, tm := range capacity.TopologyManagers {
+		header = append(header, tm.String())
+	}
+	fmt.Fprintf(tw, "%s\n", strings.Join(header, "\t"))
+	for _, nodeRep := range rep.Nodes {
+		row := []string{nodeRep.Name, "N/A"}
+		if nodeRep.Configured != nil {
+			row[1] = fmt.Sprintf("%s=%d", nodeRep.Configured.String(), *nodeRep.ConfiguredPods)
+		}
+		for _, fit := range nodeRep.Fits {
+			row = append(row, fmt.Sprintf("%d", fit.Pods))
+		}
+		fmt.Fprintf(tw, "%s\n", strings.Join(row, "\t"))
+	}
+	row := []string{"TOTAL", fmt.Sprintf("%d", rep.ConfiguredPods)}
+	for _, fit := range rep.Totals {
+		row = append(row, fmt.Sprintf("%d", fit.Pods))
+	}
+	fmt.Fprintf(tw, "%s\n", strings.Join(row, "\t"))
+	return tw.Flush()
+}
@@ -0,0 +1,176 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	intnrt "github.com/openshift-kni/numaresources-operator/internal/noderesourcetopology"
+	"github.com/openshift-kni/numaresources-operator/internal/nrthistory"
+)
+
+const (
+	defaultHistoryFile = "nrt-history.jsonl"
+	defaultMaxSize     = 64 * 1024 * 1024
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %s record [--file F] [--max-size BYTES]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s show [--file F] [--node NAME] [--at RFC3339-TIMESTAMP]\n", os.Args[0])
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "record":
+		err = record(os.Args[2:])
+	case "show":
+		err = show(os.Args[2:])
+	default:
+		usage()
+		os.Exit(1)
+	}
+	if err != nil {
+		klog.ErrorS(err, os.Args[1])
+		os.Exit(1)
+	}
+}
+
+// record watches the NRT objects, and stores their changes in the history file until killed
+func record(args []string) error {
+	flags := flag.NewFlagSet("record", flag.ExitOnError)
+	historyFile := flags.String("file", defaultHistoryFile, "store the history in this file, rotated once bigger than max-size")
+	maxSize := flags.Int64("max-size", defaultMaxSize, "rotate the history file once bigger than this size, in bytes. Use 0 to never rotate.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(nrtv1alpha2.AddToScheme(scheme))
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("error getting the config: %w", err)
+	}
+	cli, err := client.NewWithWatch(cfg, client.Options{
+		Scheme: scheme,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating a watchable client: %w", err)
+	}
+
+	rec, err := nrthistory.NewRecorder(*historyFile, *maxSize)
+	if err != nil {
+		return err
+	}
+	defer rec.Close()
+
+	ctx := context.Background()
+	for {
+		klog.InfoS("start watching NRT objects", "file", *historyFile)
+		nrtObjs := nrtv1alpha2.NodeResourceTopologyList{}
+		wa, err := cli.Watch(ctx, &nrtObjs)
+		if err != nil {
+			return fmt.Errorf("cannot watch NRT objects: %w", err)
+		}
+		// the channel is closed when the watch expires: start over. The recorder
+		// remembers the last states, so the initial Added events record only the changes.
+		for ev := range wa.ResultChan() {
+			if err := processEvent(rec, ev); err != nil {
+				wa.Stop()
+				return err
+			}
+		}
+	}
+}
+
+func processEvent(rec *nrthistory.Recorder, ev watch.Event) error {
+	nrtObj, ok := ev.Object.(*nrtv1alpha2.NodeResourceTopology)
+	if !ok {
+		klog.InfoS("unexpected object", "type", fmt.Sprintf("%T", ev.Object))
+		return nil
+	}
+	switch ev.Type {
+	case watch.Added, watch.Modified:
+		return rec.Observe(time.Now(), nrtObj)
+	case watch.Deleted:
+		return rec.Delete(time.Now(), nrtObj.Name)
+	}
+	return nil
+}
+
+// show reconstructs the NRT objects as they were at the given time
+func show(args []string) error {
+	flags := flag.NewFlagSet("show", flag.ExitOnError)
+	historyFile := flags.String("file", defaultHistoryFile, "read the history from this file, and from the rotated one if any")
+	node := flags.String("node", "", "show only the NRT object of this node")
+	at := flags.String("at", "", "show the NRT objects as they were at this time, in RFC3339 format. Defaults to the latest recorded state.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ts := time.Now()
+	if *at != "" {
+		var err error
+		ts, err = time.Parse(time.RFC3339, *at)
+		if err != nil {
+			return err
+		}
+	}
+
+	records, err := nrthistory.ReadFile(*historyFile)
+	if err != nil {
+		return err
+	}
+
+	nodes := nrthistory.Nodes(records)
+	if *node != "" {
+		nodes = []string{*node}
+	}
+	var nrts []nrtv1alpha2.NodeResourceTopology
+	for _, name := range nodes {
+		nrt := nrthistory.At(records, name, ts)
+		if nrt == nil {
+			klog.InfoS("no NRT object recorded", "node", name, "at", ts.Format(time.RFC3339))
+			continue
+		}
+		nrts = append(nrts, *nrt)
+	}
+	fmt.Print(intnrt.ListToString(nrts, " at "+ts.Format(time.RFC3339)))
+	return nil
+}
@@ -0,0 +1,282 @@
+/*
+ * Copyright 2024 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	nrtv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	nropv1 "github.com/openshift-kni/numaresources-operator/api/numaresourcesoperator/v1"
+	"github.com/openshift-kni/numaresources-operator/internal/schedsim"
+	"github.com/openshift-kni/numaresources-operator/pkg/objectnames"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+var (
+	scheme = runtime.NewScheme()
+)
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(nrtv1alpha2.AddToScheme(scheme))
+	utilruntime.Must(nropv1.AddToScheme(scheme))
+}
+
+// objects are the objects the simulation needs, read from files or from the cluster
+type objects struct {
+	nrts   []nrtv1alpha2.NodeResourceTopology
+	pods   []corev1.Pod
+	scheds []nropv1.NUMAResourcesScheduler
+}
+
+func main() {
+	nrtsPath := flag.String("nrts", "", "read the NRT objects from this file or directory, like a must-gather. Defaults to the NRT objects of the cluster.")
+	podsPath := flag.String("pods", "", "read the pods to schedule, in order, from this file or directory")
+	schedPath := flag.String("scheduler", "", "read the NUMAResourcesScheduler object from this file. Defaults to the object of the cluster, if any, otherwise to the defaults.")
+	strategy := flag.String("scoring-strategy", "", "override the scoring strategy: MostAllocated, BalancedAllocation or LeastAllocated")
+	output := flag.String("output", outputTable, "output format: \"table\" or \"json\"")
+	flag.Parse()
+
+	if *podsPath == "" {
+		fmt.Fprintf(os.Stderr, "missing --pods\n")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *output != outputTable && *output != outputJSON {
+		fmt.Fprintf(os.Stderr, "unsupported output format %q\n", *output)
+		os.Exit(1)
+	}
+
+	podObjs, err := loadObjects(*podsPath)
+	if err != nil {
+		klog.ErrorS(err, "loading the pods", "path", *podsPath)
+		os.Exit(1)
+	}
+	var nrts []nrtv1alpha2.NodeResourceTopology
+	var sched *nropv1.NUMAResourcesScheduler
+	if *nrtsPath != "" {
+		nrtObjs, err := loadObjects(*nrtsPath)
+		if err != nil {
+			klog.ErrorS(err, "loading the NRT objects", "path", *nrtsPath)
+			os.Exit(1)
+		}
+		nrts = nrtObjs.nrts
+	}
+	if *schedPath != "" {
+		schedObjs, err := loadObjects(*schedPath)
+		if err != nil || len(schedObjs.scheds) != 1 {
+			klog.ErrorS(err, "loading the NUMAResourcesScheduler object", "path", *schedPath, "found", len(schedObjs.scheds))
+			os.Exit(1)
+		}
+		sched = &schedObjs.scheds[0]
+	}
+
+	if *nrtsPath == "" || (*schedPath == "" && *strategy == "") {
+		nrts, sched, err = fromCluster(nrts, sched)
+		if err != nil {
+			klog.ErrorS(err, "reading the cluster objects")
+			os.Exit(1)
+		}
+	}
+
+	spec := nropv1.NUMAResourcesSchedulerSpec{}
+	if sched != nil {
+		spec = sched.Spec
+	}
+	if *strategy != "" {
+		spec.ScoringStrategy = &nropv1.ScoringStrategyParams{
+			Type: nropv1.ScoringStrategyType(*strategy),
+		}
+		if sched != nil && sched.Spec.ScoringStrategy != nil {
+			spec.ScoringStrategy.Resources = sched.Spec.ScoringStrategy.Resources
+		}
+	}
+	cfg := schedsim.ConfigFromSpec(spec)
+	klog.V(2).InfoS("simulating", "scoringStrategy", cfg.ScoringStrategy, "weights", cfg.Weights, "nodes", len(nrts), "pods", len(podObjs.pods))
+
+	placements := schedsim.New(cfg, nrts).Run(podObjs.pods)
+	if *output == outputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(placements)
+	} else {
+		err = writeTable(os.Stdout, placements)
+	}
+	if err != nil {
+		klog.ErrorS(err, "writing the placements")
+		os.Exit(1)
+	}
+}
+
+// fromCluster fills the NRT objects and the NUMAResourcesScheduler object not provided with the ones of the cluster
+func fromCluster(nrts []nrtv1alpha2.NodeResourceTopology, sched *nropv1.NUMAResourcesScheduler) ([]nrtv1alpha2.NodeResourceTopology, *nropv1.NUMAResourcesScheduler, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nrts, sched, err
+	}
+	cli, err := client.New(cfg, client.Options{
+		Scheme: scheme,
+	})
+	if err != nil {
+		return nrts, sched, err
+	}
+
+	ctx := context.Background()
+	if nrts == nil {
+		nrtList := nrtv1alpha2.NodeResourceTopologyList{}
+		if err := cli.List(ctx, &nrtList); err != nil {
+			return nrts, sched, err
+		}
+		nrts = nrtList.Items
+	}
+	if sched == nil {
+		nroSched := nropv1.NUMAResourcesScheduler{}
+		err := cli.Get(ctx, client.ObjectKey{Name: objectnames.DefaultNUMAResourcesSchedulerCrName}, &nroSched)
+		if err == nil {
+			sched = &nroSched
+		} else if !apierrors.IsNotFound(err) {
+			return nrts, sched, err
+		}
+	}
+	return nrts, sched, nil
+}
+
+// loadObjects decodes the objects found in the YAML or JSON files at path, which can be a directory
+func loadObjects(path string) (objects, error) {
+	var objs objects
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch filepath.Ext(p) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		return objs.loadFile(p)
+	})
+	return objs, err
+}
+
+func (objs *objects) loadFile(path string) error {
+	fh, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+	reader := k8syaml.NewYAMLReader(bufio.NewReader(fh))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		obj, _, err := decoder.Decode(doc, nil, nil)
+		if err != nil {
+			// files like the must-gather index are not objects
+			klog.V(4).InfoS("skipping undecodable document", "path", path, "error", err)
+			continue
+		}
+		if err := objs.add(decoder, obj); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+}
+
+func (objs *objects) add(decoder runtime.Decoder, obj runtime.Object) error {
+	switch o := obj.(type) {
+	case *nrtv1alpha2.NodeResourceTopology:
+		objs.nrts = append(objs.nrts, *o)
+	case *nrtv1alpha2.NodeResourceTopologyList:
+		objs.nrts = append(objs.nrts, o.Items...)
+	case *corev1.Pod:
+		objs.pods = append(objs.pods, *o)
+	case *corev1.PodList:
+		objs.pods = append(objs.pods, o.Items...)
+	case *nropv1.NUMAResourcesScheduler:
+		objs.scheds = append(objs.scheds, *o)
+	case *corev1.List:
+		// like `oc get -o yaml` emits
+		for _, item := range o.Items {
+			itemObj, _, err := decoder.Decode(item.Raw, nil, nil)
+			if err != nil {
+				return err
+			}
+			if err := objs.add(decoder, itemObj); err != nil {
+				return err
+			}
+		}
+	default:
+		klog.V(4).InfoS("skipping object", "kind", obj.GetObjectKind().GroupVersionKind().Kind)
+	}
+	return nil
+}
+
+func writeTable(w io.Writer, placements []schedsim.Placement) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "POD\tNODE\tZONES\tSCORE\tREASON\n")
+	for _, pl := range placements {
+		node, zones := pl.Node, strings.Join(pl.Zones, ",")
+		if node == "" {
+			node = "-"
+		}
+		if zones == "" {
+			zones = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", pl.Pod, node, zones, pl.Score, pl.Reason)
+	}
+	return tw.Flush()
+}